@@ -2,28 +2,38 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
-	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/subscription-service/configs"
+	"github.com/subscription-service/internal/auth"
+	graphqlDelivery "github.com/subscription-service/internal/delivery/graphql"
+	grpcDelivery "github.com/subscription-service/internal/delivery/grpc"
 	httpDelivery "github.com/subscription-service/internal/delivery/http"
 	"github.com/subscription-service/internal/delivery/http/handler"
-	"github.com/subscription-service/internal/repository/postgresql"
+	"github.com/subscription-service/internal/health"
+	"github.com/subscription-service/internal/metrics"
+	"github.com/subscription-service/internal/outbox"
+	"github.com/subscription-service/internal/ratelimit"
+	"github.com/subscription-service/internal/retention"
+	"github.com/subscription-service/internal/tracing"
 	"github.com/subscription-service/internal/usecase"
+	"github.com/subscription-service/internal/webhook"
 )
 
+// serviceName - имя сервиса в трассировках
+const serviceName = "subscription-service"
+
 // @title Subscription Service API
 // @version 1.0
 // @description API for managing user subscriptions
@@ -31,6 +41,11 @@ import (
 // @host localhost:8080
 // @BasePath /api/v1
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description Ключ API; можно передать и как Authorization: Bearer <ключ>
+
 func main() {
 	// Инициализируем логгер
 	setupLogger()
@@ -44,29 +59,103 @@ func main() {
 	// Настраиваем логгер согласно конфигурации
 	setupLoggerFromConfig(config.Logger)
 
-	// Подключаемся к базе данных
-	db, err := setupDatabase(config.Database)
+	// Настраиваем трассировку OpenTelemetry
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: serviceName,
+		Exporter:    config.Tracing.Exporter,
+		Endpoint:    config.Tracing.Endpoint,
+		Insecure:    config.Tracing.Insecure,
+		SampleRatio: config.Tracing.SampleRatio,
+	})
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to connect to database")
+		log.Fatal().Err(err).Msg("Failed to configure tracing")
 	}
-	defer db.Close()
 
-	// Применяем миграции
-	if err := applyMigrations(db.DB, config.Database); err != nil {
-		log.Fatal().Err(err).Msg("Failed to apply migrations")
+	// Метрики Prometheus: пул соединений, запросы репозиториев, HTTP-запросы
+	// и число действующих подписок
+	registry := setupMetrics(config.Metrics)
+
+	// Проверки готовности; хранилище добавляет проверки базы данных
+	readiness := health.NewReadiness(config.Server.ReadinessTimeout)
+
+	// Пока база недоступна, запуск повторяет попытки; сигнал остановки
+	// прерывает ожидание
+	startupCtx, stopStartup := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+
+	// Инициализируем репозитории в хранилище из конфигурации
+	repos, err := setupRepositories(startupCtx, config, readiness, registry)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up storage")
 	}
+	defer repos.close()
+	stopStartup()
 
-	// Инициализируем репозиторий
-	subscriptionRepo := postgresql.NewSubscriptionRepository(db)
+	if registry != nil {
+		registry.MustRegister(metrics.NewSubscriptionCollector(repos.subscriptions))
+	}
+
+	// Инициализируем сервисы; события подписок и записи журнала аудита
+	// сохраняются в одной транзакции с изменением, а доступ к подпискам
+	// определяется ролью пользователя в организации
+	policy := usecase.NewPolicy(repos.members)
+	webhookService := usecase.NewWebhookService(repos.webhooks)
+	subscriptionService := usecase.NewSubscriptionService(repos.subscriptions,
+		usecase.WithEventPublisher(outbox.NewPublisher(repos.outbox)),
+		usecase.WithTransactionManager(repos.tx),
+		usecase.WithAuditLog(repos.audit),
+		usecase.WithPolicy(policy),
+	)
+	auditService := usecase.NewAuditService(repos.audit, repos.subscriptions, policy)
+	memberService := usecase.NewMemberService(repos.members, policy)
+	apiKeyService := usecase.NewAPIKeyService(repos.apiKeys, usecase.WithBootstrapKey(config.Auth.BootstrapKey))
+	if config.Auth.BootstrapKey != "" {
+		log.Warn().Msg("Bootstrap API key is enabled; issue personal keys and unset AUTH_BOOTSTRAP_KEY")
+	}
 
-	// Инициализируем сервис
-	subscriptionService := usecase.NewSubscriptionService(subscriptionRepo)
+	// Клиенты аутентифицируются ключами API и, если задан набор ключей JWKS,
+	// токенами JWT пользователей
+	authenticator, err := setupAuthenticator(config.Auth, apiKeyService)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to configure authentication")
+	}
 
 	// Инициализируем HTTP-обработчики
 	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	memberHandler := handler.NewMemberHandler(memberService)
+	healthHandler := handler.NewHealthHandler(readiness)
+	eventHandler := handler.NewEventHandler(repos.outbox, policy, handler.EventStreamConfig{
+		PollInterval: config.Events.PollInterval,
+		Heartbeat:    config.Events.Heartbeat,
+		BatchSize:    config.Events.BatchSize,
+	})
 
-	// Создаем маршрутизатор
-	router := httpDelivery.NewRouter(subscriptionHandler)
+	// Квоты частоты запросов по клиентам
+	limiter, err := setupRateLimiter(config.RateLimit)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to configure rate limiting")
+	}
+
+	// Инициализируем обработчик GraphQL
+	graphqlHandler, err := graphqlDelivery.NewHandler(subscriptionService, graphqlDelivery.Limits{
+		MaxDepth:      config.GraphQL.MaxDepth,
+		MaxComplexity: config.GraphQL.MaxComplexity,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to build GraphQL schema")
+	}
+
+	// Создаем маршрутизатор; все маршруты, кроме проверки здоровья,
+	// метрик и документации, требуют ключа API
+	router := httpDelivery.NewRouter(subscriptionHandler, webhookHandler, eventHandler, auditHandler, apiKeyHandler,
+		memberHandler, healthHandler, graphqlHandler, authenticator, limiter, registry)
+
+	// Контекст запросов отменяется при остановке сервера, чтобы потоки событий,
+	// которые сами не завершаются, не задерживали graceful shutdown
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
 
 	// Настраиваем HTTP-сервер
 	server := &http.Server{
@@ -75,7 +164,9 @@ func main() {
 		ReadTimeout:  config.Server.ReadTimeout,
 		WriteTimeout: config.Server.WriteTimeout,
 		IdleTimeout:  config.Server.IdleTimeout,
+		BaseContext:  func(net.Listener) context.Context { return baseCtx },
 	}
+	server.RegisterOnShutdown(cancelRequests)
 
 	// Запускаем сервер в отдельной горутине
 	go func() {
@@ -85,6 +176,87 @@ func main() {
 		}
 	}()
 
+	// Запускаем gRPC-сервер на отдельном порту
+	grpcServer := grpcDelivery.NewServer(subscriptionService, authenticator, limiter)
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", config.GRPC.Port))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to listen gRPC port")
+	}
+
+	go func() {
+		log.Info().Int("port", config.GRPC.Port).Msg("Starting gRPC server")
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatal().Err(err).Msg("Failed to start gRPC server")
+		}
+	}()
+
+	// Запускаем фоновые обработчики: пересылку событий из outbox, отправку
+	// webhook-уведомлений, очистку удаленных подписок и опубликованных событий
+	sinks, err := setupOutboxSinks(config.Outbox.Sinks, webhookService)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to configure outbox sinks")
+	}
+	relay := outbox.NewRelay(repos.outbox, outbox.Config{
+		PollInterval: config.Outbox.PollInterval,
+		BatchSize:    config.Outbox.BatchSize,
+		BackoffBase:  config.Outbox.BackoffBase,
+		BackoffMax:   config.Outbox.BackoffMax,
+		Lease:        config.Outbox.Lease,
+	}, sinks...)
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(2)
+
+	go func() {
+		defer workers.Done()
+		log.Info().Strs("sinks", config.Outbox.Sinks).Msg("Starting outbox relay")
+		relay.Run(workersCtx)
+	}()
+
+	dispatcher := webhook.NewDispatcher(repos.webhooks, webhook.Config{
+		PollInterval: config.Webhook.PollInterval,
+		BatchSize:    config.Webhook.BatchSize,
+		Timeout:      config.Webhook.Timeout,
+		MaxAttempts:  config.Webhook.MaxAttempts,
+		BackoffBase:  config.Webhook.BackoffBase,
+		BackoffMax:   config.Webhook.BackoffMax,
+		Lease:        config.Webhook.Lease,
+	})
+	go func() {
+		defer workers.Done()
+		log.Info().Msg("Starting webhook dispatcher")
+		dispatcher.Run(workersCtx)
+	}()
+
+	if config.Retention.DeletedRetention > 0 {
+		purger := retention.NewPurger(repos.subscriptions, retention.Config{
+			Interval:  config.Retention.PurgeInterval,
+			Retention: config.Retention.DeletedRetention,
+			BatchSize: config.Retention.BatchSize,
+		})
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			log.Info().Dur("retention", config.Retention.DeletedRetention).Msg("Starting deleted subscriptions purger")
+			purger.Run(workersCtx)
+		}()
+	}
+
+	if config.Retention.OutboxRetention > 0 {
+		outboxPurger := retention.NewOutboxPurger(repos.outbox, retention.Config{
+			Interval:  config.Retention.PurgeInterval,
+			Retention: config.Retention.OutboxRetention,
+			BatchSize: config.Retention.BatchSize,
+		})
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			log.Info().Dur("retention", config.Retention.OutboxRetention).Msg("Starting published outbox messages purger")
+			outboxPurger.Run(workersCtx)
+		}()
+	}
+
 	// Ждем сигнала для graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -93,20 +265,129 @@ func main() {
 	// Graceful shutdown
 	log.Info().Msg("Shutting down server...")
 
+	// Сначала сообщаем о неготовности, чтобы балансировщик перестал
+	// направлять новые запросы, пока сервер еще обслуживает текущие
+	readiness.Shutdown()
+	time.Sleep(config.Server.ShutdownDelay)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// gRPC-сервер дожидается завершения активных вызовов, но не дольше таймаута
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatal().Err(err).Msg("Server forced to shutdown")
 	}
 
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		log.Warn().Msg("gRPC server forced to stop")
+		grpcServer.Stop()
+	}
+
+	// Прерванные доставки и пересылки будут повторены после истечения аренды
+	stopWorkers()
+	workers.Wait()
+
+	// Отправляем накопленные span
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush traces")
+	}
+
 	log.Info().Msg("Server exited properly")
 }
 
+// setupOutboxSinks создает приемники событий outbox по именам из конфигурации
+func setupOutboxSinks(names []string, webhookService *usecase.WebhookService) ([]outbox.Sink, error) {
+	sinks := make([]outbox.Sink, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case "webhook":
+			sinks = append(sinks, outbox.NewPublisherSink(name, webhookService))
+		case "log":
+			sinks = append(sinks, outbox.NewLogSink())
+		default:
+			return nil, fmt.Errorf("unknown outbox sink %q", name)
+		}
+	}
+	return sinks, nil
+}
+
+// setupAuthenticator собирает способы аутентификации: ключи API и, если
+// задан набор ключей JWKS, токены JWT пользователей
+func setupAuthenticator(config configs.AuthConfig, apiKeys auth.Authenticator) (auth.Authenticator, error) {
+	if config.JWKS == "" {
+		return apiKeys, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	jwks, err := auth.LoadJWKS(ctx, config.JWKS, config.JWKSRefreshInterval)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Str("jwks", config.JWKS).Msg("JWT authentication enabled")
+
+	return auth.Chain(apiKeys, auth.NewJWTAuthenticator(jwks, auth.JWTConfig{
+		Issuer:   config.Issuer,
+		Audience: config.Audience,
+	})), nil
+}
+
+// setupRateLimiter создает ограничитель частоты запросов с квотами из
+// конфигурации; nil, если ограничение выключено
+func setupRateLimiter(config configs.RateLimitConfig) (*ratelimit.Limiter, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+
+	var store ratelimit.Store
+	switch config.Store {
+	case "memory":
+		store = ratelimit.NewMemoryStore()
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", config.Store)
+	}
+
+	limits := map[string]ratelimit.Limit{ratelimit.DefaultRoute: rateLimit(config.Default)}
+	for route, rule := range config.Routes {
+		limits[route] = rateLimit(rule)
+	}
+	for route, limit := range limits {
+		if !limit.Valid() {
+			return nil, fmt.Errorf("invalid rate limit for route %q", route)
+		}
+	}
+	return ratelimit.NewLimiter(store, limits), nil
+}
+
+// setupMetrics создает реестр метрик; nil, если метрики выключены
+func setupMetrics(config configs.MetricsConfig) *prometheus.Registry {
+	if !config.Enabled {
+		return nil
+	}
+	return metrics.NewRegistry()
+}
+
+// rateLimit преобразует квоту из конфигурации в квоту ограничителя
+func rateLimit(rule configs.RateLimitRule) ratelimit.Limit {
+	return ratelimit.Limit{Requests: rule.Requests, Period: rule.Period, Burst: rule.Burst}
+}
+
 // setupLogger настраивает базовый логгер
 func setupLogger() {
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stdout})
+
+	// Без логгера в контексте записи идут в глобальный логгер
+	zerolog.DefaultContextLogger = &log.Logger
 }
 
 // setupLoggerFromConfig настраивает логгер согласно конфигурации
@@ -132,53 +413,3 @@ func setupLoggerFromConfig(config configs.LoggerConfig) {
 		log.Logger = log.Output(os.Stdout)
 	}
 }
-
-// setupDatabase устанавливает соединение с базой данных
-func setupDatabase(config configs.DatabaseConfig) (*sqlx.DB, error) {
-	db, err := sqlx.Connect("postgres", config.DSN())
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
-	}
-
-	// Настраиваем пул соединений
-	db.SetMaxOpenConns(config.MaxOpenConns)
-	db.SetMaxIdleConns(config.MaxIdleConns)
-	db.SetConnMaxLifetime(config.ConnMaxLifetime)
-
-	// Проверяем соединение
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	log.Info().Msg("Connected to database")
-	return db, nil
-}
-
-// applyMigrations применяет миграции к базе данных
-func applyMigrations(db *sql.DB, config configs.DatabaseConfig) error {
-	log.Info().Str("path", config.MigrationsPath).Msg("Applying database migrations")
-
-	// Создаем экземпляр драйвера для migrate
-	driver, err := postgres.WithInstance(db, &postgres.Config{
-		MigrationsTable: config.MigrationsTable,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create migrations driver: %w", err)
-	}
-
-	// Создаем экземпляр migrate
-	m, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", config.MigrationsPath),
-		"postgres", driver)
-	if err != nil {
-		return fmt.Errorf("failed to create migrate instance: %w", err)
-	}
-
-	// Применяем миграции
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("failed to apply migrations: %w", err)
-	}
-
-	log.Info().Msg("Migrations applied successfully")
-	return nil
-}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/configs"
+	"github.com/subscription-service/internal/backoff"
+	"github.com/subscription-service/internal/domain/apikey"
+	"github.com/subscription-service/internal/domain/audit"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/member"
+	outboxDomain "github.com/subscription-service/internal/domain/outbox"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/domain/transaction"
+	webhookDomain "github.com/subscription-service/internal/domain/webhook"
+	"github.com/subscription-service/internal/health"
+	"github.com/subscription-service/internal/metrics"
+	"github.com/subscription-service/internal/migration"
+	"github.com/subscription-service/internal/repository/memory"
+	"github.com/subscription-service/internal/repository/postgresql"
+	"github.com/subscription-service/internal/repository/sqlite"
+)
+
+// subscriptionRepository - хранилище подписок, которое также считает
+// действующие подписки для метрик
+type subscriptionRepository interface {
+	subscription.Repository
+	metrics.ActiveCounter
+}
+
+// outboxRepository - outbox, который также служит журналом событий для потоков SSE
+type outboxRepository interface {
+	outboxDomain.Repository
+	event.Log
+}
+
+// repositories - репозитории сервиса в одном хранилище
+type repositories struct {
+	tx            transaction.Manager
+	subscriptions subscriptionRepository
+	webhooks      webhookDomain.Repository
+	outbox        outboxRepository
+	audit         audit.Repository
+	apiKeys       apikey.Repository
+	members       member.Repository
+	// close освобождает соединения хранилища
+	close func() error
+}
+
+// setupRepositories создает репозитории в хранилище database.driver.
+// Проверки готовности и метрики хранилища добавляются в readiness и
+// registry (nil, если метрики выключены)
+func setupRepositories(ctx context.Context, config *configs.Config, readiness *health.Readiness, registry *prometheus.Registry) (*repositories, error) {
+	switch config.Database.Driver {
+	case "postgres":
+		return setupPostgres(ctx, config, readiness, registry)
+	case "sqlite":
+		return setupSQLite(ctx, config, readiness, registry)
+	case "memory":
+		log.Warn().Msg("Using in-memory storage; data is not persisted and is lost on restart")
+		return memoryRepositories(), nil
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", config.Database.Driver)
+	}
+}
+
+// memoryRepositories создает репозитории в памяти процесса
+func memoryRepositories() *repositories {
+	return &repositories{
+		tx:            memory.NewTxManager(),
+		subscriptions: memory.NewSubscriptionRepository(),
+		webhooks:      memory.NewWebhookRepository(),
+		outbox:        memory.NewOutboxRepository(),
+		audit:         memory.NewAuditRepository(),
+		apiKeys:       memory.NewAPIKeyRepository(),
+		members:       memory.NewMemberRepository(),
+		close:         func() error { return nil },
+	}
+}
+
+// setupSQLite открывает базу SQLite, применяет встроенные в сервис миграции
+// и создает репозитории в этой базе
+func setupSQLite(ctx context.Context, config *configs.Config, readiness *health.Readiness, registry *prometheus.Registry) (*repositories, error) {
+	db, err := sqlite.Open(ctx, config.Database.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := sqlite.Migrate(db, config.Database.MigrationsTable); err != nil {
+		db.Close()
+		return nil, err
+	}
+	log.Info().Str("path", config.Database.Path).Msg("Opened SQLite database")
+
+	readiness.Add("database", health.DatabaseCheck(db))
+	if registry != nil {
+		registry.MustRegister(collectors.NewDBStatsCollector(db.DB, config.Database.Path))
+	}
+
+	return &repositories{
+		tx:            sqlite.NewTxManager(db),
+		subscriptions: sqlite.NewSubscriptionRepository(db),
+		webhooks:      sqlite.NewWebhookRepository(db),
+		outbox:        sqlite.NewOutboxRepository(db),
+		audit:         sqlite.NewAuditRepository(db),
+		apiKeys:       sqlite.NewAPIKeyRepository(db),
+		members:       sqlite.NewMemberRepository(db),
+		close:         db.Close,
+	}, nil
+}
+
+// setupPostgres подключается к PostgreSQL, применяет миграции и создает
+// репозитории. Готовность требует доступной базы и схемы, равной последней
+// миграции из каталога миграций
+func setupPostgres(ctx context.Context, config *configs.Config, readiness *health.Readiness, registry *prometheus.Registry) (*repositories, error) {
+	expected, err := migration.Latest(config.Database.MigrationsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	db, err := setupDatabase(ctx, config.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// Применяем миграции, если их не применяет отдельный шаг развертывания
+	// (cmd/migrate). Одновременно запущенные экземпляры ждут друг друга на
+	// блокировке миграций, неудачные попытки повторяются
+	if config.Database.AutoMigrate {
+		err = backoff.Retry(ctx, retryPolicy(config.Database), func(context.Context) error {
+			return applyMigrations(config.Database)
+		}, func(attempt int, err error, delay time.Duration) {
+			log.Warn().Err(err).Int("attempt", attempt).Dur("retry_in", delay).Msg("Failed to apply migrations, retrying")
+		})
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to apply migrations: %w", err)
+		}
+	} else {
+		log.Info().Msg("Automatic migrations are disabled")
+	}
+
+	readiness.Add("database", health.DatabaseCheck(db))
+	readiness.Add("migrations", health.MigrationCheck(func(ctx context.Context) (uint, bool, error) {
+		return postgresql.SchemaVersion(ctx, db, config.Database.MigrationsTable)
+	}, expected))
+
+	// Статистика пула соединений и длительность методов репозиториев
+	var opts []postgresql.Option
+	if registry != nil {
+		registry.MustRegister(collectors.NewDBStatsCollector(db.DB, config.Database.DBName))
+		opts = append(opts, postgresql.WithQueryObserver(metrics.NewQueryMetrics(registry)))
+	}
+
+	return &repositories{
+		tx:            postgresql.NewTxManager(db),
+		subscriptions: postgresql.NewSubscriptionRepository(db, opts...),
+		webhooks:      postgresql.NewWebhookRepository(db, opts...),
+		outbox:        postgresql.NewOutboxRepository(db, opts...),
+		audit:         postgresql.NewAuditRepository(db, opts...),
+		apiKeys:       postgresql.NewAPIKeyRepository(db, opts...),
+		members:       postgresql.NewMemberRepository(db, opts...),
+		close:         db.Close,
+	}, nil
+}
+
+// retryPolicy возвращает политику повторов подключения и миграций при запуске
+func retryPolicy(config configs.DatabaseConfig) backoff.Policy {
+	return backoff.Policy{
+		Attempts: config.RetryAttempts,
+		Base:     config.RetryBase,
+		Max:      config.RetryMax,
+	}
+}
+
+// setupDatabase устанавливает соединение с базой данных, повторяя попытки,
+// пока база недоступна
+func setupDatabase(ctx context.Context, config configs.DatabaseConfig) (*sqlx.DB, error) {
+	db, err := postgresql.Connect(ctx, postgresql.ConnectConfig{
+		DSN:     config.DSN(),
+		Timeout: config.ConnectTimeout,
+		Retry:   retryPolicy(config),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Настраиваем пул соединений
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+
+	log.Info().Msg("Connected to database")
+	return db, nil
+}
+
+// applyMigrations применяет миграции к базе данных
+func applyMigrations(config configs.DatabaseConfig) error {
+	log.Info().Str("path", config.MigrationsPath).Msg("Applying database migrations")
+
+	m, err := migration.Open(migrationConfig(config))
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil {
+		return err
+	}
+
+	log.Info().Msg("Migrations applied successfully")
+	return nil
+}
+
+// migrationConfig возвращает настройки миграций из конфигурации базы
+func migrationConfig(config configs.DatabaseConfig) migration.Config {
+	return migration.Config{
+		DSN:         config.DSN(),
+		Path:        config.MigrationsPath,
+		Table:       config.MigrationsTable,
+		LockTimeout: config.MigrationsLockTimeout,
+	}
+}
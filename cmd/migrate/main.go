@@ -0,0 +1,189 @@
+// Команда migrate управляет миграциями схемы базы данных сервиса и
+// используется, когда миграции применяются отдельным шагом развертывания
+// (database.auto_migrate: false)
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/configs"
+	"github.com/subscription-service/internal/backoff"
+	"github.com/subscription-service/internal/migration"
+)
+
+const usage = `Usage: migrate [-config DIR] COMMAND [ARG]
+
+Commands:
+  up           apply all pending migrations
+  down N       roll back the last N migrations
+  goto V       migrate up or down to version V
+  status       show the schema version and pending migrations
+  force V      set version V without running migrations (after a failed migration)
+  create NAME  create empty up and down files for a new migration
+`
+
+func main() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	configPath := flag.String("config", "", "directory with config.yaml")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	config, err := configs.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load config")
+	}
+
+	if err := run(config.Database, flag.Arg(0), flag.Args()[1:]); err != nil {
+		var usageErr usageError
+		if errors.As(err, &usageErr) {
+			fmt.Fprintf(os.Stderr, "%v\n\n", err)
+			flag.Usage()
+			os.Exit(2)
+		}
+		log.Fatal().Err(err).Msg("Migration command failed")
+	}
+}
+
+// usageError - неверные аргументы команды
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// run выполняет команду command с аргументами args
+func run(config configs.DatabaseConfig, command string, args []string) error {
+	// n - числовой аргумент down, goto и force
+	var n int
+	switch command {
+	case "create":
+		name, err := argument(command, args)
+		if err != nil {
+			return err
+		}
+		paths, err := migration.Create(config.MigrationsPath, name)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			fmt.Println(path)
+		}
+		return nil
+	case "up", "status":
+		if len(args) != 0 {
+			return usageError(fmt.Sprintf("%s takes no arguments", command))
+		}
+	case "down", "goto", "force":
+		arg, err := argument(command, args)
+		if err != nil {
+			return err
+		}
+		if n, err = strconv.Atoi(arg); err != nil || (command == "goto" && n < 0) {
+			return usageError(fmt.Sprintf("%s: invalid number %q", command, arg))
+		}
+	default:
+		return usageError(fmt.Sprintf("unknown command %q", command))
+	}
+
+	m, err := open(config)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := execute(m, command, n); err != nil {
+		return err
+	}
+
+	// После любой команды выводим состояние схемы
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	printStatus(status)
+	return nil
+}
+
+// execute выполняет команду, изменяющую схему; status ничего не меняет
+func execute(m *migration.Migrator, command string, n int) error {
+	switch command {
+	case "up":
+		return m.Up()
+	case "down":
+		return m.Down(n)
+	case "goto":
+		return m.Goto(uint(n))
+	case "force":
+		return m.Force(n)
+	}
+	return nil
+}
+
+// argument возвращает единственный аргумент команды
+func argument(command string, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", usageError(fmt.Sprintf("%s takes exactly one argument", command))
+	}
+	return args[0], nil
+}
+
+// open подключается к базе, повторяя попытки, пока она недоступна;
+// сигнал остановки прерывает ожидание
+func open(config configs.DatabaseConfig) (*migration.Migrator, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var m *migration.Migrator
+	policy := backoff.Policy{
+		Attempts: config.RetryAttempts,
+		Base:     config.RetryBase,
+		Max:      config.RetryMax,
+	}
+	err := backoff.Retry(ctx, policy, func(context.Context) error {
+		var err error
+		m, err = migration.Open(migration.Config{
+			DSN:         config.DSN(),
+			Path:        config.MigrationsPath,
+			Table:       config.MigrationsTable,
+			LockTimeout: config.MigrationsLockTimeout,
+		})
+		return err
+	}, func(attempt int, err error, delay time.Duration) {
+		log.Warn().Err(err).Int("attempt", attempt).Dur("retry_in", delay).Msg("Database is not available, retrying")
+	})
+	return m, err
+}
+
+// printStatus выводит состояние схемы
+func printStatus(status migration.Status) {
+	fmt.Printf("version: %d\n", status.Version)
+	fmt.Printf("dirty:   %t\n", status.Dirty)
+	fmt.Printf("latest:  %d\n", status.Latest)
+
+	pending := make([]string, len(status.Pending))
+	for i, v := range status.Pending {
+		pending[i] = strconv.FormatUint(uint64(v), 10)
+	}
+	if len(pending) == 0 {
+		pending = []string{"none"}
+	}
+	fmt.Printf("pending: %s\n", strings.Join(pending, ", "))
+}
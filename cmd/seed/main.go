@@ -0,0 +1,148 @@
+// Команда seed заполняет базу воспроизводимым набором подписок для
+// демо-стендов и нагрузочных тестов. Подписки сохраняются через репозиторий
+// одной транзакцией: набор загружается целиком или не загружается совсем
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/configs"
+	"github.com/subscription-service/internal/backoff"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/export"
+	"github.com/subscription-service/internal/repository/postgresql"
+	"github.com/subscription-service/internal/seed"
+	"github.com/subscription-service/internal/tenant"
+)
+
+const usage = `Usage: seed [flags]
+
+Generates subscriptions and saves them into the organization's data. The same
+-seed and flags, including -from and -to, produce the same dataset; only the
+subscription IDs and timestamps assigned by the database differ.
+
+Flags:
+`
+
+func main() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	config := seed.DefaultConfig(time.Now())
+	var (
+		configPath = flag.String("config", "", "directory with config.yaml")
+		org        = flag.String("org", tenant.Default.String(), "organization ID to seed")
+		from       = flag.String("from", subscription.FormatMonthYear(config.From), "first start month, MM-YYYY")
+		to         = flag.String("to", subscription.FormatMonthYear(config.To), "last start month, MM-YYYY")
+		dryRun     = flag.Bool("dry-run", false, "print the dataset as CSV instead of saving it")
+	)
+	flag.Int64Var(&config.Seed, "seed", config.Seed, "random seed")
+	flag.IntVar(&config.Users, "users", config.Users, "number of users")
+	flag.IntVar(&config.Subscriptions, "subscriptions", config.Subscriptions, "number of subscriptions")
+	flag.Float64Var(&config.TrialRate, "trial-rate", config.TrialRate, "share of subscriptions starting with a trial month")
+	flag.Float64Var(&config.ChurnRate, "churn-rate", config.ChurnRate, "monthly probability of cancellation")
+	flag.Float64Var(&config.PriceChangeRate, "price-change-rate", config.PriceChangeRate, "probability of a price increase at each yearly renewal")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	organization, err := uuid.Parse(*org)
+	if err != nil {
+		usageFatal(fmt.Errorf("invalid -org: %w", err))
+	}
+	if config.From, err = subscription.ParseMonthYear(*from); err != nil {
+		usageFatal(fmt.Errorf("invalid -from: %w", err))
+	}
+	if config.To, err = subscription.ParseMonthYear(*to); err != nil {
+		usageFatal(fmt.Errorf("invalid -to: %w", err))
+	}
+
+	subs, err := seed.Generate(config)
+	if err != nil {
+		usageFatal(err)
+	}
+
+	if *dryRun {
+		if err := printCSV(subs); err != nil {
+			log.Fatal().Err(err).Msg("Failed to print dataset")
+		}
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	appConfig, err := configs.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load config")
+	}
+	if err := save(tenant.WithOrganization(ctx, organization), appConfig.Database, subs); err != nil {
+		stop()
+		log.Fatal().Err(err).Msg("Failed to seed database")
+	}
+	log.Info().
+		Int("subscriptions", len(subs)).
+		Int64("seed", config.Seed).
+		Str("organization", organization.String()).
+		Msg("Database seeded")
+}
+
+// usageFatal выводит ошибку аргументов и справку и завершает работу с кодом 2
+func usageFatal(err error) {
+	fmt.Fprintf(os.Stderr, "%v\n\n", err)
+	flag.Usage()
+	os.Exit(2)
+}
+
+// save подключается к базе, повторяя попытки, пока она недоступна, и
+// сохраняет подписки одной транзакцией
+func save(ctx context.Context, config configs.DatabaseConfig, subs []*subscription.Subscription) error {
+	db, err := postgresql.Connect(ctx, postgresql.ConnectConfig{
+		DSN:     config.DSN(),
+		Timeout: config.ConnectTimeout,
+		Retry: backoff.Policy{
+			Attempts: config.RetryAttempts,
+			Base:     config.RetryBase,
+			Max:      config.RetryMax,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	repo := postgresql.NewSubscriptionRepository(db)
+	return postgresql.NewTxManager(db).WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := seed.Load(ctx, repo, subs)
+		return err
+	})
+}
+
+// printCSV выводит набор в формате выгрузки CSV
+func printCSV(subs []*subscription.Subscription) error {
+	w, err := export.NewWriter(export.FormatCSV, os.Stdout)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if err := w.Write(sub); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
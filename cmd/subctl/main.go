@@ -0,0 +1,62 @@
+// Команда subctl управляет подписками через HTTP API сервиса или, с флагом
+// --direct, напрямую через базу данных
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/configs"
+	"github.com/subscription-service/internal/backoff"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/outbox"
+	"github.com/subscription-service/internal/repository/postgresql"
+	"github.com/subscription-service/internal/subctl"
+	"github.com/subscription-service/internal/usecase"
+)
+
+func main() {
+	// Логи сервиса не должны смешиваться с выводом команд
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := subctl.NewRootCommand(openDirect).ExecuteContext(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		stop()
+		os.Exit(1)
+	}
+}
+
+// openDirect создает сервис подписок так же, как сервер: изменения
+// сохраняются вместе с событиями outbox и записями журнала аудита
+func openDirect(ctx context.Context, configPath string) (subscription.Service, func() error, error) {
+	config, err := configs.LoadConfig(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	db, err := postgresql.Connect(ctx, postgresql.ConnectConfig{
+		DSN:     config.Database.DSN(),
+		Timeout: config.Database.ConnectTimeout,
+		Retry:   backoff.Policy{Attempts: 1},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	service := usecase.NewSubscriptionService(postgresql.NewSubscriptionRepository(db),
+		usecase.WithEventPublisher(outbox.NewPublisher(postgresql.NewOutboxRepository(db))),
+		usecase.WithTransactionManager(postgresql.NewTxManager(db)),
+		usecase.WithAuditLog(postgresql.NewAuditRepository(db)),
+		usecase.WithPolicy(usecase.NewPolicy(postgresql.NewMemberRepository(db))),
+	)
+	return service, db.Close, nil
+}
@@ -12,9 +12,19 @@ import (
 
 // Config хранит все настройки приложения
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Logger   LoggerConfig
+	Server    ServerConfig
+	GRPC      GRPCConfig
+	GraphQL   GraphQLConfig
+	Webhook   WebhookConfig
+	Outbox    OutboxConfig
+	Events    EventsConfig
+	Retention RetentionConfig
+	Auth      AuthConfig
+	RateLimit RateLimitConfig
+	Metrics   MetricsConfig
+	Tracing   TracingConfig
+	Database  DatabaseConfig
+	Logger    LoggerConfig
 }
 
 // ServerConfig хранит настройки HTTP-сервера
@@ -23,10 +33,122 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// ReadinessTimeout ограничивает время проверок готовности /readyz
+	ReadinessTimeout time.Duration
+	// ShutdownDelay - пауза между переходом в неготовое состояние и
+	// остановкой сервера, за которую балансировщик убирает сервис из ротации
+	ShutdownDelay time.Duration
+}
+
+// GRPCConfig хранит настройки gRPC-сервера
+type GRPCConfig struct {
+	Port int
+}
+
+// GraphQLConfig хранит ограничения запросов GraphQL
+type GraphQLConfig struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// WebhookConfig хранит настройки отправки webhook-уведомлений
+type WebhookConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	Timeout      time.Duration
+	MaxAttempts  int
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	Lease        time.Duration
+}
+
+// OutboxConfig хранит настройки пересылки событий из outbox
+type OutboxConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	Lease        time.Duration
+	// Sinks - приемники событий: webhook, log
+	Sinks []string
+}
+
+// EventsConfig хранит настройки потока событий Server-Sent Events
+type EventsConfig struct {
+	PollInterval time.Duration
+	Heartbeat    time.Duration
+	BatchSize    int
+}
+
+// RetentionConfig хранит настройки очистки удаленных подписок
+type RetentionConfig struct {
+	// DeletedRetention - срок, в течение которого удаленную подписку можно
+	// восстановить; 0 отключает очистку
+	DeletedRetention time.Duration
+	// OutboxRetention - срок хранения опубликованных событий outbox, в течение
+	// которого поток событий можно возобновить; 0 отключает очистку
+	OutboxRetention time.Duration
+	PurgeInterval   time.Duration
+	BatchSize       int
+}
+
+// AuthConfig хранит настройки аутентификации
+type AuthConfig struct {
+	// BootstrapKey - начальный ключ с правом admin для выпуска первых ключей
+	// API; пустое значение отключает его. Не попадает в лог конфигурации
+	BootstrapKey string `json:"-"`
+	// JWKS - файл или URL набора ключей для проверки токенов JWT
+	// пользователей; пустое значение отключает аутентификацию по JWT
+	JWKS string
+	// JWKSRefreshInterval - минимальный период перечитывания набора по URL
+	JWKSRefreshInterval time.Duration
+	Issuer              string
+	Audience            string
+}
+
+// RateLimitConfig хранит квоты частоты запросов к HTTP и gRPC API
+type RateLimitConfig struct {
+	Enabled bool
+	// Store - хранилище квот: memory
+	Store string
+	// Default - квота клиента на все маршруты API
+	Default RateLimitRule
+	// Routes - дополнительные квоты маршрутов: calculate-cost, export, graphql,
+	// а также ip - квота IP-адреса до аутентификации
+	Routes map[string]RateLimitRule
+}
+
+// RateLimitRule - квота корзины токенов: Requests запросов за Period и
+// всплеск до Burst запросов подряд (0 - равен Requests)
+type RateLimitRule struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// MetricsConfig хранит настройки метрик Prometheus
+type MetricsConfig struct {
+	Enabled bool
+}
+
+// TracingConfig хранит настройки трассировки OpenTelemetry
+type TracingConfig struct {
+	// Exporter - экспортер span: none, stdout, otlp
+	Exporter string
+	// Endpoint - адрес коллектора OTLP/gRPC
+	Endpoint string
+	Insecure bool
+	// SampleRatio - доля сохраняемых трассировок, начатых сервисом
+	SampleRatio float64
 }
 
 // DatabaseConfig хранит настройки базы данных
 type DatabaseConfig struct {
+	// Driver - хранилище данных: postgres, sqlite, memory. memory хранит
+	// данные в памяти процесса и предназначен для разработки и демо-стендов
+	Driver string
+	// Path - файл базы SQLite для database.driver: sqlite
+	Path            string
 	Host            string
 	Port            int
 	User            string
@@ -36,8 +158,22 @@ type DatabaseConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+	// ConnectTimeout ограничивает одну попытку подключения при запуске
+	ConnectTimeout time.Duration
+	// RetryAttempts - число попыток подключения и применения миграций при
+	// запуске (0 - без ограничения); задержка между ними растет от
+	// RetryBase до RetryMax
+	RetryAttempts   int
+	RetryBase       time.Duration
+	RetryMax        time.Duration
 	MigrationsPath  string
 	MigrationsTable string
+	// AutoMigrate применяет миграции при запуске сервера. Отключается, если
+	// миграции применяются отдельным шагом развертывания (cmd/migrate)
+	AutoMigrate bool
+	// MigrationsLockTimeout - сколько ждать блокировки миграций, которую
+	// держит другой экземпляр сервиса
+	MigrationsLockTimeout time.Duration
 }
 
 // LoggerConfig хранит настройки логгера
@@ -79,15 +215,86 @@ func LoadConfig(configPath string) (*Config, error) {
 		log.Info().Str("file", viper.ConfigFileUsed()).Msg("Using config file")
 	}
 
+	// Квоты маршрутов задаются словарем и разбираются отдельно
+	var rateLimitRoutes map[string]RateLimitRule
+	if err := viper.UnmarshalKey("rate_limit.routes", &rateLimitRoutes); err != nil {
+		return nil, fmt.Errorf("error parsing rate limit routes: %w", err)
+	}
+
 	// Парсим конфигурацию
 	config := &Config{
 		Server: ServerConfig{
-			Port:         viper.GetInt("server.port"),
-			ReadTimeout:  viper.GetDuration("server.read_timeout"),
-			WriteTimeout: viper.GetDuration("server.write_timeout"),
-			IdleTimeout:  viper.GetDuration("server.idle_timeout"),
+			Port:             viper.GetInt("server.port"),
+			ReadTimeout:      viper.GetDuration("server.read_timeout"),
+			WriteTimeout:     viper.GetDuration("server.write_timeout"),
+			IdleTimeout:      viper.GetDuration("server.idle_timeout"),
+			ReadinessTimeout: viper.GetDuration("server.readiness_timeout"),
+			ShutdownDelay:    viper.GetDuration("server.shutdown_delay"),
+		},
+		GRPC: GRPCConfig{
+			Port: viper.GetInt("grpc.port"),
+		},
+		GraphQL: GraphQLConfig{
+			MaxDepth:      viper.GetInt("graphql.max_depth"),
+			MaxComplexity: viper.GetInt("graphql.max_complexity"),
+		},
+		Webhook: WebhookConfig{
+			PollInterval: viper.GetDuration("webhook.poll_interval"),
+			BatchSize:    viper.GetInt("webhook.batch_size"),
+			Timeout:      viper.GetDuration("webhook.timeout"),
+			MaxAttempts:  viper.GetInt("webhook.max_attempts"),
+			BackoffBase:  viper.GetDuration("webhook.backoff_base"),
+			BackoffMax:   viper.GetDuration("webhook.backoff_max"),
+			Lease:        viper.GetDuration("webhook.lease"),
+		},
+		Outbox: OutboxConfig{
+			PollInterval: viper.GetDuration("outbox.poll_interval"),
+			BatchSize:    viper.GetInt("outbox.batch_size"),
+			BackoffBase:  viper.GetDuration("outbox.backoff_base"),
+			BackoffMax:   viper.GetDuration("outbox.backoff_max"),
+			Lease:        viper.GetDuration("outbox.lease"),
+			Sinks:        viper.GetStringSlice("outbox.sinks"),
+		},
+		Events: EventsConfig{
+			PollInterval: viper.GetDuration("events.poll_interval"),
+			Heartbeat:    viper.GetDuration("events.heartbeat"),
+			BatchSize:    viper.GetInt("events.batch_size"),
+		},
+		Retention: RetentionConfig{
+			DeletedRetention: viper.GetDuration("retention.deleted_retention"),
+			OutboxRetention:  viper.GetDuration("retention.outbox_retention"),
+			PurgeInterval:    viper.GetDuration("retention.purge_interval"),
+			BatchSize:        viper.GetInt("retention.batch_size"),
+		},
+		Auth: AuthConfig{
+			BootstrapKey:        viper.GetString("auth.bootstrap_key"),
+			JWKS:                viper.GetString("auth.jwks"),
+			JWKSRefreshInterval: viper.GetDuration("auth.jwks_refresh_interval"),
+			Issuer:              viper.GetString("auth.issuer"),
+			Audience:            viper.GetString("auth.audience"),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: viper.GetBool("rate_limit.enabled"),
+			Store:   viper.GetString("rate_limit.store"),
+			Default: RateLimitRule{
+				Requests: viper.GetInt("rate_limit.default.requests"),
+				Period:   viper.GetDuration("rate_limit.default.period"),
+				Burst:    viper.GetInt("rate_limit.default.burst"),
+			},
+			Routes: rateLimitRoutes,
+		},
+		Metrics: MetricsConfig{
+			Enabled: viper.GetBool("metrics.enabled"),
+		},
+		Tracing: TracingConfig{
+			Exporter:    viper.GetString("tracing.exporter"),
+			Endpoint:    viper.GetString("tracing.endpoint"),
+			Insecure:    viper.GetBool("tracing.insecure"),
+			SampleRatio: viper.GetFloat64("tracing.sample_ratio"),
 		},
 		Database: DatabaseConfig{
+			Driver:          viper.GetString("database.driver"),
+			Path:            viper.GetString("database.path"),
 			Host:            viper.GetString("database.host"),
 			Port:            viper.GetInt("database.port"),
 			User:            viper.GetString("database.user"),
@@ -97,8 +304,15 @@ func LoadConfig(configPath string) (*Config, error) {
 			MaxOpenConns:    viper.GetInt("database.max_open_conns"),
 			MaxIdleConns:    viper.GetInt("database.max_idle_conns"),
 			ConnMaxLifetime: viper.GetDuration("database.conn_max_lifetime"),
+			ConnectTimeout:  viper.GetDuration("database.connect_timeout"),
+			RetryAttempts:   viper.GetInt("database.retry_attempts"),
+			RetryBase:       viper.GetDuration("database.retry_base"),
+			RetryMax:        viper.GetDuration("database.retry_max"),
 			MigrationsPath:  viper.GetString("database.migrations_path"),
 			MigrationsTable: viper.GetString("database.migrations_table"),
+			AutoMigrate:     viper.GetBool("database.auto_migrate"),
+
+			MigrationsLockTimeout: viper.GetDuration("database.migrations_lock_timeout"),
 		},
 		Logger: LoggerConfig{
 			Level:  viper.GetString("logger.level"),
@@ -135,8 +349,76 @@ func setDefaults() {
 	viper.SetDefault("server.read_timeout", "15s")
 	viper.SetDefault("server.write_timeout", "15s")
 	viper.SetDefault("server.idle_timeout", "60s")
+	viper.SetDefault("server.readiness_timeout", "2s")
+	viper.SetDefault("server.shutdown_delay", "5s")
+
+	// Настройки gRPC-сервера
+	viper.SetDefault("grpc.port", 9090)
+
+	// Ограничения GraphQL
+	viper.SetDefault("graphql.max_depth", 8)
+	viper.SetDefault("graphql.max_complexity", 10000)
+
+	// Настройки webhook-уведомлений
+	viper.SetDefault("webhook.poll_interval", "1s")
+	viper.SetDefault("webhook.batch_size", 50)
+	viper.SetDefault("webhook.timeout", "10s")
+	viper.SetDefault("webhook.max_attempts", 8)
+	viper.SetDefault("webhook.backoff_base", "30s")
+	viper.SetDefault("webhook.backoff_max", "1h")
+	viper.SetDefault("webhook.lease", "1m")
+
+	// Настройки outbox
+	viper.SetDefault("outbox.poll_interval", "500ms")
+	viper.SetDefault("outbox.batch_size", 100)
+	viper.SetDefault("outbox.backoff_base", "1s")
+	viper.SetDefault("outbox.backoff_max", "5m")
+	viper.SetDefault("outbox.lease", "30s")
+	viper.SetDefault("outbox.sinks", []string{"webhook"})
+
+	// Настройки потока событий
+	viper.SetDefault("events.poll_interval", "1s")
+	viper.SetDefault("events.heartbeat", "15s")
+	viper.SetDefault("events.batch_size", 100)
+
+	// Настройки очистки удаленных подписок
+	viper.SetDefault("retention.deleted_retention", "720h")
+	viper.SetDefault("retention.outbox_retention", "168h")
+	viper.SetDefault("retention.purge_interval", "1h")
+	viper.SetDefault("retention.batch_size", 500)
+
+	// Настройки аутентификации
+	viper.SetDefault("auth.bootstrap_key", "")
+	viper.SetDefault("auth.jwks", "")
+	viper.SetDefault("auth.jwks_refresh_interval", "5m")
+	viper.SetDefault("auth.issuer", "")
+	viper.SetDefault("auth.audience", "")
+
+	// Квоты частоты запросов
+	viper.SetDefault("rate_limit.enabled", true)
+	viper.SetDefault("rate_limit.store", "memory")
+	viper.SetDefault("rate_limit.default.requests", 600)
+	viper.SetDefault("rate_limit.default.period", "1m")
+	viper.SetDefault("rate_limit.default.burst", 100)
+	viper.SetDefault("rate_limit.routes", map[string]interface{}{
+		"ip":             map[string]interface{}{"requests": 1200, "period": "1m", "burst": 200},
+		"calculate-cost": map[string]interface{}{"requests": 30, "period": "1m", "burst": 5},
+		"export":         map[string]interface{}{"requests": 10, "period": "1m", "burst": 2},
+		"graphql":        map[string]interface{}{"requests": 120, "period": "1m", "burst": 20},
+	})
+
+	// Метрики Prometheus
+	viper.SetDefault("metrics.enabled", true)
+
+	// Трассировка OpenTelemetry
+	viper.SetDefault("tracing.exporter", "none")
+	viper.SetDefault("tracing.endpoint", "localhost:4317")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.sample_ratio", 1.0)
 
 	// Настройки базы данных
+	viper.SetDefault("database.driver", "postgres")
+	viper.SetDefault("database.path", "subscriptions.db")
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
 	viper.SetDefault("database.user", "postgres")
@@ -146,8 +428,14 @@ func setDefaults() {
 	viper.SetDefault("database.max_open_conns", 20)
 	viper.SetDefault("database.max_idle_conns", 5)
 	viper.SetDefault("database.conn_max_lifetime", "5m")
+	viper.SetDefault("database.connect_timeout", "5s")
+	viper.SetDefault("database.retry_attempts", 10)
+	viper.SetDefault("database.retry_base", "500ms")
+	viper.SetDefault("database.retry_max", "10s")
 	viper.SetDefault("database.migrations_path", "./migrations")
 	viper.SetDefault("database.migrations_table", "schema_migrations")
+	viper.SetDefault("database.auto_migrate", true)
+	viper.SetDefault("database.migrations_lock_timeout", "1m")
 
 	// Настройки логгера
 	viper.SetDefault("logger.level", "info")
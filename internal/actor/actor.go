@@ -0,0 +1,26 @@
+package actor
+
+import "context"
+
+// Header - имя заголовка HTTP (и ключа метаданных gRPC) с идентификатором
+// пользователя или системы, выполняющей запрос
+const Header = "X-Actor"
+
+// Anonymous - исполнитель запроса, не передавшего идентификатор
+const Anonymous = "anonymous"
+
+// actorKey - ключ контекста для исполнителя запроса
+type actorKey struct{}
+
+// WithActor сохраняет исполнителя запроса в контексте
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// FromContext возвращает исполнителя запроса из контекста или Anonymous
+func FromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return Anonymous
+}
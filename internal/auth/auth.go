@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// Scope - право доступа к группе операций API
+type Scope string
+
+// Права доступа
+const (
+	// ScopeSubscriptionsRead - чтение подписок, их истории и потока событий
+	ScopeSubscriptionsRead Scope = "subscriptions:read"
+	// ScopeSubscriptionsWrite - создание, изменение, удаление и восстановление подписок
+	ScopeSubscriptionsWrite Scope = "subscriptions:write"
+	// ScopeReportsRead - расчет стоимости и отчетные запросы GraphQL
+	ScopeReportsRead Scope = "reports:read"
+	// ScopeAdmin - управление ключами API, webhooks и журналом аудита;
+	// включает все остальные права
+	ScopeAdmin Scope = "admin"
+)
+
+// Scopes - все права доступа в порядке объявления
+var Scopes = []Scope{ScopeSubscriptionsRead, ScopeSubscriptionsWrite, ScopeReportsRead, ScopeAdmin}
+
+// UserScopes - права пользователя, в токене которого права не перечислены
+var UserScopes = []Scope{ScopeSubscriptionsRead, ScopeSubscriptionsWrite, ScopeReportsRead}
+
+// Valid проверяет, что право доступа известно
+func (s Scope) Valid() bool {
+	for _, known := range Scopes {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Ошибки аутентификации и авторизации
+var (
+	// ErrUnauthenticated возвращается, если учетные данные не переданы или неверны
+	ErrUnauthenticated = errors.New("unauthenticated")
+
+	// ErrForbidden возвращается, если у клиента нет нужного права доступа
+	// или он обращается к подпискам другого пользователя
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Principal - аутентифицированный клиент API
+type Principal struct {
+	// Subject идентифицирует клиента в журнале аудита и логах
+	Subject string
+	Scopes  []Scope
+	// UserID - пользователь, от имени которого действует клиент; uuid.Nil
+	// у ключей API, выпущенных для сервисов
+	UserID uuid.UUID
+	// OrganizationID - организация, к данным которой ограничен клиент; nil у
+	// клиентов уровня платформы, например начального ключа из конфигурации
+	OrganizationID *uuid.UUID
+}
+
+// Allows проверяет, есть ли у клиента право доступа. Право admin включает все остальные
+func (p *Principal) Allows(scope Scope) bool {
+	for _, granted := range p.Scopes {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// RestrictedTo возвращает пользователя, подписками которого ограничен
+// клиент. Ограничение действует для пользователей без права admin
+func (p *Principal) RestrictedTo() (uuid.UUID, bool) {
+	if p.UserID == uuid.Nil || p.Allows(ScopeAdmin) {
+		return uuid.Nil, false
+	}
+	return p.UserID, true
+}
+
+// Authenticator проверяет учетные данные, переданные клиентом
+type Authenticator interface {
+	// Authenticate возвращает клиента по токену или ErrUnauthenticated
+	Authenticate(ctx context.Context, token string) (*Principal, error)
+}
+
+// AuthenticatorFunc позволяет использовать функцию как Authenticator
+type AuthenticatorFunc func(ctx context.Context, token string) (*Principal, error)
+
+// Authenticate вызывает f(ctx, token)
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	return f(ctx, token)
+}
+
+// Chain возвращает Authenticator, проверяющий токен по очереди каждым из
+// authenticators. Токен отклоняется, только если его не принял ни один
+func Chain(authenticators ...Authenticator) Authenticator {
+	return chain(authenticators)
+}
+
+// chain - цепочка способов аутентификации
+type chain []Authenticator
+
+// Authenticate возвращает клиента, найденного первым способом, принявшим токен
+func (c chain) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(ctx, token)
+		if errors.Is(err, ErrUnauthenticated) {
+			continue
+		}
+		return principal, err
+	}
+	return nil, ErrUnauthenticated
+}
+
+// principalKey - ключ контекста для аутентифицированного клиента
+type principalKey struct{}
+
+// WithPrincipal сохраняет аутентифицированного клиента в контексте
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext возвращает аутентифицированного клиента из контекста
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
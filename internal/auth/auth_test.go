@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipal_Allows(t *testing.T) {
+	t.Run("только выданные права", func(t *testing.T) {
+		principal := &Principal{Scopes: []Scope{ScopeSubscriptionsRead}}
+		assert.True(t, principal.Allows(ScopeSubscriptionsRead))
+		assert.False(t, principal.Allows(ScopeSubscriptionsWrite))
+		assert.False(t, principal.Allows(ScopeAdmin))
+	})
+
+	t.Run("admin включает все права", func(t *testing.T) {
+		principal := &Principal{Scopes: []Scope{ScopeAdmin}}
+		for _, scope := range Scopes {
+			assert.True(t, principal.Allows(scope), scope)
+		}
+	})
+}
+
+func TestFromContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	principal := &Principal{Subject: "api-key:reports"}
+	got, ok := FromContext(WithPrincipal(context.Background(), principal))
+	assert.True(t, ok)
+	assert.Same(t, principal, got)
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// jwtLeeway - допустимое расхождение часов с издателем токенов
+const jwtLeeway = 30 * time.Second
+
+// JWTConfig хранит требования к токенам пользователей
+type JWTConfig struct {
+	// Issuer - ожидаемый издатель (claim iss); пустое значение не проверяется
+	Issuer string
+	// Audience - ожидаемый получатель (claim aud); пустое значение не проверяется
+	Audience string
+}
+
+// JWTAuthenticator аутентифицирует пользователей по токенам JWT, подписанным
+// ключами из набора JWKS алгоритмами RS256 или ES256. Subject токена должен
+// быть UUID пользователя, а claim org_id, если он есть, - UUID организации
+type JWTAuthenticator struct {
+	keys   *JWKS
+	parser *jwt.Parser
+}
+
+// NewJWTAuthenticator создает проверку токенов по набору ключей keys
+func NewJWTAuthenticator(keys *JWKS, config JWTConfig) *JWTAuthenticator {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(jwtLeeway),
+	}
+	if config.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		opts = append(opts, jwt.WithAudience(config.Audience))
+	}
+
+	return &JWTAuthenticator{keys: keys, parser: jwt.NewParser(opts...)}
+}
+
+// userClaims - claims токена пользователя
+type userClaims struct {
+	jwt.RegisteredClaims
+	// Scope - права через пробел, как в OAuth 2.0; без него пользователь
+	// получает UserScopes
+	Scope *string `json:"scope,omitempty"`
+	// OrganizationID - организация пользователя; без нее пользователь
+	// работает с организацией по умолчанию
+	OrganizationID string `json:"org_id,omitempty"`
+}
+
+// Authenticate возвращает пользователя по токену или ErrUnauthenticated
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	var claims userClaims
+	_, err := a.parser.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return a.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: subject is not a user ID", ErrUnauthenticated)
+	}
+
+	principal := &Principal{
+		Subject: "user:" + userID.String(),
+		Scopes:  claims.scopes(),
+		UserID:  userID,
+	}
+	if claims.OrganizationID != "" {
+		organizationID, err := uuid.Parse(claims.OrganizationID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: org_id is not an organization ID", ErrUnauthenticated)
+		}
+		principal.OrganizationID = &organizationID
+	}
+
+	return principal, nil
+}
+
+// scopes возвращает известные права из claim scope
+func (c userClaims) scopes() []Scope {
+	if c.Scope == nil {
+		return UserScopes
+	}
+
+	var scopes []Scope
+	for _, name := range strings.Fields(*c.Scope) {
+		if scope := Scope(name); scope.Valid() {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
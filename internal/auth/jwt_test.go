@@ -0,0 +1,234 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSigner - локально сгенерированный ключ подписи с ID
+type testSigner struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.Signer
+}
+
+func newRSASigner(t *testing.T, kid string) testSigner {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return testSigner{kid: kid, method: jwt.SigningMethodRS256, key: key}
+}
+
+func newECSigner(t *testing.T, kid string) testSigner {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return testSigner{kid: kid, method: jwt.SigningMethodES256, key: key}
+}
+
+// jwk возвращает открытую часть ключа в формате JWK
+func (s testSigner) jwk() map[string]string {
+	encode := func(n *big.Int, size int) string {
+		return base64.RawURLEncoding.EncodeToString(n.FillBytes(make([]byte, size)))
+	}
+	switch key := s.key.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]string{
+			"kty": "RSA", "kid": s.kid, "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		return map[string]string{
+			"kty": "EC", "kid": s.kid, "crv": "P-256",
+			"x": encode(key.X, 32), "y": encode(key.Y, 32),
+		}
+	}
+	panic("unsupported key")
+}
+
+// sign выпускает токен с claims
+func (s testSigner) sign(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(s.method, claims)
+	token.Header["kid"] = s.kid
+	signed, err := token.SignedString(s.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func jwksDocument(t *testing.T, signers ...testSigner) []byte {
+	keys := make([]map[string]string, 0, len(signers))
+	for _, signer := range signers {
+		keys = append(keys, signer.jwk())
+	}
+	data, err := json.Marshal(map[string]interface{}{"keys": keys})
+	require.NoError(t, err)
+	return data
+}
+
+func writeJWKS(t *testing.T, signers ...testSigner) string {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwksDocument(t, signers...), 0o600))
+	return path
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	ctx := context.Background()
+	rsaSigner := newRSASigner(t, "rsa-1")
+	ecSigner := newECSigner(t, "ec-1")
+
+	keys, err := LoadJWKS(ctx, writeJWKS(t, rsaSigner, ecSigner), time.Minute)
+	require.NoError(t, err)
+	authenticator := NewJWTAuthenticator(keys, JWTConfig{Issuer: "https://id.example.com", Audience: "subscriptions"})
+
+	userID := uuid.New()
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub": userID.String(),
+			"iss": "https://id.example.com",
+			"aud": "subscriptions",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	t.Run("RS256 и ES256", func(t *testing.T) {
+		for _, signer := range []testSigner{rsaSigner, ecSigner} {
+			principal, err := authenticator.Authenticate(ctx, signer.sign(t, validClaims()))
+			require.NoError(t, err, signer.kid)
+			assert.Equal(t, userID, principal.UserID)
+			assert.Equal(t, "user:"+userID.String(), principal.Subject)
+			assert.Equal(t, UserScopes, principal.Scopes)
+
+			restrictedTo, restricted := principal.RestrictedTo()
+			assert.True(t, restricted)
+			assert.Equal(t, userID, restrictedTo)
+		}
+	})
+
+	t.Run("права из claim scope", func(t *testing.T) {
+		claims := validClaims()
+		claims["scope"] = "subscriptions:read openid admin"
+		principal, err := authenticator.Authenticate(ctx, rsaSigner.sign(t, claims))
+		require.NoError(t, err)
+		assert.Equal(t, []Scope{ScopeSubscriptionsRead, ScopeAdmin}, principal.Scopes)
+
+		_, restricted := principal.RestrictedTo()
+		assert.False(t, restricted, "администратор не ограничен своими подписками")
+	})
+
+	t.Run("организация из claim org_id", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(ctx, rsaSigner.sign(t, validClaims()))
+		require.NoError(t, err)
+		assert.Nil(t, principal.OrganizationID)
+
+		organizationID := uuid.New()
+		principal, err = authenticator.Authenticate(ctx, rsaSigner.sign(t, with(validClaims(), "org_id", organizationID.String())))
+		require.NoError(t, err)
+		require.NotNil(t, principal.OrganizationID)
+		assert.Equal(t, organizationID, *principal.OrganizationID)
+	})
+
+	t.Run("отклоняются недействительные токены", func(t *testing.T) {
+		foreign := newRSASigner(t, "rsa-1")
+		cases := map[string]string{
+			"истек срок":        rsaSigner.sign(t, with(validClaims(), "exp", time.Now().Add(-time.Hour).Unix())),
+			"без срока":         rsaSigner.sign(t, with(validClaims(), "exp", nil)),
+			"другой издатель":   rsaSigner.sign(t, with(validClaims(), "iss", "https://evil.example.com")),
+			"другой получатель": rsaSigner.sign(t, with(validClaims(), "aud", "billing")),
+			"subject не UUID":   rsaSigner.sign(t, with(validClaims(), "sub", "alice")),
+			"org_id не UUID":    rsaSigner.sign(t, with(validClaims(), "org_id", "team-a")),
+			"чужая подпись":     foreign.sign(t, validClaims()),
+			"неизвестный ключ":  newRSASigner(t, "rsa-2").sign(t, validClaims()),
+			"HS256 с открытым ключом": func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims())
+				token.Header["kid"] = "rsa-1"
+				signed, err := token.SignedString([]byte(rsaSigner.jwk()["n"]))
+				require.NoError(t, err)
+				return signed
+			}(),
+			"не JWT": "sk_0123456789ab_secret",
+		}
+		for name, token := range cases {
+			_, err := authenticator.Authenticate(ctx, token)
+			assert.ErrorIs(t, err, ErrUnauthenticated, name)
+		}
+	})
+
+	t.Run("набор по URL перечитывается при смене ключа", func(t *testing.T) {
+		current := newECSigner(t, "ec-old")
+		var document atomic.Value
+		document.Store(jwksDocument(t, current))
+		var fetches atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fetches.Add(1)
+			_, _ = w.Write(document.Load().([]byte))
+		}))
+		defer server.Close()
+
+		keys, err := LoadJWKS(ctx, server.URL, 0)
+		require.NoError(t, err)
+		authenticator := NewJWTAuthenticator(keys, JWTConfig{})
+
+		claims := jwt.MapClaims{"sub": userID.String(), "exp": time.Now().Add(time.Hour).Unix()}
+		_, err = authenticator.Authenticate(ctx, current.sign(t, claims))
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), fetches.Load())
+
+		rotated := newECSigner(t, "ec-new")
+		document.Store(jwksDocument(t, rotated))
+		_, err = authenticator.Authenticate(ctx, rotated.sign(t, claims))
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), fetches.Load())
+	})
+}
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	reader := AuthenticatorFunc(func(_ context.Context, token string) (*Principal, error) {
+		if token != "reader" {
+			return nil, ErrUnauthenticated
+		}
+		return &Principal{Subject: "reader"}, nil
+	})
+	admin := AuthenticatorFunc(func(_ context.Context, token string) (*Principal, error) {
+		if token != "admin" {
+			return nil, ErrUnauthenticated
+		}
+		return &Principal{Subject: "admin"}, nil
+	})
+	chain := Chain(reader, admin)
+
+	principal, err := chain.Authenticate(ctx, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", principal.Subject)
+
+	_, err = chain.Authenticate(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+// with возвращает claims с замененным значением; nil удаляет claim
+func with(claims jwt.MapClaims, name string, value interface{}) jwt.MapClaims {
+	if value == nil {
+		delete(claims, name)
+	} else {
+		claims[name] = value
+	}
+	return claims
+}
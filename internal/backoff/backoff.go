@@ -0,0 +1,19 @@
+package backoff
+
+import "time"
+
+// Exponential возвращает задержку перед попыткой, следующей за attempt-й:
+// base·2^(attempt-1), но не больше max
+func Exponential(base, max time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
@@ -0,0 +1,76 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExponential(t *testing.T) {
+	base, max := 30*time.Second, 5*time.Minute
+
+	assert.Equal(t, 30*time.Second, Exponential(base, max, 1))
+	assert.Equal(t, time.Minute, Exponential(base, max, 2))
+	assert.Equal(t, 4*time.Minute, Exponential(base, max, 4))
+	assert.Equal(t, 5*time.Minute, Exponential(base, max, 5))
+	assert.Equal(t, 5*time.Minute, Exponential(base, max, 20))
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		delay := Jitter(time.Second)
+		assert.GreaterOrEqual(t, delay, 500*time.Millisecond)
+		assert.LessOrEqual(t, delay, time.Second)
+	}
+	assert.Equal(t, time.Duration(0), Jitter(0))
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	policy := Policy{Attempts: 4, Base: time.Millisecond, Max: 4 * time.Millisecond}
+	unavailable := errors.New("connection refused")
+
+	t.Run("успех после неудачных попыток", func(t *testing.T) {
+		var calls int
+		var notified []int
+		err := Retry(ctx, policy, func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return unavailable
+			}
+			return nil
+		}, func(attempt int, err error, delay time.Duration) {
+			notified = append(notified, attempt)
+			assert.ErrorIs(t, err, unavailable)
+			assert.LessOrEqual(t, delay, policy.Max)
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, []int{1, 2}, notified)
+	})
+
+	t.Run("попытки исчерпаны", func(t *testing.T) {
+		var calls int
+		err := Retry(ctx, policy, func(context.Context) error {
+			calls++
+			return unavailable
+		}, nil)
+
+		assert.ErrorIs(t, err, unavailable)
+		assert.Equal(t, policy.Attempts, calls)
+	})
+
+	t.Run("отмена контекста прерывает ожидание", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		err := Retry(ctx, Policy{Base: time.Hour, Max: time.Hour}, func(context.Context) error {
+			cancel()
+			return unavailable
+		}, nil)
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,55 @@
+package backoff
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Policy - политика повторов с экспоненциальной задержкой и разбросом
+type Policy struct {
+	// Attempts - число попыток; 0 - повторять до отмены контекста
+	Attempts int
+	Base     time.Duration
+	Max      time.Duration
+}
+
+// Jitter возвращает случайную задержку из [d/2, d], чтобы экземпляры,
+// начавшие повторы одновременно, не обращались к зависимости синхронно
+func Jitter(d time.Duration) time.Duration {
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// Retry вызывает fn, пока она не завершится успешно, не кончатся попытки
+// или не будет отменен ctx, и возвращает последнюю ошибку fn. Перед каждым
+// повтором вызывается notify, если он задан, с номером неудачной попытки,
+// ее ошибкой и задержкой до следующей
+func Retry(ctx context.Context, policy Policy, fn func(ctx context.Context) error, notify func(attempt int, err error, delay time.Duration)) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if policy.Attempts > 0 && attempt >= policy.Attempts {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		delay := Jitter(Exponential(policy.Base, policy.Max, attempt))
+		if notify != nil {
+			notify(attempt, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/member"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/i18n"
+)
+
+// Error - ошибка резолвера. Код и поле передаются клиенту в extensions,
+// коды совпадают с кодами ошибок REST API
+type Error struct {
+	Code    problem.Code
+	Field   string
+	Message string
+}
+
+// Error возвращает текст ошибки
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Extensions возвращает дополнительные сведения об ошибке для ответа GraphQL
+func (e *Error) Extensions() map[string]interface{} {
+	ext := map[string]interface{}{"code": e.Code}
+	if e.Field != "" {
+		ext["field"] = e.Field
+	}
+	return ext
+}
+
+// invalidIDError описывает аргумент, который должен быть UUID
+func invalidIDError(ctx context.Context, field string) error {
+	return &Error{Code: problem.CodeInvalidID, Field: field, Message: i18n.T(ctx, "{0} must be a valid UUID", field)}
+}
+
+// requiredError описывает отсутствующий обязательный аргумент
+func requiredError(ctx context.Context, field string) error {
+	return &Error{Code: problem.CodeValidationFailed, Field: field, Message: i18n.T(ctx, "{0} is required", field)}
+}
+
+// monthYearError описывает аргумент с датой в неверном формате
+func monthYearError(ctx context.Context, field string) error {
+	return &Error{Code: problem.CodeValidationFailed, Field: field, Message: i18n.T(ctx, "{0} must be in MM-YYYY format", field)}
+}
+
+// serviceError сопоставляет ошибку сервисного слоя с кодом ошибки API.
+// fallback используется как описание для непредвиденных ошибок
+func serviceError(ctx context.Context, err error, fallback string) error {
+	var validationErr *subscription.ValidationError
+	switch {
+	case errors.Is(err, subscription.ErrSubscriptionNotFound):
+		return &Error{Code: problem.CodeNotFound, Message: i18n.T(ctx, "Subscription not found")}
+	case errors.Is(err, member.ErrInsufficientRole):
+		return &Error{Code: problem.CodeForbidden, Message: i18n.T(ctx, "Your role in the organization does not allow this operation")}
+	case errors.Is(err, auth.ErrForbidden):
+		return &Error{Code: problem.CodeForbidden, Message: i18n.T(ctx, "Access to subscriptions of another user is forbidden")}
+	case errors.As(err, &validationErr):
+		return &Error{Code: problem.CodeValidationFailed, Field: validationErr.Field, Message: i18n.T(ctx, validationErr.Message)}
+	case errors.Is(err, subscription.ErrInvalidInput):
+		return &Error{Code: problem.CodeInvalidInput, Message: i18n.T(ctx, "Request contains invalid input")}
+	default:
+		return &Error{Code: problem.CodeInternal, Message: i18n.T(ctx, fallback)}
+	}
+}
@@ -0,0 +1,133 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/i18n"
+)
+
+// maxRequestSize ограничивает размер тела запроса GraphQL
+const maxRequestSize = 1 << 20
+
+// Handler обслуживает запросы GraphQL по HTTP
+type Handler struct {
+	schema graphql.Schema
+	limits Limits
+}
+
+// NewHandler создает обработчик GraphQL поверх сервиса подписок
+func NewHandler(service subscription.ReportService, limits Limits) (*Handler, error) {
+	schema, err := NewSchema(service)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{schema: schema, limits: limits}, nil
+}
+
+// request - тело запроса GraphQL
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// ServeHTTP выполняет запрос GraphQL, переданный в теле POST-запроса в формате
+// JSON или в параметрах query-строки GET-запроса
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req request
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				respondWithErrors(w, http.StatusBadRequest, requestError(i18n.T(ctx, "Query contains invalid parameters"), problem.CodeInvalidQuery))
+				return
+			}
+		}
+	} else {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to decode GraphQL request")
+			respondWithErrors(w, http.StatusBadRequest, requestError(i18n.T(ctx, "Request body is not valid JSON"), problem.CodeInvalidPayload))
+			return
+		}
+	}
+
+	if req.Query == "" {
+		respondWithErrors(w, http.StatusBadRequest, requestError(i18n.T(ctx, "{0} is required", "query"), problem.CodeValidationFailed))
+		return
+	}
+
+	// Разбираем и проверяем запрос до выполнения, чтобы отклонить слишком
+	// тяжелые запросы, не обращаясь к сервису
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		respondWithErrors(w, http.StatusBadRequest, gqlerrors.FormatErrors(err)...)
+		return
+	}
+
+	if result := graphql.ValidateDocument(&h.schema, doc, nil); !result.IsValid {
+		respondWithErrors(w, http.StatusBadRequest, result.Errors...)
+		return
+	}
+
+	if err := checkLimits(ctx, doc, req.OperationName, req.Variables, h.limits); err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("GraphQL query rejected")
+		respondWithErrors(w, http.StatusBadRequest, formatError(err))
+		return
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+
+	respondWithResult(w, http.StatusOK, result)
+}
+
+// requestError описывает ошибку запроса, до выполнения которого дело не дошло
+func requestError(message string, code problem.Code) gqlerrors.FormattedError {
+	return formatError(&Error{Code: code, Message: message})
+}
+
+// formatError преобразует ошибку в формат GraphQL с сохранением extensions
+func formatError(err error) gqlerrors.FormattedError {
+	return gqlerrors.FormatError(gqlerrors.NewError(err.Error(), nil, "", nil, nil, err))
+}
+
+// respondWithErrors отправляет ответ GraphQL, содержащий только ошибки
+func respondWithErrors(w http.ResponseWriter, code int, errs ...gqlerrors.FormattedError) {
+	respondWithResult(w, code, &graphql.Result{Errors: errs})
+}
+
+// respondWithResult отправляет результат выполнения запроса GraphQL
+func respondWithResult(w http.ResponseWriter, code int, result *graphql.Result) {
+	response, err := json.Marshal(result)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to marshal GraphQL response")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if _, err := w.Write(response); err != nil {
+		log.Error().Err(err).Msg("Failed to write GraphQL response")
+	}
+}
@@ -0,0 +1,429 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/i18n"
+)
+
+// MockSubscriptionService - мок для subscription.ReportService
+type MockSubscriptionService struct {
+	mock.Mock
+}
+
+func (m *MockSubscriptionService) Create(ctx context.Context, req subscription.CreateSubscriptionRequest) (*subscription.Subscription, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Get(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) GetAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (*subscription.Subscription, error) {
+	args := m.Called(ctx, id, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Update(ctx context.Context, id uuid.UUID, req subscription.UpdateSubscriptionRequest) (*subscription.Subscription, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionService) Restore(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) List(ctx context.Context, filter subscription.ListFilter) ([]*subscription.Subscription, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Export(ctx context.Context, filter subscription.ListFilter, fn func(*subscription.Subscription) error) error {
+	args := m.Called(ctx, filter, fn)
+	if subs, ok := args.Get(0).([]*subscription.Subscription); ok {
+		for _, sub := range subs {
+			if err := fn(sub); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockSubscriptionService) CalculateTotalCost(ctx context.Context, filter subscription.SubscriptionFilter) (*subscription.TotalCostResponse, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.TotalCostResponse), args.Error(1)
+}
+
+func (m *MockSubscriptionService) SummarizeByService(ctx context.Context, filter subscription.SummaryFilter) ([]*subscription.ServiceSummary, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*subscription.ServiceSummary), args.Error(1)
+}
+
+// response - ответ GraphQL в тестах
+type response struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func newTestHandler(t *testing.T, service subscription.ReportService) *Handler {
+	t.Helper()
+	h, err := NewHandler(service, Limits{MaxDepth: 6, MaxComplexity: 1000})
+	require.NoError(t, err)
+	return h
+}
+
+func execute(t *testing.T, h http.Handler, query string, variables map[string]interface{}) (int, response) {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	var resp response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	return rr.Code, resp
+}
+
+func TestHandler_Subscriptions(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	h := newTestHandler(t, mockService)
+
+	userID := uuid.New()
+	end := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	subs := []*subscription.Subscription{
+		{ID: uuid.New(), ServiceName: "Netflix", Price: 999, UserID: userID, StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: &end},
+		{ID: uuid.New(), ServiceName: "Spotify", Price: 299, UserID: userID, StartDate: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), ServiceName: "Yandex Plus", Price: 400, UserID: userID, StartDate: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	t.Run("Страница с фильтром и выбором полей", func(t *testing.T) {
+		mockService.On("List", mock.Anything, subscription.ListFilter{UserID: &userID, Limit: 3, Offset: 0}).
+			Return(subs, nil).Once()
+
+		code, resp := execute(t, h, `query($userId: ID) {
+			subscriptions(userId: $userId, limit: 2) {
+				items { id serviceName price startDate endDate user { id } }
+				hasNextPage
+				nextOffset
+			}
+		}`, map[string]interface{}{"userId": userID.String()})
+
+		assert.Equal(t, http.StatusOK, code)
+		require.Empty(t, resp.Errors)
+
+		page := resp.Data["subscriptions"].(map[string]interface{})
+		items := page["items"].([]interface{})
+		require.Len(t, items, 2)
+		assert.Equal(t, true, page["hasNextPage"])
+		assert.Equal(t, float64(2), page["nextOffset"])
+
+		first := items[0].(map[string]interface{})
+		assert.Equal(t, "Netflix", first["serviceName"])
+		assert.Equal(t, "01-2025", first["startDate"])
+		assert.Equal(t, "12-2025", first["endDate"])
+		assert.Equal(t, userID.String(), first["user"].(map[string]interface{})["id"])
+		assert.Nil(t, items[1].(map[string]interface{})["endDate"])
+	})
+
+	t.Run("Подписка не найдена", func(t *testing.T) {
+		id := uuid.New()
+		mockService.On("Get", mock.Anything, id).Return(nil, subscription.ErrSubscriptionNotFound).Once()
+
+		code, resp := execute(t, h, `query($id: ID!) { subscription(id: $id) { id } }`,
+			map[string]interface{}{"id": id.String()})
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Empty(t, resp.Errors)
+		assert.Nil(t, resp.Data["subscription"])
+	})
+
+	t.Run("Некорректный ID", func(t *testing.T) {
+		_, resp := execute(t, h, `{ subscription(id: "invalid") { id } }`, nil)
+
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "invalid_id", resp.Errors[0].Extensions["code"])
+		assert.Equal(t, "id", resp.Errors[0].Extensions["field"])
+	})
+
+	t.Run("Внутренняя ошибка не раскрывается клиенту", func(t *testing.T) {
+		id := uuid.New()
+		mockService.On("Get", mock.Anything, id).Return(nil, errors.New("pq: connection refused")).Once()
+
+		_, resp := execute(t, h, `query($id: ID!) { subscription(id: $id) { id } }`,
+			map[string]interface{}{"id": id.String()})
+
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "Failed to get subscription", resp.Errors[0].Message)
+		assert.Equal(t, "internal_error", resp.Errors[0].Extensions["code"])
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_Aggregates(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	h := newTestHandler(t, mockService)
+
+	userID := uuid.New()
+	otherUserID := uuid.New()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endPeriod := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	netflix, spotify := "Netflix", "Spotify"
+
+	t.Run("Стоимость и разбивка по сервисам пользователя", func(t *testing.T) {
+		mockService.On("CalculateTotalCost", mock.Anything, subscription.SubscriptionFilter{
+			UserID: &userID, StartPeriod: start, EndPeriod: endPeriod,
+		}).Return(&subscription.TotalCostResponse{TotalCost: 1298}, nil).Once()
+		// Разбивка строится одной сводкой, без расчета стоимости по каждому сервису
+		mockService.On("SummarizeByService", mock.Anything, subscription.SummaryFilter{
+			UserID: &userID, StartPeriod: start, EndPeriod: endPeriod,
+		}).Return([]*subscription.ServiceSummary{
+			{ServiceName: "Apple Music", SubscriptionCount: 1, UserCount: 1},
+			{ServiceName: netflix, SubscriptionCount: 1, UserCount: 1, MinPrice: 999, MaxPrice: 999, TotalCost: 999},
+			{ServiceName: spotify, SubscriptionCount: 1, UserCount: 1, MinPrice: 299, MaxPrice: 299, TotalCost: 299},
+		}, nil).Once()
+
+		code, resp := execute(t, h, `query($id: ID!) {
+			user(id: $id) {
+				totalCost(startPeriod: "01-2025", endPeriod: "12-2025")
+				costBreakdown(startPeriod: "01-2025", endPeriod: "12-2025") { serviceName totalCost }
+			}
+		}`, map[string]interface{}{"id": userID.String()})
+
+		assert.Equal(t, http.StatusOK, code)
+		require.Empty(t, resp.Errors)
+
+		user := resp.Data["user"].(map[string]interface{})
+		assert.Equal(t, float64(1298), user["totalCost"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"serviceName": "Netflix", "totalCost": float64(999)},
+			map[string]interface{}{"serviceName": "Spotify", "totalCost": float64(299)},
+		}, user["costBreakdown"])
+	})
+
+	t.Run("Каталог сервисов", func(t *testing.T) {
+		mockService.On("SummarizeByService", mock.Anything, subscription.SummaryFilter{}).
+			Return([]*subscription.ServiceSummary{
+				{ServiceName: netflix, SubscriptionCount: 2, UserCount: 2, MinPrice: 699, MaxPrice: 999, TotalCost: 1698},
+				{ServiceName: spotify, SubscriptionCount: 1, UserCount: 1, MinPrice: 299, MaxPrice: 299, TotalCost: 299},
+			}, nil).Once()
+
+		_, resp := execute(t, h, `{ services { name subscriptionCount userCount minPrice maxPrice } }`, nil)
+		require.Empty(t, resp.Errors)
+
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"name": "Netflix", "subscriptionCount": float64(2), "userCount": float64(2), "minPrice": float64(699), "maxPrice": float64(999)},
+			map[string]interface{}{"name": "Spotify", "subscriptionCount": float64(1), "userCount": float64(1), "minPrice": float64(299), "maxPrice": float64(299)},
+		}, resp.Data["services"])
+	})
+
+	t.Run("Размер списка сервисов", func(t *testing.T) {
+		mockService.On("SummarizeByService", mock.Anything, subscription.SummaryFilter{UserID: &otherUserID}).
+			Return([]*subscription.ServiceSummary{
+				{ServiceName: netflix, SubscriptionCount: 1, UserCount: 1, MinPrice: 699, MaxPrice: 699, TotalCost: 699},
+				{ServiceName: spotify, SubscriptionCount: 1, UserCount: 1, MinPrice: 299, MaxPrice: 299, TotalCost: 299},
+			}, nil).Once()
+
+		_, resp := execute(t, h, `query($id: ID) { services(userId: $id, limit: 1) { name } }`,
+			map[string]interface{}{"id": otherUserID.String()})
+		require.Empty(t, resp.Errors)
+		assert.Equal(t, []interface{}{map[string]interface{}{"name": "Netflix"}}, resp.Data["services"])
+
+		_, resp = execute(t, h, `{ services(limit: 0) { name } }`, nil)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "limit", resp.Errors[0].Extensions["field"])
+	})
+
+	t.Run("Некорректный период", func(t *testing.T) {
+		_, resp := execute(t, h, `{ totalCost(startPeriod: "12-2025", endPeriod: "01-2025") }`, nil)
+
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "validation_failed", resp.Errors[0].Extensions["code"])
+		assert.Equal(t, "endPeriod", resp.Errors[0].Extensions["field"])
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_Limits(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	h := newTestHandler(t, mockService)
+
+	t.Run("Превышена глубина", func(t *testing.T) {
+		code, resp := execute(t, h, `{
+			subscriptions(limit: 1) { items { user { subscriptions(limit: 1) { items { user { id } } } } } }
+		}`, nil)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "query_too_complex", resp.Errors[0].Extensions["code"])
+		assert.Contains(t, resp.Errors[0].Message, "depth")
+	})
+
+	t.Run("Превышена сложность через переменную", func(t *testing.T) {
+		code, resp := execute(t, h, `query($limit: Int) {
+			subscriptions(limit: $limit) { items { id serviceName price } }
+		}`, map[string]interface{}{"limit": 500})
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		require.Len(t, resp.Errors, 1)
+		assert.Contains(t, resp.Errors[0].Message, "complexity")
+	})
+
+	t.Run("Превышена сложность через значение переменной по умолчанию", func(t *testing.T) {
+		code, resp := execute(t, h, `query($n: Int = 500) {
+			subscriptions(limit: $n) { items { id } }
+		}`, nil)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "query_too_complex", resp.Errors[0].Extensions["code"])
+		assert.Contains(t, resp.Errors[0].Message, "complexity")
+	})
+
+	t.Run("Размер списка в оценке не превышает максимальный", func(t *testing.T) {
+		// 1 + 1000 * (items + id) = 2001: limit сверх максимума оценивается
+		// как maxPageSize, а затем отклоняется резолвером
+		h, err := NewHandler(mockService, Limits{MaxComplexity: 2001})
+		require.NoError(t, err)
+
+		code, resp := execute(t, h, `query($n: Int = 1000000) { subscriptions(limit: $n) { items { id } } }`, nil)
+		assert.Equal(t, http.StatusOK, code)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "validation_failed", resp.Errors[0].Extensions["code"])
+	})
+
+	t.Run("Сложность через фрагмент", func(t *testing.T) {
+		code, resp := execute(t, h, `
+			query { subscriptions(limit: 300) { items { ...fields } } }
+			fragment fields on Subscription { id serviceName price }
+		`, nil)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "query_too_complex", resp.Errors[0].Extensions["code"])
+	})
+
+	t.Run("Интроспекция учитывается в ограничениях", func(t *testing.T) {
+		code, resp := execute(t, h, `{ __schema { queryType { name fields { name } } } }`, nil)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Empty(t, resp.Errors)
+
+		code, resp = execute(t, h, `{ __schema { types { name fields { name type { name ofType { name ofType { name } } } } } } }`, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "query_too_complex", resp.Errors[0].Extensions["code"])
+		assert.Contains(t, resp.Errors[0].Message, "depth")
+	})
+
+	t.Run("Вложенные подписки каталога умножают сложность", func(t *testing.T) {
+		code, resp := execute(t, h, `{ services { subscriptions(limit: 100) { items { id } } } }`, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+		require.Len(t, resp.Errors, 1)
+		assert.Contains(t, resp.Errors[0].Message, "complexity")
+	})
+
+	t.Run("Ошибка на русском", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{
+			"query": `{ subscriptions(limit: 1) { items { user { subscriptions(limit: 1) { items { user { id } } } } } } }`,
+		})
+		req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+		req = req.WithContext(i18n.WithLocale(req.Context(), i18n.LocaleRU))
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "глубина запроса 7 превышает допустимую 6")
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_Request(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	h := newTestHandler(t, mockService)
+
+	t.Run("GET-запрос", func(t *testing.T) {
+		mockService.On("List", mock.Anything, subscription.ListFilter{Limit: 51}).
+			Return([]*subscription.Subscription{}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`{ subscriptions { hasNextPage } }`), nil)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"data":{"subscriptions":{"hasNextPage":false}}}`, rr.Body.String())
+	})
+
+	t.Run("Некорректный JSON", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBufferString("{"))
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "invalid_payload")
+	})
+
+	t.Run("Синтаксическая ошибка", func(t *testing.T) {
+		code, resp := execute(t, h, `{ subscriptions {`, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.NotEmpty(t, resp.Errors)
+	})
+
+	t.Run("Неизвестное поле", func(t *testing.T) {
+		code, resp := execute(t, h, `{ unknown }`, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.NotEmpty(t, resp.Errors)
+	})
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,171 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/i18n"
+)
+
+// Limits ограничивает глубину и сложность запросов GraphQL
+type Limits struct {
+	// MaxDepth - максимальная вложенность полей; 0 снимает ограничение
+	MaxDepth int
+	// MaxComplexity - максимальная оценка числа разрешаемых полей; 0 снимает ограничение
+	MaxComplexity int
+}
+
+// limitedFields - поля-списки, размер которых задается аргументом limit;
+// без аргумента они возвращают не больше defaultPageSize элементов
+var limitedFields = map[string]bool{
+	"subscriptions": true,
+	"costBreakdown": true,
+	"services":      true,
+}
+
+// analyzer оценивает глубину и сложность операции
+type analyzer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	// defaults - значения по умолчанию переменных операции, которые
+	// применяются, если переменная не передана в запросе
+	defaults map[string]ast.Value
+}
+
+// checkLimits проверяет, что выбранная операция документа укладывается в ограничения.
+// Документ должен быть предварительно провалидирован по схеме
+func checkLimits(ctx context.Context, doc *ast.Document, operationName string, variables map[string]interface{}, limits Limits) error {
+	a := &analyzer{
+		fragments: map[string]*ast.FragmentDefinition{},
+		variables: variables,
+		defaults:  map[string]ast.Value{},
+	}
+
+	var operation *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			a.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operation = def
+			}
+		}
+	}
+	if operation == nil {
+		return nil
+	}
+	for _, def := range operation.VariableDefinitions {
+		if def.DefaultValue != nil {
+			a.defaults[def.Variable.Name.Value] = def.DefaultValue
+		}
+	}
+
+	depth, complexity := a.selectionSet(operation.SelectionSet, 1)
+
+	if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+		return &Error{
+			Code: problem.CodeQueryTooComplex,
+			Message: i18n.T(ctx, "query depth {0} exceeds the limit of {1}",
+				strconv.Itoa(depth), strconv.Itoa(limits.MaxDepth)),
+		}
+	}
+	if limits.MaxComplexity > 0 && complexity > limits.MaxComplexity {
+		return &Error{
+			Code: problem.CodeQueryTooComplex,
+			Message: i18n.T(ctx, "query complexity {0} exceeds the limit of {1}",
+				strconv.Itoa(complexity), strconv.Itoa(limits.MaxComplexity)),
+		}
+	}
+
+	return nil
+}
+
+// selectionSet возвращает максимальную глубину и суммарную сложность набора полей.
+// Каждое поле стоит 1, а сложность вложенных полей умножается на размер списка.
+// Поля интроспекции (__schema, __type) учитываются наравне с остальными, чтобы
+// вложенная интроспекция не обходила ограничения
+func (a *analyzer) selectionSet(set *ast.SelectionSet, depth int) (int, int) {
+	if set == nil {
+		return depth - 1, 0
+	}
+
+	maxDepth, complexity := depth, 0
+	for _, selection := range set.Selections {
+		var childDepth, childComplexity int
+
+		switch sel := selection.(type) {
+		case *ast.Field:
+			childDepth, childComplexity = a.selectionSet(sel.SelectionSet, depth+1)
+			childComplexity = 1 + a.multiplier(sel)*childComplexity
+		case *ast.InlineFragment:
+			childDepth, childComplexity = a.selectionSet(sel.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			fragment, ok := a.fragments[sel.Name.Value]
+			if !ok {
+				continue
+			}
+			childDepth, childComplexity = a.selectionSet(fragment.SelectionSet, depth)
+		}
+
+		if childDepth > maxDepth {
+			maxDepth = childDepth
+		}
+		complexity += childComplexity
+	}
+
+	return maxDepth, complexity
+}
+
+// multiplier возвращает ожидаемое число элементов, которое вернет поле.
+// Оценка не превышает maxPageSize: больший limit резолвер отклоняет
+func (a *analyzer) multiplier(field *ast.Field) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value == "limit" {
+			limit := a.intValue(arg.Value)
+			switch {
+			case limit > maxPageSize:
+				return maxPageSize
+			case limit > 0:
+				return limit
+			}
+			return defaultPageSize
+		}
+	}
+
+	if limitedFields[field.Name.Value] {
+		return defaultPageSize
+	}
+	return 1
+}
+
+// intValue возвращает целое значение литерала или переменной. Непереданная
+// переменная принимает значение по умолчанию из определения операции
+func (a *analyzer) intValue(value ast.Value) int {
+	switch v := value.(type) {
+	case *ast.IntValue:
+		n, _ := strconv.Atoi(v.Value)
+		return n
+	case *ast.Variable:
+		passed, ok := a.variables[v.Name.Value]
+		if !ok {
+			if def, ok := a.defaults[v.Name.Value]; ok {
+				return a.intValue(def)
+			}
+			return 0
+		}
+		switch n := passed.(type) {
+		case int:
+			return n
+		case float64:
+			return int(n)
+		case json.Number:
+			i, _ := n.Int64()
+			return int(i)
+		}
+	}
+	return 0
+}
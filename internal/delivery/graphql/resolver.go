@@ -0,0 +1,372 @@
+package graphql
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+	"github.com/rs/zerolog"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/i18n"
+)
+
+// resolver разрешает поля схемы через subscription.ReportService
+type resolver struct {
+	service subscription.ReportService
+}
+
+// subscriptionPage - страница подписок
+type subscriptionPage struct {
+	Items       []*subscription.Subscription
+	HasNextPage bool
+	NextOffset  *int
+}
+
+// userRef - пользователь, известный только по ID
+type userRef struct {
+	ID uuid.UUID
+}
+
+// serviceCost - стоимость подписок на один сервис
+type serviceCost struct {
+	ServiceName string
+	TotalCost   int
+}
+
+// catalogService - сводная статистика по сервису каталога
+type catalogService struct {
+	Name              string
+	SubscriptionCount int
+	UserCount         int
+	MinPrice          int
+	MaxPrice          int
+
+	// userID ограничивает вложенные подписки пользователем, если каталог запрошен для него
+	userID *uuid.UUID
+}
+
+// subscription возвращает подписку по ID или null, если она не найдена
+func (r *resolver) subscription(p graphql.ResolveParams) (interface{}, error) {
+	id, err := uuid.Parse(p.Args["id"].(string))
+	if err != nil {
+		return nil, invalidIDError(p.Context, "id")
+	}
+
+	sub, err := r.service.Get(p.Context, id)
+	if errors.Is(err, subscription.ErrSubscriptionNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		zerolog.Ctx(p.Context).Error().Err(err).Str("id", id.String()).Msg("Failed to get subscription")
+		return nil, serviceError(p.Context, err, "Failed to get subscription")
+	}
+
+	return sub, nil
+}
+
+// subscriptions возвращает страницу подписок с фильтрацией
+func (r *resolver) subscriptions(p graphql.ResolveParams) (interface{}, error) {
+	var filter subscription.ListFilter
+
+	userID, err := optionalUUIDArg(p, "userId")
+	if err != nil {
+		return nil, err
+	}
+	filter.UserID = userID
+	filter.ServiceName = optionalStringArg(p, "serviceName")
+
+	return r.page(p, filter)
+}
+
+// user возвращает пользователя по ID
+func (r *resolver) user(p graphql.ResolveParams) (interface{}, error) {
+	id, err := uuid.Parse(p.Args["id"].(string))
+	if err != nil {
+		return nil, invalidIDError(p.Context, "id")
+	}
+	return &userRef{ID: id}, nil
+}
+
+// totalCost рассчитывает суммарную стоимость подписок за период
+func (r *resolver) totalCost(p graphql.ResolveParams) (interface{}, error) {
+	userID, err := optionalUUIDArg(p, "userId")
+	if err != nil {
+		return nil, err
+	}
+	return r.calculateTotalCost(p, userID, optionalStringArg(p, "serviceName"))
+}
+
+// costBreakdown рассчитывает стоимость подписок за период по каждому сервису
+func (r *resolver) costBreakdown(p graphql.ResolveParams) (interface{}, error) {
+	userID, err := optionalUUIDArg(p, "userId")
+	if err != nil {
+		return nil, err
+	}
+	return r.breakdown(p, userID)
+}
+
+// services возвращает каталог сервисов со сводной статистикой
+func (r *resolver) services(p graphql.ResolveParams) (interface{}, error) {
+	userID, err := optionalUUIDArg(p, "userId")
+	if err != nil {
+		return nil, err
+	}
+	limit, err := limitArg(p)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := r.service.SummarizeByService(p.Context, subscription.SummaryFilter{UserID: userID})
+	if err != nil {
+		zerolog.Ctx(p.Context).Error().Err(err).Msg("Failed to build service catalog")
+		return nil, serviceError(p.Context, err, "Failed to list subscriptions")
+	}
+
+	result := make([]*catalogService, 0, len(summaries))
+	for _, summary := range summaries {
+		result = append(result, &catalogService{
+			Name:              summary.ServiceName,
+			SubscriptionCount: summary.SubscriptionCount,
+			UserCount:         summary.UserCount,
+			MinPrice:          summary.MinPrice,
+			MaxPrice:          summary.MaxPrice,
+			userID:            userID,
+		})
+	}
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+// subscriptionID возвращает ID подписки
+func (r *resolver) subscriptionID(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(*subscription.Subscription).ID.String(), nil
+}
+
+// subscriptionUserID возвращает ID владельца подписки
+func (r *resolver) subscriptionUserID(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(*subscription.Subscription).UserID.String(), nil
+}
+
+// subscriptionStartDate возвращает месяц начала подписки в формате MM-YYYY
+func (r *resolver) subscriptionStartDate(p graphql.ResolveParams) (interface{}, error) {
+	return subscription.FormatMonthYear(p.Source.(*subscription.Subscription).StartDate), nil
+}
+
+// subscriptionEndDate возвращает месяц окончания подписки в формате MM-YYYY
+func (r *resolver) subscriptionEndDate(p graphql.ResolveParams) (interface{}, error) {
+	sub := p.Source.(*subscription.Subscription)
+	if sub.EndDate == nil {
+		return nil, nil
+	}
+	return subscription.FormatMonthYear(*sub.EndDate), nil
+}
+
+// subscriptionUser возвращает владельца подписки
+func (r *resolver) subscriptionUser(p graphql.ResolveParams) (interface{}, error) {
+	return &userRef{ID: p.Source.(*subscription.Subscription).UserID}, nil
+}
+
+// userID возвращает ID пользователя
+func (r *resolver) userID(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(*userRef).ID.String(), nil
+}
+
+// userSubscriptions возвращает страницу подписок пользователя
+func (r *resolver) userSubscriptions(p graphql.ResolveParams) (interface{}, error) {
+	userID := p.Source.(*userRef).ID
+	return r.page(p, subscription.ListFilter{
+		UserID:      &userID,
+		ServiceName: optionalStringArg(p, "serviceName"),
+	})
+}
+
+// userTotalCost рассчитывает стоимость подписок пользователя за период
+func (r *resolver) userTotalCost(p graphql.ResolveParams) (interface{}, error) {
+	userID := p.Source.(*userRef).ID
+	return r.calculateTotalCost(p, &userID, optionalStringArg(p, "serviceName"))
+}
+
+// userCostBreakdown рассчитывает стоимость подписок пользователя по сервисам
+func (r *resolver) userCostBreakdown(p graphql.ResolveParams) (interface{}, error) {
+	userID := p.Source.(*userRef).ID
+	return r.breakdown(p, &userID)
+}
+
+// catalogServiceSubscriptions возвращает страницу подписок на сервис из каталога
+func (r *resolver) catalogServiceSubscriptions(p graphql.ResolveParams) (interface{}, error) {
+	entry := p.Source.(*catalogService)
+	name := entry.Name
+	return r.page(p, subscription.ListFilter{UserID: entry.userID, ServiceName: &name})
+}
+
+// page выбирает страницу подписок по аргументам limit и offset
+func (r *resolver) page(p graphql.ResolveParams, filter subscription.ListFilter) (interface{}, error) {
+	limit, err := limitArg(p)
+	if err != nil {
+		return nil, err
+	}
+	offset, _ := p.Args["offset"].(int)
+
+	if offset < 0 {
+		return nil, &Error{
+			Code:    problem.CodeValidationFailed,
+			Field:   "offset",
+			Message: i18n.T(p.Context, "{0} is invalid", "offset"),
+		}
+	}
+
+	// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+	filter.Limit = limit + 1
+	filter.Offset = offset
+
+	subs, err := r.service.List(p.Context, filter)
+	if err != nil {
+		zerolog.Ctx(p.Context).Error().Err(err).Msg("Failed to list subscriptions")
+		return nil, serviceError(p.Context, err, "Failed to list subscriptions")
+	}
+
+	page := &subscriptionPage{Items: subs}
+	if len(subs) > limit {
+		next := offset + limit
+		page.Items = subs[:limit]
+		page.HasNextPage = true
+		page.NextOffset = &next
+	}
+
+	return page, nil
+}
+
+// calculateTotalCost рассчитывает стоимость подписок за период из аргументов
+func (r *resolver) calculateTotalCost(p graphql.ResolveParams, userID *uuid.UUID, serviceName *string) (interface{}, error) {
+	startPeriod, endPeriod, err := periodArgs(p)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCost, err := r.service.CalculateTotalCost(p.Context, subscription.SubscriptionFilter{
+		UserID:      userID,
+		ServiceName: serviceName,
+		StartPeriod: startPeriod,
+		EndPeriod:   endPeriod,
+	})
+	if err != nil {
+		zerolog.Ctx(p.Context).Error().Err(err).Msg("Failed to calculate total cost")
+		return nil, serviceError(p.Context, err, "Failed to calculate total cost")
+	}
+
+	return totalCost.TotalCost, nil
+}
+
+// breakdown рассчитывает стоимость подписок за период отдельно по каждому сервису.
+// Сервисы без расходов за период в результат не попадают
+func (r *resolver) breakdown(p graphql.ResolveParams, userID *uuid.UUID) (interface{}, error) {
+	startPeriod, endPeriod, err := periodArgs(p)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := limitArg(p)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := r.service.SummarizeByService(p.Context, subscription.SummaryFilter{
+		UserID:      userID,
+		StartPeriod: startPeriod,
+		EndPeriod:   endPeriod,
+	})
+	if err != nil {
+		zerolog.Ctx(p.Context).Error().Err(err).Msg("Failed to calculate cost breakdown")
+		return nil, serviceError(p.Context, err, "Failed to calculate total cost")
+	}
+
+	result := make([]*serviceCost, 0, len(summaries))
+	for _, summary := range summaries {
+		if summary.TotalCost > 0 {
+			result = append(result, &serviceCost{ServiceName: summary.ServiceName, TotalCost: summary.TotalCost})
+		}
+	}
+
+	// Самые дорогие сервисы - первыми
+	sort.SliceStable(result, func(i, j int) bool { return result[i].TotalCost > result[j].TotalCost })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+// limitArg разбирает аргумент limit - размер страницы или списка
+func limitArg(p graphql.ResolveParams) (int, error) {
+	limit, _ := p.Args["limit"].(int)
+	if limit < 1 || limit > maxPageSize {
+		return 0, &Error{
+			Code:    problem.CodeValidationFailed,
+			Field:   "limit",
+			Message: i18n.T(p.Context, "{0} must be between {1} and {2}", "limit", "1", strconv.Itoa(maxPageSize)),
+		}
+	}
+	return limit, nil
+}
+
+// periodArgs разбирает аргументы startPeriod и endPeriod
+func periodArgs(p graphql.ResolveParams) (time.Time, time.Time, error) {
+	startStr, _ := p.Args["startPeriod"].(string)
+	endStr, _ := p.Args["endPeriod"].(string)
+
+	if startStr == "" {
+		return time.Time{}, time.Time{}, requiredError(p.Context, "startPeriod")
+	}
+	if endStr == "" {
+		return time.Time{}, time.Time{}, requiredError(p.Context, "endPeriod")
+	}
+
+	startPeriod, err := subscription.ParseMonthYear(startStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, monthYearError(p.Context, "startPeriod")
+	}
+
+	endPeriod, err := subscription.ParseMonthYear(endStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, monthYearError(p.Context, "endPeriod")
+	}
+
+	if endPeriod.Before(startPeriod) {
+		return time.Time{}, time.Time{}, &Error{
+			Code:    problem.CodeValidationFailed,
+			Field:   "endPeriod",
+			Message: i18n.T(p.Context, "{0} cannot be before {1}", "endPeriod", "startPeriod"),
+		}
+	}
+
+	return startPeriod, endPeriod, nil
+}
+
+// optionalUUIDArg разбирает необязательный аргумент-UUID
+func optionalUUIDArg(p graphql.ResolveParams, name string) (*uuid.UUID, error) {
+	value, ok := p.Args[name].(string)
+	if !ok || value == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, invalidIDError(p.Context, name)
+	}
+	return &id, nil
+}
+
+// optionalStringArg возвращает необязательный строковый аргумент или nil
+func optionalStringArg(p graphql.ResolveParams, name string) *string {
+	value, ok := p.Args[name].(string)
+	if !ok || value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,226 @@
+package graphql
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/subscription-service/internal/domain/subscription"
+)
+
+// Ограничения размера страницы подписок
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
+// NewSchema строит схему GraphQL поверх сервиса подписок
+func NewSchema(service subscription.ReportService) (graphql.Schema, error) {
+	r := &resolver{service: service}
+
+	var subscriptionType, userType *graphql.Object
+
+	// pageArgs - аргументы постраничной выборки
+	pageArgs := func(extra graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		args := graphql.FieldConfigArgument{
+			"limit": &graphql.ArgumentConfig{
+				Type:         graphql.Int,
+				DefaultValue: defaultPageSize,
+				Description:  "Размер страницы, не больше 1000",
+			},
+			"offset": &graphql.ArgumentConfig{
+				Type:         graphql.Int,
+				DefaultValue: 0,
+				Description:  "Количество пропускаемых записей",
+			},
+		}
+		for name, arg := range extra {
+			args[name] = arg
+		}
+		return args
+	}
+
+	// periodArgs - аргументы периода расчета стоимости
+	periodArgs := func(extra graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		args := graphql.FieldConfigArgument{
+			"startPeriod": &graphql.ArgumentConfig{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "Начало периода в формате MM-YYYY",
+			},
+			"endPeriod": &graphql.ArgumentConfig{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "Конец периода в формате MM-YYYY",
+			},
+		}
+		for name, arg := range extra {
+			args[name] = arg
+		}
+		return args
+	}
+
+	// serviceLimitArg - размер списка сервисов; сводка строится одним запросом,
+	// а limit ограничивает число элементов для оценки сложности вложенных полей
+	serviceLimitArg := &graphql.ArgumentConfig{
+		Type:         graphql.Int,
+		DefaultValue: defaultPageSize,
+		Description:  "Число сервисов в списке, не больше 1000",
+	}
+
+	pageType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "SubscriptionPage",
+		Description: "Страница подписок",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"items":       &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(subscriptionType)))},
+				"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+				"nextOffset": &graphql.Field{
+					Type:        graphql.Int,
+					Description: "Смещение следующей страницы; null, если страница последняя",
+				},
+			}
+		}),
+	})
+
+	serviceCostType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "ServiceCost",
+		Description: "Стоимость подписок на один сервис за период",
+		Fields: graphql.Fields{
+			"serviceName": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"totalCost":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	subscriptionType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Subscription",
+		Description: "Подписка пользователя на сервис",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: r.subscriptionID},
+				"serviceName": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"price": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.Int),
+					Description: "Стоимость месячной подписки в рублях",
+				},
+				"userId": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: r.subscriptionUserID},
+				"startDate": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "Месяц начала подписки в формате MM-YYYY",
+					Resolve:     r.subscriptionStartDate,
+				},
+				"endDate": &graphql.Field{
+					Type:        graphql.String,
+					Description: "Месяц окончания подписки в формате MM-YYYY; null у бессрочной подписки",
+					Resolve:     r.subscriptionEndDate,
+				},
+				"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+				"updatedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+				"user":      &graphql.Field{Type: graphql.NewNonNull(userType), Resolve: r.subscriptionUser},
+			}
+		}),
+	})
+
+	userType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "User",
+		Description: "Пользователь и его подписки",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: r.userID},
+				"subscriptions": &graphql.Field{
+					Type: graphql.NewNonNull(pageType),
+					Args: pageArgs(graphql.FieldConfigArgument{
+						"serviceName": &graphql.ArgumentConfig{Type: graphql.String},
+					}),
+					Resolve: r.userSubscriptions,
+				},
+				"totalCost": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.Int),
+					Args: periodArgs(graphql.FieldConfigArgument{
+						"serviceName": &graphql.ArgumentConfig{Type: graphql.String},
+					}),
+					Resolve: r.userTotalCost,
+				},
+				"costBreakdown": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(serviceCostType))),
+					Args: periodArgs(graphql.FieldConfigArgument{
+						"limit": serviceLimitArg,
+					}),
+					Resolve: r.userCostBreakdown,
+				},
+			}
+		}),
+	})
+
+	catalogServiceType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "CatalogService",
+		Description: "Сервис из каталога подписок со сводной статистикой",
+		Fields: graphql.Fields{
+			"name":              &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"subscriptionCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"userCount":         &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"minPrice":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"maxPrice":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"subscriptions": &graphql.Field{
+				Type:    graphql.NewNonNull(pageType),
+				Args:    pageArgs(nil),
+				Resolve: r.catalogServiceSubscriptions,
+			},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"subscription": &graphql.Field{
+				Type:        subscriptionType,
+				Description: "Подписка по ID; null, если подписка не найдена",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: r.subscription,
+			},
+			"subscriptions": &graphql.Field{
+				Type:        graphql.NewNonNull(pageType),
+				Description: "Страница подписок с фильтрацией",
+				Args: pageArgs(graphql.FieldConfigArgument{
+					"userId":      &graphql.ArgumentConfig{Type: graphql.ID},
+					"serviceName": &graphql.ArgumentConfig{Type: graphql.String},
+				}),
+				Resolve: r.subscriptions,
+			},
+			"user": &graphql.Field{
+				Type:        graphql.NewNonNull(userType),
+				Description: "Пользователь по ID",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: r.user,
+			},
+			"totalCost": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "Суммарная стоимость подписок за период",
+				Args: periodArgs(graphql.FieldConfigArgument{
+					"userId":      &graphql.ArgumentConfig{Type: graphql.ID},
+					"serviceName": &graphql.ArgumentConfig{Type: graphql.String},
+				}),
+				Resolve: r.totalCost,
+			},
+			"costBreakdown": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(serviceCostType))),
+				Description: "Стоимость подписок за период в разбивке по сервисам",
+				Args: periodArgs(graphql.FieldConfigArgument{
+					"userId": &graphql.ArgumentConfig{Type: graphql.ID},
+					"limit":  serviceLimitArg,
+				}),
+				Resolve: r.costBreakdown,
+			},
+			"services": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(catalogServiceType))),
+				Description: "Каталог сервисов, на которые оформлены подписки",
+				Args: graphql.FieldConfigArgument{
+					"userId": &graphql.ArgumentConfig{Type: graphql.ID},
+					"limit":  serviceLimitArg,
+				},
+				Resolve: r.services,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
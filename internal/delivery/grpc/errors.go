@@ -0,0 +1,76 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/domain/member"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/i18n"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// invalidArgument возвращает ошибку codes.InvalidArgument с описанием
+// некорректных полей в деталях errdetails.BadRequest
+func invalidArgument(ctx context.Context, detail string, violations ...*errdetails.BadRequest_FieldViolation) error {
+	st := status.New(codes.InvalidArgument, i18n.T(ctx, detail))
+	if len(violations) == 0 {
+		return st.Err()
+	}
+
+	withDetails, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// fieldViolation описывает ошибку в конкретном поле запроса
+func fieldViolation(field, description string) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{Field: field, Description: description}
+}
+
+// requiredField описывает отсутствующее обязательное поле
+func requiredField(ctx context.Context, field string) *errdetails.BadRequest_FieldViolation {
+	return fieldViolation(field, i18n.T(ctx, "{0} is required", field))
+}
+
+// invalidUUIDField описывает поле, которое должно быть UUID
+func invalidUUIDField(ctx context.Context, field string) *errdetails.BadRequest_FieldViolation {
+	return fieldViolation(field, i18n.T(ctx, "{0} must be a valid UUID", field))
+}
+
+// monthYearField описывает поле с датой в неверном формате
+func monthYearField(ctx context.Context, field string) *errdetails.BadRequest_FieldViolation {
+	return fieldViolation(field, i18n.T(ctx, "{0} must be in MM-YYYY format", field))
+}
+
+// priceOutOfRange возвращает ошибку codes.OutOfRange для цены, которая не
+// помещается в поле int32 ответа
+func priceOutOfRange(ctx context.Context) error {
+	return status.Error(codes.OutOfRange, i18n.T(ctx, "Subscription price exceeds the maximum supported value"))
+}
+
+// serviceError сопоставляет ошибку сервисного слоя с кодом gRPC.
+// fallback используется как описание для непредвиденных ошибок
+func serviceError(ctx context.Context, err error, fallback string) error {
+	var validationErr *subscription.ValidationError
+	switch {
+	case errors.Is(err, subscription.ErrSubscriptionNotFound):
+		return status.Error(codes.NotFound, i18n.T(ctx, "Subscription not found"))
+	case errors.Is(err, member.ErrInsufficientRole):
+		return status.Error(codes.PermissionDenied, i18n.T(ctx, "Your role in the organization does not allow this operation"))
+	case errors.Is(err, auth.ErrForbidden):
+		return status.Error(codes.PermissionDenied, i18n.T(ctx, "Access to subscriptions of another user is forbidden"))
+	case errors.As(err, &validationErr):
+		return invalidArgument(ctx, "Request contains invalid fields",
+			fieldViolation(validationErr.Field, i18n.T(ctx, validationErr.Message)))
+	case errors.Is(err, subscription.ErrInvalidInput):
+		return invalidArgument(ctx, "Request contains invalid input")
+	default:
+		return status.Error(codes.Internal, i18n.T(ctx, fallback))
+	}
+}
@@ -0,0 +1,24 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/subscription-service/internal/actor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// actorKey - ключ метаданных gRPC с исполнителем запроса
+var actorKey = strings.ToLower(actor.Header)
+
+// Actor сохраняет в контексте исполнителя запроса из метаданных x-actor
+func Actor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(actorKey); len(values) > 0 && values[0] != "" {
+			ctx = actor.WithActor(ctx, values[0])
+		}
+	}
+
+	return handler(ctx, req)
+}
@@ -0,0 +1,77 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/internal/actor"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/i18n"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// apiKeyKey - ключ метаданных gRPC с ключом API; ключ API и токен JWT можно
+// передать и как authorization: Bearer
+const apiKeyKey = "x-api-key"
+
+// Auth создает перехватчик, проверяющий учетные данные и право доступа к методу.
+// scopes сопоставляет полное имя метода с требуемым правом; методы, которых
+// нет в списке, доступны только с правом admin
+func Auth(authenticator auth.Authenticator, scopes map[string]auth.Scope) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		token := credentials(ctx)
+		if token == "" {
+			return nil, status.Error(codes.Unauthenticated, i18n.T(ctx, "Missing or invalid credentials"))
+		}
+
+		principal, err := authenticator.Authenticate(ctx, token)
+		if err != nil {
+			if errors.Is(err, auth.ErrUnauthenticated) {
+				log.Warn().Str("method", info.FullMethod).Msg("Rejected invalid credentials")
+				return nil, status.Error(codes.Unauthenticated, i18n.T(ctx, "Missing or invalid credentials"))
+			}
+			log.Error().Err(err).Msg("Failed to authenticate request")
+			return nil, status.Error(codes.Internal, i18n.T(ctx, "Failed to authenticate request"))
+		}
+
+		scope, ok := scopes[info.FullMethod]
+		if !ok {
+			scope = auth.ScopeAdmin
+		}
+		if !principal.Allows(scope) {
+			log.Warn().
+				Str("subject", principal.Subject).
+				Str("scope", string(scope)).
+				Str("method", info.FullMethod).
+				Msg("Insufficient scope")
+			return nil, status.Error(codes.PermissionDenied, i18n.T(ctx, "Scope {0} is required", string(scope)))
+		}
+
+		ctx = auth.WithPrincipal(ctx, principal)
+		ctx = actor.WithActor(ctx, principal.Subject)
+		return handler(ctx, req)
+	}
+}
+
+// credentials извлекает ключ API или токен JWT из метаданных x-api-key или authorization: Bearer
+func credentials(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(apiKeyKey); len(values) > 0 && values[0] != "" {
+		return values[0]
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		scheme, token, ok := strings.Cut(values[0], " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,21 @@
+package interceptor
+
+import (
+	"context"
+
+	"github.com/subscription-service/internal/i18n"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Locale выбирает язык сообщений об ошибках по метаданным accept-language
+func Locale(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	var acceptLanguage string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("accept-language"); len(values) > 0 {
+			acceptLanguage = values[0]
+		}
+	}
+
+	return handler(i18n.WithLocale(ctx, i18n.Match(acceptLanguage)), req)
+}
@@ -0,0 +1,44 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/internal/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Logger логирует каждый вызов gRPC с кодом результата и длительностью
+func Logger(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	duration := time.Since(start)
+	code := status.Code(err)
+
+	// Формируем лог
+	logger := log.Info()
+	if code != codes.OK {
+		logger = log.Error()
+	}
+
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+
+	logger.
+		Str("method", info.FullMethod).
+		Str("code", code.String()).
+		Dur("duration", duration).
+		Str("ip", addr).
+		Str("request_id", requestid.FromContext(ctx)).
+		Msg("gRPC request")
+
+	return resp, err
+}
@@ -0,0 +1,58 @@
+package interceptor
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/internal/i18n"
+	"github.com/subscription-service/internal/ratelimit"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RateLimit создает перехватчик, ограничивающий частоту вызовов клиента
+// квотами маршрутов, которые routes возвращает для метода. Квоты и ключ
+// клиента те же, что в HTTP API: до Auth клиент определяется по IP-адресу,
+// после - по ключу API или пользователю в организации запроса. Без
+// ограничителя вызовы не ограничиваются, а ошибка хранилища квот не
+// отклоняет вызов
+func RateLimit(limiter *ratelimit.Limiter, routes func(method string) []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if limiter == nil {
+			return handler(ctx, req)
+		}
+
+		var addr string
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			addr = p.Addr.String()
+		}
+		client := ratelimit.Client(ctx, addr)
+
+		for _, route := range routes(info.FullMethod) {
+			result, ok, err := limiter.Allow(ctx, route, client)
+			if err != nil {
+				log.Warn().Err(err).Str("route", route).Msg("Failed to check rate limit")
+				continue
+			}
+			if !ok || result.Allowed {
+				continue
+			}
+
+			log.Warn().Str("client", client).Str("route", route).Str("method", info.FullMethod).Msg("Rate limit exceeded")
+			retryAfter := strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds())))
+			st := status.New(codes.ResourceExhausted, i18n.T(ctx, "Too many requests, retry in {0} s", retryAfter))
+			if withDetails, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(result.RetryAfter)}); err == nil {
+				st = withDetails
+			}
+			return nil, st.Err()
+		}
+
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,30 @@
+package interceptor
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/internal/i18n"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Recover перехватывает панику в обработчике и возвращает клиенту codes.Internal
+func Recover(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			// Логируем информацию о панике
+			log.Error().
+				Interface("panic", p).
+				Str("method", info.FullMethod).
+				Str("stack", string(debug.Stack())).
+				Msg("Recovered from gRPC handler panic")
+
+			err = status.Error(codes.Internal, i18n.T(ctx, "Unexpected server error"))
+		}
+	}()
+
+	return handler(ctx, req)
+}
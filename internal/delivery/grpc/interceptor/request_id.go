@@ -0,0 +1,40 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/internal/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDKey - ключ метаданных gRPC с ID запроса
+var requestIDKey = strings.ToLower(requestid.Header)
+
+// RequestID берет ID запроса из метаданных x-request-id или генерирует новый,
+// возвращает его клиенту в заголовке ответа и сохраняет в контексте
+func RequestID(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDKey); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = requestid.New()
+	}
+
+	if err := grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, requestID)); err != nil {
+		log.Warn().Err(err).Msg("Failed to set request ID header")
+	}
+
+	ctx = requestid.WithRequestID(ctx, requestID)
+
+	// Добавляем request ID в логгер контекста
+	logger := log.With().Str("request_id", requestID).Logger()
+	ctx = logger.WithContext(ctx)
+
+	return handler(ctx, req)
+}
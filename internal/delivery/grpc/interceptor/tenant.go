@@ -0,0 +1,50 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/i18n"
+	"github.com/subscription-service/internal/tenant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// organizationKey - ключ метаданных gRPC с организацией запроса
+var organizationKey = strings.ToLower(tenant.Header)
+
+// Tenant определяет организацию запроса по клиенту и метаданным
+// x-organization-id и сохраняет ее в контексте. Подключается после Auth
+func Tenant(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, i18n.T(ctx, "Missing or invalid credentials"))
+	}
+
+	var requested string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(organizationKey); len(values) > 0 {
+			requested = values[0]
+		}
+	}
+
+	organizationID, err := tenant.Resolve(principal, requested)
+	switch {
+	case errors.Is(err, tenant.ErrInvalidOrganization):
+		return nil, status.Error(codes.InvalidArgument, i18n.T(ctx, "Invalid organization ID {0}", requested))
+	case err != nil:
+		log.Warn().
+			Str("subject", principal.Subject).
+			Str("organization_id", requested).
+			Str("method", info.FullMethod).
+			Msg("Access to organization denied")
+		return nil, status.Error(codes.PermissionDenied, i18n.T(ctx, "Access to organization {0} is forbidden", requested))
+	}
+
+	return handler(tenant.WithOrganization(ctx, organizationID), req)
+}
@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	subscriptionv1 "github.com/subscription-service/api/proto/subscription/v1"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/delivery/grpc/interceptor"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// methodScopes - права доступа, требуемые методами сервиса подписок
+var methodScopes = map[string]auth.Scope{
+	subscriptionv1.SubscriptionService_CreateSubscription_FullMethodName: auth.ScopeSubscriptionsWrite,
+	subscriptionv1.SubscriptionService_GetSubscription_FullMethodName:    auth.ScopeSubscriptionsRead,
+	subscriptionv1.SubscriptionService_UpdateSubscription_FullMethodName: auth.ScopeSubscriptionsWrite,
+	subscriptionv1.SubscriptionService_DeleteSubscription_FullMethodName: auth.ScopeSubscriptionsWrite,
+	subscriptionv1.SubscriptionService_ListSubscriptions_FullMethodName:  auth.ScopeSubscriptionsRead,
+	subscriptionv1.SubscriptionService_CalculateTotalCost_FullMethodName: auth.ScopeReportsRead,
+}
+
+// methodRoutes - дополнительные квоты методов сверх общей квоты клиента,
+// совпадающие с квотами соответствующих маршрутов HTTP API
+var methodRoutes = map[string]string{
+	subscriptionv1.SubscriptionService_CalculateTotalCost_FullMethodName: "calculate-cost",
+}
+
+// NewServer создает gRPC-сервер с зарегистрированным сервисом подписок.
+// Цепочка перехватчиков повторяет middleware HTTP-маршрутизатора, включая
+// квоты limiter, общие с HTTP API; nil отключает квоты
+func NewServer(service subscription.Service, authenticator auth.Authenticator, limiter *ratelimit.Limiter, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts, grpc.ChainUnaryInterceptor(
+		interceptor.RequestID,
+		interceptor.Locale,
+		interceptor.Actor,
+		interceptor.Logger,
+		interceptor.Recover,
+		interceptor.RateLimit(limiter, func(string) []string { return []string{ratelimit.IPRoute} }),
+		interceptor.Auth(authenticator, methodScopes),
+		interceptor.Tenant,
+		interceptor.RateLimit(limiter, clientRoutes),
+	))
+
+	server := grpc.NewServer(opts...)
+	subscriptionv1.RegisterSubscriptionServiceServer(server, NewSubscriptionServer(service))
+
+	// Reflection позволяет обращаться к API через grpcurl без .proto-файлов
+	reflection.Register(server)
+
+	return server
+}
+
+// clientRoutes возвращает квоты клиента для метода: общую и квоту метода, если она есть
+func clientRoutes(method string) []string {
+	if route, ok := methodRoutes[method]; ok {
+		return []string{ratelimit.DefaultRoute, route}
+	}
+	return []string{ratelimit.DefaultRoute}
+}
@@ -0,0 +1,528 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	subscriptionv1 "github.com/subscription-service/api/proto/subscription/v1"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/ratelimit"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// MockSubscriptionService - мок для subscription.Service
+type MockSubscriptionService struct {
+	mock.Mock
+}
+
+func (m *MockSubscriptionService) Create(ctx context.Context, req subscription.CreateSubscriptionRequest) (*subscription.Subscription, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Get(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) GetAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (*subscription.Subscription, error) {
+	args := m.Called(ctx, id, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Update(ctx context.Context, id uuid.UUID, req subscription.UpdateSubscriptionRequest) (*subscription.Subscription, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionService) Restore(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) List(ctx context.Context, filter subscription.ListFilter) ([]*subscription.Subscription, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Export(ctx context.Context, filter subscription.ListFilter, fn func(*subscription.Subscription) error) error {
+	args := m.Called(ctx, filter, fn)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionService) CalculateTotalCost(ctx context.Context, filter subscription.SubscriptionFilter) (*subscription.TotalCostResponse, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.TotalCostResponse), args.Error(1)
+}
+
+// newTestClient запускает сервер на bufconn-листенере и возвращает клиента к нему
+// testKeys - ключи API тестового сервера и права их владельцев
+var testKeys = map[string][]auth.Scope{
+	"admin-key":  {auth.ScopeAdmin},
+	"reader-key": {auth.ScopeSubscriptionsRead},
+}
+
+// staticAuthenticator аутентифицирует клиентов по testKeys
+type staticAuthenticator struct{}
+
+func (staticAuthenticator) Authenticate(_ context.Context, token string) (*auth.Principal, error) {
+	scopes, ok := testKeys[token]
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+	return &auth.Principal{Subject: "api-key:" + token, Scopes: scopes}, nil
+}
+
+func newTestClient(t *testing.T, service subscription.Service) subscriptionv1.SubscriptionServiceClient {
+	return newTestClientWithKey(t, service, "admin-key")
+}
+
+// newTestClientWithKey создает клиента, передающего ключ API в каждом вызове;
+// пустой ключ не передается
+func newTestClientWithKey(t *testing.T, service subscription.Service, key string) subscriptionv1.SubscriptionServiceClient {
+	return newTestClientWithLimiter(t, service, key, nil)
+}
+
+// newTestClientWithLimiter создает клиента сервера с квотами limiter
+func newTestClientWithLimiter(t *testing.T, service subscription.Service, key string, limiter *ratelimit.Limiter) subscriptionv1.SubscriptionServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(service, staticAuthenticator{}, limiter)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			if key != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", key)
+			}
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return subscriptionv1.NewSubscriptionServiceClient(conn)
+}
+
+func TestSubscriptionServer_Auth(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	t.Run("без ключа", func(t *testing.T) {
+		client := newTestClientWithKey(t, new(MockSubscriptionService), "")
+		_, err := client.GetSubscription(ctx, &subscriptionv1.GetSubscriptionRequest{Id: id.String()})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("неизвестный ключ", func(t *testing.T) {
+		client := newTestClientWithKey(t, new(MockSubscriptionService), "unknown-key")
+		_, err := client.GetSubscription(ctx, &subscriptionv1.GetSubscriptionRequest{Id: id.String()})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("чтение разрешено, изменение запрещено", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		mockService.On("Get", mock.Anything, id).Return(&subscription.Subscription{ID: id, StartDate: time.Now()}, nil)
+		client := newTestClientWithKey(t, mockService, "reader-key")
+
+		_, err := client.GetSubscription(ctx, &subscriptionv1.GetSubscriptionRequest{Id: id.String()})
+		assert.NoError(t, err)
+
+		_, err = client.DeleteSubscription(ctx, &subscriptionv1.DeleteSubscriptionRequest{Id: id.String()})
+		st := status.Convert(err)
+		assert.Equal(t, codes.PermissionDenied, st.Code())
+		assert.Contains(t, st.Message(), "subscriptions:write")
+		mockService.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSubscriptionServer_RateLimit(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+	newLimiter := func(limits map[string]ratelimit.Limit) *ratelimit.Limiter {
+		return ratelimit.NewLimiter(ratelimit.NewMemoryStore(), limits)
+	}
+
+	t.Run("квота расчета стоимости общая с HTTP API", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		mockService.On("CalculateTotalCost", mock.Anything, mock.Anything).Return(&subscription.TotalCostResponse{TotalCost: 100}, nil).Once()
+		mockService.On("Get", mock.Anything, id).Return(&subscription.Subscription{ID: id, StartDate: time.Now()}, nil)
+		client := newTestClientWithLimiter(t, mockService, "admin-key", newLimiter(map[string]ratelimit.Limit{
+			"calculate-cost": {Requests: 1, Period: time.Minute},
+		}))
+		req := &subscriptionv1.CalculateTotalCostRequest{StartPeriod: "01-2025", EndPeriod: "12-2025"}
+
+		_, err := client.CalculateTotalCost(ctx, req)
+		require.NoError(t, err)
+
+		_, err = client.CalculateTotalCost(ctx, req)
+		st := status.Convert(err)
+		assert.Equal(t, codes.ResourceExhausted, st.Code())
+		require.Len(t, st.Details(), 1)
+		retry, ok := st.Details()[0].(*errdetails.RetryInfo)
+		require.True(t, ok)
+		assert.InDelta(t, time.Minute.Seconds(), retry.RetryDelay.AsDuration().Seconds(), 1)
+
+		// Квота метода не ограничивает другие методы
+		_, err = client.GetSubscription(ctx, &subscriptionv1.GetSubscriptionRequest{Id: id.String()})
+		assert.NoError(t, err)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("квота IP-адреса ограничивает вызовы с неверными ключами", func(t *testing.T) {
+		client := newTestClientWithLimiter(t, new(MockSubscriptionService), "unknown-key", newLimiter(map[string]ratelimit.Limit{
+			ratelimit.IPRoute: {Requests: 1, Period: time.Minute},
+		}))
+
+		_, err := client.GetSubscription(ctx, &subscriptionv1.GetSubscriptionRequest{Id: id.String()})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = client.GetSubscription(ctx, &subscriptionv1.GetSubscriptionRequest{Id: id.String()})
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+}
+
+func TestSubscriptionServer_CreateSubscription(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	client := newTestClient(t, mockService)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	now := time.Now()
+	endDate := "12-2025"
+
+	t.Run("Успешное создание подписки", func(t *testing.T) {
+		expectedReq := subscription.CreateSubscriptionRequest{
+			ServiceName: "Yandex Plus",
+			Price:       400,
+			UserID:      userID,
+			StartDate:   "07-2025",
+			EndDate:     &endDate,
+		}
+		end := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+		mockService.On("Create", mock.Anything, expectedReq).Return(&subscription.Subscription{
+			ID:          uuid.New(),
+			ServiceName: "Yandex Plus",
+			Price:       400,
+			UserID:      userID,
+			StartDate:   time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:     &end,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}, nil).Once()
+
+		var header metadata.MD
+		resp, err := client.CreateSubscription(ctx, &subscriptionv1.CreateSubscriptionRequest{
+			ServiceName: "Yandex Plus",
+			Price:       400,
+			UserId:      userID.String(),
+			StartDate:   "07-2025",
+			EndDate:     &endDate,
+		}, grpc.Header(&header))
+
+		require.NoError(t, err)
+		assert.Equal(t, "Yandex Plus", resp.GetServiceName())
+		assert.Equal(t, "07-2025", resp.GetStartDate())
+		assert.Equal(t, "12-2025", resp.GetEndDate())
+		assert.Equal(t, now.Unix(), resp.GetCreatedAt().AsTime().Unix())
+		assert.NotEmpty(t, header.Get("x-request-id"))
+	})
+
+	t.Run("Ошибка валидации", func(t *testing.T) {
+		_, err := client.CreateSubscription(ctx, &subscriptionv1.CreateSubscriptionRequest{
+			ServiceName: "Yandex Plus",
+			UserId:      userID.String(),
+			StartDate:   "07-2025",
+		})
+
+		st := status.Convert(err)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		require.Len(t, st.Details(), 1)
+		badRequest := st.Details()[0].(*errdetails.BadRequest)
+		assert.Equal(t, "price", badRequest.GetFieldViolations()[0].GetField())
+	})
+
+	t.Run("Ошибка валидации на русском", func(t *testing.T) {
+		ruCtx := metadata.AppendToOutgoingContext(ctx, "accept-language", "ru")
+		_, err := client.CreateSubscription(ruCtx, &subscriptionv1.CreateSubscriptionRequest{
+			ServiceName: "Yandex Plus",
+			UserId:      "not-a-uuid",
+			Price:       400,
+			StartDate:   "07-2025",
+		})
+
+		st := status.Convert(err)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		assert.Equal(t, "Запрос содержит некорректные поля", st.Message())
+	})
+
+	t.Run("Ошибка валидации в сервисе", func(t *testing.T) {
+		mockService.On("Create", mock.Anything, mock.Anything).
+			Return(nil, subscription.NewValidationError("end_date", subscription.CodeEndBeforeStart, "end date cannot be before start date")).Once()
+
+		_, err := client.CreateSubscription(ctx, &subscriptionv1.CreateSubscriptionRequest{
+			ServiceName: "Yandex Plus",
+			Price:       400,
+			UserId:      userID.String(),
+			StartDate:   "07-2025",
+			EndDate:     &endDate,
+		})
+
+		st := status.Convert(err)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		require.Len(t, st.Details(), 1)
+		violation := st.Details()[0].(*errdetails.BadRequest).GetFieldViolations()[0]
+		assert.Equal(t, "end_date", violation.GetField())
+		assert.Equal(t, "end date cannot be before start date", violation.GetDescription())
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestSubscriptionServer_GetSubscription(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	client := newTestClient(t, mockService)
+	ctx := context.Background()
+
+	t.Run("Подписка найдена", func(t *testing.T) {
+		id := uuid.New()
+		mockService.On("Get", mock.Anything, id).Return(&subscription.Subscription{
+			ID:          id,
+			ServiceName: "Netflix",
+			Price:       999,
+			UserID:      uuid.New(),
+			StartDate:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		}, nil).Once()
+
+		resp, err := client.GetSubscription(ctx, &subscriptionv1.GetSubscriptionRequest{Id: id.String()})
+		require.NoError(t, err)
+		assert.Equal(t, id.String(), resp.GetId())
+		assert.Nil(t, resp.EndDate)
+	})
+
+	t.Run("Цена не помещается в int32", func(t *testing.T) {
+		id := uuid.New()
+		mockService.On("Get", mock.Anything, id).Return(&subscription.Subscription{
+			ID:          id,
+			ServiceName: "Netflix",
+			Price:       math.MaxInt32 + 1,
+			UserID:      uuid.New(),
+			StartDate:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		}, nil).Once()
+
+		resp, err := client.GetSubscription(ctx, &subscriptionv1.GetSubscriptionRequest{Id: id.String()})
+		assert.Equal(t, codes.OutOfRange, status.Code(err))
+		assert.Nil(t, resp)
+	})
+
+	t.Run("Подписка не найдена", func(t *testing.T) {
+		id := uuid.New()
+		mockService.On("Get", mock.Anything, id).
+			Return(nil, errors.Join(errors.New("failed to get subscription"), subscription.ErrSubscriptionNotFound)).Once()
+
+		_, err := client.GetSubscription(ctx, &subscriptionv1.GetSubscriptionRequest{Id: id.String()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Некорректный ID", func(t *testing.T) {
+		_, err := client.GetSubscription(ctx, &subscriptionv1.GetSubscriptionRequest{Id: "invalid"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Внутренняя ошибка", func(t *testing.T) {
+		id := uuid.New()
+		mockService.On("Get", mock.Anything, id).Return(nil, errors.New("database is down")).Once()
+
+		_, err := client.GetSubscription(ctx, &subscriptionv1.GetSubscriptionRequest{Id: id.String()})
+		st := status.Convert(err)
+		assert.Equal(t, codes.Internal, st.Code())
+		assert.Equal(t, "Failed to get subscription", st.Message())
+	})
+
+	t.Run("Паника в сервисе", func(t *testing.T) {
+		id := uuid.New()
+		mockService.On("Get", mock.Anything, id).Run(func(mock.Arguments) {
+			panic("boom")
+		}).Once()
+
+		_, err := client.GetSubscription(ctx, &subscriptionv1.GetSubscriptionRequest{Id: id.String()})
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestSubscriptionServer_UpdateAndDelete(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	client := newTestClient(t, mockService)
+	ctx := context.Background()
+	id := uuid.New()
+
+	t.Run("Частичное обновление", func(t *testing.T) {
+		price := int32(500)
+		emptyEnd := ""
+		expectedPrice := 500
+		mockService.On("Update", mock.Anything, id, subscription.UpdateSubscriptionRequest{
+			Price:   &expectedPrice,
+			EndDate: &emptyEnd,
+		}).Return(&subscription.Subscription{ID: id, Price: 500}, nil).Once()
+
+		resp, err := client.UpdateSubscription(ctx, &subscriptionv1.UpdateSubscriptionRequest{
+			Id:      id.String(),
+			Price:   &price,
+			EndDate: &emptyEnd,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(500), resp.GetPrice())
+	})
+
+	t.Run("Удаление", func(t *testing.T) {
+		mockService.On("Delete", mock.Anything, id).Return(nil).Once()
+
+		_, err := client.DeleteSubscription(ctx, &subscriptionv1.DeleteSubscriptionRequest{Id: id.String()})
+		assert.NoError(t, err)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestSubscriptionServer_ListSubscriptions(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	client := newTestClient(t, mockService)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	subs := []*subscription.Subscription{
+		{ID: uuid.New(), UserID: userID},
+		{ID: uuid.New(), UserID: userID},
+		{ID: uuid.New(), UserID: userID},
+	}
+
+	t.Run("Первая страница", func(t *testing.T) {
+		mockService.On("List", mock.Anything, subscription.ListFilter{UserID: &userID, Limit: 3, Offset: 0}).
+			Return(subs, nil).Once()
+
+		userIDStr := userID.String()
+		resp, err := client.ListSubscriptions(ctx, &subscriptionv1.ListSubscriptionsRequest{
+			UserId:   &userIDStr,
+			PageSize: 2,
+		})
+		require.NoError(t, err)
+		assert.Len(t, resp.GetSubscriptions(), 2)
+		require.NotEmpty(t, resp.GetNextPageToken())
+
+		offset, err := decodePageToken(resp.GetNextPageToken())
+		require.NoError(t, err)
+		assert.Equal(t, 2, offset)
+	})
+
+	t.Run("Последняя страница", func(t *testing.T) {
+		mockService.On("List", mock.Anything, subscription.ListFilter{Limit: 3, Offset: 2}).
+			Return(subs[2:], nil).Once()
+
+		resp, err := client.ListSubscriptions(ctx, &subscriptionv1.ListSubscriptionsRequest{
+			PageSize:  2,
+			PageToken: encodePageToken(2),
+		})
+		require.NoError(t, err)
+		assert.Len(t, resp.GetSubscriptions(), 1)
+		assert.Empty(t, resp.GetNextPageToken())
+	})
+
+	t.Run("Некорректный токен страницы", func(t *testing.T) {
+		_, err := client.ListSubscriptions(ctx, &subscriptionv1.ListSubscriptionsRequest{PageToken: "!!!"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Слишком большая страница", func(t *testing.T) {
+		_, err := client.ListSubscriptions(ctx, &subscriptionv1.ListSubscriptionsRequest{PageSize: maxPageSize + 1})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestSubscriptionServer_CalculateTotalCost(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	client := newTestClient(t, mockService)
+	ctx := context.Background()
+
+	t.Run("Успешный расчет", func(t *testing.T) {
+		mockService.On("CalculateTotalCost", mock.Anything, subscription.SubscriptionFilter{
+			StartPeriod: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndPeriod:   time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+		}).Return(&subscription.TotalCostResponse{TotalCost: 4800}, nil).Once()
+
+		resp, err := client.CalculateTotalCost(ctx, &subscriptionv1.CalculateTotalCostRequest{
+			StartPeriod: "01-2025",
+			EndPeriod:   "12-2025",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(4800), resp.GetTotalCost())
+	})
+
+	t.Run("Конец периода раньше начала", func(t *testing.T) {
+		_, err := client.CalculateTotalCost(ctx, &subscriptionv1.CalculateTotalCostRequest{
+			StartPeriod: "12-2025",
+			EndPeriod:   "01-2025",
+		})
+
+		st := status.Convert(err)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		require.Len(t, st.Details(), 1)
+		assert.Equal(t, "end_period", st.Details()[0].(*errdetails.BadRequest).GetFieldViolations()[0].GetField())
+	})
+
+	t.Run("Не указан период", func(t *testing.T) {
+		_, err := client.CalculateTotalCost(ctx, &subscriptionv1.CalculateTotalCostRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,310 @@
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	subscriptionv1 "github.com/subscription-service/api/proto/subscription/v1"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/i18n"
+	"github.com/subscription-service/internal/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Ограничения размера страницы ListSubscriptions
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// SubscriptionServer реализует gRPC API подписок поверх subscription.Service
+type SubscriptionServer struct {
+	subscriptionv1.UnimplementedSubscriptionServiceServer
+
+	service   subscription.Service
+	validator *validator.Validate
+}
+
+// NewSubscriptionServer создает новый экземпляр gRPC-сервера подписок
+func NewSubscriptionServer(service subscription.Service) *SubscriptionServer {
+	return &SubscriptionServer{
+		service:   service,
+		validator: validation.Validator(),
+	}
+}
+
+// CreateSubscription создает новую подписку
+func (s *SubscriptionServer) CreateSubscription(ctx context.Context, req *subscriptionv1.CreateSubscriptionRequest) (*subscriptionv1.Subscription, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, invalidArgument(ctx, "Request contains invalid fields", invalidUUIDField(ctx, "user_id"))
+	}
+
+	createReq := subscription.CreateSubscriptionRequest{
+		ServiceName: req.GetServiceName(),
+		Price:       int(req.GetPrice()),
+		UserID:      userID,
+		StartDate:   req.GetStartDate(),
+		EndDate:     req.EndDate,
+	}
+
+	// Валидируем запрос теми же правилами, что и REST API
+	if err := s.validator.Struct(createReq); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		return nil, s.validationError(ctx, err)
+	}
+
+	sub, err := s.service.Create(ctx, createReq)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create subscription")
+		return nil, serviceError(ctx, err, "Failed to create subscription")
+	}
+
+	return toProto(ctx, sub)
+}
+
+// GetSubscription возвращает подписку по ID
+func (s *SubscriptionServer) GetSubscription(ctx context.Context, req *subscriptionv1.GetSubscriptionRequest) (*subscriptionv1.Subscription, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, invalidArgument(ctx, "Subscription ID must be a valid UUID", invalidUUIDField(ctx, "id"))
+	}
+
+	sub, err := s.service.Get(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("id", id.String()).Msg("Failed to get subscription")
+		return nil, serviceError(ctx, err, "Failed to get subscription")
+	}
+
+	return toProto(ctx, sub)
+}
+
+// UpdateSubscription частично обновляет подписку
+func (s *SubscriptionServer) UpdateSubscription(ctx context.Context, req *subscriptionv1.UpdateSubscriptionRequest) (*subscriptionv1.Subscription, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, invalidArgument(ctx, "Subscription ID must be a valid UUID", invalidUUIDField(ctx, "id"))
+	}
+
+	updateReq := subscription.UpdateSubscriptionRequest{
+		ServiceName: req.GetServiceName(),
+		StartDate:   req.GetStartDate(),
+		EndDate:     req.EndDate,
+	}
+	if req.Price != nil {
+		price := int(req.GetPrice())
+		updateReq.Price = &price
+	}
+
+	if err := s.validator.Struct(updateReq); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		return nil, s.validationError(ctx, err)
+	}
+
+	sub, err := s.service.Update(ctx, id, updateReq)
+	if err != nil {
+		log.Error().Err(err).Str("id", id.String()).Msg("Failed to update subscription")
+		return nil, serviceError(ctx, err, "Failed to update subscription")
+	}
+
+	return toProto(ctx, sub)
+}
+
+// DeleteSubscription удаляет подписку по ID
+func (s *SubscriptionServer) DeleteSubscription(ctx context.Context, req *subscriptionv1.DeleteSubscriptionRequest) (*emptypb.Empty, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, invalidArgument(ctx, "Subscription ID must be a valid UUID", invalidUUIDField(ctx, "id"))
+	}
+
+	if err := s.service.Delete(ctx, id); err != nil {
+		log.Error().Err(err).Str("id", id.String()).Msg("Failed to delete subscription")
+		return nil, serviceError(ctx, err, "Failed to delete subscription")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListSubscriptions возвращает страницу подписок с фильтрацией
+func (s *SubscriptionServer) ListSubscriptions(ctx context.Context, req *subscriptionv1.ListSubscriptionsRequest) (*subscriptionv1.ListSubscriptionsResponse, error) {
+	var filter subscription.ListFilter
+
+	if req.UserId != nil {
+		userID, err := uuid.Parse(req.GetUserId())
+		if err != nil {
+			return nil, invalidArgument(ctx, "Request contains invalid fields", invalidUUIDField(ctx, "user_id"))
+		}
+		filter.UserID = &userID
+	}
+
+	if req.GetServiceName() != "" {
+		serviceName := req.GetServiceName()
+		filter.ServiceName = &serviceName
+	}
+
+	pageSize := int(req.GetPageSize())
+	switch {
+	case pageSize < 0 || pageSize > maxPageSize:
+		return nil, invalidArgument(ctx, "Request contains invalid fields", fieldViolation("page_size",
+			i18n.T(ctx, "{0} must be between {1} and {2}", "page_size", "0", strconv.Itoa(maxPageSize))))
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	}
+
+	offset, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, invalidArgument(ctx, "Request contains invalid fields", fieldViolation("page_token",
+			i18n.T(ctx, "{0} is invalid", "page_token")))
+	}
+
+	// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+	filter.Limit = pageSize + 1
+	filter.Offset = offset
+
+	subs, err := s.service.List(ctx, filter)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list subscriptions")
+		return nil, serviceError(ctx, err, "Failed to list subscriptions")
+	}
+
+	resp := &subscriptionv1.ListSubscriptionsResponse{}
+	if len(subs) > pageSize {
+		subs = subs[:pageSize]
+		resp.NextPageToken = encodePageToken(offset + pageSize)
+	}
+
+	resp.Subscriptions = make([]*subscriptionv1.Subscription, 0, len(subs))
+	for _, sub := range subs {
+		msg, err := toProto(ctx, sub)
+		if err != nil {
+			return nil, err
+		}
+		resp.Subscriptions = append(resp.Subscriptions, msg)
+	}
+
+	return resp, nil
+}
+
+// CalculateTotalCost рассчитывает суммарную стоимость подписок за период
+func (s *SubscriptionServer) CalculateTotalCost(ctx context.Context, req *subscriptionv1.CalculateTotalCostRequest) (*subscriptionv1.CalculateTotalCostResponse, error) {
+	var filter subscription.SubscriptionFilter
+
+	if req.UserId != nil {
+		userID, err := uuid.Parse(req.GetUserId())
+		if err != nil {
+			return nil, invalidArgument(ctx, "Request contains invalid fields", invalidUUIDField(ctx, "user_id"))
+		}
+		filter.UserID = &userID
+	}
+
+	if req.GetServiceName() != "" {
+		serviceName := req.GetServiceName()
+		filter.ServiceName = &serviceName
+	}
+
+	// Период обязателен
+	if req.GetStartPeriod() == "" {
+		return nil, invalidArgument(ctx, "Request contains invalid fields", requiredField(ctx, "start_period"))
+	}
+	if req.GetEndPeriod() == "" {
+		return nil, invalidArgument(ctx, "Request contains invalid fields", requiredField(ctx, "end_period"))
+	}
+
+	startPeriod, err := subscription.ParseMonthYear(req.GetStartPeriod())
+	if err != nil {
+		return nil, invalidArgument(ctx, "Request contains invalid fields", monthYearField(ctx, "start_period"))
+	}
+
+	endPeriod, err := subscription.ParseMonthYear(req.GetEndPeriod())
+	if err != nil {
+		return nil, invalidArgument(ctx, "Request contains invalid fields", monthYearField(ctx, "end_period"))
+	}
+
+	if endPeriod.Before(startPeriod) {
+		return nil, invalidArgument(ctx, "Request contains invalid fields", fieldViolation("end_period",
+			i18n.T(ctx, "{0} cannot be before {1}", "end_period", "start_period")))
+	}
+
+	filter.StartPeriod = startPeriod
+	filter.EndPeriod = endPeriod
+
+	totalCost, err := s.service.CalculateTotalCost(ctx, filter)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to calculate total cost")
+		return nil, serviceError(ctx, err, "Failed to calculate total cost")
+	}
+
+	return &subscriptionv1.CalculateTotalCostResponse{TotalCost: int64(totalCost.TotalCost)}, nil
+}
+
+// validationError преобразует ошибки validator в нарушения полей
+func (s *SubscriptionServer) validationError(ctx context.Context, err error) error {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return invalidArgument(ctx, "Request contains invalid input")
+	}
+
+	trans := i18n.Translator(ctx)
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(verrs))
+	for _, fe := range verrs {
+		violations = append(violations, fieldViolation(fe.Field(), fe.Translate(trans)))
+	}
+	return invalidArgument(ctx, "Request contains invalid fields", violations...)
+}
+
+// toProto преобразует доменную подписку в сообщение protobuf. Цена, не
+// помещающаяся в int32, возвращается ошибкой codes.OutOfRange, а не усекается
+func toProto(ctx context.Context, sub *subscription.Subscription) (*subscriptionv1.Subscription, error) {
+	if sub.Price > subscription.MaxPrice {
+		log.Error().Str("id", sub.ID.String()).Int("price", sub.Price).Msg("Subscription price is out of range")
+		return nil, priceOutOfRange(ctx)
+	}
+
+	msg := &subscriptionv1.Subscription{
+		Id:          sub.ID.String(),
+		ServiceName: sub.ServiceName,
+		Price:       int32(sub.Price),
+		UserId:      sub.UserID.String(),
+		StartDate:   subscription.FormatMonthYear(sub.StartDate),
+		CreatedAt:   timestamppb.New(sub.CreatedAt),
+		UpdatedAt:   timestamppb.New(sub.UpdatedAt),
+	}
+	if sub.EndDate != nil {
+		endDate := subscription.FormatMonthYear(*sub.EndDate)
+		msg.EndDate = &endDate
+	}
+	return msg, nil
+}
+
+// encodePageToken кодирует смещение следующей страницы в непрозрачный токен
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+// decodePageToken возвращает смещение из токена страницы; пустой токен - первая страница
+func decodePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		return 0, errors.New("negative page offset")
+	}
+	return offset, nil
+}
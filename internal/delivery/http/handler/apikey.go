@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/apikey"
+	"github.com/subscription-service/internal/validation"
+)
+
+// APIKeyHandler обрабатывает HTTP запросы управления ключами API
+type APIKeyHandler struct {
+	service   apikey.Service
+	validator *validator.Validate
+}
+
+// NewAPIKeyHandler создает новый экземпляр обработчика ключей API
+func NewAPIKeyHandler(service apikey.Service) *APIKeyHandler {
+	return &APIKeyHandler{
+		service:   service,
+		validator: validation.Validator(),
+	}
+}
+
+// Issue обрабатывает запрос на выпуск ключа
+// @Summary Выпустить ключ API
+// @Description Выпускает ключ с указанными правами доступа. Токен возвращается только в этом ответе
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body apikey.IssueKeyRequest true "Название и права ключа"
+// @Success 201 {object} apikey.IssuedKey
+// @Failure 400 {object} problem.Details
+// @Failure 401 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/api-keys [post]
+func (h *APIKeyHandler) Issue(w http.ResponseWriter, r *http.Request) {
+	var req apikey.IssueKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to decode request body")
+		respondWithProblem(w, r, problem.CodeInvalidPayload, "Request body is not valid JSON")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Validation failed")
+		respondWithValidationError(w, r, err)
+		return
+	}
+
+	issued, err := h.service.Issue(r.Context(), req)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to issue API key")
+		respondWithServiceError(w, r, err, "Failed to issue API key")
+		return
+	}
+
+	zerolog.Ctx(r.Context()).Info().Str("api_key", issued.Prefix).Str("name", issued.Name).Msg("API key issued")
+	respondWithJSON(w, r, http.StatusCreated, issued)
+}
+
+// List обрабатывает запрос на получение списка ключей
+// @Summary Список ключей API
+// @Description Возвращает все ключи, включая отозванные, без токенов
+// @Tags api-keys
+// @Produce json
+// @Success 200 {array} apikey.Key
+// @Failure 401 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/api-keys [get]
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.List(r.Context())
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to list API keys")
+		respondWithServiceError(w, r, err, "Failed to list API keys")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, keys)
+}
+
+// Revoke обрабатывает запрос на отзыв ключа
+// @Summary Отозвать ключ API
+// @Description Отзывает ключ; запросы с ним отклоняются, а сам ключ остается в списке
+// @Tags api-keys
+// @Param id path string true "ID ключа"
+// @Success 204 "No Content"
+// @Failure 400 {object} problem.Details
+// @Failure 401 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Invalid UUID format")
+		respondWithProblem(w, r, problem.CodeInvalidID, "API key ID must be a valid UUID")
+		return
+	}
+
+	if err := h.service.Revoke(r.Context(), id); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("id", id.String()).Msg("Failed to revoke API key")
+		respondWithServiceError(w, r, err, "Failed to revoke API key")
+		return
+	}
+
+	zerolog.Ctx(r.Context()).Info().Str("id", id.String()).Msg("API key revoked")
+	w.WriteHeader(http.StatusNoContent)
+}
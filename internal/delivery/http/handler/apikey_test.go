@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/apikey"
+)
+
+// MockAPIKeyService мок для сервиса ключей API
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) Issue(ctx context.Context, req apikey.IssueKeyRequest) (*apikey.IssuedKey, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apikey.IssuedKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) List(ctx context.Context) ([]*apikey.Key, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*apikey.Key), args.Error(1)
+}
+
+func (m *MockAPIKeyService) Revoke(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestAPIKeyHandler(t *testing.T) {
+	newRouter := func(handler *APIKeyHandler) http.Handler {
+		r := chi.NewRouter()
+		r.Post("/api/v1/api-keys", handler.Issue)
+		r.Delete("/api/v1/api-keys/{id}", handler.Revoke)
+		return r
+	}
+
+	t.Run("выпуск возвращает токен, но не хеш", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		req := apikey.IssueKeyRequest{Name: "reports", Scopes: []string{"reports:read"}}
+		mockService.On("Issue", mock.Anything, req).Return(&apikey.IssuedKey{
+			Key: apikey.Key{
+				ID:     uuid.New(),
+				Name:   "reports",
+				Prefix: "0123456789ab",
+				Hash:   []byte("hash"),
+				Scopes: []auth.Scope{auth.ScopeReportsRead},
+			},
+			Token: "sk_0123456789ab_secret",
+		}, nil)
+
+		body := `{"name": "reports", "scopes": ["reports:read"]}`
+		w := httptest.NewRecorder()
+		newRouter(NewAPIKeyHandler(mockService)).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/api-keys", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "sk_0123456789ab_secret", response["token"])
+		assert.NotContains(t, response, "hash")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("неизвестное право доступа", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		mockService.On("Issue", mock.Anything, mock.Anything).
+			Return(nil, fmt.Errorf("%w: %q", apikey.ErrUnknownScope, "subscriptions:delete"))
+
+		body := `{"name": "bad", "scopes": ["subscriptions:delete"]}`
+		w := httptest.NewRecorder()
+		newRouter(NewAPIKeyHandler(mockService)).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/api-keys", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+		assert.Equal(t, problem.CodeValidationFailed, details.Code)
+		assert.Equal(t, "scopes", details.Errors[0].Field)
+	})
+
+	t.Run("отзыв неизвестного ключа", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		id := uuid.New()
+		mockService.On("Revoke", mock.Anything, id).Return(apikey.ErrKeyNotFound)
+
+		w := httptest.NewRecorder()
+		newRouter(NewAPIKeyHandler(mockService)).ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/v1/api-keys/"+id.String(), nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+		assert.Equal(t, problem.CodeNotFound, details.Code)
+	})
+}
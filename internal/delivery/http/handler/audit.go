@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/audit"
+	"github.com/subscription-service/internal/i18n"
+)
+
+// Ограничения размера страницы журнала аудита
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// AuditHandler обрабатывает HTTP запросы к журналу аудита изменений подписок
+type AuditHandler struct {
+	service audit.Service
+}
+
+// NewAuditHandler создает новый экземпляр обработчика журнала аудита
+func NewAuditHandler(service audit.Service) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// History обрабатывает запрос на получение истории изменений подписки
+// @Summary История изменений подписки
+// @Description Возвращает записи журнала аудита подписки, начиная с последних. История удаленной подписки остается доступной
+// @Tags audit
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param actor query string false "Исполнитель изменения"
+// @Param operation query string false "Вид изменения: create, update, delete, restore"
+// @Param from query string false "Начало периода (RFC 3339, включительно)"
+// @Param to query string false "Конец периода (RFC 3339, исключительно)"
+// @Param limit query int false "Размер страницы (1-500, по умолчанию 50)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} audit.Entry
+// @Failure 400 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/subscriptions/{id}/history [get]
+func (h *AuditHandler) History(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Invalid UUID format")
+		respondWithProblem(w, r, problem.CodeInvalidID, "Subscription ID must be a valid UUID")
+		return
+	}
+
+	filter, fieldErr := parseAuditFilter(r)
+	if fieldErr != nil {
+		zerolog.Ctx(r.Context()).Error().Str("field", fieldErr.Field).Msg("Invalid audit filter")
+		respondWithQueryError(w, r, *fieldErr)
+		return
+	}
+
+	entries, err := h.service.History(r.Context(), id, filter)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("id", id.String()).Msg("Failed to get subscription history")
+		respondWithServiceError(w, r, err, "Failed to get subscription history")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, entries)
+}
+
+// List обрабатывает запрос к журналу аудита по всем подпискам
+// @Summary Журнал аудита
+// @Description Возвращает записи журнала аудита по всем подпискам, начиная с последних
+// @Tags audit
+// @Produce json
+// @Param subscription_id query string false "ID подписки"
+// @Param actor query string false "Исполнитель изменения"
+// @Param operation query string false "Вид изменения: create, update, delete, restore"
+// @Param from query string false "Начало периода (RFC 3339, включительно)"
+// @Param to query string false "Конец периода (RFC 3339, исключительно)"
+// @Param limit query int false "Размер страницы (1-500, по умолчанию 50)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} audit.Entry
+// @Failure 400 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/audit [get]
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter, fieldErr := parseAuditFilter(r)
+	if fieldErr != nil {
+		zerolog.Ctx(r.Context()).Error().Str("field", fieldErr.Field).Msg("Invalid audit filter")
+		respondWithQueryError(w, r, *fieldErr)
+		return
+	}
+
+	if idStr := r.URL.Query().Get("subscription_id"); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			zerolog.Ctx(r.Context()).Error().Err(err).Str("subscription_id", idStr).Msg("Invalid subscription ID format")
+			respondWithQueryError(w, r, invalidUUIDField(r, "subscription_id"))
+			return
+		}
+		filter.SubscriptionID = &id
+	}
+
+	entries, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to list audit entries")
+		respondWithServiceError(w, r, err, "Failed to list audit entries")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, entries)
+}
+
+// parseAuditFilter разбирает общие параметры выборки журнала аудита из query-строки
+func parseAuditFilter(r *http.Request) (audit.Filter, *problem.FieldError) {
+	filter := audit.Filter{}
+	query := r.URL.Query()
+
+	filter.Actor = query.Get("actor")
+
+	if operationStr := query.Get("operation"); operationStr != "" {
+		operation := audit.Operation(operationStr)
+		if !operation.Valid() {
+			names := make([]string, 0, len(audit.Operations))
+			for _, known := range audit.Operations {
+				names = append(names, string(known))
+			}
+			return filter, &problem.FieldError{
+				Field:   "operation",
+				Code:    "oneof",
+				Message: i18n.T(r.Context(), "{0} must be one of {1}", "operation", strings.Join(names, ", ")),
+				Param:   strings.Join(names, " "),
+			}
+		}
+		filter.Operation = &operation
+	}
+
+	var fieldErr *problem.FieldError
+	if filter.From, fieldErr = parseTime(r, "from"); fieldErr != nil {
+		return filter, fieldErr
+	}
+	if filter.To, fieldErr = parseTime(r, "to"); fieldErr != nil {
+		return filter, fieldErr
+	}
+
+	limit, offset, fieldErr := parsePage(r, defaultAuditLimit, maxAuditLimit)
+	if fieldErr != nil {
+		return filter, fieldErr
+	}
+	filter.Limit, filter.Offset = limit, offset
+
+	return filter, nil
+}
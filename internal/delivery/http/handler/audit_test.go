@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/audit"
+	"github.com/subscription-service/internal/domain/subscription"
+)
+
+// MockAuditService мок для сервиса журнала аудита
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) History(ctx context.Context, subscriptionID uuid.UUID, filter audit.Filter) ([]*audit.Entry, error) {
+	args := m.Called(ctx, subscriptionID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*audit.Entry), args.Error(1)
+}
+
+func (m *MockAuditService) List(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*audit.Entry), args.Error(1)
+}
+
+func TestAuditHandler(t *testing.T) {
+	newRouter := func(handler *AuditHandler) http.Handler {
+		r := chi.NewRouter()
+		r.Get("/api/v1/subscriptions/{id}/history", handler.History)
+		r.Get("/api/v1/audit", handler.List)
+		return r
+	}
+
+	t.Run("история подписки", func(t *testing.T) {
+		mockService := new(MockAuditService)
+		id := uuid.New()
+		mockService.On("History", mock.Anything, id, audit.Filter{Limit: defaultAuditLimit}).Return([]*audit.Entry{
+			{ID: uuid.New(), SubscriptionID: id, Operation: audit.OperationUpdate, Actor: "alice@example.com", Changes: map[string]audit.Change{
+				"price": {Before: json.RawMessage(`400`), After: json.RawMessage(`500`)},
+			}},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		newRouter(NewAuditHandler(mockService)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/"+id.String()+"/history", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var entries []audit.Entry
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+		assert.Len(t, entries, 1)
+		assert.JSONEq(t, `500`, string(entries[0].Changes["price"].After))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("история неизвестной подписки", func(t *testing.T) {
+		mockService := new(MockAuditService)
+		id := uuid.New()
+		mockService.On("History", mock.Anything, id, mock.Anything).Return(nil, subscription.ErrSubscriptionNotFound)
+
+		w := httptest.NewRecorder()
+		newRouter(NewAuditHandler(mockService)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/"+id.String()+"/history", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("журнал с фильтрами по времени", func(t *testing.T) {
+		mockService := new(MockAuditService)
+		id := uuid.New()
+		operation := audit.OperationDelete
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		mockService.On("List", mock.Anything, audit.Filter{
+			SubscriptionID: &id,
+			Actor:          "bob",
+			Operation:      &operation,
+			From:           &from,
+			To:             &to,
+			Limit:          10,
+			Offset:         5,
+		}).Return([]*audit.Entry{}, nil)
+
+		url := "/api/v1/audit?subscription_id=" + id.String() + "&actor=bob&operation=delete" +
+			"&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z&limit=10&offset=5"
+		w := httptest.NewRecorder()
+		newRouter(NewAuditHandler(mockService)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("некорректные параметры журнала", func(t *testing.T) {
+		for query, field := range map[string]string{
+			"from=yesterday":      "from",
+			"operation=rename":    "operation",
+			"subscription_id=abc": "subscription_id",
+			"limit=1000":          "limit",
+		} {
+			mockService := new(MockAuditService)
+
+			w := httptest.NewRecorder()
+			newRouter(NewAuditHandler(mockService)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/audit?"+query, nil))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+			var details problem.Details
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+			assert.Equal(t, problem.CodeInvalidQuery, details.Code)
+			assert.Equal(t, field, details.Errors[0].Field)
+			mockService.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+		}
+	})
+}
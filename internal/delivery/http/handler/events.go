@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/member"
+	"github.com/subscription-service/internal/i18n"
+)
+
+// lastEventIDHeader - заголовок, которым EventSource передает номер последнего
+// полученного события при переподключении
+const lastEventIDHeader = "Last-Event-ID"
+
+// EventStreamConfig хранит настройки потока событий
+type EventStreamConfig struct {
+	// PollInterval - период опроса журнала событий
+	PollInterval time.Duration
+	// Heartbeat - период отправки комментариев, не дающих прокси закрыть соединение
+	Heartbeat time.Duration
+	// BatchSize - максимальное число событий, читаемых из журнала за раз
+	BatchSize int
+}
+
+// EventHandler отдает поток событий подписок в формате Server-Sent Events
+type EventHandler struct {
+	events event.Log
+	policy member.Policy
+	config EventStreamConfig
+}
+
+// NewEventHandler создает новый экземпляр обработчика потока событий.
+// policy ограничивает поток событиями подписок, которые видит клиент
+func NewEventHandler(events event.Log, policy member.Policy, config EventStreamConfig) *EventHandler {
+	return &EventHandler{events: events, policy: policy, config: config}
+}
+
+// Stream обрабатывает запрос на подписку на поток событий
+// @Summary Поток событий подписок
+// @Description Отдает события создания, изменения, отмены и удаления подписок в формате Server-Sent Events. Поле id события - его номер в журнале; при переподключении он передается в заголовке Last-Event-ID, и поток продолжается со следующего события
+// @Tags subscriptions
+// @Produce text/event-stream
+// @Param user_id query string false "ID пользователя"
+// @Param Last-Event-ID header string false "Номер последнего полученного события"
+// @Param last_event_id query string false "То же, что Last-Event-ID, для первого подключения EventSource"
+// @Success 200 {string} string "Поток событий"
+// @Failure 400 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/subscriptions/events [get]
+func (h *EventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var filter event.LogFilter
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			zerolog.Ctx(r.Context()).Error().Err(err).Str("user_id", userIDStr).Msg("Invalid user ID format")
+			respondWithQueryError(w, r, invalidUUIDField(r, "user_id"))
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	// Участник получает события только своих подписок
+	userID, err := h.policy.ScopeUser(ctx, filter.UserID)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to authorize event stream")
+		respondWithServiceError(w, r, err, "Failed to stream subscription events")
+		return
+	}
+	filter.UserID = userID
+
+	cursor, fieldErr := parseLastEventID(r)
+	if fieldErr != nil {
+		zerolog.Ctx(r.Context()).Error().Str("field", fieldErr.Field).Msg("Invalid last event ID")
+		respondWithQueryError(w, r, *fieldErr)
+		return
+	}
+
+	// Без номера последнего события поток начинается с текущего конца журнала
+	if cursor < 0 {
+		last, err := h.events.LastSequence(ctx)
+		if err != nil {
+			zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to get last event sequence")
+			respondWithProblem(w, r, problem.CodeInternal, "Failed to stream subscription events")
+			return
+		}
+		cursor = last
+	}
+
+	// Поток открыт дольше WriteTimeout сервера
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		zerolog.Ctx(r.Context()).Warn().Err(err).Msg("Failed to reset write deadline")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Отключаем буферизацию в nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Streaming is not supported")
+		return
+	}
+
+	poll := time.NewTicker(h.config.PollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(h.config.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		records, err := h.events.ListAfter(ctx, cursor, filter, h.config.BatchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			// Сбой чтения журнала не обрывает поток: следующий опрос продолжит с того же места
+			zerolog.Ctx(r.Context()).Error().Err(err).Int64("cursor", cursor).Msg("Failed to read event log")
+		}
+
+		for _, record := range records {
+			if err := writeEvent(w, record); err != nil {
+				zerolog.Ctx(r.Context()).Debug().Err(err).Msg("Event stream closed by client")
+				return
+			}
+			cursor = record.Sequence
+		}
+		if len(records) > 0 {
+			if err := rc.Flush(); err != nil {
+				return
+			}
+			// Полная пачка означает, что в журнале могут быть еще события
+			if len(records) == h.config.BatchSize {
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeEvent записывает событие журнала в формате Server-Sent Events
+func writeEvent(w io.Writer, record event.Record) error {
+	data, err := json.Marshal(record.Event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", record.Sequence, record.Type, data)
+	return err
+}
+
+// parseLastEventID разбирает номер последнего полученного события из заголовка
+// Last-Event-ID или параметра last_event_id. Возвращает -1, если номер не передан
+func parseLastEventID(r *http.Request) (int64, *problem.FieldError) {
+	field, value := lastEventIDHeader, r.Header.Get(lastEventIDHeader)
+	if value == "" {
+		field, value = "last_event_id", r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return -1, nil
+	}
+
+	cursor, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || cursor < 0 {
+		return -1, &problem.FieldError{
+			Field:   field,
+			Code:    "numeric",
+			Message: i18n.T(r.Context(), "{0} is invalid", field),
+		}
+	}
+	return cursor, nil
+}
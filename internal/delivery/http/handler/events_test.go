@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/event"
+)
+
+// memoryEventLog - журнал событий в памяти
+type memoryEventLog struct {
+	mu      sync.Mutex
+	records []event.Record
+	filters []event.LogFilter
+}
+
+func (l *memoryEventLog) append(t *testing.T, eventType event.Type, userID uuid.UUID) event.Record {
+	evt, err := event.New(eventType, map[string]string{"user_id": userID.String()})
+	require.NoError(t, err)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	record := event.Record{Sequence: int64(len(l.records) + 1), Event: evt}
+	l.records = append(l.records, record)
+	return record
+}
+
+func (l *memoryEventLog) ListAfter(_ context.Context, after int64, filter event.LogFilter, limit int) ([]event.Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.filters = append(l.filters, filter)
+
+	var records []event.Record
+	for _, record := range l.records {
+		if record.Sequence <= after {
+			continue
+		}
+		if filter.UserID != nil {
+			var data struct {
+				UserID uuid.UUID `json:"user_id"`
+			}
+			if err := json.Unmarshal(record.Data, &data); err != nil || data.UserID != *filter.UserID {
+				continue
+			}
+		}
+		if len(records) == limit {
+			break
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (l *memoryEventLog) LastSequence(_ context.Context) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int64(len(l.records)), nil
+}
+
+// scopedPolicy - политика доступа, ограничивающая клиента подписками
+// пользователя restrictedTo; uuid.Nil снимает ограничение
+type scopedPolicy struct {
+	restrictedTo uuid.UUID
+}
+
+func (p scopedPolicy) ScopeUser(_ context.Context, userID *uuid.UUID) (*uuid.UUID, error) {
+	if p.restrictedTo == uuid.Nil {
+		return userID, nil
+	}
+	if userID != nil && *userID != p.restrictedTo {
+		return nil, auth.ErrForbidden
+	}
+	return &p.restrictedTo, nil
+}
+
+// sseEvent - разобранное событие потока
+type sseEvent struct {
+	id, name, data string
+}
+
+// readEvents читает из потока n событий, пропуская комментарии
+func readEvents(t *testing.T, scanner *bufio.Scanner, n int) []sseEvent {
+	var (
+		events  []sseEvent
+		current sseEvent
+	)
+	for len(events) < n && scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if current.id != "" {
+				events = append(events, current)
+			}
+			current = sseEvent{}
+		case strings.HasPrefix(line, "id: "):
+			current.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			current.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	require.Len(t, events, n)
+	return events
+}
+
+func TestEventHandler_Stream(t *testing.T) {
+	config := EventStreamConfig{PollInterval: 10 * time.Millisecond, Heartbeat: time.Second, BatchSize: 2}
+
+	// openStream подключается к потоку и возвращает сканер его строк
+	openStream := func(t *testing.T, server *httptest.Server, query string, header http.Header) (*http.Response, *bufio.Scanner) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		t.Cleanup(cancel)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/subscriptions/events"+query, nil)
+		require.NoError(t, err)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp, bufio.NewScanner(resp.Body)
+	}
+
+	t.Run("поток продолжается после Last-Event-ID", func(t *testing.T) {
+		events := &memoryEventLog{}
+		userID := uuid.New()
+		events.append(t, event.SubscriptionCreated, userID)
+		second := events.append(t, event.SubscriptionUpdated, userID)
+		events.append(t, event.SubscriptionCancelled, userID)
+		events.append(t, event.SubscriptionDeleted, userID)
+
+		server := httptest.NewServer(http.HandlerFunc(NewEventHandler(events, scopedPolicy{}, config).Stream))
+		t.Cleanup(server.Close)
+
+		resp, scanner := openStream(t, server, "", http.Header{"Last-Event-ID": {"1"}})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		// Три события не помещаются в одну пачку и читаются двумя запросами к журналу
+		received := readEvents(t, scanner, 3)
+		assert.Equal(t, "2", received[0].id)
+		assert.Equal(t, string(event.SubscriptionUpdated), received[0].name)
+		assert.Equal(t, "3", received[1].id)
+		assert.Equal(t, "4", received[2].id)
+		assert.Equal(t, string(event.SubscriptionDeleted), received[2].name)
+
+		var evt event.Event
+		require.NoError(t, json.Unmarshal([]byte(received[0].data), &evt))
+		assert.Equal(t, second.ID, evt.ID)
+	})
+
+	t.Run("без Last-Event-ID передаются только новые события пользователя", func(t *testing.T) {
+		events := &memoryEventLog{}
+		userID := uuid.New()
+		events.append(t, event.SubscriptionCreated, userID)
+
+		server := httptest.NewServer(http.HandlerFunc(NewEventHandler(events, scopedPolicy{}, config).Stream))
+		t.Cleanup(server.Close)
+
+		_, scanner := openStream(t, server, "?user_id="+userID.String(), nil)
+
+		events.append(t, event.SubscriptionCreated, uuid.New())
+		expected := events.append(t, event.SubscriptionUpdated, userID)
+
+		received := readEvents(t, scanner, 1)
+		assert.Equal(t, "3", received[0].id)
+		assert.Equal(t, string(expected.Type), received[0].name)
+
+		events.mu.Lock()
+		defer events.mu.Unlock()
+		require.NotEmpty(t, events.filters)
+		assert.Equal(t, userID, *events.filters[0].UserID)
+	})
+
+	t.Run("участнику запрещены события другого пользователя", func(t *testing.T) {
+		handler := NewEventHandler(&memoryEventLog{}, scopedPolicy{restrictedTo: uuid.New()}, config)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/events?user_id="+uuid.New().String(), nil)
+		rr := httptest.NewRecorder()
+		handler.Stream(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &details))
+		assert.Equal(t, problem.CodeForbidden, details.Code)
+	})
+
+	t.Run("некорректные параметры", func(t *testing.T) {
+		handler := NewEventHandler(&memoryEventLog{}, scopedPolicy{}, config)
+
+		for _, tc := range []struct {
+			name, target, header, field string
+		}{
+			{name: "user_id", target: "/api/v1/subscriptions/events?user_id=invalid", field: "user_id"},
+			{name: "Last-Event-ID", target: "/api/v1/subscriptions/events", header: "abc", field: "Last-Event-ID"},
+			{name: "last_event_id", target: "/api/v1/subscriptions/events?last_event_id=-1", field: "last_event_id"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+				if tc.header != "" {
+					req.Header.Set("Last-Event-ID", tc.header)
+				}
+				rr := httptest.NewRecorder()
+				handler.Stream(rr, req)
+
+				assert.Equal(t, http.StatusBadRequest, rr.Code)
+				assert.Contains(t, rr.Body.String(), `"field":"`+tc.field+`"`)
+			})
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog"
+	"github.com/subscription-service/internal/health"
+)
+
+// HealthHandler обрабатывает пробы живости и готовности для оркестратора
+type HealthHandler struct {
+	readiness *health.Readiness
+}
+
+// NewHealthHandler создает новый экземпляр обработчика проб
+func NewHealthHandler(readiness *health.Readiness) *HealthHandler {
+	return &HealthHandler{readiness: readiness}
+}
+
+// Live сообщает, что процесс работает. Зависимости не проверяются: их
+// недоступность не лечится перезапуском
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, r, http.StatusOK, map[string]string{"status": health.StatusOK})
+}
+
+// Ready выполняет проверки готовности и возвращает отчет по каждой;
+// неготовый сервис отвечает 503
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	report := h.readiness.Check(r.Context())
+	if !report.Ready() {
+		zerolog.Ctx(r.Context()).Warn().Interface("checks", report.Checks).Msg("Readiness check failed")
+		respondWithJSON(w, r, http.StatusServiceUnavailable, report)
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, report)
+}
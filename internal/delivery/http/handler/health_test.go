@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/health"
+)
+
+func TestHealthHandler(t *testing.T) {
+	var dbErr error
+	readiness := health.NewReadiness(time.Second)
+	readiness.Add("database", func(context.Context) error { return dbErr })
+	h := NewHealthHandler(readiness)
+
+	ready := func() (int, health.Report) {
+		w := httptest.NewRecorder()
+		h.Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var report health.Report
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		return w.Code, report
+	}
+
+	t.Run("готов", func(t *testing.T) {
+		code, report := ready()
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+	})
+
+	t.Run("база недоступна", func(t *testing.T) {
+		dbErr = errors.New("connection refused")
+		defer func() { dbErr = nil }()
+
+		code, report := ready()
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, health.StatusFail, report.Status)
+		assert.Equal(t, "connection refused", report.Checks["database"].Error)
+
+		// Живость от зависимостей не зависит
+		w := httptest.NewRecorder()
+		h.Live(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("остановка", func(t *testing.T) {
+		readiness.Shutdown()
+
+		code, report := ready()
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Contains(t, report.Checks, "shutdown")
+	})
+}
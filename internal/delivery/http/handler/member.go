@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/member"
+	"github.com/subscription-service/internal/validation"
+)
+
+// MemberHandler обрабатывает HTTP запросы управления участниками организации
+type MemberHandler struct {
+	service   member.Service
+	validator *validator.Validate
+}
+
+// NewMemberHandler создает новый экземпляр обработчика участников
+func NewMemberHandler(service member.Service) *MemberHandler {
+	return &MemberHandler{
+		service:   service,
+		validator: validation.Validator(),
+	}
+}
+
+// List обрабатывает запрос на получение списка участников
+// @Summary Список участников организации
+// @Description Возвращает пользователей, которым в организации назначена роль. Доступно владельцам и администраторам
+// @Tags members
+// @Produce json
+// @Success 200 {array} member.Member
+// @Failure 401 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/members [get]
+func (h *MemberHandler) List(w http.ResponseWriter, r *http.Request) {
+	members, err := h.service.List(r.Context())
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to list members")
+		respondWithServiceError(w, r, err, "Failed to list members")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, members)
+}
+
+// SetRole обрабатывает запрос на назначение роли
+// @Summary Назначить роль участнику
+// @Description Добавляет пользователя в организацию или меняет его роль. Роль owner назначают и снимают только владельцы; ключам API нужно право admin
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param user_id path string true "ID пользователя"
+// @Param request body member.SetRoleRequest true "Роль"
+// @Success 200 {object} member.Member
+// @Failure 400 {object} problem.Details
+// @Failure 401 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/members/{user_id} [put]
+func (h *MemberHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Invalid UUID format")
+		respondWithProblem(w, r, problem.CodeInvalidID, "User ID must be a valid UUID")
+		return
+	}
+
+	var req member.SetRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to decode request body")
+		respondWithProblem(w, r, problem.CodeInvalidPayload, "Request body is not valid JSON")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Validation failed")
+		respondWithValidationError(w, r, err)
+		return
+	}
+
+	m, err := h.service.SetRole(r.Context(), userID, req)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("user_id", userID.String()).Msg("Failed to set member role")
+		respondWithServiceError(w, r, err, "Failed to set member role")
+		return
+	}
+
+	zerolog.Ctx(r.Context()).Info().Str("user_id", userID.String()).Str("role", string(m.Role)).Msg("Member role set")
+	respondWithJSON(w, r, http.StatusOK, m)
+}
+
+// Remove обрабатывает запрос на исключение участника
+// @Summary Исключить участника
+// @Description Удаляет роль пользователя в организации; после этого он работает только со своими подписками
+// @Tags members
+// @Param user_id path string true "ID пользователя"
+// @Success 204 "No Content"
+// @Failure 400 {object} problem.Details
+// @Failure 401 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/members/{user_id} [delete]
+func (h *MemberHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Invalid UUID format")
+		respondWithProblem(w, r, problem.CodeInvalidID, "User ID must be a valid UUID")
+		return
+	}
+
+	if err := h.service.Remove(r.Context(), userID); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("user_id", userID.String()).Msg("Failed to remove member")
+		respondWithServiceError(w, r, err, "Failed to remove member")
+		return
+	}
+
+	zerolog.Ctx(r.Context()).Info().Str("user_id", userID.String()).Msg("Member removed")
+	w.WriteHeader(http.StatusNoContent)
+}
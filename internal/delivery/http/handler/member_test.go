@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/member"
+	"github.com/subscription-service/internal/repository/memory"
+	"github.com/subscription-service/internal/usecase"
+)
+
+// MockMemberService мок для сервиса участников
+type MockMemberService struct {
+	mock.Mock
+}
+
+func (m *MockMemberService) List(ctx context.Context) ([]*member.Member, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*member.Member), args.Error(1)
+}
+
+func (m *MockMemberService) SetRole(ctx context.Context, userID uuid.UUID, req member.SetRoleRequest) (*member.Member, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*member.Member), args.Error(1)
+}
+
+func (m *MockMemberService) Remove(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func TestMemberHandler(t *testing.T) {
+	newRouter := func(handler *MemberHandler) http.Handler {
+		r := chi.NewRouter()
+		r.Get("/api/v1/members", handler.List)
+		r.Put("/api/v1/members/{user_id}", handler.SetRole)
+		r.Delete("/api/v1/members/{user_id}", handler.Remove)
+		return r
+	}
+	userID := uuid.New()
+
+	t.Run("назначение роли", func(t *testing.T) {
+		mockService := new(MockMemberService)
+		mockService.On("SetRole", mock.Anything, userID, member.SetRoleRequest{Role: "viewer"}).
+			Return(&member.Member{UserID: userID, Role: member.RoleViewer}, nil)
+
+		w := httptest.NewRecorder()
+		newRouter(NewMemberHandler(mockService)).ServeHTTP(w,
+			httptest.NewRequest(http.MethodPut, "/api/v1/members/"+userID.String(), strings.NewReader(`{"role": "viewer"}`)))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response member.Member
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, member.RoleViewer, response.Role)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("неизвестная роль", func(t *testing.T) {
+		mockService := new(MockMemberService)
+		mockService.On("SetRole", mock.Anything, userID, mock.Anything).
+			Return(nil, fmt.Errorf("%w: %q", member.ErrUnknownRole, "superuser"))
+
+		w := httptest.NewRecorder()
+		newRouter(NewMemberHandler(mockService)).ServeHTTP(w,
+			httptest.NewRequest(http.MethodPut, "/api/v1/members/"+userID.String(), strings.NewReader(`{"role": "superuser"}`)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+		assert.Equal(t, problem.CodeValidationFailed, details.Code)
+		assert.Equal(t, "role", details.Errors[0].Field)
+	})
+
+	t.Run("недостаточная роль", func(t *testing.T) {
+		mockService := new(MockMemberService)
+		mockService.On("List", mock.Anything).Return(nil, fmt.Errorf("failed to list members: %w", member.ErrInsufficientRole))
+
+		w := httptest.NewRecorder()
+		newRouter(NewMemberHandler(mockService)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/members", nil))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+		assert.Equal(t, problem.CodeForbidden, details.Code)
+		assert.Equal(t, "Your role in the organization does not allow this operation", details.Detail)
+	})
+
+	t.Run("исключение пользователя не из организации", func(t *testing.T) {
+		mockService := new(MockMemberService)
+		mockService.On("Remove", mock.Anything, userID).Return(member.ErrMemberNotFound)
+
+		w := httptest.NewRecorder()
+		newRouter(NewMemberHandler(mockService)).ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/v1/members/"+userID.String(), nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestMemberHandler_APIKey(t *testing.T) {
+	// Ключи API не ограничены ролями, но управлять участниками без права
+	// admin не могут: проверка выполняется политикой настоящего сервиса
+	userID := uuid.New()
+	members := memory.NewMemberRepository()
+	handler := NewMemberHandler(usecase.NewMemberService(members, usecase.NewPolicy(members)))
+
+	r := chi.NewRouter()
+	r.Put("/api/v1/members/{user_id}", handler.SetRole)
+	request := func(scopes ...auth.Scope) *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/members/"+userID.String(), strings.NewReader(`{"role": "owner"}`))
+		return req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: "api-key:" + uuid.NewString(), Scopes: scopes}))
+	}
+
+	t.Run("ключ с правом subscriptions:write не назначает владельца", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, request(auth.ScopeSubscriptionsWrite))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		_, err := members.Get(context.Background(), userID)
+		assert.ErrorIs(t, err, member.ErrMemberNotFound)
+	})
+
+	t.Run("ключ с правом admin назначает владельца", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, request(auth.ScopeAdmin))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		m, err := members.Get(context.Background(), userID)
+		require.NoError(t, err)
+		assert.Equal(t, member.RoleOwner, m.Role)
+	})
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/delivery/http/middleware"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/apikey"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/member"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/domain/webhook"
+	"github.com/subscription-service/internal/i18n"
+)
+
+// respondWithProblem отправляет ответ об ошибке в формате RFC 7807.
+// Заголовок и описание переводятся на язык запроса
+func respondWithProblem(w http.ResponseWriter, r *http.Request, code problem.Code, detail string, fields ...problem.FieldError) {
+	ctx := r.Context()
+
+	details := problem.New(code, i18n.T(ctx, detail)).
+		WithInstance(middleware.GetRequestID(ctx)).
+		WithErrors(fields...)
+	details.Title = i18n.T(ctx, details.Title)
+
+	problem.Write(w, details)
+}
+
+// respondWithValidationError отправляет ошибки validator в виде списка ошибок полей
+func respondWithValidationError(w http.ResponseWriter, r *http.Request, err error) {
+	fields := problem.FromValidationErrors(err, i18n.Translator(r.Context()))
+	respondWithProblem(w, r, problem.CodeValidationFailed, "Request contains invalid fields", fields...)
+}
+
+// respondWithQueryError отправляет ошибку некорректного параметра query-строки
+func respondWithQueryError(w http.ResponseWriter, r *http.Request, field problem.FieldError) {
+	respondWithProblem(w, r, problem.CodeInvalidQuery, "Query contains invalid parameters", field)
+}
+
+// respondWithServiceError сопоставляет ошибку сервисного слоя с кодом ошибки API.
+// fallback используется как описание для непредвиденных ошибок
+func respondWithServiceError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	var validationErr *subscription.ValidationError
+	switch {
+	case errors.Is(err, subscription.ErrSubscriptionNotFound):
+		respondWithProblem(w, r, problem.CodeNotFound, "Subscription not found")
+	case errors.Is(err, member.ErrInsufficientRole):
+		respondWithProblem(w, r, problem.CodeForbidden, "Your role in the organization does not allow this operation")
+	case errors.Is(err, auth.ErrForbidden):
+		respondWithProblem(w, r, problem.CodeForbidden, "Access to subscriptions of another user is forbidden")
+	case errors.Is(err, subscription.ErrSubscriptionNotDeleted):
+		respondWithProblem(w, r, problem.CodeConflict, "Subscription is not deleted")
+	case errors.Is(err, webhook.ErrEndpointNotFound):
+		respondWithProblem(w, r, problem.CodeNotFound, "Webhook endpoint not found")
+	case errors.Is(err, webhook.ErrDeliveryNotFound):
+		respondWithProblem(w, r, problem.CodeNotFound, "Webhook delivery not found")
+	case errors.Is(err, webhook.ErrUnknownEventType):
+		respondWithProblem(w, r, problem.CodeValidationFailed, "Request contains invalid fields", problem.FieldError{
+			Field:   "events",
+			Code:    "oneof",
+			Message: i18n.T(r.Context(), "{0} must be one of {1}", "events", eventTypeList()),
+		})
+	case errors.Is(err, apikey.ErrKeyNotFound):
+		respondWithProblem(w, r, problem.CodeNotFound, "API key not found")
+	case errors.Is(err, apikey.ErrUnknownScope):
+		respondWithProblem(w, r, problem.CodeValidationFailed, "Request contains invalid fields", problem.FieldError{
+			Field:   "scopes",
+			Code:    "oneof",
+			Message: i18n.T(r.Context(), "{0} must be one of {1}", "scopes", scopeList()),
+		})
+	case errors.Is(err, member.ErrMemberNotFound):
+		respondWithProblem(w, r, problem.CodeNotFound, "Member not found")
+	case errors.Is(err, member.ErrUnknownRole):
+		respondWithProblem(w, r, problem.CodeValidationFailed, "Request contains invalid fields", problem.FieldError{
+			Field:   "role",
+			Code:    "oneof",
+			Message: i18n.T(r.Context(), "{0} must be one of {1}", "role", roleList()),
+		})
+	case errors.As(err, &validationErr):
+		respondWithProblem(w, r, problem.CodeValidationFailed, "Request contains invalid fields", problem.FieldError{
+			Field:   validationErr.Field,
+			Code:    validationErr.Code,
+			Message: i18n.T(r.Context(), validationErr.Message),
+		})
+	case errors.Is(err, subscription.ErrInvalidInput):
+		respondWithProblem(w, r, problem.CodeInvalidInput, "Request contains invalid input")
+	default:
+		respondWithProblem(w, r, problem.CodeInternal, fallback)
+	}
+}
+
+// eventTypeList перечисляет известные типы событий через запятую
+func eventTypeList() string {
+	names := make([]string, 0, len(event.Types))
+	for _, t := range event.Types {
+		names = append(names, string(t))
+	}
+	return strings.Join(names, ", ")
+}
+
+// scopeList перечисляет известные права доступа через запятую
+func scopeList() string {
+	names := make([]string, 0, len(auth.Scopes))
+	for _, scope := range auth.Scopes {
+		names = append(names, string(scope))
+	}
+	return strings.Join(names, ", ")
+}
+
+// roleList перечисляет роли участников через запятую
+func roleList() string {
+	names := make([]string, 0, len(member.Roles))
+	for _, role := range member.Roles {
+		names = append(names, string(role))
+	}
+	return strings.Join(names, ", ")
+}
+
+// requiredField описывает отсутствующий обязательный параметр
+func requiredField(r *http.Request, field string) problem.FieldError {
+	return problem.FieldError{Field: field, Code: "required", Message: i18n.T(r.Context(), "{0} is required", field)}
+}
+
+// invalidUUIDField описывает параметр, который должен быть UUID
+func invalidUUIDField(r *http.Request, field string) problem.FieldError {
+	return problem.FieldError{Field: field, Code: "uuid", Message: i18n.T(r.Context(), "{0} must be a valid UUID", field)}
+}
+
+// monthYearField описывает параметр с датой в неверном формате
+func monthYearField(r *http.Request, field string) problem.FieldError {
+	return problem.FieldError{Field: field, Code: subscription.CodeMonthYear, Message: i18n.T(r.Context(), "{0} must be in MM-YYYY format", field)}
+}
+
+// parseBool разбирает необязательный логический параметр query-строки
+func parseBool(r *http.Request, field string) (bool, *problem.FieldError) {
+	value := r.URL.Query().Get(field)
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, &problem.FieldError{
+			Field:   field,
+			Code:    "boolean",
+			Message: i18n.T(r.Context(), "{0} is invalid", field),
+		}
+	}
+	return parsed, nil
+}
+
+// parseTime разбирает необязательную метку времени RFC 3339 из query-строки
+func parseTime(r *http.Request, field string) (*time.Time, *problem.FieldError) {
+	value := r.URL.Query().Get(field)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, &problem.FieldError{
+			Field:   field,
+			Code:    "datetime",
+			Message: i18n.T(r.Context(), "{0} must be an RFC 3339 timestamp", field),
+		}
+	}
+	return &parsed, nil
+}
+
+// parsePage разбирает параметры limit и offset постраничной выборки
+func parsePage(r *http.Request, defaultLimit, maxLimit int) (int, int, *problem.FieldError) {
+	query := r.URL.Query()
+	limit, offset := defaultLimit, 0
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > maxLimit {
+			return 0, 0, &problem.FieldError{
+				Field:   "limit",
+				Code:    "range",
+				Message: i18n.T(r.Context(), "{0} must be between {1} and {2}", "limit", "1", strconv.Itoa(maxLimit)),
+			}
+		}
+		limit = parsed
+	}
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		parsed, err := strconv.Atoi(offsetStr)
+		if err != nil || parsed < 0 {
+			return 0, 0, &problem.FieldError{
+				Field:   "offset",
+				Code:    "min",
+				Message: i18n.T(r.Context(), "{0} is invalid", "offset"),
+			}
+		}
+		offset = parsed
+	}
+
+	return limit, offset, nil
+}
+
+// respondWithJSON отправляет JSON-ответ
+func respondWithJSON(w http.ResponseWriter, r *http.Request, code int, payload interface{}) {
+	response, err := json.Marshal(payload)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to marshal JSON response")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, err = w.Write(response)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to write response")
+	}
+}
@@ -3,13 +3,19 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
+	"github.com/subscription-service/internal/delivery/http/problem"
 	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/export"
+	"github.com/subscription-service/internal/i18n"
+	"github.com/subscription-service/internal/validation"
 )
 
 // SubscriptionHandler обрабатывает HTTP запросы связанные с подписками
@@ -22,7 +28,7 @@ type SubscriptionHandler struct {
 func NewSubscriptionHandler(service subscription.Service) *SubscriptionHandler {
 	return &SubscriptionHandler{
 		service:   service,
-		validator: validator.New(),
+		validator: validation.Validator(),
 	}
 }
 
@@ -34,73 +40,80 @@ func NewSubscriptionHandler(service subscription.Service) *SubscriptionHandler {
 // @Produce json
 // @Param request body subscription.CreateSubscriptionRequest true "Данные для создания подписки"
 // @Success 201 {object} subscription.Subscription
-// @Failure 400 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
 // @Router /api/v1/subscriptions [post]
 func (h *SubscriptionHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req subscription.CreateSubscriptionRequest
 
 	// Декодируем тело запроса
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Error().Err(err).Msg("Failed to decode request body")
-		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to decode request body")
+		respondWithProblem(w, r, problem.CodeInvalidPayload, "Request body is not valid JSON")
 		return
 	}
 
 	// Валидируем запрос
 	if err := h.validator.Struct(req); err != nil {
-		log.Error().Err(err).Msg("Validation failed")
-		respondWithError(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Validation failed")
+		respondWithValidationError(w, r, err)
 		return
 	}
 
 	// Создаем подписку
 	sub, err := h.service.Create(r.Context(), req)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to create subscription")
-		if errors.Is(err, subscription.ErrInvalidInput) {
-			respondWithError(w, http.StatusBadRequest, err.Error())
-			return
-		}
-		respondWithError(w, http.StatusInternalServerError, "Failed to create subscription")
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to create subscription")
+		respondWithServiceError(w, r, err, "Failed to create subscription")
 		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, sub)
+	respondWithJSON(w, r, http.StatusCreated, sub)
 }
 
 // Get обрабатывает запрос на получение подписки по ID
 // @Summary Получить подписку
-// @Description Получает информацию о подписке по её ID
+// @Description Получает информацию о подписке по её ID; с as_of - в том виде, в котором подписка существовала в указанный момент
 // @Tags subscriptions
 // @Accept json
 // @Produce json
 // @Param id path string true "ID подписки"
+// @Param as_of query string false "Момент времени (RFC 3339), по состоянию на который возвращается подписка"
 // @Success 200 {object} subscription.Subscription
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
 // @Router /api/v1/subscriptions/{id} [get]
 func (h *SubscriptionHandler) Get(w http.ResponseWriter, r *http.Request) {
 	id, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
-		log.Error().Err(err).Msg("Invalid UUID format")
-		respondWithError(w, http.StatusBadRequest, "Invalid UUID format")
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Invalid UUID format")
+		respondWithProblem(w, r, problem.CodeInvalidID, "Subscription ID must be a valid UUID")
+		return
+	}
+
+	asOf, fieldErr := parseTime(r, "as_of")
+	if fieldErr != nil {
+		zerolog.Ctx(r.Context()).Error().Str("as_of", r.URL.Query().Get("as_of")).Msg("Invalid as_of value")
+		respondWithQueryError(w, r, *fieldErr)
 		return
 	}
 
-	sub, err := h.service.Get(r.Context(), id)
+	var sub *subscription.Subscription
+	if asOf != nil {
+		sub, err = h.service.GetAsOf(r.Context(), id, *asOf)
+	} else {
+		sub, err = h.service.Get(r.Context(), id)
+	}
 	if err != nil {
-		log.Error().Err(err).Str("id", id.String()).Msg("Failed to get subscription")
-		if errors.Is(err, subscription.ErrSubscriptionNotFound) {
-			respondWithError(w, http.StatusNotFound, "Subscription not found")
-			return
-		}
-		respondWithError(w, http.StatusInternalServerError, "Failed to get subscription")
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("id", id.String()).Msg("Failed to get subscription")
+		respondWithServiceError(w, r, err, "Failed to get subscription")
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, sub)
+	respondWithJSON(w, r, http.StatusOK, sub)
 }
 
 // Update обрабатывает запрос на обновление подписки
@@ -112,101 +125,228 @@ func (h *SubscriptionHandler) Get(w http.ResponseWriter, r *http.Request) {
 // @Param id path string true "ID подписки"
 // @Param request body subscription.UpdateSubscriptionRequest true "Данные для обновления подписки"
 // @Success 200 {object} subscription.Subscription
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
 // @Router /api/v1/subscriptions/{id} [put]
 func (h *SubscriptionHandler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
-		log.Error().Err(err).Msg("Invalid UUID format")
-		respondWithError(w, http.StatusBadRequest, "Invalid UUID format")
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Invalid UUID format")
+		respondWithProblem(w, r, problem.CodeInvalidID, "Subscription ID must be a valid UUID")
 		return
 	}
 
 	var req subscription.UpdateSubscriptionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Error().Err(err).Msg("Failed to decode request body")
-		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to decode request body")
+		respondWithProblem(w, r, problem.CodeInvalidPayload, "Request body is not valid JSON")
 		return
 	}
 
 	// Валидируем запрос
 	if err := h.validator.Struct(req); err != nil {
-		log.Error().Err(err).Msg("Validation failed")
-		respondWithError(w, http.StatusBadRequest, "Validation error: "+err.Error())
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Validation failed")
+		respondWithValidationError(w, r, err)
 		return
 	}
 
 	sub, err := h.service.Update(r.Context(), id, req)
 	if err != nil {
-		log.Error().Err(err).Str("id", id.String()).Msg("Failed to update subscription")
-		if errors.Is(err, subscription.ErrSubscriptionNotFound) {
-			respondWithError(w, http.StatusNotFound, "Subscription not found")
-			return
-		}
-		if errors.Is(err, subscription.ErrInvalidInput) {
-			respondWithError(w, http.StatusBadRequest, err.Error())
-			return
-		}
-		respondWithError(w, http.StatusInternalServerError, "Failed to update subscription")
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("id", id.String()).Msg("Failed to update subscription")
+		respondWithServiceError(w, r, err, "Failed to update subscription")
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, sub)
+	respondWithJSON(w, r, http.StatusOK, sub)
 }
 
 // Delete обрабатывает запрос на удаление подписки
 // @Summary Удалить подписку
-// @Description Удаляет подписку по её ID
+// @Description Помечает подписку удаленной. Удаленная подписка скрыта из выборок, может быть восстановлена и окончательно удаляется по истечении срока хранения
 // @Tags subscriptions
 // @Accept json
 // @Produce json
 // @Param id path string true "ID подписки"
 // @Success 204 "No Content"
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
 // @Router /api/v1/subscriptions/{id} [delete]
 func (h *SubscriptionHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
-		log.Error().Err(err).Msg("Invalid UUID format")
-		respondWithError(w, http.StatusBadRequest, "Invalid UUID format")
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Invalid UUID format")
+		respondWithProblem(w, r, problem.CodeInvalidID, "Subscription ID must be a valid UUID")
 		return
 	}
 
 	if err := h.service.Delete(r.Context(), id); err != nil {
-		log.Error().Err(err).Str("id", id.String()).Msg("Failed to delete subscription")
-		if errors.Is(err, subscription.ErrSubscriptionNotFound) {
-			respondWithError(w, http.StatusNotFound, "Subscription not found")
-			return
-		}
-		respondWithError(w, http.StatusInternalServerError, "Failed to delete subscription")
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("id", id.String()).Msg("Failed to delete subscription")
+		respondWithServiceError(w, r, err, "Failed to delete subscription")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// List обрабатывает запрос на получение списка всех подписок
+// Restore обрабатывает запрос на восстановление удаленной подписки
+// @Summary Восстановить подписку
+// @Description Снимает пометку об удалении с подписки, пока она не очищена по сроку хранения
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {object} subscription.Subscription
+// @Failure 400 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 409 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/subscriptions/{id}/restore [post]
+func (h *SubscriptionHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Invalid UUID format")
+		respondWithProblem(w, r, problem.CodeInvalidID, "Subscription ID must be a valid UUID")
+		return
+	}
+
+	sub, err := h.service.Restore(r.Context(), id)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("id", id.String()).Msg("Failed to restore subscription")
+		respondWithServiceError(w, r, err, "Failed to restore subscription")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, sub)
+}
+
+// List обрабатывает запрос на получение списка подписок
 // @Summary Список подписок
-// @Description Получает список всех подписок
+// @Description Получает список подписок с опциональной фильтрацией
 // @Tags subscriptions
 // @Accept json
 // @Produce json
+// @Param user_id query string false "ID пользователя"
+// @Param service_name query string false "Название сервиса"
+// @Param include_deleted query bool false "Учитывать удаленные подписки (только для владельцев и администраторов организации)"
+// @Param as_of query string false "Момент времени (RFC 3339), по состоянию на который строится ответ"
 // @Success 200 {array} subscription.Subscription
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
 // @Router /api/v1/subscriptions [get]
 func (h *SubscriptionHandler) List(w http.ResponseWriter, r *http.Request) {
-	subs, err := h.service.List(r.Context())
+	filter, fieldErr := parseListFilter(r)
+	if fieldErr != nil {
+		zerolog.Ctx(r.Context()).Error().Str("field", fieldErr.Field).Msg("Invalid list filter")
+		respondWithProblem(w, r, problem.CodeInvalidQuery, "Query contains invalid parameters", *fieldErr)
+		return
+	}
+
+	subs, err := h.service.List(r.Context(), filter)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to list subscriptions")
-		respondWithError(w, http.StatusInternalServerError, "Failed to list subscriptions")
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to list subscriptions")
+		respondWithServiceError(w, r, err, "Failed to list subscriptions")
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, subs)
+	respondWithJSON(w, r, http.StatusOK, subs)
+}
+
+// Export обрабатывает запрос на выгрузку подписок в файл
+// @Summary Выгрузить подписки
+// @Description Потоково выгружает подписки в CSV, NDJSON или XLSX с теми же фильтрами, что и список
+// @Tags subscriptions
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Формат выгрузки (csv, ndjson, xlsx)" default(csv)
+// @Param user_id query string false "ID пользователя"
+// @Param service_name query string false "Название сервиса"
+// @Param include_deleted query bool false "Учитывать удаленные подписки (только для владельцев и администраторов организации)"
+// @Param as_of query string false "Момент времени (RFC 3339), по состоянию на который строится ответ"
+// @Success 200 {file} file
+// @Failure 400 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/subscriptions/export [get]
+func (h *SubscriptionHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Unsupported export format")
+		respondWithProblem(w, r, problem.CodeInvalidQuery, "Unsupported export format", problem.FieldError{
+			Field:   "format",
+			Code:    "oneof",
+			Message: i18n.T(r.Context(), "{0} must be one of {1}", "format", "csv, ndjson, xlsx"),
+			Param:   "csv ndjson xlsx",
+		})
+		return
+	}
+
+	filter, fieldErr := parseListFilter(r)
+	if fieldErr != nil {
+		zerolog.Ctx(r.Context()).Error().Str("field", fieldErr.Field).Msg("Invalid export filter")
+		respondWithProblem(w, r, problem.CodeInvalidQuery, "Query contains invalid parameters", *fieldErr)
+		return
+	}
+
+	// Выгрузка большой таблицы может длиться дольше WriteTimeout сервера
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		zerolog.Ctx(r.Context()).Warn().Err(err).Msg("Failed to reset write deadline")
+	}
+
+	// Заголовки отправляются только вместе с первыми данными, чтобы до этого
+	// момента ошибку можно было вернуть обычным ответом
+	out := &lazyResponseWriter{ResponseWriter: w, onFirstWrite: func() {
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf(
+			`attachment; filename="subscriptions-%s.%s"`,
+			time.Now().UTC().Format("20060102-150405"), format.Extension(),
+		))
+		w.WriteHeader(http.StatusOK)
+	}}
+
+	ew, err := export.NewWriter(format, out)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to create export writer")
+		respondWithProblem(w, r, problem.CodeInternal, "Failed to export subscriptions")
+		return
+	}
+
+	rows := 0
+	err = h.service.Export(r.Context(), filter, func(sub *subscription.Subscription) error {
+		if err := ew.Write(sub); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushEvery == 0 {
+			if err := ew.Flush(); err != nil {
+				return err
+			}
+			_ = rc.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = ew.Close()
+	}
+
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Int("rows", rows).Msg("Failed to export subscriptions")
+		if !out.written {
+			respondWithServiceError(w, r, err, "Failed to export subscriptions")
+			return
+		}
+		// Часть файла уже отправлена: обрываем соединение, чтобы клиент
+		// не принял усеченную выгрузку за полную
+		panic(http.ErrAbortHandler)
+	}
+
+	zerolog.Ctx(r.Context()).Info().Int("rows", rows).Str("format", string(format)).Msg("Subscriptions exported")
 }
 
 // CalculateTotalCost обрабатывает запрос на подсчет общей стоимости подписок
@@ -217,11 +357,14 @@ func (h *SubscriptionHandler) List(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param user_id query string false "ID пользователя"
 // @Param service_name query string false "Название сервиса"
+// @Param include_deleted query bool false "Учитывать удаленные подписки (только для владельцев и администраторов организации)"
+// @Param as_of query string false "Момент времени (RFC 3339), по состоянию на который строится ответ"
 // @Param start_period query string true "Начало периода (MM-YYYY)"
 // @Param end_period query string true "Конец периода (MM-YYYY)"
 // @Success 200 {object} subscription.TotalCostResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
 // @Router /api/v1/subscriptions/calculate-cost [get]
 func (h *SubscriptionHandler) CalculateTotalCost(w http.ResponseWriter, r *http.Request) {
 	// Получаем параметры запроса
@@ -232,8 +375,8 @@ func (h *SubscriptionHandler) CalculateTotalCost(w http.ResponseWriter, r *http.
 	if userIDStr != "" {
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			log.Error().Err(err).Str("user_id", userIDStr).Msg("Invalid user ID format")
-			respondWithError(w, http.StatusBadRequest, "Invalid user ID format")
+			zerolog.Ctx(r.Context()).Error().Err(err).Str("user_id", userIDStr).Msg("Invalid user ID format")
+			respondWithQueryError(w, r, invalidUUIDField(r, "user_id"))
 			return
 		}
 		filter.UserID = &userID
@@ -245,40 +388,60 @@ func (h *SubscriptionHandler) CalculateTotalCost(w http.ResponseWriter, r *http.
 		filter.ServiceName = &serviceName
 	}
 
+	includeDeleted, fieldErr := parseBool(r, "include_deleted")
+	if fieldErr != nil {
+		zerolog.Ctx(r.Context()).Error().Str("include_deleted", r.URL.Query().Get("include_deleted")).Msg("Invalid include_deleted value")
+		respondWithQueryError(w, r, *fieldErr)
+		return
+	}
+	filter.IncludeDeleted = includeDeleted
+
+	asOf, fieldErr := parseTime(r, "as_of")
+	if fieldErr != nil {
+		zerolog.Ctx(r.Context()).Error().Str("as_of", r.URL.Query().Get("as_of")).Msg("Invalid as_of value")
+		respondWithQueryError(w, r, *fieldErr)
+		return
+	}
+	filter.AsOf = asOf
+
 	// Парсим период (обязательные параметры)
 	startPeriodStr := r.URL.Query().Get("start_period")
 	if startPeriodStr == "" {
-		log.Error().Msg("Start period is required")
-		respondWithError(w, http.StatusBadRequest, "Start period is required")
+		zerolog.Ctx(r.Context()).Error().Msg("Start period is required")
+		respondWithQueryError(w, r, requiredField(r, "start_period"))
 		return
 	}
 
 	endPeriodStr := r.URL.Query().Get("end_period")
 	if endPeriodStr == "" {
-		log.Error().Msg("End period is required")
-		respondWithError(w, http.StatusBadRequest, "End period is required")
+		zerolog.Ctx(r.Context()).Error().Msg("End period is required")
+		respondWithQueryError(w, r, requiredField(r, "end_period"))
 		return
 	}
 
 	// Конвертируем строки в time.Time
 	startPeriod, err := subscription.ParseMonthYear(startPeriodStr)
 	if err != nil {
-		log.Error().Err(err).Str("start_period", startPeriodStr).Msg("Invalid start period format")
-		respondWithError(w, http.StatusBadRequest, "Invalid start period format")
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("start_period", startPeriodStr).Msg("Invalid start period format")
+		respondWithQueryError(w, r, monthYearField(r, "start_period"))
 		return
 	}
 
 	endPeriod, err := subscription.ParseMonthYear(endPeriodStr)
 	if err != nil {
-		log.Error().Err(err).Str("end_period", endPeriodStr).Msg("Invalid end period format")
-		respondWithError(w, http.StatusBadRequest, "Invalid end period format")
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("end_period", endPeriodStr).Msg("Invalid end period format")
+		respondWithQueryError(w, r, monthYearField(r, "end_period"))
 		return
 	}
 
 	// Проверяем, что конечная дата не раньше начальной
 	if endPeriod.Before(startPeriod) {
-		log.Error().Msg("End period cannot be before start period")
-		respondWithError(w, http.StatusBadRequest, "End period cannot be before start period")
+		zerolog.Ctx(r.Context()).Error().Msg("End period cannot be before start period")
+		respondWithQueryError(w, r, problem.FieldError{
+			Field:   "end_period",
+			Code:    subscription.CodeEndBeforeStart,
+			Message: i18n.T(r.Context(), "{0} cannot be before {1}", "end_period", "start_period"),
+		})
 		return
 	}
 
@@ -288,37 +451,61 @@ func (h *SubscriptionHandler) CalculateTotalCost(w http.ResponseWriter, r *http.
 	// Вызываем сервис для расчета
 	totalCost, err := h.service.CalculateTotalCost(r.Context(), filter)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to calculate total cost")
-		respondWithError(w, http.StatusInternalServerError, "Failed to calculate total cost")
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to calculate total cost")
+		respondWithServiceError(w, r, err, "Failed to calculate total cost")
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, totalCost)
+	respondWithJSON(w, r, http.StatusOK, totalCost)
 }
 
-// ErrorResponse представляет структуру ответа с ошибкой
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
+// exportFlushEvery задает, через сколько строк выгрузка сбрасывается клиенту
+const exportFlushEvery = 500
 
-// respondWithError отправляет JSON-ответ с ошибкой
-func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, ErrorResponse{Error: message})
-}
+// parseListFilter разбирает параметры фильтрации списка из query-строки.
+// Право учитывать удаленные подписки (include_deleted) проверяет политика
+// доступа сервиса: без него запрос отклоняется с ответом 403
+func parseListFilter(r *http.Request) (subscription.ListFilter, *problem.FieldError) {
+	var filter subscription.ListFilter
 
-// respondWithJSON отправляет JSON-ответ
-func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
-	response, err := json.Marshal(payload)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to marshal JSON response")
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			fieldErr := invalidUUIDField(r, "user_id")
+			return filter, &fieldErr
+		}
+		filter.UserID = &userID
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	_, err = w.Write(response)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to write response")
+	if serviceName := r.URL.Query().Get("service_name"); serviceName != "" {
+		filter.ServiceName = &serviceName
+	}
+
+	includeDeleted, fieldErr := parseBool(r, "include_deleted")
+	if fieldErr != nil {
+		return filter, fieldErr
+	}
+	filter.IncludeDeleted = includeDeleted
+
+	if filter.AsOf, fieldErr = parseTime(r, "as_of"); fieldErr != nil {
+		return filter, fieldErr
+	}
+
+	return filter, nil
+}
+
+// lazyResponseWriter откладывает отправку заголовков до первой записи тела
+type lazyResponseWriter struct {
+	http.ResponseWriter
+	onFirstWrite func()
+	written      bool
+}
+
+// Write отправляет заголовки перед первым фрагментом данных
+func (lw *lazyResponseWriter) Write(p []byte) (int, error) {
+	if !lw.written {
+		lw.written = true
+		lw.onFirstWrite()
 	}
+	return lw.ResponseWriter.Write(p)
 }
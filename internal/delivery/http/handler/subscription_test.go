@@ -4,17 +4,27 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/delivery/http/middleware"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/member"
 	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/repository/memory"
+	"github.com/subscription-service/internal/usecase"
 )
 
 // MockSubscriptionService мок для сервиса подписок
@@ -38,6 +48,14 @@ func (m *MockSubscriptionService) Get(ctx context.Context, id uuid.UUID) (*subsc
 	return args.Get(0).(*subscription.Subscription), args.Error(1)
 }
 
+func (m *MockSubscriptionService) GetAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (*subscription.Subscription, error) {
+	args := m.Called(ctx, id, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
 func (m *MockSubscriptionService) Update(ctx context.Context, id uuid.UUID, req subscription.UpdateSubscriptionRequest) (*subscription.Subscription, error) {
 	args := m.Called(ctx, id, req)
 	if args.Get(0) == nil {
@@ -51,11 +69,24 @@ func (m *MockSubscriptionService) Delete(ctx context.Context, id uuid.UUID) erro
 	return args.Error(0)
 }
 
-func (m *MockSubscriptionService) List(ctx context.Context) ([]*subscription.Subscription, error) {
-	args := m.Called(ctx)
+func (m *MockSubscriptionService) Restore(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) List(ctx context.Context, filter subscription.ListFilter) ([]*subscription.Subscription, error) {
+	args := m.Called(ctx, filter)
 	return args.Get(0).([]*subscription.Subscription), args.Error(1)
 }
 
+func (m *MockSubscriptionService) Export(ctx context.Context, filter subscription.ListFilter, fn func(*subscription.Subscription) error) error {
+	args := m.Called(ctx, filter, fn)
+	return args.Error(0)
+}
+
 func (m *MockSubscriptionService) CalculateTotalCost(ctx context.Context, filter subscription.SubscriptionFilter) (*subscription.TotalCostResponse, error) {
 	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
@@ -171,6 +202,112 @@ func TestSubscriptionHandler_Get(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestSubscriptionHandler_ErrorLog(t *testing.T) {
+	// Ошибки пишутся логгером запроса, в который middleware.Trace добавляет
+	// ID трассировки, чтобы их можно было связать с трассой
+	mockService := new(MockSubscriptionService)
+	subscriptionID := uuid.New()
+	mockService.On("Get", mock.Anything, subscriptionID).Return(nil, errors.New("database error"))
+
+	r := chi.NewRouter()
+	r.Get("/api/v1/subscriptions/{id}", NewSubscriptionHandler(mockService).Get)
+
+	var logs bytes.Buffer
+	logger := zerolog.New(&logs).With().Str("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736").Logger()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/"+subscriptionID.String(), nil)
+	req = req.WithContext(logger.WithContext(req.Context()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, logs.String(), `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`)
+	assert.Contains(t, logs.String(), "Failed to get subscription")
+}
+
+func TestSubscriptionHandler_GetAsOf(t *testing.T) {
+	newRouter := func(service *MockSubscriptionService) *chi.Mux {
+		r := chi.NewRouter()
+		r.Get("/api/v1/subscriptions/{id}", NewSubscriptionHandler(service).Get)
+		return r
+	}
+
+	t.Run("ревизия на указанный момент", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		id := uuid.New()
+		asOf := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+		mockService.On("GetAsOf", mock.Anything, id, asOf).Return(&subscription.Subscription{ID: id, Price: 400}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/"+id.String()+"?as_of=2024-01-31T23:59:59Z", nil)
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body subscription.Subscription
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, 400, body.Price)
+		mockService.AssertExpectations(t)
+		mockService.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	})
+
+	t.Run("некорректный as_of", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/"+uuid.NewString()+"?as_of=2024-01-31", nil)
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+		assert.Len(t, details.Errors, 1)
+		assert.Equal(t, "as_of", details.Errors[0].Field)
+		assert.Equal(t, "datetime", details.Errors[0].Code)
+	})
+}
+
+func TestSubscriptionHandler_Restore(t *testing.T) {
+	newRouter := func(service *MockSubscriptionService) *chi.Mux {
+		r := chi.NewRouter()
+		r.Post("/api/v1/subscriptions/{id}/restore", NewSubscriptionHandler(service).Restore)
+		return r
+	}
+
+	t.Run("восстановленная подписка возвращается в ответе", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		id := uuid.New()
+		now := time.Now()
+		restored := &subscription.Subscription{ID: id, ServiceName: "Netflix", Price: 599, UserID: uuid.New(), StartDate: now, CreatedAt: now, UpdatedAt: now}
+		mockService.On("Restore", mock.Anything, id).Return(restored, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions/"+id.String()+"/restore", nil)
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body subscription.Subscription
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, id, body.ID)
+		assert.Nil(t, body.DeletedAt)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("подписка не удалена", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		id := uuid.New()
+		mockService.On("Restore", mock.Anything, id).Return(nil, subscription.ErrSubscriptionNotDeleted)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions/"+id.String()+"/restore", nil)
+		w := httptest.NewRecorder()
+		newRouter(mockService).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+		assert.Equal(t, problem.CodeConflict, details.Code)
+	})
+}
+
 func TestSubscriptionHandler_CalculateTotalCost(t *testing.T) {
 	// Создаем мок сервиса
 	mockService := new(MockSubscriptionService)
@@ -213,3 +350,348 @@ func TestSubscriptionHandler_CalculateTotalCost(t *testing.T) {
 	// Проверяем, что мок был вызван
 	mockService.AssertExpectations(t)
 }
+
+func TestSubscriptionHandler_Export(t *testing.T) {
+	userID := uuid.New()
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	subs := []*subscription.Subscription{
+		{ID: uuid.New(), ServiceName: "Netflix", Price: 599, UserID: userID, StartDate: now, CreatedAt: now, UpdatedAt: now},
+		{ID: uuid.New(), ServiceName: "Spotify", Price: 199, UserID: userID, StartDate: now, CreatedAt: now, UpdatedAt: now},
+	}
+
+	streamSubs := func(args mock.Arguments) {
+		fn := args.Get(2).(func(*subscription.Subscription) error)
+		for _, sub := range subs {
+			if err := fn(sub); err != nil {
+				return
+			}
+		}
+	}
+
+	t.Run("CSV с фильтром по пользователю", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		handler := NewSubscriptionHandler(mockService)
+
+		filter := subscription.ListFilter{UserID: &userID}
+		mockService.On("Export", mock.Anything, filter, mock.Anything).Run(streamSubs).Return(nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/export?format=csv&user_id="+userID.String(), nil)
+		w := httptest.NewRecorder()
+
+		handler.Export(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), ".csv")
+
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		assert.Len(t, lines, 3)
+		assert.True(t, strings.HasPrefix(lines[0], "id,service_name,price"))
+		assert.Contains(t, lines[1], "Netflix")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("с удаленными подписками", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		handler := NewSubscriptionHandler(mockService)
+
+		filter := subscription.ListFilter{IncludeDeleted: true}
+		mockService.On("Export", mock.Anything, filter, mock.Anything).Run(streamSubs).Return(nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/export?format=ndjson&include_deleted=true", nil)
+		w := httptest.NewRecorder()
+
+		handler.Export(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("на момент времени", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		handler := NewSubscriptionHandler(mockService)
+
+		asOf := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		filter := subscription.ListFilter{AsOf: &asOf}
+		mockService.On("Export", mock.Anything, filter, mock.Anything).Run(streamSubs).Return(nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/export?format=ndjson&as_of=2024-01-01T00:00:00Z", nil)
+		w := httptest.NewRecorder()
+
+		handler.Export(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("некорректный include_deleted", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		handler := NewSubscriptionHandler(mockService)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/export?include_deleted=maybe", nil)
+		w := httptest.NewRecorder()
+
+		handler.Export(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+		assert.Len(t, details.Errors, 1)
+		assert.Equal(t, "include_deleted", details.Errors[0].Field)
+		assert.Equal(t, "boolean", details.Errors[0].Code)
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		handler := NewSubscriptionHandler(mockService)
+
+		mockService.On("Export", mock.Anything, subscription.ListFilter{}, mock.Anything).Run(streamSubs).Return(nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/export?format=ndjson", nil)
+		w := httptest.NewRecorder()
+
+		handler.Export(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		assert.Len(t, lines, 2)
+		var first subscription.Subscription
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+		assert.Equal(t, subs[0].ID, first.ID)
+	})
+
+	t.Run("неизвестный формат", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		handler := NewSubscriptionHandler(mockService)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/export?format=pdf", nil)
+		w := httptest.NewRecorder()
+
+		handler.Export(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "Export", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ошибка до начала выгрузки", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		handler := NewSubscriptionHandler(mockService)
+
+		mockService.On("Export", mock.Anything, subscription.ListFilter{}, mock.Anything).Return(errors.New("database error"))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/export?format=ndjson", nil)
+		w := httptest.NewRecorder()
+
+		handler.Export(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	})
+}
+
+func TestSubscriptionHandler_IncludeDeleted(t *testing.T) {
+	// Право на include_deleted проверяет политика доступа настоящего сервиса
+	memberID, adminID := uuid.New(), uuid.New()
+	members := memory.NewMemberRepository()
+	require.NoError(t, members.Save(context.Background(), &member.Member{UserID: memberID, Role: member.RoleMember}))
+	require.NoError(t, members.Save(context.Background(), &member.Member{UserID: adminID, Role: member.RoleAdmin}))
+
+	repo := memory.NewSubscriptionRepository()
+	deleted := &subscription.Subscription{UserID: memberID, ServiceName: "Netflix", Price: 400, StartDate: time.Now()}
+	require.NoError(t, repo.Create(context.Background(), deleted))
+	require.NoError(t, repo.Delete(context.Background(), deleted.ID))
+
+	handler := NewSubscriptionHandler(usecase.NewSubscriptionService(repo, usecase.WithPolicy(usecase.NewPolicy(members))))
+	request := func(userID uuid.UUID, target string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		return req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{UserID: userID, Scopes: auth.UserScopes}))
+	}
+
+	t.Run("участник не получает удаленные подписки", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.List(w, request(memberID, "/api/v1/subscriptions?include_deleted=true"))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.NotContains(t, w.Body.String(), deleted.ID.String())
+
+		w = httptest.NewRecorder()
+		handler.Export(w, request(memberID, "/api/v1/subscriptions/export?format=ndjson&include_deleted=true"))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.NotContains(t, w.Body.String(), deleted.ID.String())
+
+		w = httptest.NewRecorder()
+		handler.CalculateTotalCost(w, request(memberID, "/api/v1/subscriptions/calculate-cost?start_period=01-2020&end_period=12-2030&include_deleted=true"))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("администратор организации получает удаленные подписки", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.List(w, request(adminID, "/api/v1/subscriptions?include_deleted=true"))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), deleted.ID.String())
+
+		w = httptest.NewRecorder()
+		handler.CalculateTotalCost(w, request(adminID, "/api/v1/subscriptions/calculate-cost?start_period=01-2020&end_period=12-2030&include_deleted=true"))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"total_cost": 400}`, w.Body.String())
+	})
+}
+
+func TestSubscriptionHandler_ProblemDetails(t *testing.T) {
+	decodeProblem := func(t *testing.T, w *httptest.ResponseRecorder) problem.Details {
+		assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+		return details
+	}
+
+	t.Run("ошибки валидации по полям", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		handler := NewSubscriptionHandler(mockService)
+
+		body := `{"service_name": "", "price": 0, "user_id": "` + uuid.New().String() + `", "start_date": "07-2023"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		middleware.RequestID(http.HandlerFunc(handler.Create)).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		details := decodeProblem(t, w)
+		assert.Equal(t, problem.CodeValidationFailed, details.Code)
+		assert.Equal(t, http.StatusBadRequest, details.Status)
+		assert.Equal(t, w.Header().Get("X-Request-ID"), details.Instance)
+		assert.ElementsMatch(t, []problem.FieldError{
+			{Field: "service_name", Code: "required", Message: "service_name is a required field"},
+			{Field: "price", Code: "required", Message: "price is a required field"},
+		}, details.Errors)
+		mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("сообщения на русском по Accept-Language", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		handler := NewSubscriptionHandler(mockService)
+
+		body := `{"service_name": "Netflix", "price": 0, "user_id": "` + uuid.New().String() + `", "start_date": "07-2023"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions", strings.NewReader(body))
+		req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
+		w := httptest.NewRecorder()
+
+		middleware.Locale(http.HandlerFunc(handler.Create)).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "ru", w.Header().Get("Content-Language"))
+		details := decodeProblem(t, w)
+		assert.Equal(t, problem.CodeValidationFailed, details.Code)
+		assert.Equal(t, "Ошибка валидации", details.Title)
+		assert.Equal(t, "Запрос содержит некорректные поля", details.Detail)
+		assert.Equal(t, []problem.FieldError{
+			{Field: "price", Code: "required", Message: "price обязательное поле"},
+		}, details.Errors)
+	})
+
+	t.Run("цена больше допустимой", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		handler := NewSubscriptionHandler(mockService)
+
+		body := `{"service_name": "Netflix", "price": 2147483648, "user_id": "` + uuid.New().String() + `", "start_date": "07-2023"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.Create(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		details := decodeProblem(t, w)
+		assert.Equal(t, problem.CodeValidationFailed, details.Code)
+		require.Len(t, details.Errors, 1)
+		assert.Equal(t, "price", details.Errors[0].Field)
+		assert.Equal(t, "max", details.Errors[0].Code)
+		mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("доменная ошибка валидации", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		handler := NewSubscriptionHandler(mockService)
+
+		reqBody := subscription.CreateSubscriptionRequest{
+			ServiceName: "Test Service",
+			Price:       100,
+			UserID:      uuid.New(),
+			StartDate:   "07-2023",
+		}
+		reqJSON, _ := json.Marshal(reqBody)
+		domainErr := fmt.Errorf("wrapped: %w", subscription.NewValidationError("end_date", subscription.CodeEndBeforeStart, "end date cannot be before start date"))
+		mockService.On("Create", mock.Anything, reqBody).Return(nil, domainErr)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions", bytes.NewBuffer(reqJSON))
+		w := httptest.NewRecorder()
+
+		handler.Create(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		details := decodeProblem(t, w)
+		assert.Equal(t, problem.CodeValidationFailed, details.Code)
+		assert.Equal(t, []problem.FieldError{
+			{Field: "end_date", Code: subscription.CodeEndBeforeStart, Message: "end date cannot be before start date"},
+		}, details.Errors)
+	})
+
+	t.Run("подписка не найдена", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		handler := NewSubscriptionHandler(mockService)
+
+		r := chi.NewRouter()
+		r.Use(middleware.RequestID)
+		r.Get("/api/v1/subscriptions/{id}", handler.Get)
+
+		id := uuid.New()
+		mockService.On("Get", mock.Anything, id).Return(nil, subscription.ErrSubscriptionNotFound)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/"+id.String(), nil)
+		req.Header.Set("X-Request-ID", "req-123")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		details := decodeProblem(t, w)
+		assert.Equal(t, problem.CodeNotFound, details.Code)
+		assert.Equal(t, "/problems/not_found", details.Type)
+		assert.Equal(t, "req-123", details.Instance)
+	})
+
+	t.Run("подписки другого пользователя", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		handler := NewSubscriptionHandler(mockService)
+
+		userID := uuid.New()
+		mockService.On("List", mock.Anything, subscription.ListFilter{UserID: &userID}).
+			Return([]*subscription.Subscription(nil), fmt.Errorf("%w: subscriptions of another user", auth.ErrForbidden))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions?user_id="+userID.String(), nil)
+		w := httptest.NewRecorder()
+
+		handler.List(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		details := decodeProblem(t, w)
+		assert.Equal(t, problem.CodeForbidden, details.Code)
+	})
+
+	t.Run("некорректный параметр запроса", func(t *testing.T) {
+		mockService := new(MockSubscriptionService)
+		handler := NewSubscriptionHandler(mockService)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/calculate-cost?start_period=13-2023&end_period=12-2023", nil)
+		w := httptest.NewRecorder()
+
+		handler.CalculateTotalCost(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		details := decodeProblem(t, w)
+		assert.Equal(t, problem.CodeInvalidQuery, details.Code)
+		assert.Len(t, details.Errors, 1)
+		assert.Equal(t, "start_period", details.Errors[0].Field)
+		assert.Equal(t, subscription.CodeMonthYear, details.Errors[0].Code)
+	})
+}
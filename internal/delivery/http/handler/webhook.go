@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/webhook"
+	"github.com/subscription-service/internal/i18n"
+	"github.com/subscription-service/internal/validation"
+)
+
+// Ограничения размера страницы журнала доставок
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+// WebhookHandler обрабатывает HTTP запросы управления webhook-получателями
+type WebhookHandler struct {
+	service   webhook.Service
+	validator *validator.Validate
+}
+
+// NewWebhookHandler создает новый экземпляр обработчика webhook-получателей
+func NewWebhookHandler(service webhook.Service) *WebhookHandler {
+	return &WebhookHandler{
+		service:   service,
+		validator: validation.Validator(),
+	}
+}
+
+// Create обрабатывает запрос на регистрацию получателя
+// @Summary Зарегистрировать webhook
+// @Description Регистрирует получателя событий жизненного цикла подписок. Секрет подписи возвращается только в этом ответе
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body webhook.CreateEndpointRequest true "Данные получателя"
+// @Success 201 {object} webhook.Endpoint
+// @Failure 400 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/webhooks [post]
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req webhook.CreateEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to decode request body")
+		respondWithProblem(w, r, problem.CodeInvalidPayload, "Request body is not valid JSON")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Validation failed")
+		respondWithValidationError(w, r, err)
+		return
+	}
+
+	endpoint, err := h.service.CreateEndpoint(r.Context(), req)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to create webhook endpoint")
+		respondWithServiceError(w, r, err, "Failed to create webhook endpoint")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusCreated, endpoint)
+}
+
+// List обрабатывает запрос на получение списка получателей
+// @Summary Список webhook-получателей
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} webhook.Endpoint
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/webhooks [get]
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	endpoints, err := h.service.ListEndpoints(r.Context())
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to list webhook endpoints")
+		respondWithServiceError(w, r, err, "Failed to list webhook endpoints")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, endpoints)
+}
+
+// Get обрабатывает запрос на получение получателя по ID
+// @Summary Получить webhook-получателя
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "ID получателя"
+// @Success 200 {object} webhook.Endpoint
+// @Failure 400 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/webhooks/{id} [get]
+func (h *WebhookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseEndpointID(w, r)
+	if !ok {
+		return
+	}
+
+	endpoint, err := h.service.GetEndpoint(r.Context(), id)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("id", id.String()).Msg("Failed to get webhook endpoint")
+		respondWithServiceError(w, r, err, "Failed to get webhook endpoint")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, endpoint)
+}
+
+// Delete обрабатывает запрос на удаление получателя
+// @Summary Удалить webhook-получателя
+// @Description Удаляет получателя вместе с журналом его доставок
+// @Tags webhooks
+// @Param id path string true "ID получателя"
+// @Success 204 "No Content"
+// @Failure 400 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseEndpointID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteEndpoint(r.Context(), id); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("id", id.String()).Msg("Failed to delete webhook endpoint")
+		respondWithServiceError(w, r, err, "Failed to delete webhook endpoint")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries обрабатывает запрос на получение журнала доставок получателя
+// @Summary Журнал доставок
+// @Description Возвращает доставки получателя, начиная с последних
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "ID получателя"
+// @Param status query string false "Состояние доставки (pending, succeeded, failed)"
+// @Param limit query int false "Размер страницы, не больше 500" default(50)
+// @Param offset query int false "Количество пропускаемых записей" default(0)
+// @Success 200 {array} webhook.Delivery
+// @Failure 400 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseEndpointID(w, r)
+	if !ok {
+		return
+	}
+
+	filter, fieldErr := parseDeliveryFilter(r)
+	if fieldErr != nil {
+		zerolog.Ctx(r.Context()).Error().Str("field", fieldErr.Field).Msg("Invalid delivery filter")
+		respondWithQueryError(w, r, *fieldErr)
+		return
+	}
+
+	deliveries, err := h.service.ListDeliveries(r.Context(), id, filter)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("id", id.String()).Msg("Failed to list webhook deliveries")
+		respondWithServiceError(w, r, err, "Failed to list webhook deliveries")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, deliveries)
+}
+
+// Redeliver обрабатывает запрос на повторную отправку события
+// @Summary Повторить доставку
+// @Description Ставит событие из доставки в очередь на повторную отправку тому же получателю
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "ID доставки"
+// @Success 202 {object} webhook.Delivery
+// @Failure 400 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Security ApiKeyAuth
+// @Router /api/v1/webhooks/deliveries/{id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Invalid UUID format")
+		respondWithProblem(w, r, problem.CodeInvalidID, "Webhook delivery ID must be a valid UUID")
+		return
+	}
+
+	delivery, err := h.service.Redeliver(r.Context(), id)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("id", id.String()).Msg("Failed to redeliver webhook")
+		respondWithServiceError(w, r, err, "Failed to redeliver webhook")
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusAccepted, delivery)
+}
+
+// parseEndpointID разбирает ID получателя из пути и отвечает ошибкой, если он некорректен
+func parseEndpointID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Invalid UUID format")
+		respondWithProblem(w, r, problem.CodeInvalidID, "Webhook endpoint ID must be a valid UUID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// parseDeliveryFilter разбирает параметры выборки журнала доставок из query-строки
+func parseDeliveryFilter(r *http.Request) (webhook.DeliveryFilter, *problem.FieldError) {
+	filter := webhook.DeliveryFilter{Limit: defaultDeliveriesLimit}
+	query := r.URL.Query()
+
+	if statusStr := query.Get("status"); statusStr != "" {
+		status := webhook.DeliveryStatus(statusStr)
+		switch status {
+		case webhook.DeliveryPending, webhook.DeliverySucceeded, webhook.DeliveryFailed:
+			filter.Status = &status
+		default:
+			return filter, &problem.FieldError{
+				Field:   "status",
+				Code:    "oneof",
+				Message: i18n.T(r.Context(), "{0} must be one of {1}", "status", "pending, succeeded, failed"),
+				Param:   "pending succeeded failed",
+			}
+		}
+	}
+
+	limit, offset, fieldErr := parsePage(r, defaultDeliveriesLimit, maxDeliveriesLimit)
+	if fieldErr != nil {
+		return filter, fieldErr
+	}
+	filter.Limit, filter.Offset = limit, offset
+
+	return filter, nil
+}
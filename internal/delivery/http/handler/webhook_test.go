@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/webhook"
+)
+
+// MockWebhookService мок для сервиса webhook-получателей
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) CreateEndpoint(ctx context.Context, req webhook.CreateEndpointRequest) (*webhook.Endpoint, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*webhook.Endpoint), args.Error(1)
+}
+
+func (m *MockWebhookService) GetEndpoint(ctx context.Context, id uuid.UUID) (*webhook.Endpoint, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*webhook.Endpoint), args.Error(1)
+}
+
+func (m *MockWebhookService) ListEndpoints(ctx context.Context) ([]*webhook.Endpoint, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*webhook.Endpoint), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) ListDeliveries(ctx context.Context, endpointID uuid.UUID, filter webhook.DeliveryFilter) ([]*webhook.Delivery, error) {
+	args := m.Called(ctx, endpointID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*webhook.Delivery), args.Error(1)
+}
+
+func (m *MockWebhookService) Redeliver(ctx context.Context, deliveryID uuid.UUID) (*webhook.Delivery, error) {
+	args := m.Called(ctx, deliveryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*webhook.Delivery), args.Error(1)
+}
+
+func TestWebhookHandler(t *testing.T) {
+	newRouter := func(handler *WebhookHandler) http.Handler {
+		r := chi.NewRouter()
+		r.Post("/api/v1/webhooks", handler.Create)
+		r.Get("/api/v1/webhooks/{id}/deliveries", handler.ListDeliveries)
+		r.Post("/api/v1/webhooks/deliveries/{id}/redeliver", handler.Redeliver)
+		return r
+	}
+
+	t.Run("регистрация возвращает секрет", func(t *testing.T) {
+		mockService := new(MockWebhookService)
+		req := webhook.CreateEndpointRequest{URL: "https://example.com/hook", Events: []string{"subscription.created"}}
+		mockService.On("CreateEndpoint", mock.Anything, req).Return(&webhook.Endpoint{
+			ID:     uuid.New(),
+			URL:    req.URL,
+			Secret: "generated-secret",
+			Events: []event.Type{event.SubscriptionCreated},
+		}, nil)
+
+		body := `{"url": "https://example.com/hook", "events": ["subscription.created"]}`
+		w := httptest.NewRecorder()
+		newRouter(NewWebhookHandler(mockService)).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var endpoint webhook.Endpoint
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &endpoint))
+		assert.Equal(t, "generated-secret", endpoint.Secret)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("некорректный URL", func(t *testing.T) {
+		mockService := new(MockWebhookService)
+
+		w := httptest.NewRecorder()
+		newRouter(NewWebhookHandler(mockService)).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(`{"url": "not a url"}`)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+		assert.Equal(t, problem.CodeValidationFailed, details.Code)
+		assert.Equal(t, "url", details.Errors[0].Field)
+		mockService.AssertNotCalled(t, "CreateEndpoint", mock.Anything, mock.Anything)
+	})
+
+	t.Run("неизвестный тип события", func(t *testing.T) {
+		mockService := new(MockWebhookService)
+		mockService.On("CreateEndpoint", mock.Anything, mock.Anything).
+			Return(nil, fmt.Errorf("%w: %q", webhook.ErrUnknownEventType, "subscription.renamed"))
+
+		body := `{"url": "https://example.com/hook", "events": ["subscription.renamed"]}`
+		w := httptest.NewRecorder()
+		newRouter(NewWebhookHandler(mockService)).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+		assert.Equal(t, problem.CodeValidationFailed, details.Code)
+		assert.Equal(t, "events", details.Errors[0].Field)
+	})
+
+	t.Run("журнал доставок с фильтром", func(t *testing.T) {
+		mockService := new(MockWebhookService)
+		endpointID := uuid.New()
+		status := webhook.DeliveryFailed
+		filter := webhook.DeliveryFilter{Status: &status, Limit: 10, Offset: 20}
+		mockService.On("ListDeliveries", mock.Anything, endpointID, filter).Return([]*webhook.Delivery{
+			{ID: uuid.New(), EndpointID: endpointID, Status: webhook.DeliveryFailed, Payload: json.RawMessage(`{}`)},
+		}, nil)
+
+		url := "/api/v1/webhooks/" + endpointID.String() + "/deliveries?status=failed&limit=10&offset=20"
+		w := httptest.NewRecorder()
+		newRouter(NewWebhookHandler(mockService)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var deliveries []webhook.Delivery
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &deliveries))
+		assert.Len(t, deliveries, 1)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("неизвестный статус доставки", func(t *testing.T) {
+		mockService := new(MockWebhookService)
+
+		url := "/api/v1/webhooks/" + uuid.New().String() + "/deliveries?status=lost"
+		w := httptest.NewRecorder()
+		newRouter(NewWebhookHandler(mockService)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+		assert.Equal(t, problem.CodeInvalidQuery, details.Code)
+		assert.Equal(t, "status", details.Errors[0].Field)
+	})
+
+	t.Run("повтор неизвестной доставки", func(t *testing.T) {
+		mockService := new(MockWebhookService)
+		deliveryID := uuid.New()
+		mockService.On("Redeliver", mock.Anything, deliveryID).Return(nil, webhook.ErrDeliveryNotFound)
+
+		url := "/api/v1/webhooks/deliveries/" + deliveryID.String() + "/redeliver"
+		w := httptest.NewRecorder()
+		newRouter(NewWebhookHandler(mockService)).ServeHTTP(w, httptest.NewRequest(http.MethodPost, url, nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+		assert.Equal(t, "Webhook delivery not found", details.Detail)
+	})
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/subscription-service/internal/actor"
+)
+
+// Actor создает middleware, сохраняющее в контексте исполнителя запроса из
+// заголовка X-Actor. Исполнитель записывается в журнал аудита изменений
+func Actor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if name := r.Header.Get(actor.Header); name != "" {
+			r = r.WithContext(actor.WithActor(r.Context(), name))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/subscription-service/internal/actor"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/i18n"
+)
+
+// APIKeyHeader - заголовок с ключом API; ключ API и токен JWT можно передать
+// и как Authorization: Bearer
+const APIKeyHeader = "X-API-Key"
+
+// Authenticate создает middleware, проверяющее учетные данные запроса и
+// сохраняющее клиента в контексте. Клиент становится исполнителем запроса
+// в журнале аудита вместо значения заголовка X-Actor
+func Authenticate(authenticator auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := credentials(r)
+			if token == "" {
+				writeUnauthorized(w, r)
+				return
+			}
+
+			principal, err := authenticator.Authenticate(r.Context(), token)
+			if err != nil {
+				if errors.Is(err, auth.ErrUnauthenticated) {
+					zerolog.Ctx(r.Context()).Warn().Msg("Rejected invalid credentials")
+					writeUnauthorized(w, r)
+					return
+				}
+				zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to authenticate request")
+				writeProblem(w, r, problem.CodeInternal, i18n.T(r.Context(), "Failed to authenticate request"))
+				return
+			}
+
+			ctx := auth.WithPrincipal(r.Context(), principal)
+			ctx = actor.WithActor(ctx, principal.Subject)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScope создает middleware, пропускающее только клиентов с правом scope
+func RequireScope(scope auth.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.FromContext(r.Context())
+			if !ok {
+				writeUnauthorized(w, r)
+				return
+			}
+			if !principal.Allows(scope) {
+				zerolog.Ctx(r.Context()).Warn().
+					Str("subject", principal.Subject).
+					Str("scope", string(scope)).
+					Msg("Insufficient scope")
+				writeProblem(w, r, problem.CodeForbidden, i18n.T(r.Context(), "Scope {0} is required", string(scope)))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// credentials извлекает ключ API или токен JWT из заголовка X-API-Key или
+// Authorization: Bearer
+func credentials(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// writeUnauthorized отвечает 401 с указанием схемы аутентификации
+func writeUnauthorized(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	writeProblem(w, r, problem.CodeUnauthorized, i18n.T(r.Context(), "Missing or invalid credentials"))
+}
+
+// writeProblem отправляет ответ об ошибке с переведенным заголовком
+func writeProblem(w http.ResponseWriter, r *http.Request, code problem.Code, detail string) {
+	ctx := r.Context()
+	details := problem.New(code, detail).WithInstance(GetRequestID(ctx))
+	details.Title = i18n.T(ctx, details.Title)
+	problem.Write(w, details)
+}
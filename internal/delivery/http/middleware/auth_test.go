@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/subscription-service/internal/actor"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/delivery/http/problem"
+)
+
+// staticAuthenticator принимает единственный ключ с правом чтения подписок
+type staticAuthenticator struct{}
+
+func (staticAuthenticator) Authenticate(_ context.Context, token string) (*auth.Principal, error) {
+	if token != "reader-key" {
+		return nil, auth.ErrUnauthenticated
+	}
+	return &auth.Principal{Subject: "api-key:reader", Scopes: []auth.Scope{auth.ScopeSubscriptionsRead}}, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	handler := Authenticate(staticAuthenticator{})(RequireScope(auth.ScopeSubscriptionsRead)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(actor.FromContext(r.Context())))
+		}),
+	))
+
+	t.Run("ключ в X-API-Key и Authorization: Bearer", func(t *testing.T) {
+		for _, header := range [][2]string{{APIKeyHeader, "reader-key"}, {"Authorization", "Bearer reader-key"}} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(header[0], header[1])
+			req.Header.Set("X-Actor", "spoofed")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code, header[0])
+			assert.Equal(t, "api-key:reader", w.Body.String(), "исполнителем становится владелец ключа")
+		}
+	})
+
+	t.Run("без ключа и с неизвестным ключом", func(t *testing.T) {
+		for _, key := range []string{"", "unknown-key"} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(APIKeyHeader, key)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+			var details problem.Details
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+			assert.Equal(t, problem.CodeUnauthorized, details.Code)
+		}
+	})
+
+	t.Run("недостаточно прав", func(t *testing.T) {
+		handler := Authenticate(staticAuthenticator{})(RequireScope(auth.ScopeSubscriptionsWrite)(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		))
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(APIKeyHeader, "reader-key")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+		assert.Equal(t, problem.CodeForbidden, details.Code)
+		assert.Contains(t, details.Detail, "subscriptions:write")
+	})
+}
@@ -4,7 +4,7 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 )
 
 // Logger создает middleware для логирования HTTP-запросов
@@ -21,10 +21,10 @@ func Logger(next http.Handler) http.Handler {
 		// После обработки запроса логируем информацию
 		duration := time.Since(start)
 
-		// Формируем лог
-		logger := log.Info()
+		// Формируем лог; логгер запроса добавляет ID запроса и трассировки
+		logger := zerolog.Ctx(r.Context()).Info()
 		if ww.statusCode >= 400 {
-			logger = log.Error()
+			logger = zerolog.Ctx(r.Context()).Error()
 		}
 
 		logger.
@@ -54,3 +54,8 @@ func (rw *ResponseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap возвращает исходный http.ResponseWriter для http.ResponseController
+func (rw *ResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
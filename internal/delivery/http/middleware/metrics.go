@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/subscription-service/internal/metrics"
+)
+
+// unmatchedRoute - метка маршрута для запросов, не нашедших обработчика.
+// Путь запроса в метку не попадает, чтобы число рядов метрик не росло
+const unmatchedRoute = "unmatched"
+
+// Metrics создает middleware, считающее HTTP-запросы и их длительность по
+// методу, шаблону маршрута chi и коду ответа, и регистрирует метрики в
+// registerer
+func Metrics(registerer prometheus.Registerer) func(http.Handler) http.Handler {
+	labels := []string{"method", "route", "status"}
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests.",
+	}, labels)
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, labels)
+	registerer.MustRegister(requests, duration)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := NewResponseWriter(w)
+
+			next.ServeHTTP(ww, r)
+
+			values := []string{r.Method, routePattern(r), strconv.Itoa(ww.statusCode)}
+			requests.WithLabelValues(values...).Inc()
+			duration.WithLabelValues(values...).Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// routePattern возвращает шаблон маршрута chi, например
+// /api/v1/subscriptions/{id}. Шаблон известен только после маршрутизации,
+// поэтому вызывается после следующего обработчика
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		return rctx.RoutePattern()
+	}
+	return unmatchedRoute
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	r := chi.NewRouter()
+	r.Use(Metrics(registry))
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Get("/subscriptions/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+	})
+
+	for _, path := range []string{"/api/v1/subscriptions/1", "/api/v1/subscriptions/2", "/unknown/path"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	t.Run("запросы считаются по шаблону маршрута и коду ответа", func(t *testing.T) {
+		expected := `
+# HELP subscription_service_http_requests_total Number of HTTP requests.
+# TYPE subscription_service_http_requests_total counter
+subscription_service_http_requests_total{method="GET",route="/api/v1/subscriptions/{id}",status="200"} 2
+subscription_service_http_requests_total{method="GET",route="unmatched",status="404"} 1
+`
+		assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+			"subscription_service_http_requests_total"))
+	})
+
+	t.Run("длительность учитывается для каждого запроса", func(t *testing.T) {
+		count, err := testutil.GatherAndCount(registry, "subscription_service_http_request_duration_seconds")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+}
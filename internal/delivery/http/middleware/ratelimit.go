@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/i18n"
+	"github.com/subscription-service/internal/ratelimit"
+)
+
+// Заголовки квот запросов (draft-ietf-httpapi-ratelimit-headers)
+const (
+	rateLimitLimitHeader     = "RateLimit-Limit"
+	rateLimitRemainingHeader = "RateLimit-Remaining"
+	rateLimitResetHeader     = "RateLimit-Reset"
+)
+
+// RateLimit создает middleware, ограничивающее частоту запросов клиента
+// квотой маршрута route. Клиент определяется по ключу API или пользователю в
+// организации запроса, а до аутентификации - по IP-адресу (квота IPRoute). При нескольких
+// квотах на одном маршруте заголовки RateLimit-* описывают ту, что ближе к
+// исчерпанию. Без ограничителя или квоты маршрута запросы не ограничиваются,
+// а ошибка хранилища квот не отклоняет запрос
+func RateLimit(limiter *ratelimit.Limiter, route string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client := rateLimitClient(r)
+			result, ok, err := limiter.Allow(r.Context(), route, client)
+			if err != nil {
+				zerolog.Ctx(r.Context()).Warn().Err(err).Str("route", route).Msg("Failed to check rate limit")
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			setRateLimitHeaders(w.Header(), result)
+			if !result.Allowed {
+				retryAfter := ceilSeconds(result.RetryAfter)
+				zerolog.Ctx(r.Context()).Warn().Str("client", client).Str("route", route).Msg("Rate limit exceeded")
+				w.Header().Set("Retry-After", retryAfter)
+				writeProblem(w, r, problem.CodeRateLimited, i18n.T(r.Context(), "Too many requests, retry in {0} s", retryAfter))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitClient возвращает ключ клиента для квот: до Authenticate - IP-адрес,
+// после - клиента в организации запроса
+func rateLimitClient(r *http.Request) string {
+	return ratelimit.Client(r.Context(), r.RemoteAddr)
+}
+
+// setRateLimitHeaders записывает заголовки квоты, если ранее записанная
+// квота не ближе к исчерпанию
+func setRateLimitHeaders(header http.Header, result ratelimit.Result) {
+	if current, err := strconv.Atoi(header.Get(rateLimitRemainingHeader)); err == nil && current <= result.Remaining {
+		return
+	}
+	header.Set(rateLimitLimitHeader, strconv.Itoa(result.Limit))
+	header.Set(rateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+	header.Set(rateLimitResetHeader, ceilSeconds(result.Reset))
+}
+
+// ceilSeconds округляет длительность вверх до целых секунд
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), map[string]ratelimit.Limit{
+		ratelimit.DefaultRoute: {Requests: 60, Period: time.Minute, Burst: 10},
+		"calculate-cost":       {Requests: 1, Period: time.Minute},
+	})
+	handler := RateLimit(limiter, ratelimit.DefaultRoute)(RateLimit(limiter, "calculate-cost")(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})))
+	serve := func(principal *auth.Principal, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/calculate-cost", nil)
+		req.RemoteAddr = remoteAddr
+		if principal != nil {
+			req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+	reports := &auth.Principal{Subject: "api-key:reports"}
+
+	t.Run("квота маршрута исчерпывается раньше общей", func(t *testing.T) {
+		w := serve(reports, "10.0.0.1:5000")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+
+		w = serve(reports, "10.0.0.1:5000")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "60", w.Header().Get("Retry-After"))
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+		assert.Equal(t, problem.CodeRateLimited, details.Code)
+	})
+
+	t.Run("клиенты ограничиваются раздельно", func(t *testing.T) {
+		w := serve(&auth.Principal{Subject: "api-key:billing"}, "10.0.0.1:5000")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("без аутентификации клиент определяется по IP", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(nil, "10.0.0.2:5000").Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(nil, "10.0.0.2:6000").Code)
+		assert.Equal(t, http.StatusOK, serve(nil, "10.0.0.3:5000").Code)
+	})
+
+	t.Run("квота IP-адреса ограничивает запросы с неверными ключами", func(t *testing.T) {
+		limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), map[string]ratelimit.Limit{
+			ratelimit.IPRoute: {Requests: 2, Period: time.Minute},
+		})
+		handler := RateLimit(limiter, ratelimit.IPRoute)(Authenticate(staticAuthenticator{})(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})))
+		serve := func(remoteAddr string) int {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions", nil)
+			req.RemoteAddr = remoteAddr
+			req.Header.Set("X-API-Key", "guessed-key")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		assert.Equal(t, http.StatusUnauthorized, serve("10.0.0.4:5000"))
+		assert.Equal(t, http.StatusUnauthorized, serve("10.0.0.4:5001"))
+		assert.Equal(t, http.StatusTooManyRequests, serve("10.0.0.4:5002"))
+		assert.Equal(t, http.StatusUnauthorized, serve("10.0.0.5:5000"))
+	})
+
+	t.Run("без ограничителя запросы не ограничиваются", func(t *testing.T) {
+		handler := RateLimit(nil, ratelimit.DefaultRoute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"net/http"
 	"runtime/debug"
 
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/i18n"
 )
 
 // Recover создает middleware для восстановления после паники
@@ -12,16 +14,19 @@ func Recover(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
+				// Намеренный обрыв соединения обрабатывает сам net/http
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
 				// Логируем информацию о панике
-				log.Error().
+				zerolog.Ctx(r.Context()).Error().
 					Interface("panic", err).
 					Str("stack", string(debug.Stack())).
 					Msg("Recovered from HTTP handler panic")
 
-				// Устанавливаем код статуса Internal Server Error
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusInternalServerError)
-				_, _ = w.Write([]byte(`{"error": "Internal server error"}`))
+				// Отвечаем Internal Server Error в формате problem details
+				writeProblem(w, r, problem.CodeInternal, i18n.T(r.Context(), "Unexpected server error"))
 			}
 		}()
 
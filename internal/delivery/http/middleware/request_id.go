@@ -4,26 +4,23 @@ import (
 	"context"
 	"net/http"
 
-	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/internal/requestid"
 )
 
-// RequestIDKey - ключ контекста для request ID
-type requestIDKey struct{}
-
 // RequestID возвращает middleware для добавления уникального ID к каждому запросу
 func RequestID(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestID := r.Header.Get("X-Request-ID")
+		requestID := r.Header.Get(requestid.Header)
 		if requestID == "" {
-			requestID = uuid.New().String()
+			requestID = requestid.New()
 		}
 
 		// Добавляем request ID в заголовки ответа
-		w.Header().Set("X-Request-ID", requestID)
+		w.Header().Set(requestid.Header, requestID)
 
 		// Добавляем request ID в контекст запроса
-		ctx := context.WithValue(r.Context(), requestIDKey{}, requestID)
+		ctx := requestid.WithRequestID(r.Context(), requestID)
 
 		// Добавляем request ID в логи
 		logger := log.With().Str("request_id", requestID).Logger()
@@ -43,8 +40,5 @@ func RequestID(next http.Handler) http.Handler {
 
 // GetRequestID возвращает request ID из контекста
 func GetRequestID(ctx context.Context) string {
-	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
-		return id
-	}
-	return ""
+	return requestid.FromContext(ctx)
 }
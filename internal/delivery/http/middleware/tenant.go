@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/i18n"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// Tenant создает middleware, определяющее организацию запроса по клиенту и
+// заголовку X-Organization-ID и сохраняющее ее в контексте. Подключается
+// после Authenticate
+func Tenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.FromContext(r.Context())
+		if !ok {
+			writeUnauthorized(w, r)
+			return
+		}
+
+		requested := r.Header.Get(tenant.Header)
+		organizationID, err := tenant.Resolve(principal, requested)
+		switch {
+		case errors.Is(err, tenant.ErrInvalidOrganization):
+			writeProblem(w, r, problem.CodeInvalidInput, i18n.T(r.Context(), "Invalid organization ID {0}", requested))
+			return
+		case err != nil:
+			zerolog.Ctx(r.Context()).Warn().
+				Str("subject", principal.Subject).
+				Str("organization_id", requested).
+				Msg("Access to organization denied")
+			writeProblem(w, r, problem.CodeForbidden, i18n.T(r.Context(), "Access to organization {0} is forbidden", requested))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(tenant.WithOrganization(r.Context(), organizationID)))
+	})
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/tenant"
+)
+
+func TestTenant(t *testing.T) {
+	organizationID := uuid.New()
+	otherID := uuid.New()
+
+	handler := Tenant(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(tenant.FromContext(r.Context()).String()))
+	}))
+	serve := func(principal *auth.Principal, header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set(tenant.Header, header)
+		}
+		req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+	assertProblem := func(t *testing.T, w *httptest.ResponseRecorder, status int, code problem.Code) {
+		assert.Equal(t, status, w.Code)
+		var details problem.Details
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &details))
+		assert.Equal(t, code, details.Code)
+	}
+
+	bound := &auth.Principal{Subject: "api-key:team", Scopes: []auth.Scope{auth.ScopeAdmin}, OrganizationID: &organizationID}
+	platform := &auth.Principal{Subject: "bootstrap", Scopes: []auth.Scope{auth.ScopeAdmin}}
+	user := &auth.Principal{Subject: "user:alice", Scopes: auth.UserScopes, UserID: uuid.New()}
+
+	t.Run("клиент работает со своей организацией", func(t *testing.T) {
+		for _, header := range []string{"", organizationID.String()} {
+			w := serve(bound, header)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, organizationID.String(), w.Body.String())
+		}
+	})
+
+	t.Run("чужая организация запрещена", func(t *testing.T) {
+		assertProblem(t, serve(bound, otherID.String()), http.StatusForbidden, problem.CodeForbidden)
+		assertProblem(t, serve(user, otherID.String()), http.StatusForbidden, problem.CodeForbidden)
+	})
+
+	t.Run("клиент без организации", func(t *testing.T) {
+		w := serve(user, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, tenant.Default.String(), w.Body.String())
+
+		w = serve(platform, otherID.String())
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, otherID.String(), w.Body.String(), "администратор платформы выбирает организацию заголовком")
+	})
+
+	t.Run("некорректный ID организации", func(t *testing.T) {
+		assertProblem(t, serve(platform, "team-a"), http.StatusBadRequest, problem.CodeInvalidInput)
+	})
+}
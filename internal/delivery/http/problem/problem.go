@@ -0,0 +1,151 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog/log"
+)
+
+// ContentType - MIME-тип ответов об ошибках по RFC 7807
+const ContentType = "application/problem+json"
+
+// typeBaseURI - префикс URI типа проблемы; к нему добавляется код ошибки
+const typeBaseURI = "/problems/"
+
+// Code - стабильный машиночитаемый код ошибки API
+type Code string
+
+// Коды ошибок API. Значения являются частью контракта и не должны меняться
+const (
+	CodeInvalidPayload   Code = "invalid_payload"
+	CodeValidationFailed Code = "validation_failed"
+	CodeInvalidID        Code = "invalid_id"
+	CodeInvalidQuery     Code = "invalid_query"
+	CodeInvalidInput     Code = "invalid_input"
+	CodeQueryTooComplex  Code = "query_too_complex"
+	CodeNotFound         Code = "not_found"
+	CodeConflict         Code = "conflict"
+	CodeUnauthorized     Code = "unauthorized"
+	CodeForbidden        Code = "forbidden"
+	CodeRateLimited      Code = "rate_limited"
+	CodeInternal         Code = "internal_error"
+)
+
+// definition описывает HTTP-статус и заголовок, соответствующие коду ошибки
+type definition struct {
+	status int
+	title  string
+}
+
+var definitions = map[Code]definition{
+	CodeInvalidPayload:   {http.StatusBadRequest, "Invalid request payload"},
+	CodeValidationFailed: {http.StatusBadRequest, "Validation failed"},
+	CodeInvalidID:        {http.StatusBadRequest, "Invalid identifier"},
+	CodeInvalidQuery:     {http.StatusBadRequest, "Invalid query parameters"},
+	CodeInvalidInput:     {http.StatusBadRequest, "Invalid input"},
+	CodeQueryTooComplex:  {http.StatusBadRequest, "Query is too complex"},
+	CodeNotFound:         {http.StatusNotFound, "Resource not found"},
+	CodeConflict:         {http.StatusConflict, "Conflict"},
+	CodeUnauthorized:     {http.StatusUnauthorized, "Unauthorized"},
+	CodeForbidden:        {http.StatusForbidden, "Forbidden"},
+	CodeRateLimited:      {http.StatusTooManyRequests, "Too many requests"},
+	CodeInternal:         {http.StatusInternalServerError, "Internal server error"},
+}
+
+// Status возвращает HTTP-статус для кода ошибки
+func (c Code) Status() int {
+	if def, ok := definitions[c]; ok {
+		return def.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Title возвращает краткое описание кода ошибки
+func (c Code) Title() string {
+	if def, ok := definitions[c]; ok {
+		return def.title
+	}
+	return http.StatusText(c.Status())
+}
+
+// Details представляет ответ об ошибке в формате RFC 7807
+type Details struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     Code         `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError описывает ошибку в конкретном поле запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Param   string `json:"param,omitempty"`
+}
+
+// New создает описание проблемы для кода ошибки
+func New(code Code, detail string) *Details {
+	return &Details{
+		Type:   typeBaseURI + string(code),
+		Title:  code.Title(),
+		Status: code.Status(),
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// WithInstance задает идентификатор конкретного случая ошибки
+func (d *Details) WithInstance(instance string) *Details {
+	d.Instance = instance
+	return d
+}
+
+// WithErrors добавляет ошибки отдельных полей
+func (d *Details) WithErrors(errs ...FieldError) *Details {
+	d.Errors = append(d.Errors, errs...)
+	return d
+}
+
+// Write отправляет описание проблемы клиенту
+func Write(w http.ResponseWriter, d *Details) {
+	response, err := json.Marshal(d)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to marshal problem details")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(d.Status)
+	if _, err := w.Write(response); err != nil {
+		log.Error().Err(err).Msg("Failed to write problem details")
+	}
+}
+
+// FromValidationErrors преобразует ошибки validator в ошибки полей с
+// сообщениями на языке переводчика. Для прочих ошибок возвращается nil
+func FromValidationErrors(err error, trans ut.Translator) []FieldError {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return nil
+	}
+
+	fields := make([]FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		fields = append(fields, FieldError{
+			Field:   fe.Field(),
+			Code:    fe.Tag(),
+			Message: fe.Translate(trans),
+			Param:   fe.Param(),
+		})
+	}
+	return fields
+}
@@ -6,44 +6,155 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/internal/auth"
 	"github.com/subscription-service/internal/delivery/http/handler"
 	"github.com/subscription-service/internal/delivery/http/middleware"
+	"github.com/subscription-service/internal/metrics"
+	"github.com/subscription-service/internal/ratelimit"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
-// NewRouter создает новый маршрутизатор с настроенными эндпоинтами
-func NewRouter(subscriptionHandler *handler.SubscriptionHandler) http.Handler {
+// requestTimeout ограничивает время обработки обычных (не потоковых) запросов
+const requestTimeout = 60 * time.Second
+
+// NewRouter создает новый маршрутизатор с настроенными эндпоинтами. Все
+// маршруты API, кроме проверок работоспособности, требуют ключ API с правом,
+// соответствующим маршруту, работают с данными организации клиента и
+// ограничены квотами limiter; nil отключает квоты. Метрики запросов
+// собираются в registry и отдаются на /metrics без ключа; nil отключает метрики
+func NewRouter(
+	subscriptionHandler *handler.SubscriptionHandler,
+	webhookHandler *handler.WebhookHandler,
+	eventHandler *handler.EventHandler,
+	auditHandler *handler.AuditHandler,
+	apiKeyHandler *handler.APIKeyHandler,
+	memberHandler *handler.MemberHandler,
+	healthHandler *handler.HealthHandler,
+	graphqlHandler http.Handler,
+	authenticator auth.Authenticator,
+	limiter *ratelimit.Limiter,
+	registry *prometheus.Registry,
+) http.Handler {
 	r := chi.NewRouter()
 
 	// Подключаем глобальные middleware
 	r.Use(middleware.RequestID)
+	r.Use(middleware.Trace)
+	r.Use(middleware.Locale)
+	r.Use(middleware.Actor)
 	r.Use(middleware.Logger)
+	if registry != nil {
+		r.Use(middleware.Metrics(registry))
+	}
 	r.Use(middleware.Recover)
-	r.Use(chiMiddleware.Timeout(60 * time.Second))
+
+	// Права доступа маршрутов
+	var (
+		readSubscriptions  = middleware.RequireScope(auth.ScopeSubscriptionsRead)
+		writeSubscriptions = middleware.RequireScope(auth.ScopeSubscriptionsWrite)
+		readReports        = middleware.RequireScope(auth.ScopeReportsRead)
+		admin              = middleware.RequireScope(auth.ScopeAdmin)
+	)
+
+	// Квоты частоты запросов: квота IP-адреса до аутентификации, общая на
+	// все маршруты API клиента и дополнительные квоты дорогих маршрутов
+	limit := func(route string) func(http.Handler) http.Handler {
+		return middleware.RateLimit(limiter, route)
+	}
 
 	// Настраиваем Swagger
 	r.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("/docs/swagger.json"), // URL к JSON-спецификации API
 	))
 
+	// Пробы живости и готовности для оркестратора
+	r.Get("/livez", healthHandler.Live)
+	r.Get("/readyz", healthHandler.Ready)
+
+	// Метрики для Prometheus
+	if registry != nil {
+		r.Method(http.MethodGet, "/metrics", metrics.Handler(registry))
+	}
+
+	// GraphQL для отчетов и дашбордов
+	r.Group(func(r chi.Router) {
+		r.Use(chiMiddleware.Timeout(requestTimeout))
+		r.Use(limit(ratelimit.IPRoute))
+		r.Use(middleware.Authenticate(authenticator))
+		r.Use(middleware.Tenant)
+		r.Use(limit(ratelimit.DefaultRoute), limit("graphql"))
+		r.Use(readReports)
+		r.Get("/graphql", graphqlHandler.ServeHTTP)
+		r.Post("/graphql", graphqlHandler.ServeHTTP)
+	})
+
 	// API v1
 	r.Route("/api/v1", func(r chi.Router) {
-		// Endpoint для проверки работоспособности
-		r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		// Endpoint для проверки работоспособности доступен без ключа
+		r.With(chiMiddleware.Timeout(requestTimeout)).Get("/health", func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 			w.Write([]byte("OK"))
 			log.Debug().Str("request_id", middleware.GetRequestID(r.Context())).Msg("Health check passed")
 		})
 
-		// Маршруты для подписок
-		r.Route("/subscriptions", func(r chi.Router) {
-			r.Post("/", subscriptionHandler.Create)
-			r.Get("/", subscriptionHandler.List)
-			r.Get("/{id}", subscriptionHandler.Get)
-			r.Put("/{id}", subscriptionHandler.Update)
-			r.Delete("/{id}", subscriptionHandler.Delete)
-			r.Get("/calculate-cost", subscriptionHandler.CalculateTotalCost)
+		r.Group(func(r chi.Router) {
+			r.Use(limit(ratelimit.IPRoute))
+			r.Use(middleware.Authenticate(authenticator))
+			r.Use(middleware.Tenant)
+			r.Use(limit(ratelimit.DefaultRoute))
+
+			// Потоковые маршруты не ограничиваются общим таймаутом запроса
+			r.With(readSubscriptions, limit("export")).Get("/subscriptions/export", subscriptionHandler.Export)
+			r.With(readSubscriptions).Get("/subscriptions/events", eventHandler.Stream)
+
+			r.Group(func(r chi.Router) {
+				r.Use(chiMiddleware.Timeout(requestTimeout))
+
+				// Маршруты для подписок
+				r.Route("/subscriptions", func(r chi.Router) {
+					r.With(writeSubscriptions).Post("/", subscriptionHandler.Create)
+					r.With(readSubscriptions).Get("/", subscriptionHandler.List)
+					r.With(readSubscriptions).Get("/{id}", subscriptionHandler.Get)
+					r.With(writeSubscriptions).Put("/{id}", subscriptionHandler.Update)
+					r.With(writeSubscriptions).Delete("/{id}", subscriptionHandler.Delete)
+					r.With(writeSubscriptions).Post("/{id}/restore", subscriptionHandler.Restore)
+					r.With(readSubscriptions).Get("/{id}/history", auditHandler.History)
+					r.With(readReports, limit("calculate-cost")).Get("/calculate-cost", subscriptionHandler.CalculateTotalCost)
+				})
+
+				// Журнал аудита изменений подписок
+				r.With(admin).Get("/audit", auditHandler.List)
+
+				// Маршруты для webhook-получателей и журнала доставок
+				r.Route("/webhooks", func(r chi.Router) {
+					r.Use(admin)
+					r.Post("/", webhookHandler.Create)
+					r.Get("/", webhookHandler.List)
+					r.Get("/{id}", webhookHandler.Get)
+					r.Delete("/{id}", webhookHandler.Delete)
+					r.Get("/{id}/deliveries", webhookHandler.ListDeliveries)
+					r.Post("/deliveries/{id}/redeliver", webhookHandler.Redeliver)
+				})
+
+				// Участники организации и их роли; кто может ими управлять,
+				// решает политика доступа по роли клиента, а ключам API для
+				// этого нужно право admin
+				r.Route("/members", func(r chi.Router) {
+					r.With(readSubscriptions).Get("/", memberHandler.List)
+					r.With(writeSubscriptions).Put("/{user_id}", memberHandler.SetRole)
+					r.With(writeSubscriptions).Delete("/{user_id}", memberHandler.Remove)
+				})
+
+				// Управление ключами API
+				r.Route("/api-keys", func(r chi.Router) {
+					r.Use(admin)
+					r.Post("/", apiKeyHandler.Issue)
+					r.Get("/", apiKeyHandler.List)
+					r.Delete("/{id}", apiKeyHandler.Revoke)
+				})
+			})
 		})
 	})
 
@@ -0,0 +1,12 @@
+package apikey
+
+import "errors"
+
+// Константы ошибок
+var (
+	// ErrKeyNotFound возвращается когда ключ не найден
+	ErrKeyNotFound = errors.New("api key not found")
+
+	// ErrUnknownScope возвращается при выпуске ключа с неизвестным правом доступа
+	ErrUnknownScope = errors.New("unknown scope")
+)
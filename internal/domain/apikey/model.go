@@ -0,0 +1,42 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/auth"
+)
+
+// Key - ключ API. Сам токен не хранится: по префиксу ключ находится в
+// хранилище, а хеш подтверждает, что клиент знает весь токен
+type Key struct {
+	ID uuid.UUID `json:"id"`
+	// OrganizationID - организация, к данным которой ограничен ключ
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Name           string    `json:"name"`
+	Prefix         string    `json:"prefix"`
+	// Hash - SHA-256 токена
+	Hash       []byte       `json:"-"`
+	Scopes     []auth.Scope `json:"scopes"`
+	CreatedAt  time.Time    `json:"created_at"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time   `json:"revoked_at,omitempty"`
+}
+
+// Revoked проверяет, отозван ли ключ
+func (k *Key) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// IssuedKey - только что выпущенный ключ вместе с токеном. Токен
+// возвращается один раз и восстановить его нельзя
+type IssuedKey struct {
+	Key
+	Token string `json:"token"`
+}
+
+// IssueKeyRequest представляет запрос на выпуск ключа
+type IssueKeyRequest struct {
+	Name   string   `json:"name" validate:"required,max=255"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,required"`
+}
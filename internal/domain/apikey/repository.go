@@ -0,0 +1,23 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Repository определяет интерфейс хранилища ключей API. Ключи выпускаются,
+// перечисляются и отзываются в организации из контекста
+type Repository interface {
+	Create(ctx context.Context, key *Key) error
+	// GetByPrefix возвращает ключ, в том числе отозванный, по префиксу токена.
+	// Поиск выполняется во всех организациях: организация запроса становится
+	// известна только после аутентификации
+	GetByPrefix(ctx context.Context, prefix string) (*Key, error)
+	List(ctx context.Context) ([]*Key, error)
+	// Revoke отзывает ключ; повторный отзыв не меняет время отзыва
+	Revoke(ctx context.Context, id uuid.UUID) error
+	// TouchLastUsed обновляет время последнего использования ключа
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
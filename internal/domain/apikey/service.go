@@ -0,0 +1,15 @@
+package apikey
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Service определяет интерфейс управления ключами API
+type Service interface {
+	// Issue выпускает ключ и возвращает его токен
+	Issue(ctx context.Context, req IssueKeyRequest) (*IssuedKey, error)
+	List(ctx context.Context) ([]*Key, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/domain/subscription"
+)
+
+// Operation - вид изменения подписки
+type Operation string
+
+// Виды изменений
+const (
+	OperationCreate  Operation = "create"
+	OperationUpdate  Operation = "update"
+	OperationDelete  Operation = "delete"
+	OperationRestore Operation = "restore"
+)
+
+// Operations перечисляет все виды изменений
+var Operations = []Operation{OperationCreate, OperationUpdate, OperationDelete, OperationRestore}
+
+// Valid проверяет, что вид изменения известен
+func (o Operation) Valid() bool {
+	for _, known := range Operations {
+		if o == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoredFields не попадают в список изменений: они меняются при каждой записи
+var ignoredFields = map[string]bool{"updated_at": true}
+
+// Change - значение поля до и после изменения; null означает отсутствие значения
+type Change struct {
+	Before json.RawMessage `json:"before" swaggertype:"object"`
+	After  json.RawMessage `json:"after" swaggertype:"object"`
+}
+
+// Entry - запись журнала аудита. Журнал только пополняется: записи не
+// изменяются и не удаляются, в том числе вместе с подпиской
+type Entry struct {
+	ID             uuid.UUID `json:"id"`
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	Operation      Operation `json:"operation"`
+	// Actor - пользователь или система, выполнившая изменение
+	Actor     string `json:"actor"`
+	RequestID string `json:"request_id,omitempty"`
+	// Before и After - подписка целиком до и после изменения
+	Before json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After  json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	// Changes - измененные поля подписки
+	Changes   map[string]Change `json:"changes"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// NewEntry создает запись журнала по состоянию подписки до и после изменения.
+// При создании и восстановлении before равен nil, при удалении after равен nil
+func NewEntry(operation Operation, before, after *subscription.Subscription) (*Entry, error) {
+	entry := &Entry{Operation: operation, Changes: map[string]Change{}}
+
+	var err error
+	var beforeFields, afterFields map[string]json.RawMessage
+	if before != nil {
+		entry.SubscriptionID = before.ID
+		if entry.Before, beforeFields, err = snapshot(before); err != nil {
+			return nil, err
+		}
+	}
+	if after != nil {
+		entry.SubscriptionID = after.ID
+		if entry.After, afterFields, err = snapshot(after); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, field := range fieldNames(beforeFields, afterFields) {
+		oldValue, newValue := beforeFields[field], afterFields[field]
+		if ignoredFields[field] || bytes.Equal(oldValue, newValue) {
+			continue
+		}
+		entry.Changes[field] = Change{Before: oldValue, After: newValue}
+	}
+
+	return entry, nil
+}
+
+// snapshot сериализует подписку целиком и по полям
+func snapshot(sub *subscription.Subscription) (json.RawMessage, map[string]json.RawMessage, error) {
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal subscription: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal subscription: %w", err)
+	}
+	return data, fields, nil
+}
+
+// fieldNames возвращает отсортированное объединение имен полей
+func fieldNames(sets ...map[string]json.RawMessage) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, set := range sets {
+		for name := range set {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Filter содержит параметры выборки журнала аудита
+type Filter struct {
+	SubscriptionID *uuid.UUID
+	Actor          string
+	Operation      *Operation
+	// From и To ограничивают время изменения: From включительно, To исключительно
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,11 @@
+package audit
+
+import "context"
+
+// Repository определяет интерфейс хранилища журнала аудита
+type Repository interface {
+	// Add добавляет запись; в транзакции из контекста, если она есть
+	Add(ctx context.Context, entry *Entry) error
+	// List возвращает записи, удовлетворяющие фильтру, начиная с последних
+	List(ctx context.Context, filter Filter) ([]*Entry, error)
+}
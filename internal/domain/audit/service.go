@@ -0,0 +1,15 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Service определяет интерфейс чтения журнала аудита
+type Service interface {
+	// History возвращает историю изменений подписки, в том числе удаленной
+	History(ctx context.Context, subscriptionID uuid.UUID, filter Filter) ([]*Entry, error)
+	// List возвращает записи журнала по всем подпискам
+	List(ctx context.Context, filter Filter) ([]*Entry, error)
+}
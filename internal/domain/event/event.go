@@ -0,0 +1,72 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Type - тип доменного события
+type Type string
+
+// Типы событий жизненного цикла подписки
+const (
+	SubscriptionCreated   Type = "subscription.created"
+	SubscriptionUpdated   Type = "subscription.updated"
+	SubscriptionCancelled Type = "subscription.cancelled"
+	SubscriptionDeleted   Type = "subscription.deleted"
+	SubscriptionRestored  Type = "subscription.restored"
+)
+
+// Types перечисляет все известные типы событий
+var Types = []Type{
+	SubscriptionCreated,
+	SubscriptionUpdated,
+	SubscriptionCancelled,
+	SubscriptionDeleted,
+	SubscriptionRestored,
+}
+
+// Valid проверяет, что тип события известен
+func (t Type) Valid() bool {
+	for _, known := range Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event - доменное событие. ID уникален для события и позволяет получателям
+// отбрасывать повторные доставки
+type Event struct {
+	ID   uuid.UUID `json:"id"`
+	Type Type      `json:"type"`
+	// OrganizationID - организация, в данных которой произошло событие
+	OrganizationID uuid.UUID       `json:"organization_id"`
+	OccurredAt     time.Time       `json:"occurred_at"`
+	Data           json.RawMessage `json:"data"`
+}
+
+// New создает событие с данными, сериализованными в JSON
+func New(eventType Type, data interface{}) (Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to marshal %s event data: %w", eventType, err)
+	}
+
+	return Event{
+		ID:         uuid.New(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Data:       payload,
+	}, nil
+}
+
+// Publisher публикует доменные события
+type Publisher interface {
+	Publish(ctx context.Context, evt Event) error
+}
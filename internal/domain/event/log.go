@@ -0,0 +1,29 @@
+package event
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Record - событие из журнала с порядковым номером. Номера возрастают в
+// порядке фиксации и используются как курсор для возобновления чтения
+type Record struct {
+	Sequence int64 `json:"-"`
+	Event
+}
+
+// LogFilter содержит параметры выборки журнала событий
+type LogFilter struct {
+	// UserID оставляет только события подписок данного пользователя
+	UserID *uuid.UUID
+}
+
+// Log - журнал опубликованных доменных событий
+type Log interface {
+	// ListAfter возвращает до limit событий организации из контекста с
+	// номером больше after в порядке номеров
+	ListAfter(ctx context.Context, after int64, filter LogFilter, limit int) ([]Record, error)
+	// LastSequence возвращает номер последнего события журнала или 0, если журнал пуст
+	LastSequence(ctx context.Context) (int64, error)
+}
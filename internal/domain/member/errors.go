@@ -0,0 +1,21 @@
+package member
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/subscription-service/internal/auth"
+)
+
+// Константы ошибок
+var (
+	// ErrMemberNotFound возвращается когда пользователь не состоит в организации
+	ErrMemberNotFound = errors.New("member not found")
+
+	// ErrUnknownRole возвращается при назначении неизвестной роли
+	ErrUnknownRole = errors.New("unknown role")
+
+	// ErrInsufficientRole возвращается, если роль клиента в организации не
+	// позволяет выполнить операцию
+	ErrInsufficientRole = fmt.Errorf("%w: insufficient role", auth.ErrForbidden)
+)
@@ -0,0 +1,54 @@
+package member
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role - роль пользователя в организации
+type Role string
+
+// Роли участников организации
+const (
+	// RoleOwner - владелец: все права администратора и назначение владельцев
+	RoleOwner Role = "owner"
+	// RoleAdmin - администратор: подписки всех участников и управление
+	// участниками, кроме владельцев
+	RoleAdmin Role = "admin"
+	// RoleMember - участник: просмотр и изменение только своих подписок
+	RoleMember Role = "member"
+	// RoleViewer - наблюдатель: просмотр подписок всех участников без изменения
+	RoleViewer Role = "viewer"
+)
+
+// Roles - все роли в порядке убывания прав
+var Roles = []Role{RoleOwner, RoleAdmin, RoleMember, RoleViewer}
+
+// DefaultRole - роль пользователя, не добавленного в организацию явно. С ней
+// пользователь, как и до появления ролей, работает только со своими подписками
+const DefaultRole = RoleMember
+
+// Valid проверяет, что роль известна
+func (r Role) Valid() bool {
+	for _, known := range Roles {
+		if r == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Member - участник организации
+type Member struct {
+	UserID         uuid.UUID `json:"user_id" db:"user_id"`
+	OrganizationID uuid.UUID `json:"organization_id" db:"organization_id"`
+	Role           Role      `json:"role" db:"role"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SetRoleRequest представляет запрос на назначение роли участнику
+type SetRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
@@ -0,0 +1,17 @@
+package member
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Repository определяет интерфейс хранилища участников. Все операции
+// выполняются в организации из контекста
+type Repository interface {
+	Get(ctx context.Context, userID uuid.UUID) (*Member, error)
+	List(ctx context.Context) ([]*Member, error)
+	// Save добавляет участника или меняет роль существующего
+	Save(ctx context.Context, member *Member) error
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
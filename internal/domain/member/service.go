@@ -0,0 +1,22 @@
+package member
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Service определяет интерфейс управления участниками организации
+type Service interface {
+	List(ctx context.Context) ([]*Member, error)
+	// SetRole добавляет пользователя в организацию или меняет его роль
+	SetRole(ctx context.Context, userID uuid.UUID, req SetRoleRequest) (*Member, error)
+	Remove(ctx context.Context, userID uuid.UUID) error
+}
+
+// Policy определяет, какие подписки доступны клиенту по его роли в организации
+type Policy interface {
+	// ScopeUser ограничивает фильтр по пользователю userID подписками,
+	// которые видит клиент. Запрос чужих подписок участником отклоняется
+	ScopeUser(ctx context.Context, userID *uuid.UUID) (*uuid.UUID, error)
+}
@@ -0,0 +1,47 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/domain/event"
+)
+
+// Message - событие, записанное в outbox вместе с изменением, которое его
+// породило. ID совпадает с ID события и служит ключом дедупликации у получателей
+type Message struct {
+	ID             uuid.UUID       `db:"id"`
+	EventType      event.Type      `db:"event_type"`
+	OrganizationID uuid.UUID       `db:"organization_id"`
+	Data           json.RawMessage `db:"data"`
+	OccurredAt     time.Time       `db:"occurred_at"`
+	Attempts       int             `db:"attempts"`
+	NextAttemptAt  time.Time       `db:"next_attempt_at"`
+	LastError      *string         `db:"last_error"`
+	PublishedAt    *time.Time      `db:"published_at"`
+	CreatedAt      time.Time       `db:"created_at"`
+}
+
+// NewMessage создает сообщение outbox для события
+func NewMessage(evt event.Event) *Message {
+	return &Message{
+		ID:             evt.ID,
+		EventType:      evt.Type,
+		OrganizationID: evt.OrganizationID,
+		Data:           evt.Data,
+		OccurredAt:     evt.OccurredAt,
+		NextAttemptAt:  evt.OccurredAt,
+	}
+}
+
+// Event восстанавливает событие из сообщения
+func (m *Message) Event() event.Event {
+	return event.Event{
+		ID:             m.ID,
+		Type:           m.EventType,
+		OrganizationID: m.OrganizationID,
+		OccurredAt:     m.OccurredAt,
+		Data:           m.Data,
+	}
+}
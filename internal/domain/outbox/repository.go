@@ -0,0 +1,26 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Repository определяет интерфейс хранилища outbox
+type Repository interface {
+	// Add записывает сообщение; если в контексте есть транзакция, запись
+	// выполняется в ней
+	Add(ctx context.Context, msg *Message) error
+	// ClaimPending выбирает до limit неопубликованных сообщений, время попытки
+	// которых наступило, и откладывает их следующую попытку на lease
+	ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Message, error)
+	// MarkPublished отмечает сообщение опубликованным во все приемники
+	MarkPublished(ctx context.Context, id uuid.UUID, publishedAt time.Time) error
+	// ScheduleRetry сохраняет ошибку публикации и время следующей попытки
+	ScheduleRetry(ctx context.Context, msg *Message) error
+	// Purge удаляет до limit сообщений, опубликованных раньше publishedBefore,
+	// во всех организациях и возвращает их число. Неопубликованные сообщения
+	// не удаляются
+	Purge(ctx context.Context, publishedBefore time.Time, limit int) (int, error)
+}
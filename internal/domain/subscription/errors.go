@@ -11,10 +11,41 @@ var (
 	// ErrSubscriptionNotFound возвращается когда подписка не найдена
 	ErrSubscriptionNotFound = errors.New("subscription not found")
 
+	// ErrSubscriptionNotDeleted возвращается при попытке восстановить неудаленную подписку
+	ErrSubscriptionNotDeleted = errors.New("subscription is not deleted")
+
 	// ErrInvalidInput возвращается при некорректных входных данных
 	ErrInvalidInput = errors.New("invalid input")
 )
 
+// ValidationError описывает некорректное значение конкретного поля запроса
+type ValidationError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+// NewValidationError создает ошибку валидации поля
+func NewValidationError(field, code, message string) *ValidationError {
+	return &ValidationError{Field: field, Code: code, Message: message}
+}
+
+// Error возвращает описание ошибки
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Unwrap позволяет проверять ошибку через errors.Is(err, ErrInvalidInput)
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// Коды ошибок валидации полей, формируемых доменным слоем
+const (
+	CodeMonthYear      = "month_year"
+	CodeEndBeforeStart = "end_before_start"
+)
+
 // ParseMonthYear парсит строку формата MM-YYYY в time.Time
 func ParseMonthYear(dateStr string) (time.Time, error) {
 	parsedDate, err := time.Parse("01-2006", dateStr)
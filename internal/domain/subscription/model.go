@@ -1,27 +1,37 @@
 package subscription
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// MaxPrice - наибольшая допустимая цена подписки. Цена хранится в столбце
+// INTEGER PostgreSQL и передается в gRPC API как int32, поэтому ограничение
+// одинаково для всех хранилищ; значение продублировано в тегах validate
+const MaxPrice = math.MaxInt32
+
 // Subscription представляет основную сущность подписки
 type Subscription struct {
-	ID          uuid.UUID  `json:"id" db:"id"`
-	ServiceName string     `json:"service_name" db:"service_name" validate:"required"`
-	Price       int        `json:"price" db:"price" validate:"required,min=1"`
-	UserID      uuid.UUID  `json:"user_id" db:"user_id" validate:"required"`
-	StartDate   time.Time  `json:"start_date" db:"start_date" validate:"required"`
-	EndDate     *time.Time `json:"end_date,omitempty" db:"end_date"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	ID uuid.UUID `json:"id" db:"id"`
+	// OrganizationID - организация, которой принадлежит подписка
+	OrganizationID uuid.UUID  `json:"organization_id" db:"organization_id"`
+	ServiceName    string     `json:"service_name" db:"service_name" validate:"required"`
+	Price          int        `json:"price" db:"price" validate:"required,min=1,max=2147483647"`
+	UserID         uuid.UUID  `json:"user_id" db:"user_id" validate:"required"`
+	StartDate      time.Time  `json:"start_date" db:"start_date" validate:"required"`
+	EndDate        *time.Time `json:"end_date,omitempty" db:"end_date"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	// DeletedAt заполнен у удаленной подписки, ожидающей окончательной очистки
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
 // CreateSubscriptionRequest представляет запрос на создание подписки
 type CreateSubscriptionRequest struct {
 	ServiceName string    `json:"service_name" validate:"required"`
-	Price       int       `json:"price" validate:"required,min=1"`
+	Price       int       `json:"price" validate:"required,min=1,max=2147483647"`
 	UserID      uuid.UUID `json:"user_id" validate:"required"`
 	StartDate   string    `json:"start_date" validate:"required"`
 	EndDate     *string   `json:"end_date,omitempty"`
@@ -30,7 +40,7 @@ type CreateSubscriptionRequest struct {
 // UpdateSubscriptionRequest представляет запрос на обновление подписки
 type UpdateSubscriptionRequest struct {
 	ServiceName string  `json:"service_name,omitempty"`
-	Price       *int    `json:"price,omitempty" validate:"omitempty,min=1"`
+	Price       *int    `json:"price,omitempty" validate:"omitempty,min=1,max=2147483647"`
 	StartDate   string  `json:"start_date,omitempty"`
 	EndDate     *string `json:"end_date,omitempty"`
 }
@@ -41,9 +51,48 @@ type SubscriptionFilter struct {
 	ServiceName *string    `json:"service_name" form:"service_name"`
 	StartPeriod time.Time  `json:"start_period" form:"start_period" validate:"required"`
 	EndPeriod   time.Time  `json:"end_period" form:"end_period" validate:"required"`
+	// IncludeDeleted включает в расчет удаленные подписки
+	IncludeDeleted bool `json:"include_deleted,omitempty" form:"include_deleted"`
+	// AsOf задает момент времени, по состоянию на который выполняется расчет;
+	// nil означает текущее состояние
+	AsOf *time.Time `json:"as_of,omitempty" form:"as_of"`
+}
+
+// ListFilter содержит параметры фильтрации списка подписок.
+// Limit = 0 означает выборку без ограничения
+type ListFilter struct {
+	UserID      *uuid.UUID `json:"user_id" form:"user_id"`
+	ServiceName *string    `json:"service_name" form:"service_name"`
+	Limit       int        `json:"limit,omitempty" form:"limit"`
+	Offset      int        `json:"offset,omitempty" form:"offset"`
+	// IncludeDeleted включает в выборку удаленные подписки
+	IncludeDeleted bool `json:"include_deleted,omitempty" form:"include_deleted"`
+	// AsOf задает момент времени, по состоянию на который строится выборка;
+	// nil означает текущее состояние
+	AsOf *time.Time `json:"as_of,omitempty" form:"as_of"`
 }
 
 // TotalCostResponse содержит результат расчета стоимости
 type TotalCostResponse struct {
 	TotalCost int `json:"total_cost"`
 }
+
+// SummaryFilter содержит параметры сводки подписок по сервисам. Если период
+// задан, в сводку попадают только подписки, действующие хотя бы в его части;
+// нулевые StartPeriod и EndPeriod означают все подписки
+type SummaryFilter struct {
+	UserID      *uuid.UUID
+	StartPeriod time.Time
+	EndPeriod   time.Time
+}
+
+// ServiceSummary содержит сводку подписок на один сервис
+type ServiceSummary struct {
+	ServiceName       string `json:"service_name" db:"service_name"`
+	SubscriptionCount int    `json:"subscription_count" db:"subscription_count"`
+	UserCount         int    `json:"user_count" db:"user_count"`
+	MinPrice          int    `json:"min_price" db:"min_price"`
+	MaxPrice          int    `json:"max_price" db:"max_price"`
+	// TotalCost - сумма цен подписок, как в CalculateTotalCost
+	TotalCost int `json:"total_cost" db:"total_cost"`
+}
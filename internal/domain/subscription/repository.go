@@ -2,16 +2,39 @@ package subscription
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-// Repository определяет интерфейс для взаимодействия с хранилищем данных о подписках
+// Repository определяет интерфейс для взаимодействия с хранилищем данных о подписках.
+// Удаленные подписки не видны через Get и Update и исключаются из выборок, если
+// фильтр не требует иного. Все операции, кроме Purge, видят только подписки
+// организации из контекста (tenant.FromContext)
 type Repository interface {
 	Create(ctx context.Context, subscription *Subscription) error
 	Get(ctx context.Context, id uuid.UUID) (*Subscription, error)
+	// GetAsOf возвращает подписку в том виде, в котором она существовала в
+	// момент asOf. Если подписки тогда не было или она была удалена,
+	// возвращает ErrSubscriptionNotFound
+	GetAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (*Subscription, error)
+	// GetDeleted возвращает удаленную подписку, еще не очищенную по сроку
+	// хранения. Для неудаленной подписки возвращает ErrSubscriptionNotDeleted
+	GetDeleted(ctx context.Context, id uuid.UUID) (*Subscription, error)
 	Update(ctx context.Context, subscription *Subscription) error
+	// Delete помечает подписку удаленной
 	Delete(ctx context.Context, id uuid.UUID) error
-	List(ctx context.Context) ([]*Subscription, error)
+	// Restore снимает пометку об удалении. Для неудаленной подписки возвращает
+	// ErrSubscriptionNotDeleted
+	Restore(ctx context.Context, id uuid.UUID) error
+	// Purge окончательно удаляет не больше limit подписок, удаленных раньше
+	// deletedBefore, и возвращает их число. Очистка - обслуживание хранилища
+	// и выполняется во всех организациях
+	Purge(ctx context.Context, deletedBefore time.Time, limit int) (int, error)
+	List(ctx context.Context, filter ListFilter) ([]*Subscription, error)
+	Stream(ctx context.Context, filter ListFilter, fn func(*Subscription) error) error
 	CalculateTotalCost(ctx context.Context, filter SubscriptionFilter) (int, error)
+	// SummarizeByService возвращает сводку неудаленных подписок по каждому
+	// сервису в порядке названий, рассчитанную одним запросом к хранилищу
+	SummarizeByService(ctx context.Context, filter SummaryFilter) ([]*ServiceSummary, error)
 }
@@ -2,6 +2,7 @@ package subscription
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -10,8 +11,18 @@ import (
 type Service interface {
 	Create(ctx context.Context, req CreateSubscriptionRequest) (*Subscription, error)
 	Get(ctx context.Context, id uuid.UUID) (*Subscription, error)
+	GetAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (*Subscription, error)
 	Update(ctx context.Context, id uuid.UUID, req UpdateSubscriptionRequest) (*Subscription, error)
 	Delete(ctx context.Context, id uuid.UUID) error
-	List(ctx context.Context) ([]*Subscription, error)
+	Restore(ctx context.Context, id uuid.UUID) (*Subscription, error)
+	List(ctx context.Context, filter ListFilter) ([]*Subscription, error)
+	Export(ctx context.Context, filter ListFilter, fn func(*Subscription) error) error
 	CalculateTotalCost(ctx context.Context, filter SubscriptionFilter) (*TotalCostResponse, error)
 }
+
+// ReportService дополняет Service сводными отчетами, которые строятся одним
+// запросом к хранилищу. HTTP-клиент subctl их не поддерживает
+type ReportService interface {
+	Service
+	SummarizeByService(ctx context.Context, filter SummaryFilter) ([]*ServiceSummary, error)
+}
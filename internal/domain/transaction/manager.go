@@ -0,0 +1,10 @@
+package transaction
+
+import "context"
+
+// Manager выполняет несколько операций репозиториев как одну единицу работы.
+// Репозитории, вызванные с контекстом, переданным в fn, работают в общей
+// транзакции: она фиксируется, если fn вернула nil, и откатывается иначе
+type Manager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,19 @@
+package webhook
+
+import "errors"
+
+// Константы ошибок
+var (
+	// ErrEndpointNotFound возвращается когда получатель не найден
+	ErrEndpointNotFound = errors.New("webhook endpoint not found")
+
+	// ErrDeliveryNotFound возвращается когда доставка не найдена
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+	// ErrLeaseLost возвращается, когда аренда выбранной доставки истекла и
+	// доставку взял другой обработчик
+	ErrLeaseLost = errors.New("webhook delivery lease lost")
+
+	// ErrUnknownEventType возвращается при подписке на неизвестный тип события
+	ErrUnknownEventType = errors.New("unknown event type")
+)
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/domain/event"
+)
+
+// Endpoint - зарегистрированный получатель webhook-уведомлений
+type Endpoint struct {
+	ID uuid.UUID `json:"id"`
+	// OrganizationID - организация, события которой получает получатель
+	OrganizationID uuid.UUID `json:"organization_id"`
+	URL            string    `json:"url"`
+	// Secret возвращается только при регистрации получателя
+	Secret string `json:"secret,omitempty"`
+	// Events - типы событий, на которые подписан получатель; пустой список - все события
+	Events    []event.Type `json:"events"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// Accepts проверяет, подписан ли получатель на событие данного типа
+func (e *Endpoint) Accepts(eventType event.Type) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, t := range e.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryStatus - состояние доставки события получателю
+type DeliveryStatus string
+
+// Состояния доставки
+const (
+	// DeliveryPending - доставка ожидает очередной попытки
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliverySucceeded - получатель ответил статусом 2xx
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryFailed - попытки исчерпаны или получатель удален
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Delivery - запись журнала доставки события получателю
+type Delivery struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	EndpointID uuid.UUID `json:"endpoint_id" db:"endpoint_id"`
+	// OrganizationID совпадает с организацией получателя и позволяет
+	// обработчику очереди найти получателя без контекста запроса
+	OrganizationID uuid.UUID  `json:"-" db:"organization_id"`
+	EventID        uuid.UUID  `json:"event_id" db:"event_id"`
+	EventType      event.Type `json:"event_type" db:"event_type"`
+	// RedeliveryOf - доставка, которую повторяет ручной повтор (Redeliver).
+	// Первая доставка события получателю, без RedeliveryOf, создается только
+	// один раз, даже если событие публикуется повторно
+	RedeliveryOf   *uuid.UUID      `json:"redelivery_of,omitempty" db:"redelivery_of"`
+	Payload        json.RawMessage `json:"payload" db:"payload" swaggertype:"object"`
+	Status         DeliveryStatus  `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code,omitempty" db:"last_status_code"`
+	LastError      *string         `json:"last_error,omitempty" db:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+	// ClaimedUntil - окончание аренды доставки, выбранной ClaimDueDeliveries.
+	// UpdateDelivery сохраняет результат, только пока аренду не перехватил
+	// другой обработчик
+	ClaimedUntil *time.Time `json:"-" db:"-"`
+}
+
+// CreateEndpointRequest представляет запрос на регистрацию получателя
+type CreateEndpointRequest struct {
+	URL string `json:"url" validate:"required,url"`
+	// Secret используется для подписи HMAC-SHA256; если не указан, генерируется сервисом
+	Secret string   `json:"secret,omitempty" validate:"omitempty,min=16"`
+	Events []string `json:"events,omitempty" validate:"dive,required"`
+}
+
+// DeliveryFilter содержит параметры выборки журнала доставок
+type DeliveryFilter struct {
+	Status *DeliveryStatus
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/domain/event"
+)
+
+// Repository определяет интерфейс хранилища получателей и журнала доставок.
+// Получатели и доставки регистрируются и читаются в организации из контекста;
+// обработка очереди доставок (ClaimDueDeliveries, UpdateDelivery) выполняется
+// во всех организациях
+type Repository interface {
+	CreateEndpoint(ctx context.Context, endpoint *Endpoint) error
+	GetEndpoint(ctx context.Context, id uuid.UUID) (*Endpoint, error)
+	ListEndpoints(ctx context.Context) ([]*Endpoint, error)
+	// ListEndpointsForEvent возвращает получателей, подписанных на событие данного типа
+	ListEndpointsForEvent(ctx context.Context, eventType event.Type) ([]*Endpoint, error)
+	DeleteEndpoint(ctx context.Context, id uuid.UUID) error
+
+	// CreateDelivery добавляет доставку в журнал. Первая доставка события
+	// получателю (без RedeliveryOf) добавляется один раз: при повторной
+	// публикации события вызов ничего не изменяет и не возвращает ошибку
+	CreateDelivery(ctx context.Context, delivery *Delivery) error
+	GetDelivery(ctx context.Context, id uuid.UUID) (*Delivery, error)
+	// UpdateDelivery сохраняет результат попытки доставки. Для доставки,
+	// выбранной ClaimDueDeliveries, возвращает ErrLeaseLost, если ее аренда
+	// истекла и доставку уже выбрал другой обработчик
+	UpdateDelivery(ctx context.Context, delivery *Delivery) error
+	ListDeliveries(ctx context.Context, endpointID uuid.UUID, filter DeliveryFilter) ([]*Delivery, error)
+	// ClaimDueDeliveries выбирает до limit доставок, время попытки которых наступило,
+	// и откладывает их следующую попытку на lease, чтобы их не взял другой обработчик
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Delivery, error)
+}
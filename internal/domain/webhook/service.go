@@ -0,0 +1,18 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Service определяет интерфейс управления webhook-получателями
+type Service interface {
+	CreateEndpoint(ctx context.Context, req CreateEndpointRequest) (*Endpoint, error)
+	GetEndpoint(ctx context.Context, id uuid.UUID) (*Endpoint, error)
+	ListEndpoints(ctx context.Context) ([]*Endpoint, error)
+	DeleteEndpoint(ctx context.Context, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, endpointID uuid.UUID, filter DeliveryFilter) ([]*Delivery, error)
+	// Redeliver ставит событие из доставки в очередь на повторную отправку
+	Redeliver(ctx context.Context, deliveryID uuid.UUID) (*Delivery, error)
+}
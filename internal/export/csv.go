@@ -0,0 +1,74 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/subscription-service/internal/domain/subscription"
+)
+
+// csvWriter записывает подписки в формате CSV с заголовком
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+// Write записывает подписку отдельной строкой
+func (c *csvWriter) Write(sub *subscription.Subscription) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	return c.w.Write(escapeFormulas(Record(sub)))
+}
+
+// Flush сбрасывает буфер csv.Writer
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// Close гарантирует наличие заголовка даже в пустой выгрузке
+func (c *csvWriter) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	return c.Flush()
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+	c.headerWritten = true
+	return c.w.Write(Columns)
+}
+
+// formulaPrefixes - первые символы, с которых Excel и другие табличные
+// редакторы начинают формулу
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeFormulas экранирует апострофом ячейки, которые табличный редактор
+// выполнил бы как формулу (CSV injection). Апостроф не отображается в ячейке,
+// а значение остается текстом
+func escapeFormulas(record []string) []string {
+	for i, cell := range record {
+		if cell != "" && strings.ContainsRune(formulaPrefixes, rune(cell[0])) {
+			record[i] = "'" + cell
+		}
+	}
+	return record
+}
+
+// UnescapeFormula снимает апостроф, добавленный при выгрузке в CSV, чтобы
+// выгруженный файл импортировался без изменения значений
+func UnescapeFormula(cell string) string {
+	if len(cell) > 1 && cell[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(cell[1])) {
+		return cell[1:]
+	}
+	return cell
+}
@@ -0,0 +1,111 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/subscription-service/internal/domain/subscription"
+)
+
+// Format определяет формат выгрузки подписок
+type Format string
+
+// Поддерживаемые форматы выгрузки
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+	FormatXLSX   Format = "xlsx"
+)
+
+// ErrUnsupportedFormat возвращается при запросе неизвестного формата выгрузки
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// Columns содержит заголовки колонок табличных форматов в порядке вывода
+var Columns = []string{
+	"id",
+	"service_name",
+	"price",
+	"user_id",
+	"start_date",
+	"end_date",
+	"created_at",
+	"updated_at",
+}
+
+// Writer последовательно записывает подписки в выходной поток
+type Writer interface {
+	// Write записывает одну подписку
+	Write(sub *subscription.Subscription) error
+	// Flush сбрасывает буферизированные данные в выходной поток
+	Flush() error
+	// Close завершает документ; сам выходной поток не закрывается
+	Close() error
+}
+
+// ParseFormat разбирает название формата; пустая строка означает CSV
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatNDJSON:
+		return FormatNDJSON, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, s)
+	}
+}
+
+// ContentType возвращает MIME-тип формата
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Extension возвращает расширение файла для формата
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// NewWriter создает Writer для указанного формата
+func NewWriter(f Format, w io.Writer) (Writer, error) {
+	switch f {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatNDJSON:
+		return newNDJSONWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, f)
+	}
+}
+
+// Record преобразует подписку в строковые значения колонок Columns
+func Record(sub *subscription.Subscription) []string {
+	endDate := ""
+	if sub.EndDate != nil {
+		endDate = subscription.FormatMonthYear(*sub.EndDate)
+	}
+
+	return []string{
+		sub.ID.String(),
+		sub.ServiceName,
+		strconv.Itoa(sub.Price),
+		sub.UserID.String(),
+		subscription.FormatMonthYear(sub.StartDate),
+		endDate,
+		sub.CreatedAt.UTC().Format(time.RFC3339),
+		sub.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
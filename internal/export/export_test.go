@@ -0,0 +1,172 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/domain/subscription"
+)
+
+func testSubscriptions() []*subscription.Subscription {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2024, 1, 5, 12, 30, 0, 0, time.UTC)
+
+	return []*subscription.Subscription{
+		{
+			ID:          uuid.New(),
+			ServiceName: "Yandex Plus",
+			Price:       400,
+			UserID:      uuid.New(),
+			StartDate:   start,
+			EndDate:     &end,
+			CreatedAt:   created,
+			UpdatedAt:   created,
+		},
+		{
+			ID:          uuid.New(),
+			ServiceName: `Tom & Jerry "<Premium>"`,
+			Price:       199,
+			UserID:      uuid.New(),
+			StartDate:   start,
+			CreatedAt:   created,
+			UpdatedAt:   created,
+		},
+	}
+}
+
+func writeAll(t *testing.T, format Format, subs []*subscription.Subscription) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	require.NoError(t, err)
+
+	for _, sub := range subs {
+		require.NoError(t, w.Write(sub))
+	}
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Format
+		wantErr  bool
+	}{
+		{"", FormatCSV, false},
+		{"csv", FormatCSV, false},
+		{"NDJSON", FormatNDJSON, false},
+		{"xlsx", FormatXLSX, false},
+		{"pdf", "", true},
+	}
+
+	for _, tt := range tests {
+		format, err := ParseFormat(tt.input)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrUnsupportedFormat)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, format)
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	subs := testSubscriptions()
+
+	records, err := csv.NewReader(bytes.NewReader(writeAll(t, FormatCSV, subs))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	assert.Equal(t, Columns, records[0])
+	assert.Equal(t, []string{
+		subs[0].ID.String(), "Yandex Plus", "400", subs[0].UserID.String(),
+		"01-2024", "06-2024", "2024-01-05T12:30:00Z", "2024-01-05T12:30:00Z",
+	}, records[1])
+	assert.Equal(t, subs[1].ServiceName, records[2][1])
+	assert.Equal(t, "", records[2][5])
+}
+
+func TestCSVWriter_Formulas(t *testing.T) {
+	names := []string{"=HYPERLINK(\"http://evil\")", "+7 Music", "-1", "@SUM(A1)", "\tTab", "\rReturn", "Netflix = Kino"}
+	subs := make([]*subscription.Subscription, 0, len(names))
+	for _, name := range names {
+		subs = append(subs, &subscription.Subscription{ID: uuid.New(), ServiceName: name, Price: 100, UserID: uuid.New()})
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(writeAll(t, FormatCSV, subs))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, len(names)+1)
+
+	for i, name := range names[:len(names)-1] {
+		assert.Equal(t, "'"+name, records[i+1][1], "ячейка, похожая на формулу, экранируется")
+	}
+	assert.Equal(t, "Netflix = Kino", records[len(names)][1], "обычные значения не изменяются")
+
+	for i, name := range names {
+		assert.Equal(t, name, UnescapeFormula(records[i+1][1]), "экранирование снимается при импорте")
+	}
+	assert.Equal(t, "'quoted", UnescapeFormula("'quoted"))
+}
+
+func TestCSVWriter_Empty(t *testing.T) {
+	records, err := csv.NewReader(bytes.NewReader(writeAll(t, FormatCSV, nil))).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{Columns}, records)
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	subs := testSubscriptions()
+
+	lines := bytes.Split(bytes.TrimSpace(writeAll(t, FormatNDJSON, subs)), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var decoded subscription.Subscription
+	require.NoError(t, json.Unmarshal(lines[1], &decoded))
+	assert.Equal(t, subs[1].ID, decoded.ID)
+	assert.Equal(t, subs[1].ServiceName, decoded.ServiceName)
+}
+
+func TestXLSXWriter(t *testing.T) {
+	subs := testSubscriptions()
+	data := writeAll(t, FormatXLSX, subs)
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		parts[f.Name] = string(content)
+	}
+
+	for _, name := range []string{
+		"[Content_Types].xml",
+		"_rels/.rels",
+		"xl/workbook.xml",
+		"xl/_rels/workbook.xml.rels",
+		"xl/worksheets/sheet1.xml",
+	} {
+		assert.Contains(t, parts, name)
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<c r="A1" t="inlineStr"><is><t>id</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="C2"><v>400</v></c>`)
+	assert.Contains(t, sheet, `Tom &amp; Jerry &#34;&lt;Premium&gt;&#34;`)
+	assert.NotContains(t, sheet, `r="F3"`)
+	assert.Contains(t, sheet, `</sheetData></worksheet>`)
+}
@@ -0,0 +1,35 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/subscription-service/internal/domain/subscription"
+)
+
+// ndjsonWriter записывает каждую подписку отдельным JSON-объектом на строке
+type ndjsonWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	buf := bufio.NewWriter(w)
+	return &ndjsonWriter{buf: buf, enc: json.NewEncoder(buf)}
+}
+
+// Write записывает подписку в том же представлении, что и JSON API
+func (n *ndjsonWriter) Write(sub *subscription.Subscription) error {
+	return n.enc.Encode(sub)
+}
+
+// Flush сбрасывает буфер в выходной поток
+func (n *ndjsonWriter) Flush() error {
+	return n.buf.Flush()
+}
+
+// Close сбрасывает оставшиеся данные
+func (n *ndjsonWriter) Close() error {
+	return n.Flush()
+}
@@ -0,0 +1,43 @@
+package health
+
+import (
+	"context"
+	"fmt"
+)
+
+// Pinger проверяет соединение с базой данных, например *sql.DB
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// DatabaseCheck проверяет, что база данных отвечает
+func DatabaseCheck(db Pinger) Check {
+	return func(ctx context.Context) error {
+		if err := db.PingContext(ctx); err != nil {
+			return fmt.Errorf("failed to ping database: %w", err)
+		}
+		return nil
+	}
+}
+
+// SchemaVersion возвращает версию примененных миграций и признак миграции,
+// прерванной на середине
+type SchemaVersion func(ctx context.Context) (version uint, dirty bool, err error)
+
+// MigrationCheck проверяет, что схема базы данных в точности соответствует
+// миграциям, с которыми собран сервис: expected - версия последней из них
+func MigrationCheck(current SchemaVersion, expected uint) Check {
+	return func(ctx context.Context) error {
+		version, dirty, err := current(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get schema version: %w", err)
+		}
+		if dirty {
+			return fmt.Errorf("migration %d is dirty", version)
+		}
+		if version != expected {
+			return fmt.Errorf("schema version %d, expected %d", version, expected)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,102 @@
+// Package health проверяет готовность сервиса обслуживать запросы
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Статусы проверок и отчета
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// ErrShuttingDown - сервис останавливается и не принимает новые запросы
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// Check проверяет зависимость сервиса; ошибка означает, что она недоступна
+type Check func(ctx context.Context) error
+
+// CheckResult - результат одной проверки
+type CheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// DurationMs - длительность проверки в миллисекундах
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// Report - отчет о готовности: общий статус и результаты проверок по именам
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Ready сообщает, прошли ли все проверки
+func (r Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+// namedCheck - проверка с именем в отчете
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Readiness выполняет проверки готовности. После Shutdown сервис считается
+// неготовым независимо от проверок, чтобы балансировщик перестал направлять
+// ему запросы до остановки сервера
+type Readiness struct {
+	timeout      time.Duration
+	checks       []namedCheck
+	shuttingDown atomic.Bool
+}
+
+// NewReadiness создает набор проверок готовности; timeout ограничивает
+// время всех проверок одного отчета
+func NewReadiness(timeout time.Duration) *Readiness {
+	return &Readiness{timeout: timeout}
+}
+
+// Add добавляет проверку с именем name. Вызывается до начала обслуживания запросов
+func (r *Readiness) Add(name string, check Check) {
+	r.checks = append(r.checks, namedCheck{name: name, check: check})
+}
+
+// Shutdown переводит сервис в состояние остановки
+func (r *Readiness) Shutdown() {
+	r.shuttingDown.Store(true)
+}
+
+// Check выполняет проверки и возвращает отчет. При остановке проверки не
+// выполняются, а отчет содержит проверку shutdown
+func (r *Readiness) Check(ctx context.Context) Report {
+	if r.shuttingDown.Load() {
+		return Report{
+			Status: StatusFail,
+			Checks: map[string]CheckResult{"shutdown": {Status: StatusFail, Error: ErrShuttingDown.Error()}},
+		}
+	}
+
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(r.checks))}
+	for _, c := range r.checks {
+		start := time.Now()
+		err := c.check(ctx)
+		result := CheckResult{Status: StatusOK, DurationMs: float64(time.Since(start).Microseconds()) / 1000}
+		if err != nil {
+			result.Status = StatusFail
+			result.Error = err.Error()
+			report.Status = StatusFail
+		}
+		report.Checks[c.name] = result
+	}
+	return report
+}
@@ -0,0 +1,101 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pingerFunc - заглушка соединения с базой
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) PingContext(ctx context.Context) error {
+	return f(ctx)
+}
+
+func TestReadiness_Check(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("все проверки прошли", func(t *testing.T) {
+		readiness := NewReadiness(time.Second)
+		readiness.Add("database", DatabaseCheck(pingerFunc(func(context.Context) error { return nil })))
+
+		report := readiness.Check(ctx)
+		assert.True(t, report.Ready())
+		assert.Equal(t, StatusOK, report.Checks["database"].Status)
+	})
+
+	t.Run("ошибка одной проверки", func(t *testing.T) {
+		readiness := NewReadiness(time.Second)
+		readiness.Add("database", DatabaseCheck(pingerFunc(func(context.Context) error {
+			return errors.New("connection refused")
+		})))
+		readiness.Add("migrations", func(context.Context) error { return nil })
+
+		report := readiness.Check(ctx)
+		assert.False(t, report.Ready())
+		assert.Equal(t, StatusFail, report.Checks["database"].Status)
+		assert.Contains(t, report.Checks["database"].Error, "connection refused")
+		assert.Equal(t, StatusOK, report.Checks["migrations"].Status)
+	})
+
+	t.Run("проверки ограничены таймаутом", func(t *testing.T) {
+		readiness := NewReadiness(10 * time.Millisecond)
+		readiness.Add("database", DatabaseCheck(pingerFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})))
+
+		report := readiness.Check(ctx)
+		assert.False(t, report.Ready())
+		assert.Contains(t, report.Checks["database"].Error, context.DeadlineExceeded.Error())
+	})
+
+	t.Run("при остановке сервис не готов", func(t *testing.T) {
+		checked := false
+		readiness := NewReadiness(time.Second)
+		readiness.Add("database", func(context.Context) error {
+			checked = true
+			return nil
+		})
+		readiness.Shutdown()
+
+		report := readiness.Check(ctx)
+		assert.False(t, report.Ready())
+		assert.Equal(t, ErrShuttingDown.Error(), report.Checks["shutdown"].Error)
+		assert.False(t, checked, "проверки не выполняются")
+	})
+}
+
+func TestMigrationCheck(t *testing.T) {
+	ctx := context.Background()
+	version := func(v uint, dirty bool, err error) SchemaVersion {
+		return func(context.Context) (uint, bool, error) { return v, dirty, err }
+	}
+
+	tests := []struct {
+		name    string
+		current SchemaVersion
+		wantErr string
+	}{
+		{name: "версия совпадает", current: version(11, false, nil)},
+		{name: "схема отстает", current: version(10, false, nil), wantErr: "schema version 10, expected 11"},
+		{name: "схема новее", current: version(12, false, nil), wantErr: "schema version 12, expected 11"},
+		{name: "прерванная миграция", current: version(11, true, nil), wantErr: "migration 11 is dirty"},
+		{name: "ошибка запроса", current: version(0, false, errors.New("timeout")), wantErr: "timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := MigrationCheck(tt.current, 11)(ctx)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
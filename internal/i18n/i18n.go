@@ -0,0 +1,163 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/ru"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	ruTranslations "github.com/go-playground/validator/v10/translations/ru"
+	"golang.org/x/text/language"
+)
+
+// Поддерживаемые локали
+const (
+	LocaleEN = "en"
+	LocaleRU = "ru"
+
+	// DefaultLocale используется, если клиент не указал поддерживаемый язык
+	DefaultLocale = LocaleEN
+)
+
+//go:embed catalogs/*.json
+var catalogFS embed.FS
+
+// supported перечисляет локали в порядке приоритета при согласовании языка;
+// первая из них используется по умолчанию
+var supported = []struct {
+	locale     string
+	tag        language.Tag
+	translator locales.Translator
+	validation func(*validator.Validate, ut.Translator) error
+}{
+	{LocaleEN, language.English, en.New(), enTranslations.RegisterDefaultTranslations},
+	{LocaleRU, language.Russian, ru.New(), ruTranslations.RegisterDefaultTranslations},
+}
+
+var (
+	universal = newUniversalTranslator()
+	matcher   = newMatcher()
+)
+
+// newUniversalTranslator создает переводчик и загружает в него каталоги сообщений
+func newUniversalTranslator() *ut.UniversalTranslator {
+	translators := make([]locales.Translator, 0, len(supported))
+	for _, s := range supported {
+		translators = append(translators, s.translator)
+	}
+	uni := ut.New(translators[0], translators...)
+
+	for _, s := range supported {
+		trans, _ := uni.GetTranslator(s.locale)
+		if err := loadCatalog(trans, s.locale); err != nil {
+			// Каталоги встроены в бинарный файл, поэтому ошибка здесь - ошибка сборки
+			panic(err)
+		}
+	}
+
+	return uni
+}
+
+// loadCatalog добавляет сообщения каталога локали в переводчик
+func loadCatalog(trans ut.Translator, locale string) error {
+	data, err := catalogFS.ReadFile("catalogs/" + locale + ".json")
+	if err != nil {
+		return fmt.Errorf("failed to read %s catalog: %w", locale, err)
+	}
+
+	var messages map[string]string
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return fmt.Errorf("failed to parse %s catalog: %w", locale, err)
+	}
+
+	for key, text := range messages {
+		if err := trans.Add(key, text, false); err != nil {
+			return fmt.Errorf("failed to add %s message %q: %w", locale, key, err)
+		}
+	}
+
+	return nil
+}
+
+func newMatcher() language.Matcher {
+	tags := make([]language.Tag, 0, len(supported))
+	for _, s := range supported {
+		tags = append(tags, s.tag)
+	}
+	return language.NewMatcher(tags)
+}
+
+// Match выбирает поддерживаемую локаль по значению заголовка Accept-Language
+func Match(acceptLanguage string) string {
+	if acceptLanguage == "" {
+		return DefaultLocale
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+	return supported[index].locale
+}
+
+// RegisterValidationTranslations подключает переводы сообщений validator
+// для всех поддерживаемых локалей. Вызывается один раз для каждого валидатора
+func RegisterValidationTranslations(v *validator.Validate) error {
+	for _, s := range supported {
+		trans, _ := universal.GetTranslator(s.locale)
+		if err := s.validation(v, trans); err != nil {
+			return fmt.Errorf("failed to register %s validation translations: %w", s.locale, err)
+		}
+	}
+	return nil
+}
+
+// localeKey - ключ контекста для выбранной локали
+type localeKey struct{}
+
+// WithLocale сохраняет локаль в контексте
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext возвращает локаль из контекста или локаль по умолчанию
+func LocaleFromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// Translator возвращает переводчик для локали из контекста
+func Translator(ctx context.Context) ut.Translator {
+	trans, _ := universal.GetTranslator(LocaleFromContext(ctx))
+	return trans
+}
+
+// T переводит сообщение на язык из контекста. Ключом служит исходный
+// английский текст, поэтому для сообщения без перевода возвращается сам ключ
+// с подставленными параметрами {0}, {1}, ...
+func T(ctx context.Context, key string, params ...string) string {
+	if text, err := Translator(ctx).T(key, params...); err == nil {
+		return text
+	}
+
+	text := key
+	for i, param := range params {
+		text = strings.ReplaceAll(text, "{"+strconv.Itoa(i)+"}", param)
+	}
+	return text
+}
@@ -0,0 +1,78 @@
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", LocaleEN},
+		{"ru", LocaleRU},
+		{"ru-RU,ru;q=0.9,en-US;q=0.8", LocaleRU},
+		{"en-GB,en;q=0.9,ru;q=0.5", LocaleEN},
+		{"de-DE,ru;q=0.7", LocaleRU},
+		{"fr-FR", LocaleEN},
+		{"not a language header;;", LocaleEN},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Match(tt.header), "Accept-Language: %q", tt.header)
+	}
+}
+
+func TestT(t *testing.T) {
+	ru := WithLocale(context.Background(), LocaleRU)
+	en := WithLocale(context.Background(), LocaleEN)
+
+	assert.Equal(t, "Подписка не найдена", T(ru, "Subscription not found"))
+	assert.Equal(t, "Subscription not found", T(en, "Subscription not found"))
+	assert.Equal(t, "Subscription not found", T(context.Background(), "Subscription not found"))
+
+	// Параметры подставляются в переведенный текст
+	assert.Equal(t, "user_id должен быть корректным UUID", T(ru, "{0} must be a valid UUID", "user_id"))
+
+	// Сообщение без перевода возвращается как есть
+	assert.Equal(t, "Unknown a b", T(ru, "Unknown {0} {1}", "a", "b"))
+}
+
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	keys := func(locale string) map[string]string {
+		data, err := catalogFS.ReadFile("catalogs/" + locale + ".json")
+		require.NoError(t, err)
+		var messages map[string]string
+		require.NoError(t, json.Unmarshal(data, &messages))
+		return messages
+	}
+
+	en, ru := keys(LocaleEN), keys(LocaleRU)
+	for key := range en {
+		assert.Contains(t, ru, key, "missing ru translation")
+	}
+	for key := range ru {
+		assert.Contains(t, en, key, "missing en message")
+	}
+}
+
+func TestRegisterValidationTranslations(t *testing.T) {
+	v := validator.New()
+	require.NoError(t, RegisterValidationTranslations(v))
+
+	type request struct {
+		Price int `validate:"required"`
+	}
+	err := v.Struct(request{})
+	require.Error(t, err)
+
+	fieldErr := err.(validator.ValidationErrors)[0]
+	ru := Translator(WithLocale(context.Background(), LocaleRU))
+	assert.Equal(t, "Price обязательное поле", fieldErr.Translate(ru))
+}
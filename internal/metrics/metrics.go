@@ -0,0 +1,30 @@
+// Package metrics собирает метрики сервиса в формате Prometheus
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace - общий префикс имен метрик сервиса
+const Namespace = "subscription_service"
+
+// NewRegistry создает реестр метрик со стандартными метриками среды
+// выполнения Go и процесса
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// Handler возвращает обработчик, отдающий метрики реестра в текстовом
+// формате Prometheus
+func Handler(registry *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
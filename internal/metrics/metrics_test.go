@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// activeCounterFunc - заглушка подсчета действующих подписок
+type activeCounterFunc func(ctx context.Context, at time.Time) (int, error)
+
+func (f activeCounterFunc) CountActive(ctx context.Context, at time.Time) (int, error) {
+	return f(ctx, at)
+}
+
+func TestSubscriptionCollector(t *testing.T) {
+	t.Run("число действующих подписок", func(t *testing.T) {
+		collector := NewSubscriptionCollector(activeCounterFunc(func(context.Context, time.Time) (int, error) {
+			return 42, nil
+		}))
+
+		expected := `
+# HELP subscription_service_active_subscriptions Number of subscriptions in effect across all organizations.
+# TYPE subscription_service_active_subscriptions gauge
+subscription_service_active_subscriptions 42
+`
+		assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+	})
+
+	t.Run("ошибка хранилища пропускает метрику", func(t *testing.T) {
+		collector := NewSubscriptionCollector(activeCounterFunc(func(context.Context, time.Time) (int, error) {
+			return 0, errors.New("connection refused")
+		}))
+
+		assert.Equal(t, 0, testutil.CollectAndCount(collector))
+	})
+}
+
+func TestQueryMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewQueryMetrics(registry)
+
+	m.ObserveQuery("subscriptions", "Get", 3*time.Millisecond)
+	m.ObserveQuery("subscriptions", "Get", 30*time.Millisecond)
+	m.ObserveQuery("members", "List", time.Millisecond)
+
+	count, err := testutil.GatherAndCount(registry, "subscription_service_repository_query_duration_seconds")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count, "ряд на каждую пару репозитория и метода")
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// QueryMetrics - гистограмма длительности методов репозиториев по
+// репозиторию и методу. Реализует postgresql.QueryObserver
+type QueryMetrics struct {
+	duration *prometheus.HistogramVec
+}
+
+// NewQueryMetrics создает метрики репозиториев и регистрирует их в registerer
+func NewQueryMetrics(registerer prometheus.Registerer) *QueryMetrics {
+	m := &QueryMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "repository",
+			Name:      "query_duration_seconds",
+			Help:      "Duration of repository method calls.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"repository", "method"}),
+	}
+	registerer.MustRegister(m.duration)
+	return m
+}
+
+// ObserveQuery учитывает длительность вызова метода репозитория
+func (m *QueryMetrics) ObserveQuery(repository, method string, duration time.Duration) {
+	m.duration.WithLabelValues(repository, method).Observe(duration.Seconds())
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// collectTimeout ограничивает время запроса бизнес-метрик при сборе
+const collectTimeout = 5 * time.Second
+
+// ActiveCounter считает подписки, действующие в момент at, во всех организациях
+type ActiveCounter interface {
+	CountActive(ctx context.Context, at time.Time) (int, error)
+}
+
+// SubscriptionCollector собирает бизнес-метрики подписок при каждом запросе
+// метрик, поэтому значения не устаревают между сборами
+type SubscriptionCollector struct {
+	counter ActiveCounter
+	active  *prometheus.Desc
+}
+
+// NewSubscriptionCollector создает сборщик бизнес-метрик подписок
+func NewSubscriptionCollector(counter ActiveCounter) *SubscriptionCollector {
+	return &SubscriptionCollector{
+		counter: counter,
+		active: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "", "active_subscriptions"),
+			"Number of subscriptions in effect across all organizations.",
+			nil, nil,
+		),
+	}
+}
+
+// Describe реализует prometheus.Collector
+func (c *SubscriptionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.active
+}
+
+// Collect реализует prometheus.Collector. Если хранилище недоступно,
+// метрика пропускается, а остальные метрики отдаются как обычно
+func (c *SubscriptionCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	count, err := c.counter.CountActive(ctx, time.Now())
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to collect subscription metrics")
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.active, prometheus.GaugeValue, float64(count))
+}
@@ -0,0 +1,50 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// versionDigits - ширина номера миграции в имени файла: 001_name.up.sql
+const versionDigits = 3
+
+var namePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Create создает в каталоге dir пустые файлы up и down для миграции name со
+// следующим порядковым номером и возвращает их пути
+func Create(dir, name string) ([]string, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid migration name %q: use lowercase letters, digits and underscores", name)
+	}
+
+	latest, err := Latest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	version := fmt.Sprintf("%0*d", versionDigits, latest+1)
+	var paths []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%s_%s.%s.sql", version, name, direction))
+		// O_EXCL не дает перезаписать миграцию, созданную одновременно
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("failed to create migration: %w", err), remove(paths))
+		}
+		f.Close()
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// remove удаляет уже созданные файлы миграции, если создать ее целиком не удалось
+func remove(paths []string) error {
+	var errs []error
+	for _, path := range paths {
+		errs = append(errs, os.Remove(path))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,195 @@
+package migration
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/lib/pq"
+)
+
+// Config хранит настройки применения миграций
+type Config struct {
+	DSN string
+	// Path - каталог с файлами миграций
+	Path string
+	// Table - таблица, в которой golang-migrate хранит версию схемы
+	Table string
+	// LockTimeout - сколько ждать блокировки, которую держит другой экземпляр
+	LockTimeout time.Duration
+}
+
+// Status описывает состояние схемы относительно каталога миграций
+type Status struct {
+	// Version - примененная версия; 0, если миграции не применялись
+	Version uint
+	// Dirty - миграция Version прервана на середине и требует force
+	Dirty bool
+	// Latest - последняя миграция в каталоге
+	Latest uint
+	// Pending - версии из каталога, которые еще не применены
+	Pending []uint
+}
+
+// Migrator управляет миграциями схемы базы
+type Migrator struct {
+	m      *migrate.Migrate
+	source source.Driver
+}
+
+// Open подключается к базе через отдельное соединение. Драйвер PostgreSQL
+// держит на нем advisory lock (pg_advisory_lock) на время изменения схемы,
+// поэтому экземпляры, запущенные одновременно, применяют миграции по очереди.
+// Соединение закрывается в Close, и блокировка не остается в пуле сервиса
+func Open(config Config) (*Migrator, error) {
+	db, err := sql.Open("postgres", config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations connection: %w", err)
+	}
+
+	driver, err := postgres.WithInstance(db, &postgres.Config{
+		MigrationsTable: config.Table,
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create migrations driver: %w", err)
+	}
+
+	m, err := New(config.Path, "postgres", driver)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+	m.m.LockTimeout = config.LockTimeout
+	return m, nil
+}
+
+// New создает Migrator для миграций из каталога path и драйвера базы driver
+func New(path, databaseName string, driver database.Driver) (*Migrator, error) {
+	src, err := (&file.File{}).Open(fmt.Sprintf("file://%s", path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("file", src, databaseName, driver)
+	if err != nil {
+		src.Close()
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+	return &Migrator{m: m, source: src}, nil
+}
+
+// Close закрывает каталог миграций и соединение с базой
+func (m *Migrator) Close() error {
+	sourceErr, databaseErr := m.m.Close()
+	return errors.Join(sourceErr, databaseErr)
+}
+
+// Up применяет все непримененные миграции
+func (m *Migrator) Up() error {
+	if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	return nil
+}
+
+// Down откатывает n последних примененных миграций
+func (m *Migrator) Down(n int) error {
+	if n <= 0 {
+		return fmt.Errorf("number of migrations to roll back must be positive, got %d", n)
+	}
+	if err := m.m.Steps(-n); err != nil {
+		return fmt.Errorf("failed to roll back migrations: %w", err)
+	}
+	return nil
+}
+
+// Goto применяет или откатывает миграции до версии version
+func (m *Migrator) Goto(version uint) error {
+	if err := m.m.Migrate(version); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to migrate to version %d: %w", version, err)
+	}
+	return nil
+}
+
+// Force записывает версию схемы без выполнения миграций и снимает признак
+// прерванной миграции. Используется, когда схема исправлена вручную после
+// сбоя; -1 означает, что миграции не применялись
+func (m *Migrator) Force(version int) error {
+	if err := m.m.Force(version); err != nil {
+		return fmt.Errorf("failed to force version %d: %w", version, err)
+	}
+	return nil
+}
+
+// Status возвращает примененную версию и список непримененных миграций
+func (m *Migrator) Status() (Status, error) {
+	var status Status
+
+	version, dirty, err := m.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return Status{}, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	status.Version, status.Dirty = version, dirty
+
+	versions, err := list(m.source)
+	if err != nil {
+		return Status{}, err
+	}
+	for _, v := range versions {
+		if v > status.Version {
+			status.Pending = append(status.Pending, v)
+		}
+		status.Latest = v
+	}
+	return status, nil
+}
+
+// Latest возвращает версию последней миграции в каталоге path
+func Latest(path string) (uint, error) {
+	src, err := (&file.File{}).Open(fmt.Sprintf("file://%s", path))
+	if err != nil {
+		return 0, fmt.Errorf("failed to open migrations: %w", err)
+	}
+	defer src.Close()
+
+	versions, err := list(src)
+	if err != nil {
+		return 0, err
+	}
+	if len(versions) == 0 {
+		return 0, nil
+	}
+	return versions[len(versions)-1], nil
+}
+
+// list возвращает версии миграций источника по возрастанию
+func list(src source.Driver) ([]uint, error) {
+	version, err := src.First()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	versions := []uint{version}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return versions, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migrations: %w", err)
+		}
+		versions = append(versions, next)
+		version = next
+	}
+}
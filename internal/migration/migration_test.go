@@ -0,0 +1,149 @@
+package migration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/database/stub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeMigrations создает в каталоге dir миграции с версиями versions
+func writeMigrations(t *testing.T, dir string, versions ...uint) {
+	t.Helper()
+	for _, v := range versions {
+		for _, direction := range []string{"up", "down"} {
+			name := filepath.Join(dir, fmt.Sprintf("%03d_step.%s.sql", v, direction))
+			require.NoError(t, os.WriteFile(name, []byte(fmt.Sprintf("-- %d %s", v, direction)), 0o644))
+		}
+	}
+}
+
+// newStubMigrator создает Migrator над базой-заглушкой golang-migrate
+func newStubMigrator(t *testing.T, dir string) (*Migrator, *stub.Stub) {
+	t.Helper()
+	driver, err := stub.WithInstance(nil, &stub.Config{})
+	require.NoError(t, err)
+
+	m, err := New(dir, "stub", driver)
+	require.NoError(t, err)
+	t.Cleanup(func() { m.Close() })
+	return m, driver.(*stub.Stub)
+}
+
+func TestMigrator(t *testing.T) {
+	t.Run("применение и откат миграций", func(t *testing.T) {
+		dir := t.TempDir()
+		writeMigrations(t, dir, 1, 2, 3)
+		m, db := newStubMigrator(t, dir)
+
+		status, err := m.Status()
+		require.NoError(t, err)
+		assert.Equal(t, Status{Version: 0, Latest: 3, Pending: []uint{1, 2, 3}}, status)
+
+		require.NoError(t, m.Up())
+		assert.Equal(t, 3, db.CurrentVersion)
+		// Повторный up без новых миграций не считается ошибкой
+		require.NoError(t, m.Up())
+
+		require.NoError(t, m.Down(2))
+		assert.Equal(t, 1, db.CurrentVersion)
+		assert.Equal(t, "-- 2 down", string(db.LastRunMigration))
+
+		status, err = m.Status()
+		require.NoError(t, err)
+		assert.Equal(t, Status{Version: 1, Latest: 3, Pending: []uint{2, 3}}, status)
+
+		require.NoError(t, m.Goto(2))
+		assert.Equal(t, 2, db.CurrentVersion)
+		require.NoError(t, m.Goto(2))
+	})
+
+	t.Run("откат требует положительного числа миграций", func(t *testing.T) {
+		dir := t.TempDir()
+		writeMigrations(t, dir, 1)
+		m, _ := newStubMigrator(t, dir)
+
+		assert.Error(t, m.Down(0))
+	})
+
+	t.Run("force снимает признак прерванной миграции", func(t *testing.T) {
+		dir := t.TempDir()
+		writeMigrations(t, dir, 1, 2)
+		m, db := newStubMigrator(t, dir)
+		require.NoError(t, db.SetVersion(2, true))
+
+		status, err := m.Status()
+		require.NoError(t, err)
+		assert.True(t, status.Dirty)
+		assert.Error(t, m.Up())
+
+		require.NoError(t, m.Force(1))
+		status, err = m.Status()
+		require.NoError(t, err)
+		assert.Equal(t, Status{Version: 1, Latest: 2, Pending: []uint{2}}, status)
+	})
+}
+
+func TestLatest(t *testing.T) {
+	t.Run("последняя миграция каталога", func(t *testing.T) {
+		dir := t.TempDir()
+		writeMigrations(t, dir, 1, 2, 10)
+
+		latest, err := Latest(dir)
+		require.NoError(t, err)
+		assert.Equal(t, uint(10), latest)
+	})
+
+	t.Run("пустой каталог", func(t *testing.T) {
+		latest, err := Latest(t.TempDir())
+		require.NoError(t, err)
+		assert.Zero(t, latest)
+	})
+
+	t.Run("миграции репозитория", func(t *testing.T) {
+		latest, err := Latest("../../migrations")
+		require.NoError(t, err)
+		assert.NotZero(t, latest)
+	})
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("следующий номер миграции", func(t *testing.T) {
+		dir := t.TempDir()
+		writeMigrations(t, dir, 1, 2)
+
+		paths, err := Create(dir, "add_plans")
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(dir, "003_add_plans.up.sql"),
+			filepath.Join(dir, "003_add_plans.down.sql"),
+		}, paths)
+
+		latest, err := Latest(dir)
+		require.NoError(t, err)
+		assert.Equal(t, uint(3), latest)
+	})
+
+	t.Run("первая миграция", func(t *testing.T) {
+		dir := t.TempDir()
+
+		paths, err := Create(dir, "init")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "001_init.up.sql"), paths[0])
+	})
+
+	t.Run("недопустимое имя", func(t *testing.T) {
+		dir := t.TempDir()
+
+		_, err := Create(dir, "Add Plans")
+		assert.Error(t, err)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
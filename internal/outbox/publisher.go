@@ -0,0 +1,28 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/outbox"
+)
+
+// Publisher реализует event.Publisher записью событий в outbox. Вызванный в
+// транзакции, он сохраняет событие атомарно с изменением, которое его породило
+type Publisher struct {
+	repo outbox.Repository
+}
+
+// NewPublisher создает издателя, пишущего события в outbox
+func NewPublisher(repo outbox.Repository) *Publisher {
+	return &Publisher{repo: repo}
+}
+
+// Publish записывает событие в outbox; отправку в приемники выполняет Relay
+func (p *Publisher) Publish(ctx context.Context, evt event.Event) error {
+	if err := p.repo.Add(ctx, outbox.NewMessage(evt)); err != nil {
+		return fmt.Errorf("failed to add event to outbox: %w", err)
+	}
+	return nil
+}
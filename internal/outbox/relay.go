@@ -0,0 +1,108 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/internal/backoff"
+	"github.com/subscription-service/internal/domain/outbox"
+)
+
+// Config хранит настройки пересылки событий из outbox
+type Config struct {
+	// PollInterval - период опроса outbox
+	PollInterval time.Duration
+	// BatchSize - максимальное число сообщений, обрабатываемых за один опрос
+	BatchSize int
+	// BackoffBase - задержка перед повторной попыткой; каждая следующая удваивается
+	BackoffBase time.Duration
+	// BackoffMax ограничивает задержку между попытками
+	BackoffMax time.Duration
+	// Lease - время, на которое выбранное сообщение скрывается от других экземпляров
+	Lease time.Duration
+}
+
+// Relay пересылает неопубликованные события из outbox во все приемники.
+// Сообщение считается опубликованным, только когда его приняли все приемники;
+// иначе оно целиком повторяется позже
+type Relay struct {
+	repo   outbox.Repository
+	sinks  []Sink
+	config Config
+	now    func() time.Time
+}
+
+// NewRelay создает новый экземпляр пересылки событий
+func NewRelay(repo outbox.Repository, config Config, sinks ...Sink) *Relay {
+	return &Relay{
+		repo:   repo,
+		sinks:  sinks,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Run опрашивает outbox до отмены контекста
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.RelayPending(ctx); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to relay outbox messages")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending публикует сообщения, время попытки которых наступило
+func (r *Relay) RelayPending(ctx context.Context) error {
+	messages, err := r.repo.ClaimPending(ctx, r.now(), r.config.Lease, r.config.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range messages {
+		if err := r.relay(ctx, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// relay публикует одно сообщение во все приемники и сохраняет результат
+func (r *Relay) relay(ctx context.Context, msg *outbox.Message) error {
+	evt := msg.Event()
+
+	var errs []error
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, evt); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		message := err.Error()
+		msg.Attempts++
+		msg.LastError = &message
+		msg.NextAttemptAt = r.now().Add(backoff.Exponential(r.config.BackoffBase, r.config.BackoffMax, msg.Attempts))
+		log.Warn().Err(err).
+			Str("event_id", msg.ID.String()).
+			Str("event_type", string(msg.EventType)).
+			Int("attempt", msg.Attempts).
+			Time("next_attempt_at", msg.NextAttemptAt).
+			Msg("Failed to relay outbox message, will retry")
+		return r.repo.ScheduleRetry(ctx, msg)
+	}
+
+	return r.repo.MarkPublished(ctx, msg.ID, r.now())
+}
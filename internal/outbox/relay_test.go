@@ -0,0 +1,240 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/outbox"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/usecase"
+)
+
+// memoryRepository - outbox в памяти
+type memoryRepository struct {
+	messages map[uuid.UUID]*outbox.Message
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{messages: map[uuid.UUID]*outbox.Message{}}
+}
+
+func (r *memoryRepository) Add(_ context.Context, msg *outbox.Message) error {
+	copied := *msg
+	r.messages[msg.ID] = &copied
+	return nil
+}
+
+func (r *memoryRepository) ClaimPending(_ context.Context, now time.Time, lease time.Duration, limit int) ([]*outbox.Message, error) {
+	var claimed []*outbox.Message
+	for _, msg := range r.messages {
+		if msg.PublishedAt != nil || msg.NextAttemptAt.After(now) {
+			continue
+		}
+		msg.NextAttemptAt = now.Add(lease)
+		copied := *msg
+		claimed = append(claimed, &copied)
+	}
+	sort.Slice(claimed, func(i, j int) bool { return claimed[i].OccurredAt.Before(claimed[j].OccurredAt) })
+	if len(claimed) > limit {
+		claimed = claimed[:limit]
+	}
+	return claimed, nil
+}
+
+func (r *memoryRepository) MarkPublished(_ context.Context, id uuid.UUID, publishedAt time.Time) error {
+	r.messages[id].PublishedAt = &publishedAt
+	return nil
+}
+
+func (r *memoryRepository) ScheduleRetry(_ context.Context, msg *outbox.Message) error {
+	copied := *msg
+	r.messages[msg.ID] = &copied
+	return nil
+}
+
+func (r *memoryRepository) Purge(_ context.Context, publishedBefore time.Time, limit int) (int, error) {
+	purged := 0
+	for id, msg := range r.messages {
+		if purged < limit && msg.PublishedAt != nil && msg.PublishedAt.Before(publishedBefore) {
+			delete(r.messages, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// recordingSink запоминает принятые события и может возвращать ошибку
+type recordingSink struct {
+	name   string
+	events []event.Event
+	err    error
+}
+
+func (s *recordingSink) Name() string {
+	return s.name
+}
+
+func (s *recordingSink) Publish(_ context.Context, evt event.Event) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, evt)
+	return nil
+}
+
+// subscriptionRepository - минимальное хранилище подписок для сквозного теста
+type subscriptionRepository struct {
+	subscription.Repository
+	err error
+}
+
+func (r subscriptionRepository) Create(_ context.Context, sub *subscription.Subscription) error {
+	sub.ID = uuid.New()
+	return r.err
+}
+
+// memoryTxManager откатывает записи в outbox, если функция вернула ошибку
+type memoryTxManager struct {
+	repo *memoryRepository
+}
+
+func (m memoryTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	snapshot := map[uuid.UUID]*outbox.Message{}
+	for id, msg := range m.repo.messages {
+		snapshot[id] = msg
+	}
+	if err := fn(ctx); err != nil {
+		m.repo.messages = snapshot
+		return err
+	}
+	return nil
+}
+
+var testConfig = Config{
+	BatchSize:   10,
+	BackoffBase: time.Minute,
+	BackoffMax:  time.Hour,
+	Lease:       time.Minute,
+}
+
+func TestRelay(t *testing.T) {
+	ctx := context.Background()
+	createReq := subscription.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       400,
+		UserID:      uuid.New(),
+		StartDate:   "07-2023",
+	}
+
+	t.Run("событие из outbox публикуется во все приемники", func(t *testing.T) {
+		repo := newMemoryRepository()
+		service := usecase.NewSubscriptionService(subscriptionRepository{},
+			usecase.WithEventPublisher(NewPublisher(repo)),
+			usecase.WithTransactionManager(memoryTxManager{repo: repo}))
+
+		sub, err := service.Create(ctx, createReq)
+		require.NoError(t, err)
+		require.Len(t, repo.messages, 1)
+
+		webhooks := &recordingSink{name: "webhook"}
+		logs := &recordingSink{name: "log"}
+		relay := NewRelay(repo, testConfig, webhooks, logs)
+		require.NoError(t, relay.RelayPending(ctx))
+
+		require.Len(t, webhooks.events, 1)
+		require.Len(t, logs.events, 1)
+		evt := webhooks.events[0]
+		assert.Equal(t, event.SubscriptionCreated, evt.Type)
+		assert.Equal(t, evt.ID, logs.events[0].ID)
+
+		var data subscription.Subscription
+		require.NoError(t, json.Unmarshal(evt.Data, &data))
+		assert.Equal(t, sub.ID, data.ID)
+
+		// Опубликованное сообщение больше не выбирается
+		assert.NotNil(t, repo.messages[evt.ID].PublishedAt)
+		require.NoError(t, relay.RelayPending(ctx))
+		assert.Len(t, webhooks.events, 1)
+	})
+
+	t.Run("ошибка сохранения подписки не оставляет событие в outbox", func(t *testing.T) {
+		repo := newMemoryRepository()
+		service := usecase.NewSubscriptionService(subscriptionRepository{err: errors.New("database error")},
+			usecase.WithEventPublisher(NewPublisher(repo)),
+			usecase.WithTransactionManager(memoryTxManager{repo: repo}))
+
+		_, err := service.Create(ctx, createReq)
+		assert.Error(t, err)
+		assert.Empty(t, repo.messages)
+	})
+
+	t.Run("сбой приемника повторяет сообщение с тем же ID", func(t *testing.T) {
+		repo := newMemoryRepository()
+		evt, err := event.New(event.SubscriptionDeleted, map[string]string{"id": uuid.New().String()})
+		require.NoError(t, err)
+		require.NoError(t, NewPublisher(repo).Publish(ctx, evt))
+
+		webhooks := &recordingSink{name: "webhook"}
+		broker := &recordingSink{name: "broker", err: errors.New("broker unavailable")}
+		relay := NewRelay(repo, testConfig, webhooks, broker)
+		now := time.Now()
+		relay.now = func() time.Time { return now }
+
+		require.NoError(t, relay.RelayPending(ctx))
+		msg := repo.messages[evt.ID]
+		assert.Nil(t, msg.PublishedAt)
+		assert.Equal(t, 1, msg.Attempts)
+		assert.Contains(t, *msg.LastError, "broker: broker unavailable")
+		assert.Equal(t, now.Add(time.Minute), msg.NextAttemptAt)
+
+		// После восстановления приемника сообщение публикуется повторно;
+		// приемник, уже принявший его, получает дубликат с тем же ID
+		broker.err = nil
+		now = now.Add(time.Minute)
+		require.NoError(t, relay.RelayPending(ctx))
+
+		assert.NotNil(t, repo.messages[evt.ID].PublishedAt)
+		require.Len(t, webhooks.events, 2)
+		assert.Equal(t, evt.ID, webhooks.events[0].ID)
+		assert.Equal(t, evt.ID, webhooks.events[1].ID)
+		require.Len(t, broker.events, 1)
+	})
+}
+
+// recordingBroker запоминает отправленные в брокер сообщения
+type recordingBroker struct {
+	subjects []string
+	messages []BrokerMessage
+}
+
+func (b *recordingBroker) Publish(_ context.Context, subject string, msg BrokerMessage) error {
+	b.subjects = append(b.subjects, subject)
+	b.messages = append(b.messages, msg)
+	return nil
+}
+
+func TestBrokerSink(t *testing.T) {
+	broker := &recordingBroker{}
+	sink := NewBrokerSink(broker, "subscriptions.")
+
+	evt, err := event.New(event.SubscriptionCreated, map[string]int{"price": 400})
+	require.NoError(t, err)
+	require.NoError(t, sink.Publish(context.Background(), evt))
+
+	require.Len(t, broker.messages, 1)
+	assert.Equal(t, "subscriptions.subscription.created", broker.subjects[0])
+	assert.Equal(t, evt.ID.String(), broker.messages[0].ID)
+
+	var published event.Event
+	require.NoError(t, json.Unmarshal(broker.messages[0].Data, &published))
+	assert.Equal(t, evt.ID, published.ID)
+	assert.JSONEq(t, `{"price":400}`, string(published.Data))
+}
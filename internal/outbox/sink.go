@@ -0,0 +1,111 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/internal/domain/event"
+)
+
+// Sink - приемник событий из outbox. Доставка выполняется по схеме «хотя бы
+// один раз»: после сбоя событие может прийти в приемник повторно с тем же ID,
+// поэтому приемники и их потребители должны отбрасывать дубликаты по evt.ID
+type Sink interface {
+	// Name возвращает имя приемника для логов
+	Name() string
+	Publish(ctx context.Context, evt event.Event) error
+}
+
+// publisherSink адаптирует event.Publisher к интерфейсу Sink
+type publisherSink struct {
+	name      string
+	publisher event.Publisher
+}
+
+// NewPublisherSink создает приемник из event.Publisher, например из сервиса
+// webhook-уведомлений
+func NewPublisherSink(name string, publisher event.Publisher) Sink {
+	return &publisherSink{name: name, publisher: publisher}
+}
+
+func (s *publisherSink) Name() string {
+	return s.name
+}
+
+func (s *publisherSink) Publish(ctx context.Context, evt event.Event) error {
+	return s.publisher.Publish(ctx, evt)
+}
+
+// LogSink записывает события в лог приложения
+type LogSink struct{}
+
+// NewLogSink создает приемник, пишущий события в лог
+func NewLogSink() *LogSink {
+	return &LogSink{}
+}
+
+// Name возвращает имя приемника
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+// Publish записывает событие в лог
+func (s *LogSink) Publish(_ context.Context, evt event.Event) error {
+	log.Info().
+		Str("event_id", evt.ID.String()).
+		Str("event_type", string(evt.Type)).
+		Time("occurred_at", evt.OccurredAt).
+		RawJSON("data", evt.Data).
+		Msg("Domain event")
+	return nil
+}
+
+// BrokerMessage - сообщение для брокера
+type BrokerMessage struct {
+	// ID - ключ дедупликации (Nats-Msg-Id в NATS JetStream, ключ сообщения в Kafka)
+	ID      string
+	Data    []byte
+	Headers map[string]string
+}
+
+// Broker - минимальный интерфейс клиента брокера сообщений, который
+// реализуется поверх NATS JetStream, Kafka и совместимых систем
+type Broker interface {
+	Publish(ctx context.Context, subject string, msg BrokerMessage) error
+}
+
+// BrokerSink публикует события в брокер сообщений. Тема сообщения - тип
+// события с префиксом, тело - событие в JSON
+type BrokerSink struct {
+	broker        Broker
+	subjectPrefix string
+}
+
+// NewBrokerSink создает приемник, публикующий события в брокер
+func NewBrokerSink(broker Broker, subjectPrefix string) *BrokerSink {
+	return &BrokerSink{broker: broker, subjectPrefix: subjectPrefix}
+}
+
+// Name возвращает имя приемника
+func (s *BrokerSink) Name() string {
+	return "broker"
+}
+
+// Publish отправляет событие в брокер
+func (s *BrokerSink) Publish(ctx context.Context, evt event.Event) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	return s.broker.Publish(ctx, s.subjectPrefix+string(evt.Type), BrokerMessage{
+		ID:   evt.ID.String(),
+		Data: data,
+		Headers: map[string]string{
+			"event-id":   evt.ID.String(),
+			"event-type": string(evt.Type),
+		},
+	})
+}
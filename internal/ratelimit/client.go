@@ -0,0 +1,27 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// IPRoute - имя квоты IP-адреса, которая проверяется до аутентификации и
+// ограничивает в том числе запросы с неверными учетными данными
+const IPRoute = "ip"
+
+// Client возвращает ключ клиента для квот: субъект аутентификации в
+// организации запроса, а до аутентификации - IP-адрес из адреса addr.
+// Ключ один для HTTP и gRPC, поэтому квота клиента общая для обоих API
+func Client(ctx context.Context, addr string) string {
+	if principal, ok := auth.FromContext(ctx); ok {
+		return tenant.FromContext(ctx).String() + "/" + principal.Subject
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return "ip:" + host
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval - период удаления полных корзин из MemoryStore
+const sweepInterval = time.Minute
+
+// bucket - корзина токенов клиента
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+// MemoryStore хранит корзины в памяти процесса. Каждый экземпляр сервиса
+// считает запросы независимо
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore создает пустое хранилище корзин в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+// Take берет токен из корзины key. Новая корзина создается полной
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Capacity()), updated: now}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+
+	var result Result
+	b.tokens, result = take(b.tokens, b.updated, limit, now)
+	if now.After(b.updated) {
+		b.updated = now
+	}
+	return result, nil
+}
+
+// sweep удаляет корзины, успевшие пополниться полностью: они не отличаются
+// от новых, а без удаления память росла бы с числом клиентов
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		missing := float64(b.limit.Capacity()) - b.tokens
+		if now.Sub(b.updated).Seconds()*b.limit.rate() >= missing {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// DefaultRoute - имя лимита, действующего на все маршруты API
+const DefaultRoute = "default"
+
+// Limit - квота корзины токенов: Requests запросов за Period с допустимым
+// всплеском до Burst запросов подряд
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	// Burst - емкость корзины; 0 означает Requests
+	Burst int
+}
+
+// Capacity возвращает емкость корзины
+func (l Limit) Capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// rate возвращает скорость пополнения корзины в токенах в секунду
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Valid проверяет, что квота задана
+func (l Limit) Valid() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// Result - результат попытки взять токен из корзины
+type Result struct {
+	Allowed bool
+	// Limit - емкость корзины
+	Limit int
+	// Remaining - число запросов, которые можно выполнить сразу
+	Remaining int
+	// Reset - время до полного пополнения корзины
+	Reset time.Duration
+	// RetryAfter - время до появления токена, если запрос отклонен
+	RetryAfter time.Duration
+}
+
+// Store хранит состояние корзин токенов. Take должен быть атомарным для
+// ключа: хранилище, общее для нескольких экземпляров сервиса (например,
+// Redis), выполняет его одной операцией на своей стороне
+type Store interface {
+	// Take берет токен из корзины key с квотой limit на момент now
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// Limiter ограничивает частоту запросов клиентов по квотам маршрутов
+type Limiter struct {
+	store  Store
+	limits map[string]Limit
+	now    func() time.Time
+}
+
+// NewLimiter создает ограничитель с квотами limits по именам маршрутов.
+// Квота DefaultRoute действует на все маршруты API, а квоты отдельных
+// маршрутов проверяются дополнительно к ней
+func NewLimiter(store Store, limits map[string]Limit) *Limiter {
+	return &Limiter{store: store, limits: limits, now: time.Now}
+}
+
+// Allow берет токен клиента client для маршрута route. ok равно false, если
+// для маршрута квота не задана и запрос не ограничивается
+func (l *Limiter) Allow(ctx context.Context, route, client string) (result Result, ok bool, err error) {
+	limit, ok := l.limits[route]
+	if !ok || !limit.Valid() {
+		return Result{}, false, nil
+	}
+	result, err = l.store.Take(ctx, route+"|"+client, limit, l.now())
+	return result, true, err
+}
+
+// take берет токен из корзины с tokens токенами, обновленной в момент
+// updated, и возвращает новое число токенов и результат
+func take(tokens float64, updated time.Time, limit Limit, now time.Time) (float64, Result) {
+	capacity := float64(limit.Capacity())
+	rate := limit.rate()
+
+	if elapsed := now.Sub(updated).Seconds(); elapsed > 0 {
+		tokens = math.Min(capacity, tokens+elapsed*rate)
+	}
+
+	result := Result{Limit: limit.Capacity()}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	result.Remaining = int(tokens)
+	result.Reset = seconds((capacity - tokens) / rate)
+	return tokens, result
+}
+
+// seconds переводит дробное число секунд в time.Duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
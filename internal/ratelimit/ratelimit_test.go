@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	ctx := context.Background()
+	limit := Limit{Requests: 60, Period: time.Minute, Burst: 3}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("всплеск до емкости корзины", func(t *testing.T) {
+		store := NewMemoryStore()
+		for remaining := 2; remaining >= 0; remaining-- {
+			result, err := store.Take(ctx, "client", limit, start)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 3, result.Limit)
+			assert.Equal(t, remaining, result.Remaining)
+		}
+
+		result, err := store.Take(ctx, "client", limit, start)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+		assert.Equal(t, time.Second, result.RetryAfter)
+		assert.Equal(t, 3*time.Second, result.Reset)
+	})
+
+	t.Run("корзина пополняется со временем", func(t *testing.T) {
+		store := NewMemoryStore()
+		for i := 0; i < 3; i++ {
+			_, err := store.Take(ctx, "client", limit, start)
+			require.NoError(t, err)
+		}
+
+		result, err := store.Take(ctx, "client", limit, start.Add(time.Second))
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+
+		// Пополнение не превышает емкость
+		result, err = store.Take(ctx, "client", limit, start.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Remaining)
+	})
+
+	t.Run("клиенты считаются раздельно", func(t *testing.T) {
+		store := NewMemoryStore()
+		for i := 0; i < 3; i++ {
+			_, err := store.Take(ctx, "first", limit, start)
+			require.NoError(t, err)
+		}
+
+		result, err := store.Take(ctx, "second", limit, start)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("полные корзины удаляются", func(t *testing.T) {
+		store := NewMemoryStore()
+		_, err := store.Take(ctx, "idle", limit, start)
+		require.NoError(t, err)
+
+		_, err = store.Take(ctx, "active", limit, start.Add(2*sweepInterval))
+		require.NoError(t, err)
+		assert.NotContains(t, store.buckets, "idle")
+		assert.Contains(t, store.buckets, "active")
+	})
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewLimiter(NewMemoryStore(), map[string]Limit{
+		DefaultRoute:     {Requests: 100, Period: time.Minute},
+		"calculate-cost": {Requests: 1, Period: time.Minute},
+	})
+
+	result, ok, err := limiter.Allow(ctx, "calculate-cost", "user:1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, result.Allowed)
+
+	result, _, err = limiter.Allow(ctx, "calculate-cost", "user:1")
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+
+	// Квоты маршрутов не расходуют друг друга
+	result, _, err = limiter.Allow(ctx, DefaultRoute, "user:1")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	_, ok, err = limiter.Allow(ctx, "export", "user:1")
+	require.NoError(t, err)
+	assert.False(t, ok, "маршрут без квоты не ограничивается")
+}
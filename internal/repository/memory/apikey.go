@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/domain/apikey"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// APIKeyRepository реализует интерфейс apikey.Repository в памяти
+type APIKeyRepository struct {
+	mu   sync.RWMutex
+	keys map[uuid.UUID]*apikey.Key
+}
+
+var _ apikey.Repository = (*APIKeyRepository)(nil)
+
+// NewAPIKeyRepository создает пустой репозиторий ключей API в памяти
+func NewAPIKeyRepository() *APIKeyRepository {
+	return &APIKeyRepository{keys: make(map[uuid.UUID]*apikey.Key)}
+}
+
+// Create сохраняет новый ключ в организации из контекста
+func (r *APIKeyRepository) Create(ctx context.Context, key *apikey.Key) error {
+	key.ID = uuid.New()
+	key.OrganizationID = tenant.FromContext(ctx)
+	key.CreatedAt = time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keys[key.ID] = cloneKey(key)
+	return nil
+}
+
+// GetByPrefix возвращает ключ по префиксу токена
+func (r *APIKeyRepository) GetByPrefix(_ context.Context, prefix string) (*apikey.Key, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.Prefix == prefix {
+			return cloneKey(key), nil
+		}
+	}
+	return nil, apikey.ErrKeyNotFound
+}
+
+// List возвращает ключи организации в порядке выпуска
+func (r *APIKeyRepository) List(ctx context.Context) ([]*apikey.Key, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*apikey.Key, 0)
+	for _, key := range r.keys {
+		if key.OrganizationID == tenant.FromContext(ctx) {
+			keys = append(keys, cloneKey(key))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return before(keys[i].CreatedAt, keys[i].ID, keys[j].CreatedAt, keys[j].ID)
+	})
+	return keys, nil
+}
+
+// Revoke отзывает ключ, сохраняя время первого отзыва
+func (r *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok || key.OrganizationID != tenant.FromContext(ctx) {
+		return apikey.ErrKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		now := time.Now()
+		key.RevokedAt = &now
+	}
+	return nil
+}
+
+// TouchLastUsed обновляет время последнего использования ключа. Более
+// позднее время, записанное параллельным запросом, не перезаписывается
+func (r *APIKeyRepository) TouchLastUsed(_ context.Context, id uuid.UUID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key, ok := r.keys[id]; ok && (key.LastUsedAt == nil || key.LastUsedAt.Before(at)) {
+		key.LastUsedAt = &at
+	}
+	return nil
+}
+
+// cloneKey возвращает копию ключа, не разделяющую с ним срезы и указатели
+func cloneKey(key *apikey.Key) *apikey.Key {
+	c := *key
+	c.Hash = append([]byte(nil), key.Hash...)
+	c.Scopes = append([]auth.Scope(nil), key.Scopes...)
+	c.LastUsedAt = cloneTime(key.LastUsedAt)
+	c.RevokedAt = cloneTime(key.RevokedAt)
+	return &c
+}
@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/domain/audit"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// auditRecord - запись журнала аудита вместе с организацией
+type auditRecord struct {
+	organizationID uuid.UUID
+	entry          audit.Entry
+}
+
+// AuditRepository реализует интерфейс audit.Repository в памяти
+type AuditRepository struct {
+	mu      sync.RWMutex
+	entries []auditRecord
+}
+
+var _ audit.Repository = (*AuditRepository)(nil)
+
+// NewAuditRepository создает пустой журнал аудита в памяти
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{}
+}
+
+// Add добавляет запись в журнал организации из контекста
+func (r *AuditRepository) Add(ctx context.Context, entry *audit.Entry) error {
+	entry.ID = uuid.New()
+	entry.CreatedAt = time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, auditRecord{organizationID: tenant.FromContext(ctx), entry: *cloneEntry(entry)})
+	return nil
+}
+
+// List возвращает записи журнала организации из контекста, удовлетворяющие
+// фильтру, начиная с последних
+func (r *AuditRepository) List(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]*audit.Entry, 0)
+	for _, record := range r.entries {
+		entry := &record.entry
+		switch {
+		case record.organizationID != tenant.FromContext(ctx),
+			filter.SubscriptionID != nil && entry.SubscriptionID != *filter.SubscriptionID,
+			filter.Actor != "" && entry.Actor != filter.Actor,
+			filter.Operation != nil && entry.Operation != *filter.Operation,
+			filter.From != nil && entry.CreatedAt.Before(*filter.From),
+			filter.To != nil && !entry.CreatedAt.Before(*filter.To):
+			continue
+		}
+		entries = append(entries, cloneEntry(entry))
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.After(entries[j].CreatedAt)
+		}
+		return before(entries[i].CreatedAt, entries[i].ID, entries[j].CreatedAt, entries[j].ID)
+	})
+	return page(entries, filter.Limit, filter.Offset), nil
+}
+
+// cloneEntry возвращает копию записи, не разделяющую с ней данные
+func cloneEntry(entry *audit.Entry) *audit.Entry {
+	c := *entry
+	c.Before = append([]byte(nil), entry.Before...)
+	c.After = append([]byte(nil), entry.After...)
+	c.Changes = make(map[string]audit.Change, len(entry.Changes))
+	for field, change := range entry.Changes {
+		c.Changes[field] = change
+	}
+	return &c
+}
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/domain/member"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// memberKey - участник в организации
+type memberKey struct {
+	organizationID uuid.UUID
+	userID         uuid.UUID
+}
+
+// MemberRepository реализует интерфейс member.Repository в памяти
+type MemberRepository struct {
+	mu      sync.RWMutex
+	members map[memberKey]member.Member
+}
+
+var _ member.Repository = (*MemberRepository)(nil)
+
+// NewMemberRepository создает пустой репозиторий участников в памяти
+func NewMemberRepository() *MemberRepository {
+	return &MemberRepository{members: make(map[memberKey]member.Member)}
+}
+
+// Get возвращает участника организации из контекста
+func (r *MemberRepository) Get(ctx context.Context, userID uuid.UUID) (*member.Member, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.members[memberKey{tenant.FromContext(ctx), userID}]
+	if !ok {
+		return nil, member.ErrMemberNotFound
+	}
+	return &m, nil
+}
+
+// List возвращает участников организации в порядке добавления
+func (r *MemberRepository) List(ctx context.Context) ([]*member.Member, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var members []*member.Member
+	for key, m := range r.members {
+		if key.organizationID == tenant.FromContext(ctx) {
+			m := m
+			members = append(members, &m)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return before(members[i].CreatedAt, members[i].UserID, members[j].CreatedAt, members[j].UserID)
+	})
+	return members, nil
+}
+
+// Save добавляет участника в организацию из контекста или меняет его роль.
+// Время добавления существующего участника сохраняется
+func (r *MemberRepository) Save(ctx context.Context, m *member.Member) error {
+	m.OrganizationID = tenant.FromContext(ctx)
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey{m.OrganizationID, m.UserID}
+	m.CreatedAt, m.UpdatedAt = now, now
+	if existing, ok := r.members[key]; ok {
+		m.CreatedAt = existing.CreatedAt
+	}
+	r.members[key] = *m
+	return nil
+}
+
+// Delete исключает участника из организации из контекста
+func (r *MemberRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey{tenant.FromContext(ctx), userID}
+	if _, ok := r.members[key]; !ok {
+		return member.ErrMemberNotFound
+	}
+	delete(r.members, key)
+	return nil
+}
@@ -0,0 +1,61 @@
+// Package memory реализует репозитории в памяти процесса для локальной
+// разработки и демо-стендов (database.driver: memory). Данные теряются при
+// остановке сервиса, а каждый экземпляр сервиса хранит свои данные
+package memory
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// before сравнивает записи так же, как ORDER BY created_at, id в PostgreSQL
+func before(at time.Time, id uuid.UUID, otherAt time.Time, otherID uuid.UUID) bool {
+	if !at.Equal(otherAt) {
+		return at.Before(otherAt)
+	}
+	return bytes.Compare(id[:], otherID[:]) < 0
+}
+
+// page применяет LIMIT и OFFSET; limit 0 - без ограничения
+func page[T any](items []T, limit, offset int) []T {
+	if limit <= 0 {
+		return items
+	}
+	if offset >= len(items) {
+		return items[:0]
+	}
+	items = items[offset:]
+	if limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// cloneTime возвращает копию времени по указателю
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+// cloneString возвращает копию строки по указателю
+func cloneString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+// cloneInt возвращает копию числа по указателю
+func cloneInt(n *int) *int {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	return &c
+}
@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/outbox"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// outboxRecord - сообщение outbox с порядковым номером журнала событий
+type outboxRecord struct {
+	seq int64
+	msg outbox.Message
+}
+
+// OutboxRepository реализует интерфейсы outbox.Repository и event.Log в памяти
+type OutboxRepository struct {
+	mu       sync.Mutex
+	messages []*outboxRecord
+	lastSeq  int64
+}
+
+var (
+	_ outbox.Repository = (*OutboxRepository)(nil)
+	_ event.Log         = (*OutboxRepository)(nil)
+)
+
+// NewOutboxRepository создает пустой outbox в памяти
+func NewOutboxRepository() *OutboxRepository {
+	return &OutboxRepository{}
+}
+
+// Add записывает сообщение в outbox и присваивает ему следующий номер журнала
+func (r *OutboxRepository) Add(_ context.Context, msg *outbox.Message) error {
+	msg.CreatedAt = time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastSeq++
+	r.messages = append(r.messages, &outboxRecord{seq: r.lastSeq, msg: *cloneMessage(msg)})
+	return nil
+}
+
+// ClaimPending выбирает неопубликованные сообщения, время попытки которых
+// наступило, в порядке возникновения событий и сдвигает их следующую попытку на lease
+func (r *OutboxRepository) ClaimPending(_ context.Context, now time.Time, lease time.Duration, limit int) ([]*outbox.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []*outboxRecord
+	for _, record := range r.messages {
+		if record.msg.PublishedAt == nil && !record.msg.NextAttemptAt.After(now) {
+			due = append(due, record)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].msg.OccurredAt.Before(due[j].msg.OccurredAt)
+	})
+
+	messages := make([]*outbox.Message, 0, len(due))
+	for _, record := range page(due, limit, 0) {
+		record.msg.NextAttemptAt = now.Add(lease)
+		messages = append(messages, cloneMessage(&record.msg))
+	}
+	return messages, nil
+}
+
+// MarkPublished отмечает сообщение опубликованным
+func (r *OutboxRepository) MarkPublished(_ context.Context, id uuid.UUID, publishedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record := r.find(id); record != nil {
+		record.msg.PublishedAt = &publishedAt
+		record.msg.LastError = nil
+	}
+	return nil
+}
+
+// ScheduleRetry сохраняет результат неудачной попытки публикации
+func (r *OutboxRepository) ScheduleRetry(_ context.Context, msg *outbox.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record := r.find(msg.ID); record != nil {
+		record.msg.Attempts = msg.Attempts
+		record.msg.NextAttemptAt = msg.NextAttemptAt
+		record.msg.LastError = cloneString(msg.LastError)
+	}
+	return nil
+}
+
+// Purge удаляет сообщения, опубликованные раньше publishedBefore. Номер
+// последнего события сохраняется, поэтому курсоры журнала не повторяются
+func (r *OutboxRepository) Purge(_ context.Context, publishedBefore time.Time, limit int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.messages[:0]
+	purged := 0
+	for _, record := range r.messages {
+		if purged < limit && record.msg.PublishedAt != nil && record.msg.PublishedAt.Before(publishedBefore) {
+			purged++
+			continue
+		}
+		kept = append(kept, record)
+	}
+	r.messages = kept
+	return purged, nil
+}
+
+// ListAfter возвращает события журнала организации из контекста с номером больше after
+func (r *OutboxRepository) ListAfter(ctx context.Context, after int64, filter event.LogFilter, limit int) ([]event.Record, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	records := make([]event.Record, 0)
+	for _, record := range r.messages {
+		if len(records) == limit {
+			break
+		}
+		if record.seq <= after || record.msg.OrganizationID != tenant.FromContext(ctx) {
+			continue
+		}
+		if filter.UserID != nil && eventUserID(record.msg.Data) != filter.UserID.String() {
+			continue
+		}
+		msg := cloneMessage(&record.msg)
+		records = append(records, event.Record{Sequence: record.seq, Event: msg.Event()})
+	}
+	return records, nil
+}
+
+// LastSequence возвращает номер последнего события журнала во всех организациях
+func (r *OutboxRepository) LastSequence(context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.lastSeq, nil
+}
+
+// find возвращает сообщение по ID или nil. Вызывается под блокировкой
+func (r *OutboxRepository) find(id uuid.UUID) *outboxRecord {
+	for _, record := range r.messages {
+		if record.msg.ID == id {
+			return record
+		}
+	}
+	return nil
+}
+
+// eventUserID возвращает поле user_id данных события, как data->>'user_id' в PostgreSQL
+func eventUserID(data json.RawMessage) string {
+	var fields struct {
+		UserID string `json:"user_id"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return ""
+	}
+	return fields.UserID
+}
+
+// cloneMessage возвращает копию сообщения, не разделяющую с ним данные
+func cloneMessage(msg *outbox.Message) *outbox.Message {
+	c := *msg
+	c.Data = append(json.RawMessage(nil), msg.Data...)
+	c.LastError = cloneString(msg.LastError)
+	c.PublishedAt = cloneTime(msg.PublishedAt)
+	return &c
+}
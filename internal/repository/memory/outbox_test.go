@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/outbox"
+	"github.com/subscription-service/internal/tenant"
+)
+
+func TestOutboxRepository(t *testing.T) {
+	repo := NewOutboxRepository()
+	ctx := context.Background()
+
+	newMessage := func(t *testing.T, organizationID, userID uuid.UUID) *outbox.Message {
+		evt, err := event.New(event.SubscriptionCreated, map[string]string{"user_id": userID.String()})
+		require.NoError(t, err)
+		evt.OrganizationID = organizationID
+		return outbox.NewMessage(evt)
+	}
+
+	t.Run("выборка очереди и публикация", func(t *testing.T) {
+		msg := newMessage(t, tenant.Default, uuid.New())
+		require.NoError(t, repo.Add(ctx, msg))
+
+		now := time.Now()
+		claimed, err := repo.ClaimPending(ctx, now, time.Minute, 10)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		assert.Equal(t, msg.ID, claimed[0].ID)
+
+		// Выбранное сообщение скрыто до окончания аренды
+		claimed, err = repo.ClaimPending(ctx, now, time.Minute, 10)
+		require.NoError(t, err)
+		assert.Empty(t, claimed)
+
+		require.NoError(t, repo.MarkPublished(ctx, msg.ID, now))
+		claimed, err = repo.ClaimPending(ctx, now.Add(time.Hour), time.Minute, 10)
+		require.NoError(t, err)
+		assert.Empty(t, claimed)
+	})
+
+	t.Run("журнал событий", func(t *testing.T) {
+		last, err := repo.LastSequence(ctx)
+		require.NoError(t, err)
+
+		organizationID, userID := uuid.New(), uuid.New()
+		var added []*outbox.Message
+		for _, m := range []struct{ organizationID, userID uuid.UUID }{
+			{organizationID, userID}, {organizationID, uuid.New()}, {uuid.New(), userID}, {organizationID, userID},
+		} {
+			msg := newMessage(t, m.organizationID, m.userID)
+			require.NoError(t, repo.Add(ctx, msg))
+			added = append(added, msg)
+		}
+		orgCtx := tenant.WithOrganization(ctx, organizationID)
+
+		// События другой организации не попадают в журнал
+		records, err := repo.ListAfter(orgCtx, last, event.LogFilter{}, 10)
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, added[0].ID, records[0].ID)
+		assert.Less(t, records[0].Sequence, records[1].Sequence)
+
+		records, err = repo.ListAfter(orgCtx, last, event.LogFilter{UserID: &userID}, 1)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, added[0].ID, records[0].ID)
+
+		records, err = repo.ListAfter(orgCtx, records[0].Sequence, event.LogFilter{UserID: &userID}, 10)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, added[3].ID, records[0].ID)
+
+		current, err := repo.LastSequence(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, last+4, current)
+	})
+
+	t.Run("очистка опубликованных сообщений", func(t *testing.T) {
+		last, err := repo.LastSequence(ctx)
+		require.NoError(t, err)
+
+		now := time.Now()
+		var messages []*outbox.Message
+		for i := 0; i < 3; i++ {
+			msg := newMessage(t, tenant.Default, uuid.New())
+			require.NoError(t, repo.Add(ctx, msg))
+			messages = append(messages, msg)
+		}
+		// Старое и недавнее опубликованные сообщения; третье не опубликовано
+		require.NoError(t, repo.MarkPublished(ctx, messages[0].ID, now.Add(-48*time.Hour)))
+		require.NoError(t, repo.MarkPublished(ctx, messages[1].ID, now))
+
+		purged, err := repo.Purge(ctx, now.Add(-24*time.Hour), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, purged)
+
+		records, err := repo.ListAfter(ctx, last, event.LogFilter{}, 10)
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, messages[1].ID, records[0].ID)
+		assert.Equal(t, messages[2].ID, records[1].ID)
+
+		// Номера журнала не переиспользуются после очистки
+		current, err := repo.LastSequence(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, last+3, current)
+	})
+}
@@ -0,0 +1,368 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// revision - состояние подписки в интервале [validFrom, validTo), как строка
+// subscription_history в PostgreSQL. Текущей ревизии соответствует validTo == nil
+type revision struct {
+	sub       subscription.Subscription
+	validFrom time.Time
+	validTo   *time.Time
+}
+
+// SubscriptionRepository реализует интерфейс subscription.Repository в памяти
+type SubscriptionRepository struct {
+	mu   sync.RWMutex
+	subs map[uuid.UUID]*subscription.Subscription
+	// history хранит ревизии каждой подписки в порядке их появления; история
+	// очищенной подписки сохраняется
+	history map[uuid.UUID][]*revision
+}
+
+var _ subscription.Repository = (*SubscriptionRepository)(nil)
+
+// NewSubscriptionRepository создает пустой репозиторий подписок в памяти
+func NewSubscriptionRepository() *SubscriptionRepository {
+	return &SubscriptionRepository{
+		subs:    make(map[uuid.UUID]*subscription.Subscription),
+		history: make(map[uuid.UUID][]*revision),
+	}
+}
+
+// Create создает новую подписку в организации из контекста
+func (r *SubscriptionRepository) Create(ctx context.Context, sub *subscription.Subscription) error {
+	now := time.Now()
+	sub.ID = uuid.New()
+	sub.OrganizationID = tenant.FromContext(ctx)
+	sub.CreatedAt = now
+	sub.UpdatedAt = now
+	sub.DeletedAt = nil
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := clone(sub)
+	r.subs[stored.ID] = stored
+	r.track(stored, now)
+	return nil
+}
+
+// Get возвращает подписку по ID
+func (r *SubscriptionRepository) Get(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sub, ok := r.subs[id]
+	if !ok || sub.OrganizationID != tenant.FromContext(ctx) || sub.DeletedAt != nil {
+		return nil, subscription.ErrSubscriptionNotFound
+	}
+	return clone(sub), nil
+}
+
+// GetDeleted возвращает удаленную подписку, еще не очищенную по сроку хранения
+func (r *SubscriptionRepository) GetDeleted(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sub, ok := r.subs[id]
+	if !ok || sub.OrganizationID != tenant.FromContext(ctx) {
+		return nil, subscription.ErrSubscriptionNotFound
+	}
+	if sub.DeletedAt == nil {
+		return nil, subscription.ErrSubscriptionNotDeleted
+	}
+	return clone(sub), nil
+}
+
+// GetAsOf возвращает ревизию подписки, действовавшую в момент asOf
+func (r *SubscriptionRepository) GetAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (*subscription.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rev := range r.history[id] {
+		if rev.sub.OrganizationID == tenant.FromContext(ctx) && rev.validAt(asOf) && rev.sub.DeletedAt == nil {
+			return clone(&rev.sub), nil
+		}
+	}
+	return nil, subscription.ErrSubscriptionNotFound
+}
+
+// Update обновляет существующую подписку. Пользователь и организация
+// подписки не меняются
+func (r *SubscriptionRepository) Update(ctx context.Context, sub *subscription.Subscription) error {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.subs[sub.ID]
+	if !ok || stored.OrganizationID != tenant.FromContext(ctx) || stored.DeletedAt != nil {
+		return subscription.ErrSubscriptionNotFound
+	}
+
+	sub.UpdatedAt = now
+	stored.ServiceName = sub.ServiceName
+	stored.Price = sub.Price
+	stored.StartDate = sub.StartDate
+	stored.EndDate = cloneTime(sub.EndDate)
+	stored.UpdatedAt = now
+	r.track(stored, now)
+	return nil
+}
+
+// Delete помечает подписку удаленной; подписка хранится до очистки
+func (r *SubscriptionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.subs[id]
+	if !ok || stored.OrganizationID != tenant.FromContext(ctx) || stored.DeletedAt != nil {
+		return subscription.ErrSubscriptionNotFound
+	}
+
+	stored.DeletedAt = &now
+	r.track(stored, now)
+	return nil
+}
+
+// Restore снимает пометку об удалении с подписки
+func (r *SubscriptionRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.subs[id]
+	if !ok || stored.OrganizationID != tenant.FromContext(ctx) {
+		return subscription.ErrSubscriptionNotFound
+	}
+	if stored.DeletedAt == nil {
+		return subscription.ErrSubscriptionNotDeleted
+	}
+
+	stored.DeletedAt = nil
+	stored.UpdatedAt = now
+	r.track(stored, now)
+	return nil
+}
+
+// Purge окончательно удаляет подписки, удаленные раньше deletedBefore, во
+// всех организациях
+func (r *SubscriptionRepository) Purge(_ context.Context, deletedBefore time.Time, limit int) (int, error) {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := 0
+	for id, sub := range r.subs {
+		if purged == limit {
+			break
+		}
+		if sub.DeletedAt == nil || !sub.DeletedAt.Before(deletedBefore) {
+			continue
+		}
+		delete(r.subs, id)
+		// Как и триггер истории в PostgreSQL, закрываем текущую ревизию
+		if current := r.current(id); current != nil {
+			current.validTo = &now
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// CountActive возвращает число неудаленных подписок, действующих в момент at,
+// во всех организациях. Используется для метрик сервиса
+func (r *SubscriptionRepository) CountActive(_ context.Context, at time.Time) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, sub := range r.subs {
+		if sub.DeletedAt == nil && !sub.StartDate.After(at) && (sub.EndDate == nil || !sub.EndDate.Before(at)) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// List возвращает список подписок, удовлетворяющих фильтру
+func (r *SubscriptionRepository) List(ctx context.Context, filter subscription.ListFilter) ([]*subscription.Subscription, error) {
+	return r.selectSubscriptions(ctx, filter), nil
+}
+
+// Stream передает в fn подписки, удовлетворяющие фильтру. Выборка копируется
+// до вызовов fn, поэтому fn может обращаться к репозиторию
+func (r *SubscriptionRepository) Stream(ctx context.Context, filter subscription.ListFilter, fn func(*subscription.Subscription) error) error {
+	for _, sub := range r.selectSubscriptions(ctx, filter) {
+		if err := fn(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CalculateTotalCost рассчитывает общую стоимость подписок по фильтру:
+// сумму цен подписок, действующих хотя бы в части периода
+func (r *SubscriptionRepository) CalculateTotalCost(ctx context.Context, filter subscription.SubscriptionFilter) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	total := 0
+	for _, sub := range r.source(ctx, filter.AsOf) {
+		if !matches(sub, filter.IncludeDeleted, filter.UserID, filter.ServiceName) {
+			continue
+		}
+		if sub.StartDate.After(filter.EndPeriod) || (sub.EndDate != nil && sub.EndDate.Before(filter.StartPeriod)) {
+			continue
+		}
+		total += sub.Price
+	}
+	return total, nil
+}
+
+// SummarizeByService возвращает сводку неудаленных подписок по каждому
+// сервису в порядке названий
+func (r *SubscriptionRepository) SummarizeByService(ctx context.Context, filter subscription.SummaryFilter) ([]*subscription.ServiceSummary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	summaries := map[string]*subscription.ServiceSummary{}
+	users := map[string]map[uuid.UUID]struct{}{}
+	for _, sub := range r.source(ctx, nil) {
+		if !matches(sub, false, filter.UserID, nil) {
+			continue
+		}
+		if !filter.StartPeriod.IsZero() && (sub.StartDate.After(filter.EndPeriod) || (sub.EndDate != nil && sub.EndDate.Before(filter.StartPeriod))) {
+			continue
+		}
+
+		summary, ok := summaries[sub.ServiceName]
+		if !ok {
+			summary = &subscription.ServiceSummary{ServiceName: sub.ServiceName, MinPrice: sub.Price, MaxPrice: sub.Price}
+			summaries[sub.ServiceName] = summary
+			users[sub.ServiceName] = map[uuid.UUID]struct{}{}
+		}
+		summary.SubscriptionCount++
+		summary.TotalCost += sub.Price
+		if sub.Price < summary.MinPrice {
+			summary.MinPrice = sub.Price
+		}
+		if sub.Price > summary.MaxPrice {
+			summary.MaxPrice = sub.Price
+		}
+		users[sub.ServiceName][sub.UserID] = struct{}{}
+	}
+
+	result := make([]*subscription.ServiceSummary, 0, len(summaries))
+	for name, summary := range summaries {
+		summary.UserCount = len(users[name])
+		result = append(result, summary)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ServiceName < result[j].ServiceName })
+	return result, nil
+}
+
+// selectSubscriptions возвращает копии подписок по фильтру в порядке создания
+func (r *SubscriptionRepository) selectSubscriptions(ctx context.Context, filter subscription.ListFilter) []*subscription.Subscription {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subs := []*subscription.Subscription{}
+	for _, sub := range r.source(ctx, filter.AsOf) {
+		if matches(sub, filter.IncludeDeleted, filter.UserID, filter.ServiceName) {
+			subs = append(subs, clone(sub))
+		}
+	}
+
+	// Стабильный порядок делает выгрузку воспроизводимой
+	sort.Slice(subs, func(i, j int) bool {
+		return before(subs[i].CreatedAt, subs[i].ID, subs[j].CreatedAt, subs[j].ID)
+	})
+	return page(subs, filter.Limit, filter.Offset)
+}
+
+// source возвращает подписки организации из контекста: текущие или, для
+// запроса на момент времени, ревизии, действовавшие в asOf. Вызывается под блокировкой
+func (r *SubscriptionRepository) source(ctx context.Context, asOf *time.Time) []*subscription.Subscription {
+	organizationID := tenant.FromContext(ctx)
+
+	var subs []*subscription.Subscription
+	if asOf == nil {
+		for _, sub := range r.subs {
+			if sub.OrganizationID == organizationID {
+				subs = append(subs, sub)
+			}
+		}
+		return subs
+	}
+
+	for _, revisions := range r.history {
+		for _, rev := range revisions {
+			if rev.sub.OrganizationID == organizationID && rev.validAt(*asOf) {
+				subs = append(subs, &rev.sub)
+			}
+		}
+	}
+	return subs
+}
+
+// track закрывает текущую ревизию подписки и добавляет ревизию с ее новым
+// состоянием. Вызывается под блокировкой
+func (r *SubscriptionRepository) track(sub *subscription.Subscription, now time.Time) {
+	if current := r.current(sub.ID); current != nil {
+		current.validTo = &now
+	}
+	r.history[sub.ID] = append(r.history[sub.ID], &revision{sub: *clone(sub), validFrom: now})
+}
+
+// current возвращает текущую ревизию подписки или nil
+func (r *SubscriptionRepository) current(id uuid.UUID) *revision {
+	revisions := r.history[id]
+	if len(revisions) == 0 {
+		return nil
+	}
+	if last := revisions[len(revisions)-1]; last.validTo == nil {
+		return last
+	}
+	return nil
+}
+
+// validAt проверяет, действовала ли ревизия в момент t
+func (rev *revision) validAt(t time.Time) bool {
+	return !rev.validFrom.After(t) && (rev.validTo == nil || rev.validTo.After(t))
+}
+
+// matches проверяет подписку по общим условиям выборки и расчета стоимости
+func matches(sub *subscription.Subscription, includeDeleted bool, userID *uuid.UUID, serviceName *string) bool {
+	if !includeDeleted && sub.DeletedAt != nil {
+		return false
+	}
+	if userID != nil && sub.UserID != *userID {
+		return false
+	}
+	if serviceName != nil && *serviceName != "" && sub.ServiceName != *serviceName {
+		return false
+	}
+	return true
+}
+
+// clone возвращает копию подписки, не разделяющую с ней указатели
+func clone(sub *subscription.Subscription) *subscription.Subscription {
+	c := *sub
+	c.EndDate = cloneTime(sub.EndDate)
+	c.DeletedAt = cloneTime(sub.DeletedAt)
+	return &c
+}
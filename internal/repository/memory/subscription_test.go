@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/repository/repotest"
+)
+
+func TestSubscriptionRepository(t *testing.T) {
+	repotest.RunSubscriptionRepository(t, func(*testing.T) subscription.Repository {
+		return NewSubscriptionRepository()
+	})
+}
@@ -0,0 +1,26 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/subscription-service/internal/domain/transaction"
+)
+
+// TxManager реализует интерфейс transaction.Manager для репозиториев в
+// памяти. Каждая операция репозитория атомарна сама по себе, а fn
+// выполняется без общей транзакции: изменения, сделанные до ошибки fn, не
+// откатываются. Репозитории в памяти не отказывают при записи, поэтому
+// изменение подписки, событие outbox и запись журнала аудита сохраняются вместе
+type TxManager struct{}
+
+var _ transaction.Manager = TxManager{}
+
+// NewTxManager создает менеджер транзакций для репозиториев в памяти
+func NewTxManager() TxManager {
+	return TxManager{}
+}
+
+// WithinTransaction выполняет fn
+func (TxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
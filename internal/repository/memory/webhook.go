@@ -0,0 +1,244 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/webhook"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// WebhookRepository реализует интерфейс webhook.Repository в памяти
+type WebhookRepository struct {
+	mu         sync.RWMutex
+	endpoints  map[uuid.UUID]*webhook.Endpoint
+	deliveries map[uuid.UUID]*webhook.Delivery
+}
+
+var _ webhook.Repository = (*WebhookRepository)(nil)
+
+// NewWebhookRepository создает пустой репозиторий webhook-уведомлений в памяти
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{
+		endpoints:  make(map[uuid.UUID]*webhook.Endpoint),
+		deliveries: make(map[uuid.UUID]*webhook.Delivery),
+	}
+}
+
+// CreateEndpoint создает нового получателя в организации из контекста
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *webhook.Endpoint) error {
+	endpoint.ID = uuid.New()
+	endpoint.OrganizationID = tenant.FromContext(ctx)
+	endpoint.CreatedAt = time.Now()
+	endpoint.UpdatedAt = endpoint.CreatedAt
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.endpoints[endpoint.ID] = cloneEndpoint(endpoint)
+	return nil
+}
+
+// GetEndpoint возвращает получателя по ID
+func (r *WebhookRepository) GetEndpoint(ctx context.Context, id uuid.UUID) (*webhook.Endpoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	endpoint, ok := r.endpoints[id]
+	if !ok || endpoint.OrganizationID != tenant.FromContext(ctx) {
+		return nil, webhook.ErrEndpointNotFound
+	}
+	return cloneEndpoint(endpoint), nil
+}
+
+// ListEndpoints возвращает всех получателей организации
+func (r *WebhookRepository) ListEndpoints(ctx context.Context) ([]*webhook.Endpoint, error) {
+	return r.selectEndpoints(ctx, func(*webhook.Endpoint) bool { return true }), nil
+}
+
+// ListEndpointsForEvent возвращает получателей, подписанных на событие данного типа.
+// Получатели с пустым списком событий подписаны на все события
+func (r *WebhookRepository) ListEndpointsForEvent(ctx context.Context, eventType event.Type) ([]*webhook.Endpoint, error) {
+	return r.selectEndpoints(ctx, func(endpoint *webhook.Endpoint) bool { return endpoint.Accepts(eventType) }), nil
+}
+
+// selectEndpoints возвращает получателей организации из контекста, для
+// которых accept возвращает true, в порядке регистрации
+func (r *WebhookRepository) selectEndpoints(ctx context.Context, accept func(*webhook.Endpoint) bool) []*webhook.Endpoint {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	endpoints := make([]*webhook.Endpoint, 0)
+	for _, endpoint := range r.endpoints {
+		if endpoint.OrganizationID == tenant.FromContext(ctx) && accept(endpoint) {
+			endpoints = append(endpoints, cloneEndpoint(endpoint))
+		}
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		return before(endpoints[i].CreatedAt, endpoints[i].ID, endpoints[j].CreatedAt, endpoints[j].ID)
+	})
+	return endpoints
+}
+
+// DeleteEndpoint удаляет получателя вместе с журналом его доставок
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	endpoint, ok := r.endpoints[id]
+	if !ok || endpoint.OrganizationID != tenant.FromContext(ctx) {
+		return webhook.ErrEndpointNotFound
+	}
+	delete(r.endpoints, id)
+	for deliveryID, delivery := range r.deliveries {
+		if delivery.EndpointID == id {
+			delete(r.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+// CreateDelivery добавляет доставку в журнал организации из контекста.
+// Повторная первая доставка события тому же получателю не добавляется
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *webhook.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Как и внешний ключ в PostgreSQL, доставка требует существующего получателя
+	if _, ok := r.endpoints[delivery.EndpointID]; !ok {
+		return fmt.Errorf("failed to create webhook delivery: %w", webhook.ErrEndpointNotFound)
+	}
+	// Как и уникальный индекс в PostgreSQL, событие доставляется получателю
+	// один раз, не считая ручных повторов
+	if delivery.RedeliveryOf == nil {
+		for _, stored := range r.deliveries {
+			if stored.EndpointID == delivery.EndpointID && stored.EventID == delivery.EventID && stored.RedeliveryOf == nil {
+				return nil
+			}
+		}
+	}
+
+	delivery.ID = uuid.New()
+	delivery.OrganizationID = tenant.FromContext(ctx)
+	delivery.CreatedAt = time.Now()
+	delivery.UpdatedAt = delivery.CreatedAt
+	r.deliveries[delivery.ID] = cloneDelivery(delivery)
+	return nil
+}
+
+// GetDelivery возвращает доставку по ID
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id uuid.UUID) (*webhook.Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	delivery, ok := r.deliveries[id]
+	if !ok || delivery.OrganizationID != tenant.FromContext(ctx) {
+		return nil, webhook.ErrDeliveryNotFound
+	}
+	return cloneDelivery(delivery), nil
+}
+
+// UpdateDelivery сохраняет результат попытки доставки; результат выбранной
+// доставки - только пока ее аренду не перехватил другой обработчик
+func (r *WebhookRepository) UpdateDelivery(_ context.Context, delivery *webhook.Delivery) error {
+	delivery.UpdatedAt = time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.deliveries[delivery.ID]
+	if !ok {
+		return webhook.ErrDeliveryNotFound
+	}
+	if delivery.ClaimedUntil != nil && (stored.NextAttemptAt == nil || !stored.NextAttemptAt.Equal(*delivery.ClaimedUntil)) {
+		return webhook.ErrLeaseLost
+	}
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.NextAttemptAt = cloneTime(delivery.NextAttemptAt)
+	stored.LastStatusCode = cloneInt(delivery.LastStatusCode)
+	stored.LastError = cloneString(delivery.LastError)
+	stored.DeliveredAt = cloneTime(delivery.DeliveredAt)
+	stored.UpdatedAt = delivery.UpdatedAt
+	return nil
+}
+
+// ListDeliveries возвращает журнал доставок получателя, начиная с последних
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, endpointID uuid.UUID, filter webhook.DeliveryFilter) ([]*webhook.Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := []*webhook.Delivery{}
+	for _, delivery := range r.deliveries {
+		if delivery.EndpointID != endpointID || delivery.OrganizationID != tenant.FromContext(ctx) {
+			continue
+		}
+		if filter.Status != nil && delivery.Status != *filter.Status {
+			continue
+		}
+		deliveries = append(deliveries, cloneDelivery(delivery))
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].CreatedAt.Equal(deliveries[j].CreatedAt) {
+			return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+		}
+		return before(deliveries[i].CreatedAt, deliveries[i].ID, deliveries[j].CreatedAt, deliveries[j].ID)
+	})
+	return page(deliveries, filter.Limit, filter.Offset), nil
+}
+
+// ClaimDueDeliveries выбирает доставки, время попытки которых наступило, и
+// сдвигает их следующую попытку на lease
+func (r *WebhookRepository) ClaimDueDeliveries(_ context.Context, now time.Time, lease time.Duration, limit int) ([]*webhook.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []*webhook.Delivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status == webhook.DeliveryPending && delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(*due[j].NextAttemptAt)
+	})
+
+	deliveries := make([]*webhook.Delivery, 0, len(due))
+	next := now.Add(lease)
+	for _, delivery := range page(due, limit, 0) {
+		delivery.NextAttemptAt = &next
+		delivery.UpdatedAt = now
+		claimed := cloneDelivery(delivery)
+		claimed.ClaimedUntil = cloneTime(&next)
+		deliveries = append(deliveries, claimed)
+	}
+	return deliveries, nil
+}
+
+// cloneEndpoint возвращает копию получателя, не разделяющую с ним список событий
+func cloneEndpoint(endpoint *webhook.Endpoint) *webhook.Endpoint {
+	c := *endpoint
+	c.Events = append([]event.Type{}, endpoint.Events...)
+	return &c
+}
+
+// cloneDelivery возвращает копию доставки, не разделяющую с ней данные
+func cloneDelivery(delivery *webhook.Delivery) *webhook.Delivery {
+	c := *delivery
+	c.Payload = append(json.RawMessage(nil), delivery.Payload...)
+	if delivery.RedeliveryOf != nil {
+		id := *delivery.RedeliveryOf
+		c.RedeliveryOf = &id
+	}
+	c.NextAttemptAt = cloneTime(delivery.NextAttemptAt)
+	c.LastStatusCode = cloneInt(delivery.LastStatusCode)
+	c.LastError = cloneString(delivery.LastError)
+	c.DeliveredAt = cloneTime(delivery.DeliveredAt)
+	return &c
+}
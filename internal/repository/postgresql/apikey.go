@@ -0,0 +1,168 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/domain/apikey"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// apiKeyColumns - столбцы таблицы api_keys в порядке полей apiKeyRow
+const apiKeyColumns = `id, organization_id, name, prefix, hash, scopes, created_at, last_used_at, revoked_at`
+
+// APIKeyRepository реализует интерфейс apikey.Repository
+type APIKeyRepository struct {
+	db    *sqlx.DB
+	timer queryTimer
+}
+
+// NewAPIKeyRepository создает новый экземпляр репозитория ключей API
+func NewAPIKeyRepository(db *sqlx.DB, opts ...Option) *APIKeyRepository {
+	return &APIKeyRepository{db: db, timer: newQueryTimer("api_keys", opts)}
+}
+
+// apiKeyRow - строка таблицы api_keys
+type apiKeyRow struct {
+	ID             uuid.UUID      `db:"id"`
+	OrganizationID uuid.UUID      `db:"organization_id"`
+	Name           string         `db:"name"`
+	Prefix         string         `db:"prefix"`
+	Hash           []byte         `db:"hash"`
+	Scopes         pq.StringArray `db:"scopes"`
+	CreatedAt      time.Time      `db:"created_at"`
+	LastUsedAt     *time.Time     `db:"last_used_at"`
+	RevokedAt      *time.Time     `db:"revoked_at"`
+}
+
+// toKey преобразует строку таблицы в доменную модель
+func (row apiKeyRow) toKey() *apikey.Key {
+	scopes := make([]auth.Scope, 0, len(row.Scopes))
+	for _, name := range row.Scopes {
+		scopes = append(scopes, auth.Scope(name))
+	}
+	return &apikey.Key{
+		ID:             row.ID,
+		OrganizationID: row.OrganizationID,
+		Name:           row.Name,
+		Prefix:         row.Prefix,
+		Hash:           row.Hash,
+		Scopes:         scopes,
+		CreatedAt:      row.CreatedAt,
+		LastUsedAt:     row.LastUsedAt,
+		RevokedAt:      row.RevokedAt,
+	}
+}
+
+// Create сохраняет новый ключ в организации из контекста
+func (r *APIKeyRepository) Create(ctx context.Context, key *apikey.Key) error {
+	defer r.timer.observe("Create", time.Now())
+
+	query := `INSERT INTO api_keys (id, organization_id, name, prefix, hash, scopes, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	key.ID = uuid.New()
+	key.OrganizationID = tenant.FromContext(ctx)
+	key.CreatedAt = time.Now()
+
+	scopes := make(pq.StringArray, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	_, err := executorFrom(ctx, r.db).ExecContext(ctx, query,
+		key.ID,
+		key.OrganizationID,
+		key.Name,
+		key.Prefix,
+		key.Hash,
+		scopes,
+		key.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return nil
+}
+
+// GetByPrefix возвращает ключ по префиксу токена
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*apikey.Key, error) {
+	defer r.timer.observe("GetByPrefix", time.Now())
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE prefix = $1`
+
+	var row apiKeyRow
+	if err := executorFrom(ctx, r.db).GetContext(ctx, &row, query, prefix); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apikey.ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return row.toKey(), nil
+}
+
+// List возвращает ключи организации в порядке выпуска
+func (r *APIKeyRepository) List(ctx context.Context) ([]*apikey.Key, error) {
+	defer r.timer.observe("List", time.Now())
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE organization_id = $1 ORDER BY created_at, id`
+
+	var rows []apiKeyRow
+	if err := executorFrom(ctx, r.db).SelectContext(ctx, &rows, query, tenant.FromContext(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	keys := make([]*apikey.Key, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, row.toKey())
+	}
+	return keys, nil
+}
+
+// Revoke отзывает ключ, сохраняя время первого отзыва
+func (r *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	defer r.timer.observe("Revoke", time.Now())
+
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $1)
+			WHERE id = $2 AND organization_id = $3`
+
+	result, err := executorFrom(ctx, r.db).ExecContext(ctx, query, time.Now(), id, tenant.FromContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return apikey.ErrKeyNotFound
+	}
+
+	return nil
+}
+
+// TouchLastUsed обновляет время последнего использования ключа. Более
+// позднее время, записанное параллельным запросом, не перезаписывается
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	defer r.timer.observe("TouchLastUsed", time.Now())
+
+	query := `UPDATE api_keys SET last_used_at = $1
+			WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $1)`
+
+	if _, err := executorFrom(ctx, r.db).ExecContext(ctx, query, at, id); err != nil {
+		return fmt.Errorf("failed to update api key last used time: %w", err)
+	}
+
+	return nil
+}
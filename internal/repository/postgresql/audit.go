@@ -0,0 +1,164 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/subscription-service/internal/domain/audit"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// AuditRepository реализует интерфейс audit.Repository
+type AuditRepository struct {
+	db    *sqlx.DB
+	timer queryTimer
+}
+
+// NewAuditRepository создает новый экземпляр репозитория журнала аудита
+func NewAuditRepository(db *sqlx.DB, opts ...Option) *AuditRepository {
+	return &AuditRepository{db: db, timer: newQueryTimer("audit", opts)}
+}
+
+// auditRow - строка таблицы subscription_audit
+type auditRow struct {
+	ID             uuid.UUID `db:"id"`
+	SubscriptionID uuid.UUID `db:"subscription_id"`
+	Operation      string    `db:"operation"`
+	Actor          string    `db:"actor"`
+	RequestID      string    `db:"request_id"`
+	Before         []byte    `db:"before"`
+	After          []byte    `db:"after"`
+	Changes        []byte    `db:"changes"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+// toEntry преобразует строку таблицы в доменную модель
+func (row auditRow) toEntry() (*audit.Entry, error) {
+	entry := &audit.Entry{
+		ID:             row.ID,
+		SubscriptionID: row.SubscriptionID,
+		Operation:      audit.Operation(row.Operation),
+		Actor:          row.Actor,
+		RequestID:      row.RequestID,
+		Before:         row.Before,
+		After:          row.After,
+		CreatedAt:      row.CreatedAt,
+	}
+	if err := json.Unmarshal(row.Changes, &entry.Changes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal audit changes: %w", err)
+	}
+	return entry, nil
+}
+
+// Add добавляет запись в журнал организации из контекста в транзакции из
+// контекста, если она есть
+func (r *AuditRepository) Add(ctx context.Context, entry *audit.Entry) error {
+	defer r.timer.observe("Add", time.Now())
+
+	query := `INSERT INTO subscription_audit
+			(id, organization_id, subscription_id, operation, actor, request_id, before, after, changes, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	entry.ID = uuid.New()
+	entry.CreatedAt = time.Now()
+
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit changes: %w", err)
+	}
+
+	_, err = executorFrom(ctx, r.db).ExecContext(ctx, query,
+		entry.ID,
+		tenant.FromContext(ctx),
+		entry.SubscriptionID,
+		entry.Operation,
+		entry.Actor,
+		entry.RequestID,
+		nullableJSON(entry.Before),
+		nullableJSON(entry.After),
+		string(changes),
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// List возвращает записи журнала организации из контекста, удовлетворяющие
+// фильтру, начиная с последних
+func (r *AuditRepository) List(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	defer r.timer.observe("List", time.Now())
+
+	query := `SELECT id, subscription_id, operation, actor, request_id, before, after, changes, created_at
+			FROM subscription_audit WHERE organization_id = :organization_id`
+	params := map[string]interface{}{"organization_id": tenant.FromContext(ctx)}
+
+	if filter.SubscriptionID != nil {
+		query += " AND subscription_id = :subscription_id"
+		params["subscription_id"] = *filter.SubscriptionID
+	}
+
+	if filter.Actor != "" {
+		query += " AND actor = :actor"
+		params["actor"] = filter.Actor
+	}
+
+	if filter.Operation != nil {
+		query += " AND operation = :operation"
+		params["operation"] = *filter.Operation
+	}
+
+	if filter.From != nil {
+		query += " AND created_at >= :from"
+		params["from"] = *filter.From
+	}
+
+	if filter.To != nil {
+		query += " AND created_at < :to"
+		params["to"] = *filter.To
+	}
+
+	query += " ORDER BY created_at DESC, id"
+
+	if filter.Limit > 0 {
+		query += " LIMIT :limit OFFSET :offset"
+		params["limit"] = filter.Limit
+		params["offset"] = filter.Offset
+	}
+
+	nstmt, err := executorFrom(ctx, r.db).PrepareNamedContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare named statement: %w", err)
+	}
+	defer nstmt.Close()
+
+	var rows []auditRow
+	if err := nstmt.SelectContext(ctx, &rows, params); err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+
+	entries := make([]*audit.Entry, 0, len(rows))
+	for _, row := range rows {
+		entry, err := row.toEntry()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// nullableJSON возвращает NULL для пустого значения JSON
+func nullableJSON(data json.RawMessage) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
@@ -0,0 +1,55 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/internal/backoff"
+)
+
+// ConnectConfig хранит настройки подключения к базе при запуске
+type ConnectConfig struct {
+	DSN string
+	// Timeout ограничивает одну попытку подключения
+	Timeout time.Duration
+	// Retry - политика повторов, пока база недоступна
+	Retry backoff.Policy
+	// Dialer устанавливает сетевые соединения драйвера; nil - обычный TCP.
+	// Подменяется в тестах
+	Dialer pq.Dialer
+}
+
+// Connect открывает пул соединений и проверяет доступность базы, повторяя
+// попытки с экспоненциальной задержкой, например пока база еще запускается
+func Connect(ctx context.Context, config ConnectConfig) (*sqlx.DB, error) {
+	connector, err := pq.NewConnector(config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database DSN: %w", err)
+	}
+	if config.Dialer != nil {
+		connector.Dialer(config.Dialer)
+	}
+	db := sqlx.NewDb(sql.OpenDB(connector), "postgres")
+
+	err = backoff.Retry(ctx, config.Retry, func(ctx context.Context) error {
+		if config.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, config.Timeout)
+			defer cancel()
+		}
+		return db.PingContext(ctx)
+	}, func(attempt int, err error, delay time.Duration) {
+		log.Warn().Err(err).Int("attempt", attempt).Dur("retry_in", delay).Msg("Database is not available, retrying")
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return db, nil
+}
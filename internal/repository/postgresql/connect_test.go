@@ -0,0 +1,122 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/backoff"
+)
+
+// fakeDialer - сетевой уровень драйвера: первые failures соединений
+// отклоняются, как будто база еще не запущена, а остальные обслуживает
+// serveFakePostgres
+type fakeDialer struct {
+	failures int32
+	dials    atomic.Int32
+}
+
+func (d *fakeDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+func (d *fakeDialer) DialTimeout(network, address string, _ time.Duration) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+func (d *fakeDialer) DialContext(_ context.Context, _, _ string) (net.Conn, error) {
+	if d.dials.Add(1) <= d.failures {
+		return nil, errors.New("connection refused")
+	}
+	client, server := net.Pipe()
+	go serveFakePostgres(server)
+	return client, nil
+}
+
+// serveFakePostgres отвечает по минимальному подмножеству протокола
+// PostgreSQL: принимает подключение без пароля, а на любой простой запрос
+// возвращает пустой результат. Этого достаточно для проверки соединения
+func serveFakePostgres(conn net.Conn) {
+	defer conn.Close()
+
+	// Стартовое сообщение не имеет типа: длина и параметры подключения
+	var length uint32
+	if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+		return
+	}
+	if _, err := io.CopyN(io.Discard, conn, int64(length)-4); err != nil {
+		return
+	}
+	writeFakeMessage(conn, 'R', []byte{0, 0, 0, 0}) // AuthenticationOk
+	writeFakeMessage(conn, 'Z', []byte{'I'})        // ReadyForQuery
+
+	for {
+		var header [5]byte
+		if _, err := io.ReadFull(conn, header[:]); err != nil {
+			return
+		}
+		if _, err := io.CopyN(io.Discard, conn, int64(binary.BigEndian.Uint32(header[1:]))-4); err != nil {
+			return
+		}
+		switch header[0] {
+		case 'Q':
+			writeFakeMessage(conn, 'I', nil)         // EmptyQueryResponse
+			writeFakeMessage(conn, 'Z', []byte{'I'}) // ReadyForQuery
+		case 'X':
+			return
+		}
+	}
+}
+
+// writeFakeMessage пишет сообщение протокола: тип, длина и тело
+func writeFakeMessage(w io.Writer, typ byte, body []byte) {
+	msg := binary.BigEndian.AppendUint32([]byte{typ}, uint32(len(body)+4))
+	_, _ = w.Write(append(msg, body...))
+}
+
+func TestConnect(t *testing.T) {
+	ctx := context.Background()
+	config := func(dialer *fakeDialer) ConnectConfig {
+		return ConnectConfig{
+			DSN:     "host=postgres port=5432 user=postgres dbname=subscription_service sslmode=disable",
+			Timeout: time.Second,
+			Retry:   backoff.Policy{Attempts: 4, Base: time.Millisecond, Max: 5 * time.Millisecond},
+			Dialer:  dialer,
+		}
+	}
+
+	t.Run("подключение после запуска базы", func(t *testing.T) {
+		dialer := &fakeDialer{failures: 2}
+		db, err := Connect(ctx, config(dialer))
+		require.NoError(t, err)
+		defer db.Close()
+
+		assert.Equal(t, int32(3), dialer.dials.Load())
+		assert.NoError(t, db.PingContext(ctx))
+	})
+
+	t.Run("база так и не стала доступна", func(t *testing.T) {
+		dialer := &fakeDialer{failures: 100}
+		_, err := Connect(ctx, config(dialer))
+
+		assert.ErrorContains(t, err, "connection refused")
+		assert.Equal(t, int32(4), dialer.dials.Load())
+	})
+
+	t.Run("некорректный DSN не повторяется", func(t *testing.T) {
+		dialer := &fakeDialer{}
+		cfg := config(dialer)
+		cfg.DSN = "host='unterminated"
+		_, err := Connect(ctx, cfg)
+
+		assert.ErrorContains(t, err, "invalid database DSN")
+		assert.Equal(t, int32(0), dialer.dials.Load())
+	})
+}
@@ -0,0 +1,107 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/subscription-service/internal/domain/member"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// memberColumns - столбцы таблицы organization_members
+const memberColumns = `organization_id, user_id, role, created_at, updated_at`
+
+// MemberRepository реализует интерфейс member.Repository
+type MemberRepository struct {
+	db    *sqlx.DB
+	timer queryTimer
+}
+
+// NewMemberRepository создает новый экземпляр репозитория участников
+func NewMemberRepository(db *sqlx.DB, opts ...Option) *MemberRepository {
+	return &MemberRepository{db: db, timer: newQueryTimer("members", opts)}
+}
+
+// Get возвращает участника организации из контекста
+func (r *MemberRepository) Get(ctx context.Context, userID uuid.UUID) (*member.Member, error) {
+	defer r.timer.observe("Get", time.Now())
+
+	query := `SELECT ` + memberColumns + ` FROM organization_members
+			WHERE organization_id = $1 AND user_id = $2`
+
+	var m member.Member
+	if err := executorFrom(ctx, r.db).GetContext(ctx, &m, query, tenant.FromContext(ctx), userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, member.ErrMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+
+	return &m, nil
+}
+
+// List возвращает участников организации в порядке добавления
+func (r *MemberRepository) List(ctx context.Context) ([]*member.Member, error) {
+	defer r.timer.observe("List", time.Now())
+
+	query := `SELECT ` + memberColumns + ` FROM organization_members
+			WHERE organization_id = $1 ORDER BY created_at, user_id`
+
+	var members []*member.Member
+	if err := executorFrom(ctx, r.db).SelectContext(ctx, &members, query, tenant.FromContext(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+
+	return members, nil
+}
+
+// Save добавляет участника в организацию из контекста или меняет его роль.
+// Время добавления существующего участника сохраняется
+func (r *MemberRepository) Save(ctx context.Context, m *member.Member) error {
+	defer r.timer.observe("Save", time.Now())
+
+	query := `INSERT INTO organization_members (` + memberColumns + `)
+			VALUES ($1, $2, $3, $4, $4)
+			ON CONFLICT (organization_id, user_id) DO UPDATE
+			SET role = EXCLUDED.role, updated_at = EXCLUDED.updated_at
+			RETURNING created_at, updated_at`
+
+	m.OrganizationID = tenant.FromContext(ctx)
+	now := time.Now()
+
+	err := executorFrom(ctx, r.db).QueryRowxContext(ctx, query, m.OrganizationID, m.UserID, m.Role, now).
+		Scan(&m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save member: %w", err)
+	}
+
+	return nil
+}
+
+// Delete исключает участника из организации из контекста
+func (r *MemberRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	defer r.timer.observe("Delete", time.Now())
+
+	query := `DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2`
+
+	result, err := executorFrom(ctx, r.db).ExecContext(ctx, query, tenant.FromContext(ctx), userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete member: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return member.ErrMemberNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/domain/member"
+	"github.com/subscription-service/internal/tenant"
+)
+
+func TestMemberRepository(t *testing.T) {
+	db, cleanup := setupTestDatabase(t)
+	defer cleanup()
+
+	repo := NewMemberRepository(db)
+	ctx := tenant.WithOrganization(context.Background(), uuid.New())
+	userID := uuid.New()
+
+	// Тест добавления и смены роли
+	t.Run("Save", func(t *testing.T) {
+		added := &member.Member{UserID: userID, Role: member.RoleViewer}
+		require.NoError(t, repo.Save(ctx, added))
+		assert.Equal(t, tenant.FromContext(ctx), added.OrganizationID)
+
+		changed := &member.Member{UserID: userID, Role: member.RoleAdmin}
+		require.NoError(t, repo.Save(ctx, changed))
+		assert.True(t, added.CreatedAt.Equal(changed.CreatedAt), "время добавления сохраняется")
+
+		fetched, err := repo.Get(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, member.RoleAdmin, fetched.Role)
+
+		members, err := repo.List(ctx)
+		require.NoError(t, err)
+		assert.Len(t, members, 1)
+	})
+
+	// Тест изоляции организаций
+	t.Run("другая организация", func(t *testing.T) {
+		other := tenant.WithOrganization(context.Background(), uuid.New())
+
+		_, err := repo.Get(other, userID)
+		assert.ErrorIs(t, err, member.ErrMemberNotFound)
+		assert.ErrorIs(t, repo.Delete(other, userID), member.ErrMemberNotFound)
+
+		members, err := repo.List(other)
+		require.NoError(t, err)
+		assert.Empty(t, members)
+	})
+
+	// Тест исключения участника
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, repo.Delete(ctx, userID))
+
+		_, err := repo.Get(ctx, userID)
+		assert.ErrorIs(t, err, member.ErrMemberNotFound)
+		assert.ErrorIs(t, repo.Delete(ctx, userID), member.ErrMemberNotFound)
+	})
+}
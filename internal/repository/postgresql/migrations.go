@@ -0,0 +1,31 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// SchemaVersion возвращает версию миграций, примененных к базе, из таблицы
+// table golang-migrate и признак миграции, прерванной на середине. Для базы
+// без миграций возвращает 0
+func SchemaVersion(ctx context.Context, db *sqlx.DB, table string) (uint, bool, error) {
+	query := `SELECT version, dirty FROM ` + pq.QuoteIdentifier(table) + ` LIMIT 1`
+
+	var row struct {
+		Version int64 `db:"version"`
+		Dirty   bool  `db:"dirty"`
+	}
+	if err := db.GetContext(ctx, &row, query); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to get schema version: %w", err)
+	}
+
+	return uint(row.Version), row.Dirty, nil
+}
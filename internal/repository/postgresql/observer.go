@@ -0,0 +1,43 @@
+package postgresql
+
+import "time"
+
+// QueryObserver получает длительность вызовов методов репозиториев, например
+// для метрик. Длительность включает все запросы метода к базе
+type QueryObserver interface {
+	ObserveQuery(repository, method string, duration time.Duration)
+}
+
+// Option настраивает репозиторий
+type Option func(*queryTimer)
+
+// WithQueryObserver передает длительность вызовов методов репозитория в observer
+func WithQueryObserver(observer QueryObserver) Option {
+	return func(t *queryTimer) {
+		t.observer = observer
+	}
+}
+
+// queryTimer замеряет длительность методов репозитория
+type queryTimer struct {
+	repository string
+	observer   QueryObserver
+}
+
+// newQueryTimer создает таймер методов репозитория с именем repository
+func newQueryTimer(repository string, opts []Option) queryTimer {
+	t := queryTimer{repository: repository}
+	for _, opt := range opts {
+		opt(&t)
+	}
+	return t
+}
+
+// observe сообщает длительность метода, начатого в start. Вызывается через
+// defer в начале метода: defer r.timer.observe("Get", time.Now())
+func (t queryTimer) observe(method string, start time.Time) {
+	if t.observer == nil {
+		return
+	}
+	t.observer.ObserveQuery(t.repository, method, time.Since(start))
+}
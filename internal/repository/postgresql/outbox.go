@@ -0,0 +1,195 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/outbox"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// outboxColumns - столбцы outbox в порядке полей outbox.Message
+const outboxColumns = `id, event_type, organization_id, data, occurred_at, attempts, next_attempt_at,
+			last_error, published_at, created_at`
+
+// OutboxRepository реализует интерфейс outbox.Repository
+type OutboxRepository struct {
+	db    *sqlx.DB
+	timer queryTimer
+}
+
+// NewOutboxRepository создает новый экземпляр репозитория outbox
+func NewOutboxRepository(db *sqlx.DB, opts ...Option) *OutboxRepository {
+	return &OutboxRepository{db: db, timer: newQueryTimer("outbox", opts)}
+}
+
+// Add записывает сообщение в outbox в транзакции из контекста, если она есть
+func (r *OutboxRepository) Add(ctx context.Context, msg *outbox.Message) error {
+	defer r.timer.observe("Add", time.Now())
+
+	query := `INSERT INTO outbox (` + outboxColumns + `)
+			VALUES (:id, :event_type, :organization_id, :data, :occurred_at, :attempts, :next_attempt_at,
+			:last_error, :published_at, :created_at)`
+
+	msg.CreatedAt = time.Now()
+
+	if _, err := executorFrom(ctx, r.db).NamedExecContext(ctx, query, msg); err != nil {
+		return fmt.Errorf("failed to add outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimPending выбирает неопубликованные сообщения, время попытки которых
+// наступило, в порядке возникновения событий и сдвигает их следующую попытку на lease
+func (r *OutboxRepository) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*outbox.Message, error) {
+	defer r.timer.observe("ClaimPending", time.Now())
+
+	query := `UPDATE outbox SET next_attempt_at = $1
+			WHERE id IN (
+				SELECT id FROM outbox
+				WHERE published_at IS NULL AND next_attempt_at <= $2
+				ORDER BY occurred_at
+				LIMIT $3
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING ` + outboxColumns
+
+	messages := []*outbox.Message{}
+	if err := executorFrom(ctx, r.db).SelectContext(ctx, &messages, query, now.Add(lease), now, limit); err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// MarkPublished отмечает сообщение опубликованным
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id uuid.UUID, publishedAt time.Time) error {
+	defer r.timer.observe("MarkPublished", time.Now())
+
+	query := `UPDATE outbox SET published_at = $1, last_error = NULL WHERE id = $2`
+
+	if _, err := executorFrom(ctx, r.db).ExecContext(ctx, query, publishedAt, id); err != nil {
+		return fmt.Errorf("failed to mark outbox message published: %w", err)
+	}
+
+	return nil
+}
+
+// ScheduleRetry сохраняет результат неудачной попытки публикации
+func (r *OutboxRepository) ScheduleRetry(ctx context.Context, msg *outbox.Message) error {
+	defer r.timer.observe("ScheduleRetry", time.Now())
+
+	query := `UPDATE outbox SET attempts = :attempts, next_attempt_at = :next_attempt_at,
+			last_error = :last_error WHERE id = :id`
+
+	if _, err := executorFrom(ctx, r.db).NamedExecContext(ctx, query, msg); err != nil {
+		return fmt.Errorf("failed to schedule outbox retry: %w", err)
+	}
+
+	return nil
+}
+
+// Purge удаляет сообщения, опубликованные раньше publishedBefore, во всех
+// организациях: очистку выполняет фоновая задача, а не запрос клиента
+func (r *OutboxRepository) Purge(ctx context.Context, publishedBefore time.Time, limit int) (int, error) {
+	defer r.timer.observe("Purge", time.Now())
+
+	query := `DELETE FROM outbox WHERE id IN (
+				SELECT id FROM outbox
+				WHERE published_at < $1
+				ORDER BY seq
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)`
+
+	result, err := executorFrom(ctx, r.db).ExecContext(ctx, query, publishedBefore, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge outbox messages: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// eventRecordRow - строка outbox, прочитанная как запись журнала событий
+type eventRecordRow struct {
+	Sequence       int64           `db:"seq"`
+	ID             uuid.UUID       `db:"id"`
+	EventType      event.Type      `db:"event_type"`
+	OrganizationID uuid.UUID       `db:"organization_id"`
+	Data           json.RawMessage `db:"data"`
+	OccurredAt     time.Time       `db:"occurred_at"`
+}
+
+// safeVisibility оставляет только события транзакций, завершенных раньше всех
+// еще активных. Без этого событие с меньшим номером, зафиксированное позже
+// события с большим номером, оказалось бы позади курсора читателя
+const safeVisibility = `tx_id < pg_snapshot_xmin(pg_current_snapshot())`
+
+// ListAfter возвращает события журнала организации из контекста с номером больше after
+func (r *OutboxRepository) ListAfter(ctx context.Context, after int64, filter event.LogFilter, limit int) ([]event.Record, error) {
+	defer r.timer.observe("ListAfter", time.Now())
+
+	query := `SELECT seq, id, event_type, organization_id, data, occurred_at FROM outbox
+			WHERE seq > :after AND organization_id = :organization_id AND ` + safeVisibility
+	params := map[string]interface{}{"after": after, "organization_id": tenant.FromContext(ctx), "limit": limit}
+
+	if filter.UserID != nil {
+		query += " AND data->>'user_id' = :user_id"
+		params["user_id"] = filter.UserID.String()
+	}
+
+	query += " ORDER BY seq LIMIT :limit"
+
+	nstmt, err := executorFrom(ctx, r.db).PrepareNamedContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare named statement: %w", err)
+	}
+	defer nstmt.Close()
+
+	var rows []eventRecordRow
+	if err := nstmt.SelectContext(ctx, &rows, params); err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	records := make([]event.Record, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, event.Record{
+			Sequence: row.Sequence,
+			Event: event.Event{
+				ID:             row.ID,
+				Type:           row.EventType,
+				OrganizationID: row.OrganizationID,
+				OccurredAt:     row.OccurredAt,
+				Data:           row.Data,
+			},
+		})
+	}
+
+	return records, nil
+}
+
+// LastSequence возвращает номер последнего события журнала. Номер общий для
+// всех организаций и служит только курсором, поэтому не ограничивается ими
+func (r *OutboxRepository) LastSequence(ctx context.Context) (int64, error) {
+	defer r.timer.observe("LastSequence", time.Now())
+
+	query := `SELECT COALESCE(MAX(seq), 0) FROM outbox WHERE ` + safeVisibility
+
+	var seq int64
+	if err := executorFrom(ctx, r.db).GetContext(ctx, &seq, query); err != nil {
+		return 0, fmt.Errorf("failed to get last event sequence: %w", err)
+	}
+
+	return seq, nil
+}
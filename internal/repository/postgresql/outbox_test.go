@@ -0,0 +1,157 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/outbox"
+	"github.com/subscription-service/internal/domain/subscription"
+)
+
+func TestOutboxRepository(t *testing.T) {
+	db, cleanup := setupTestDatabase(t)
+	defer cleanup()
+
+	txManager := NewTxManager(db)
+	subscriptions := NewSubscriptionRepository(db)
+	repo := NewOutboxRepository(db)
+	ctx := context.Background()
+
+	newSubscription := func() *subscription.Subscription {
+		return &subscription.Subscription{
+			ServiceName: "Netflix",
+			Price:       400,
+			UserID:      uuid.New(),
+			StartDate:   time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+
+	// Тест отката: ни подписка, ни событие не сохраняются
+	t.Run("Rollback", func(t *testing.T) {
+		sub := newSubscription()
+		evt, err := event.New(event.SubscriptionCreated, map[string]string{})
+		require.NoError(t, err)
+
+		err = txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			require.NoError(t, subscriptions.Create(ctx, sub))
+			require.NoError(t, repo.Add(ctx, outbox.NewMessage(evt)))
+			return errors.New("abort")
+		})
+		assert.EqualError(t, err, "abort")
+
+		_, err = subscriptions.Get(ctx, sub.ID)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+
+		claimed, err := repo.ClaimPending(ctx, time.Now(), time.Minute, 10)
+		require.NoError(t, err)
+		assert.Empty(t, claimed)
+	})
+
+	// Тест фиксации и выборки очереди
+	t.Run("CommitAndClaim", func(t *testing.T) {
+		sub := newSubscription()
+		evt, err := event.New(event.SubscriptionCreated, map[string]string{"service_name": sub.ServiceName})
+		require.NoError(t, err)
+
+		err = txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := subscriptions.Create(ctx, sub); err != nil {
+				return err
+			}
+			return repo.Add(ctx, outbox.NewMessage(evt))
+		})
+		require.NoError(t, err)
+
+		now := time.Now()
+		claimed, err := repo.ClaimPending(ctx, now, time.Minute, 10)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		assert.Equal(t, evt.ID, claimed[0].ID)
+		assert.JSONEq(t, string(evt.Data), string(claimed[0].Data))
+
+		// Выбранное сообщение скрыто до окончания аренды
+		claimed, err = repo.ClaimPending(ctx, now, time.Minute, 10)
+		require.NoError(t, err)
+		assert.Empty(t, claimed)
+
+		require.NoError(t, repo.MarkPublished(ctx, evt.ID, now))
+		claimed, err = repo.ClaimPending(ctx, now.Add(time.Hour), time.Minute, 10)
+		require.NoError(t, err)
+		assert.Empty(t, claimed)
+	})
+
+	// Тест чтения журнала событий
+	t.Run("ListAfter", func(t *testing.T) {
+		last, err := repo.LastSequence(ctx)
+		require.NoError(t, err)
+
+		userID := uuid.New()
+		var published []event.Event
+		for _, owner := range []uuid.UUID{userID, uuid.New(), userID} {
+			evt, err := event.New(event.SubscriptionCreated, map[string]string{"user_id": owner.String()})
+			require.NoError(t, err)
+			require.NoError(t, repo.Add(ctx, outbox.NewMessage(evt)))
+			published = append(published, evt)
+		}
+
+		records, err := repo.ListAfter(ctx, last, event.LogFilter{}, 10)
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, published[0].ID, records[0].ID)
+		assert.Less(t, records[0].Sequence, records[1].Sequence)
+
+		// Фильтр по пользователю и продолжение после курсора
+		records, err = repo.ListAfter(ctx, last, event.LogFilter{UserID: &userID}, 1)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, published[0].ID, records[0].ID)
+
+		records, err = repo.ListAfter(ctx, records[0].Sequence, event.LogFilter{UserID: &userID}, 10)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, published[2].ID, records[0].ID)
+
+		current, err := repo.LastSequence(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, last+3, current)
+	})
+
+	// Тест очистки опубликованных сообщений
+	t.Run("Purge", func(t *testing.T) {
+		last, err := repo.LastSequence(ctx)
+		require.NoError(t, err)
+
+		now := time.Now()
+		var messages []*outbox.Message
+		for i := 0; i < 3; i++ {
+			evt, err := event.New(event.SubscriptionCreated, map[string]string{"user_id": uuid.NewString()})
+			require.NoError(t, err)
+			msg := outbox.NewMessage(evt)
+			require.NoError(t, repo.Add(ctx, msg))
+			messages = append(messages, msg)
+		}
+		// Старое и недавнее опубликованные сообщения; третье не опубликовано
+		require.NoError(t, repo.MarkPublished(ctx, messages[0].ID, now.Add(-48*time.Hour)))
+		require.NoError(t, repo.MarkPublished(ctx, messages[1].ID, now))
+
+		purged, err := repo.Purge(ctx, now.Add(-24*time.Hour), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, purged)
+
+		records, err := repo.ListAfter(ctx, last, event.LogFilter{}, 10)
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, messages[1].ID, records[0].ID)
+		assert.Equal(t, messages[2].ID, records[1].ID)
+
+		// Номера журнала не переиспользуются после очистки
+		current, err := repo.LastSequence(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, last+3, current)
+	})
+}
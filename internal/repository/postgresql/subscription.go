@@ -2,38 +2,50 @@ package postgresql
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/tenant"
 )
 
+// subscriptionColumns - столбцы таблицы subscriptions в порядке полей subscription.Subscription
+const subscriptionColumns = `id, organization_id, service_name, price, user_id, start_date, end_date, created_at, updated_at, deleted_at`
+
 // SubscriptionRepository реализует интерфейс repository.SubscriptionRepository
 type SubscriptionRepository struct {
-	db *sqlx.DB
+	db    *sqlx.DB
+	timer queryTimer
 }
 
 // NewSubscriptionRepository создает новый экземпляр репозитория подписок
-func NewSubscriptionRepository(db *sqlx.DB) *SubscriptionRepository {
-	return &SubscriptionRepository{db: db}
+func NewSubscriptionRepository(db *sqlx.DB, opts ...Option) *SubscriptionRepository {
+	return &SubscriptionRepository{db: db, timer: newQueryTimer("subscriptions", opts)}
 }
 
-// Create создает новую запись о подписке
+// Create создает новую запись о подписке в организации из контекста
 func (r *SubscriptionRepository) Create(ctx context.Context, sub *subscription.Subscription) error {
+	defer r.timer.observe("Create", time.Now())
+
 	query := `INSERT INTO subscriptions 
-			(id, service_name, price, user_id, start_date, end_date, created_at, updated_at) 
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+			(id, organization_id, service_name, price, user_id, start_date, end_date, created_at, updated_at) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
 
 	sub.ID = uuid.New()
+	sub.OrganizationID = tenant.FromContext(ctx)
 	sub.CreatedAt = time.Now()
 	sub.UpdatedAt = time.Now()
 
-	_, err := r.db.ExecContext(
-		ctx,
+	qctx, span := startSpan(ctx, "SubscriptionRepository.Create", query)
+	_, err := executorFrom(ctx, r.db).ExecContext(
+		qctx,
 		query,
 		sub.ID,
+		sub.OrganizationID,
 		sub.ServiceName,
 		sub.Price,
 		sub.UserID,
@@ -42,6 +54,7 @@ func (r *SubscriptionRepository) Create(ctx context.Context, sub *subscription.S
 		sub.CreatedAt,
 		sub.UpdatedAt,
 	)
+	endSpan(span, err)
 
 	if err != nil {
 		return fmt.Errorf("failed to create subscription: %w", err)
@@ -52,11 +65,15 @@ func (r *SubscriptionRepository) Create(ctx context.Context, sub *subscription.S
 
 // Get возвращает подписку по ID
 func (r *SubscriptionRepository) Get(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
-	query := `SELECT id, service_name, price, user_id, start_date, end_date, created_at, updated_at 
-			FROM subscriptions WHERE id = $1`
+	defer r.timer.observe("Get", time.Now())
 
+	query := `SELECT ` + subscriptionColumns + `
+			FROM subscriptions WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL`
+
+	qctx, span := startSpan(ctx, "SubscriptionRepository.Get", query)
 	var sub subscription.Subscription
-	err := r.db.GetContext(ctx, &sub, query, id)
+	err := executorFrom(ctx, r.db).GetContext(qctx, &sub, query, id, tenant.FromContext(ctx))
+	endSpan(span, err)
 	if err != nil {
 		// Проверяем, является ли ошибка "no rows in result set"
 		if err.Error() == "sql: no rows in result set" {
@@ -68,16 +85,66 @@ func (r *SubscriptionRepository) Get(ctx context.Context, id uuid.UUID) (*subscr
 	return &sub, nil
 }
 
+// GetDeleted возвращает удаленную подписку, еще не очищенную по сроку хранения
+func (r *SubscriptionRepository) GetDeleted(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	defer r.timer.observe("GetDeleted", time.Now())
+
+	query := `SELECT ` + subscriptionColumns + `
+			FROM subscriptions WHERE id = $1 AND organization_id = $2`
+
+	qctx, span := startSpan(ctx, "SubscriptionRepository.GetDeleted", query)
+	var sub subscription.Subscription
+	err := executorFrom(ctx, r.db).GetContext(qctx, &sub, query, id, tenant.FromContext(ctx))
+	endSpan(span, err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, subscription.ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to get deleted subscription: %w", err)
+	}
+	if sub.DeletedAt == nil {
+		return nil, subscription.ErrSubscriptionNotDeleted
+	}
+
+	return &sub, nil
+}
+
+// GetAsOf возвращает ревизию подписки, действовавшую в момент asOf
+func (r *SubscriptionRepository) GetAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (*subscription.Subscription, error) {
+	defer r.timer.observe("GetAsOf", time.Now())
+
+	query := `SELECT ` + subscriptionColumns + `
+			FROM subscription_history
+			WHERE id = $1 AND organization_id = $3
+			AND valid_from <= $2 AND (valid_to IS NULL OR valid_to > $2) AND deleted_at IS NULL`
+
+	qctx, span := startSpan(ctx, "SubscriptionRepository.GetAsOf", query)
+	var sub subscription.Subscription
+	err := executorFrom(ctx, r.db).GetContext(qctx, &sub, query, id, asOf, tenant.FromContext(ctx))
+	endSpan(span, err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, subscription.ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to get subscription revision: %w", err)
+	}
+
+	return &sub, nil
+}
+
 // Update обновляет существующую подписку
 func (r *SubscriptionRepository) Update(ctx context.Context, sub *subscription.Subscription) error {
+	defer r.timer.observe("Update", time.Now())
+
 	query := `UPDATE subscriptions SET 
 			service_name = $1, price = $2, start_date = $3, end_date = $4, updated_at = $5 
-			WHERE id = $6`
+			WHERE id = $6 AND organization_id = $7 AND deleted_at IS NULL`
 
 	sub.UpdatedAt = time.Now()
 
-	result, err := r.db.ExecContext(
-		ctx,
+	qctx, span := startSpan(ctx, "SubscriptionRepository.Update", query)
+	result, err := executorFrom(ctx, r.db).ExecContext(
+		qctx,
 		query,
 		sub.ServiceName,
 		sub.Price,
@@ -85,7 +152,9 @@ func (r *SubscriptionRepository) Update(ctx context.Context, sub *subscription.S
 		sub.EndDate,
 		sub.UpdatedAt,
 		sub.ID,
+		tenant.FromContext(ctx),
 	)
+	endSpan(span, err)
 
 	if err != nil {
 		return fmt.Errorf("failed to update subscription: %w", err)
@@ -103,11 +172,16 @@ func (r *SubscriptionRepository) Update(ctx context.Context, sub *subscription.S
 	return nil
 }
 
-// Delete удаляет подписку по ID
+// Delete помечает подписку удаленной; строка остается в таблице до очистки
 func (r *SubscriptionRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM subscriptions WHERE id = $1`
+	defer r.timer.observe("Delete", time.Now())
+
+	query := `UPDATE subscriptions SET deleted_at = $1
+			WHERE id = $2 AND organization_id = $3 AND deleted_at IS NULL`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	qctx, span := startSpan(ctx, "SubscriptionRepository.Delete", query)
+	result, err := executorFrom(ctx, r.db).ExecContext(qctx, query, time.Now(), id, tenant.FromContext(ctx))
+	endSpan(span, err)
 	if err != nil {
 		return fmt.Errorf("failed to delete subscription: %w", err)
 	}
@@ -124,24 +198,204 @@ func (r *SubscriptionRepository) Delete(ctx context.Context, id uuid.UUID) error
 	return nil
 }
 
-// List возвращает список всех подписок
-func (r *SubscriptionRepository) List(ctx context.Context) ([]*subscription.Subscription, error) {
-	query := `SELECT id, service_name, price, user_id, start_date, end_date, created_at, updated_at 
-			FROM subscriptions`
+// Restore снимает пометку об удалении с подписки
+func (r *SubscriptionRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	defer r.timer.observe("Restore", time.Now())
+
+	exec := executorFrom(ctx, r.db)
+
+	// Блокируем строку, чтобы проверка и снятие пометки не разошлись с
+	// параллельным удалением или очисткой
+	query := `SELECT deleted_at FROM subscriptions
+			WHERE id = $1 AND organization_id = $2 FOR UPDATE`
+	qctx, span := startSpan(ctx, "SubscriptionRepository.Restore", query)
+	var deletedAt *time.Time
+	err := exec.GetContext(qctx, &deletedAt, query, id, tenant.FromContext(ctx))
+	endSpan(span, err)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return subscription.ErrSubscriptionNotFound
+		}
+		return fmt.Errorf("failed to get subscription for restore: %w", err)
+	}
+	if deletedAt == nil {
+		return subscription.ErrSubscriptionNotDeleted
+	}
+
+	query = `UPDATE subscriptions SET deleted_at = NULL, updated_at = $1 WHERE id = $2`
+	qctx, span = startSpan(ctx, "SubscriptionRepository.Restore", query)
+	_, err = exec.ExecContext(qctx, query, time.Now(), id)
+	endSpan(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to restore subscription: %w", err)
+	}
+
+	return nil
+}
+
+// Purge окончательно удаляет подписки, удаленные раньше deletedBefore, во
+// всех организациях: очистку выполняет фоновая задача, а не запрос клиента
+func (r *SubscriptionRepository) Purge(ctx context.Context, deletedBefore time.Time, limit int) (int, error) {
+	defer r.timer.observe("Purge", time.Now())
+
+	query := `DELETE FROM subscriptions WHERE id IN (
+				SELECT id FROM subscriptions
+				WHERE deleted_at < $1
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)`
+
+	qctx, span := startSpan(ctx, "SubscriptionRepository.Purge", query)
+	result, err := executorFrom(ctx, r.db).ExecContext(qctx, query, deletedBefore, limit)
+	endSpan(span, err)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge subscriptions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// CountActive возвращает число неудаленных подписок, действующих в момент at,
+// во всех организациях. Используется для метрик сервиса
+func (r *SubscriptionRepository) CountActive(ctx context.Context, at time.Time) (int, error) {
+	defer r.timer.observe("CountActive", time.Now())
+
+	query := `SELECT COUNT(*) FROM subscriptions
+			WHERE deleted_at IS NULL AND start_date <= $1 AND (end_date IS NULL OR end_date >= $1)`
+
+	qctx, span := startSpan(ctx, "SubscriptionRepository.CountActive", query)
+	var count int
+	err := executorFrom(ctx, r.db).GetContext(qctx, &count, query, at)
+	endSpan(span, err)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count active subscriptions: %w", err)
+	}
+
+	return count, nil
+}
+
+// List возвращает список подписок, удовлетворяющих фильтру
+func (r *SubscriptionRepository) List(ctx context.Context, filter subscription.ListFilter) ([]*subscription.Subscription, error) {
+	defer r.timer.observe("List", time.Now())
+
+	query, params := buildListQuery(ctx, filter)
+
+	qctx, span := startSpan(ctx, "SubscriptionRepository.List", query)
+	nstmt, err := executorFrom(ctx, r.db).PrepareNamedContext(qctx, query)
+	if err != nil {
+		endSpan(span, err)
+		return nil, fmt.Errorf("failed to prepare named statement: %w", err)
+	}
+	defer nstmt.Close()
 
-	var subs []*subscription.Subscription
-	if err := r.db.SelectContext(ctx, &subs, query); err != nil {
+	subs := []*subscription.Subscription{}
+	err = nstmt.SelectContext(qctx, &subs, params)
+	endSpan(span, err)
+	if err != nil {
 		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
 	}
 
 	return subs, nil
 }
 
+// Stream построчно читает подписки из курсора и передает каждую в fn,
+// не загружая всю выборку в память
+func (r *SubscriptionRepository) Stream(ctx context.Context, filter subscription.ListFilter, fn func(*subscription.Subscription) error) error {
+	defer r.timer.observe("Stream", time.Now())
+
+	query, params := buildListQuery(ctx, filter)
+
+	// Span охватывает чтение курсора целиком, включая обработку строк в fn
+	qctx, span := startSpan(ctx, "SubscriptionRepository.Stream", query)
+	defer span.End()
+
+	rows, err := sqlx.NamedQueryContext(qctx, executorFrom(ctx, r.db), query, params)
+	if err != nil {
+		recordError(span, err)
+		return fmt.Errorf("failed to query subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sub subscription.Subscription
+		if err := rows.StructScan(&sub); err != nil {
+			recordError(span, err)
+			return fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		if err := fn(&sub); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		recordError(span, err)
+		return fmt.Errorf("failed to iterate subscriptions: %w", err)
+	}
+
+	return nil
+}
+
+// subscriptionSource возвращает источник строк подписок: саму таблицу или,
+// для запроса на момент времени, ревизии из истории, действовавшие в asOf.
+// Столбцы ревизий совпадают со столбцами таблицы, поэтому остальные условия
+// запроса от источника не зависят. Источник ограничен организацией из контекста
+func subscriptionSource(ctx context.Context, asOf *time.Time, params map[string]interface{}) string {
+	params["organization_id"] = tenant.FromContext(ctx)
+	if asOf == nil {
+		return `subscriptions WHERE organization_id = :organization_id`
+	}
+	params["as_of"] = *asOf
+	return `subscription_history WHERE organization_id = :organization_id
+			AND valid_from <= :as_of AND (valid_to IS NULL OR valid_to > :as_of)`
+}
+
+// buildListQuery строит запрос выборки подписок с именованными параметрами фильтра
+func buildListQuery(ctx context.Context, filter subscription.ListFilter) (string, map[string]interface{}) {
+	params := map[string]interface{}{}
+	query := `SELECT ` + subscriptionColumns + ` FROM ` + subscriptionSource(ctx, filter.AsOf, params)
+
+	if !filter.IncludeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+
+	if filter.UserID != nil {
+		query += " AND user_id = :user_id"
+		params["user_id"] = *filter.UserID
+	}
+
+	if filter.ServiceName != nil && *filter.ServiceName != "" {
+		query += " AND service_name = :service_name"
+		params["service_name"] = *filter.ServiceName
+	}
+
+	// Стабильный порядок делает выгрузку воспроизводимой
+	query += " ORDER BY created_at, id"
+
+	if filter.Limit > 0 {
+		query += " LIMIT :limit OFFSET :offset"
+		params["limit"] = filter.Limit
+		params["offset"] = filter.Offset
+	}
+
+	return query, params
+}
+
 // CalculateTotalCost рассчитывает общую стоимость подписок по фильтру
 func (r *SubscriptionRepository) CalculateTotalCost(ctx context.Context, filter subscription.SubscriptionFilter) (int, error) {
+	defer r.timer.observe("CalculateTotalCost", time.Now())
+
 	// Строим запрос с использованием именованных параметров для безопасности
-	query := `SELECT COALESCE(SUM(price), 0) FROM subscriptions WHERE 1=1`
 	params := map[string]interface{}{}
+	query := `SELECT COALESCE(SUM(price), 0) FROM ` + subscriptionSource(ctx, filter.AsOf, params)
+
+	if !filter.IncludeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
 
 	// Безопасно добавляем фильтр по ID пользователя (если указан)
 	if filter.UserID != nil {
@@ -163,16 +417,61 @@ func (r *SubscriptionRepository) CalculateTotalCost(ctx context.Context, filter
 	params["start_period"] = filter.StartPeriod
 
 	// Выполняем запрос с именованными параметрами
-	nstmt, err := r.db.PrepareNamedContext(ctx, query)
+	qctx, span := startSpan(ctx, "SubscriptionRepository.CalculateTotalCost", query)
+	nstmt, err := executorFrom(ctx, r.db).PrepareNamedContext(qctx, query)
 	if err != nil {
+		endSpan(span, err)
 		return 0, fmt.Errorf("failed to prepare named statement: %w", err)
 	}
 	defer nstmt.Close()
 
 	var totalCost int
-	if err := nstmt.GetContext(ctx, &totalCost, params); err != nil {
+	err = nstmt.GetContext(qctx, &totalCost, params)
+	endSpan(span, err)
+	if err != nil {
 		return 0, fmt.Errorf("failed to calculate total cost: %w", err)
 	}
 
 	return totalCost, nil
 }
+
+// SummarizeByService возвращает сводку неудаленных подписок по каждому
+// сервису в порядке названий
+func (r *SubscriptionRepository) SummarizeByService(ctx context.Context, filter subscription.SummaryFilter) ([]*subscription.ServiceSummary, error) {
+	defer r.timer.observe("SummarizeByService", time.Now())
+
+	params := map[string]interface{}{}
+	query := `SELECT service_name, COUNT(*) AS subscription_count, COUNT(DISTINCT user_id) AS user_count,
+			MIN(price) AS min_price, MAX(price) AS max_price, SUM(price) AS total_cost
+			FROM ` + subscriptionSource(ctx, nil, params) + ` AND deleted_at IS NULL`
+
+	if filter.UserID != nil {
+		query += " AND user_id = :user_id"
+		params["user_id"] = *filter.UserID
+	}
+
+	// Как и в CalculateTotalCost, подписка должна действовать в периоде
+	if !filter.StartPeriod.IsZero() {
+		query += " AND start_date <= :end_period AND (end_date IS NULL OR end_date >= :start_period)"
+		params["start_period"] = filter.StartPeriod
+		params["end_period"] = filter.EndPeriod
+	}
+	query += " GROUP BY service_name ORDER BY service_name"
+
+	qctx, span := startSpan(ctx, "SubscriptionRepository.SummarizeByService", query)
+	nstmt, err := executorFrom(ctx, r.db).PrepareNamedContext(qctx, query)
+	if err != nil {
+		endSpan(span, err)
+		return nil, fmt.Errorf("failed to prepare named statement: %w", err)
+	}
+	defer nstmt.Close()
+
+	summaries := []*subscription.ServiceSummary{}
+	err = nstmt.SelectContext(qctx, &summaries, params)
+	endSpan(span, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize subscriptions: %w", err)
+	}
+
+	return summaries, nil
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/migration"
+	"github.com/subscription-service/internal/repository/repotest"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
@@ -50,25 +52,12 @@ func setupTestDatabase(t *testing.T) (*sqlx.DB, func()) {
 	db, err := sqlx.Connect("postgres", dsn)
 	require.NoError(t, err)
 
-	// Создание тестовой таблицы
-	_, err = db.Exec(`
-		CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
-		
-		CREATE TABLE IF NOT EXISTS subscriptions (
-			id UUID PRIMARY KEY,
-			service_name VARCHAR(255) NOT NULL,
-			price INT NOT NULL CHECK (price > 0),
-			user_id UUID NOT NULL,
-			start_date DATE NOT NULL,
-			end_date DATE,
-			created_at TIMESTAMPTZ NOT NULL,
-			updated_at TIMESTAMPTZ NOT NULL
-		);
-		
-		CREATE INDEX IF NOT EXISTS idx_subscriptions_user_id ON subscriptions(user_id);
-		CREATE INDEX IF NOT EXISTS idx_subscriptions_service_name ON subscriptions(service_name);
-		CREATE INDEX IF NOT EXISTS idx_subscriptions_date_range ON subscriptions(start_date, end_date);
-	`)
+	// Схема создается настоящими миграциями, чтобы тесты проверяли триггеры
+	// и политики из migrations/
+	migrator, err := migration.Open(migration.Config{DSN: dsn, Path: "../../../migrations", Table: "schema_migrations"})
+	require.NoError(t, err)
+	defer migrator.Close()
+	err = migrator.Up()
 	require.NoError(t, err)
 
 	// Функция очистки
@@ -130,6 +119,76 @@ func TestSubscriptionRepository_CRUD(t *testing.T) {
 		assert.Equal(t, "Updated Service", updatedSub.ServiceName)
 	})
 
+	// Тест запросов на момент времени
+	t.Run("AsOf", func(t *testing.T) {
+		var revisions []time.Time
+		err := db.SelectContext(ctx, &revisions, `SELECT valid_from FROM subscription_history WHERE id = $1 ORDER BY revision`, sub.ID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		created, updated := revisions[0], revisions[1]
+
+		original, err := repo.GetAsOf(ctx, sub.ID, created)
+		assert.NoError(t, err)
+		assert.Equal(t, 100, original.Price)
+		assert.Equal(t, "Test Service", original.ServiceName)
+
+		current, err := repo.GetAsOf(ctx, sub.ID, updated)
+		assert.NoError(t, err)
+		assert.Equal(t, 150, current.Price)
+
+		// До создания подписки еще не существовало
+		_, err = repo.GetAsOf(ctx, sub.ID, created.Add(-time.Second))
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+
+		subs, err := repo.List(ctx, subscription.ListFilter{UserID: &userID, AsOf: &created})
+		assert.NoError(t, err)
+		require.Len(t, subs, 1)
+		assert.Equal(t, 100, subs[0].Price)
+
+		totalCost, err := repo.CalculateTotalCost(ctx, subscription.SubscriptionFilter{
+			UserID:      &userID,
+			StartPeriod: startDate,
+			EndPeriod:   startDate.AddDate(0, 11, 0),
+			AsOf:        &created,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 100, totalCost)
+	})
+
+	// Время ревизий берется из clock_timestamp(), поэтому изменения в одной
+	// транзакции дают упорядоченные ревизии ненулевой длины
+	t.Run("ревизии в одной транзакции упорядочены", func(t *testing.T) {
+		err := NewTxManager(db).WithinTransaction(ctx, func(ctx context.Context) error {
+			// Последнее изменение возвращает цену, которую проверяют следующие подтесты
+			for _, price := range []int{160, 150} {
+				sub.Price = price
+				if err := repo.Update(ctx, sub); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		var revisions []struct {
+			ValidFrom time.Time  `db:"valid_from"`
+			ValidTo   *time.Time `db:"valid_to"`
+		}
+		err = db.SelectContext(ctx, &revisions, `SELECT valid_from, valid_to FROM subscription_history WHERE id = $1 ORDER BY revision`, sub.ID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 4)
+		for i, rev := range revisions[:len(revisions)-1] {
+			require.NotNil(t, rev.ValidTo)
+			assert.True(t, rev.ValidFrom.Before(*rev.ValidTo), "ревизия %d должна заканчиваться позже, чем начинается", i)
+			assert.True(t, rev.ValidTo.Equal(revisions[i+1].ValidFrom), "ревизия %d должна начинаться сразу после %d", i+1, i)
+		}
+		assert.Nil(t, revisions[len(revisions)-1].ValidTo)
+
+		current, err := repo.Get(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 150, current.Price)
+	})
+
 	// Тест списка подписок
 	t.Run("List", func(t *testing.T) {
 		// Создаем ещё одну подписку для проверки списка
@@ -143,9 +202,34 @@ func TestSubscriptionRepository_CRUD(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Получаем список всех подписок
-		subs, err := repo.List(ctx)
+		subs, err := repo.List(ctx, subscription.ListFilter{})
 		assert.NoError(t, err)
 		assert.Len(t, subs, 2)
+
+		// Фильтр по названию сервиса
+		serviceName := "Another Service"
+		subs, err = repo.List(ctx, subscription.ListFilter{ServiceName: &serviceName})
+		assert.NoError(t, err)
+		assert.Len(t, subs, 1)
+		assert.Equal(t, sub2.ID, subs[0].ID)
+
+		// Постраничная выборка
+		subs, err = repo.List(ctx, subscription.ListFilter{Limit: 1, Offset: 1})
+		assert.NoError(t, err)
+		assert.Len(t, subs, 1)
+		assert.Equal(t, sub2.ID, subs[0].ID)
+	})
+
+	// Тест потокового чтения
+	t.Run("Stream", func(t *testing.T) {
+		var streamed []uuid.UUID
+		err := repo.Stream(ctx, subscription.ListFilter{UserID: &userID}, func(s *subscription.Subscription) error {
+			streamed = append(streamed, s.ID)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Len(t, streamed, 2)
+		assert.Equal(t, sub.ID, streamed[0])
 	})
 
 	// Тест расчета стоимости
@@ -163,6 +247,18 @@ func TestSubscriptionRepository_CRUD(t *testing.T) {
 		assert.Equal(t, 350, cost)
 	})
 
+	// Тест подсчета действующих подписок
+	t.Run("CountActive", func(t *testing.T) {
+		count, err := repo.CountActive(ctx, time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		// До начала подписки не действуют
+		count, err = repo.CountActive(ctx, time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
 	// Тест удаления подписки
 	t.Run("Delete", func(t *testing.T) {
 		err := repo.Delete(ctx, sub.ID)
@@ -172,5 +268,56 @@ func TestSubscriptionRepository_CRUD(t *testing.T) {
 		_, err = repo.Get(ctx, sub.ID)
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+
+		// Удаленная подписка видна только с include_deleted
+		subs, err := repo.List(ctx, subscription.ListFilter{UserID: &sub.UserID})
+		assert.NoError(t, err)
+		assert.Empty(t, subs)
+
+		subs, err = repo.List(ctx, subscription.ListFilter{UserID: &sub.UserID, IncludeDeleted: true})
+		assert.NoError(t, err)
+		assert.Len(t, subs, 1)
+		assert.NotNil(t, subs[0].DeletedAt)
+	})
+
+	// Тест восстановления удаленной подписки
+	t.Run("Restore", func(t *testing.T) {
+		err := repo.Restore(ctx, sub.ID)
+		assert.NoError(t, err)
+
+		restored, err := repo.Get(ctx, sub.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+
+		// Повторное восстановление невозможно
+		assert.ErrorIs(t, repo.Restore(ctx, sub.ID), subscription.ErrSubscriptionNotDeleted)
+		assert.ErrorIs(t, repo.Restore(ctx, uuid.New()), subscription.ErrSubscriptionNotFound)
+	})
+
+	// Тест окончательной очистки удаленных подписок
+	t.Run("Purge", func(t *testing.T) {
+		assert.NoError(t, repo.Delete(ctx, sub.ID))
+
+		// Подписка удалена позже границы и не очищается
+		purged, err := repo.Purge(ctx, time.Now().Add(-time.Hour), 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, purged)
+
+		purged, err = repo.Purge(ctx, time.Now().Add(time.Minute), 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, purged)
+
+		assert.ErrorIs(t, repo.Restore(ctx, sub.ID), subscription.ErrSubscriptionNotFound)
+	})
+}
+
+func TestSubscriptionRepository_Conformance(t *testing.T) {
+	db, cleanup := setupTestDatabase(t)
+	defer cleanup()
+
+	repotest.RunSubscriptionRepository(t, func(t *testing.T) subscription.Repository {
+		_, err := db.Exec("TRUNCATE subscriptions, subscription_history")
+		require.NoError(t, err)
+		return NewSubscriptionRepository(db)
 	})
 }
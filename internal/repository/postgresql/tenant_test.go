@@ -0,0 +1,242 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/domain/apikey"
+	"github.com/subscription-service/internal/domain/audit"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/domain/webhook"
+	"github.com/subscription-service/internal/outbox"
+	"github.com/subscription-service/internal/tenant"
+	"github.com/subscription-service/internal/usecase"
+)
+
+// TestTenantIsolation проверяет, что данные одной организации не видны и не
+// изменяются из другой
+func TestTenantIsolation(t *testing.T) {
+	db, cleanup := setupTestDatabase(t)
+	defer cleanup()
+
+	subscriptions := NewSubscriptionRepository(db)
+	audits := NewAuditRepository(db)
+	events := NewOutboxRepository(db)
+	webhooks := NewWebhookRepository(db)
+	apiKeys := NewAPIKeyRepository(db)
+	service := usecase.NewSubscriptionService(subscriptions,
+		usecase.WithTransactionManager(NewTxManager(db)),
+		usecase.WithAuditLog(audits),
+		usecase.WithEventPublisher(outbox.NewPublisher(events)))
+
+	owner := tenant.WithOrganization(context.Background(), uuid.New())
+	intruder := tenant.WithOrganization(context.Background(), uuid.New())
+
+	userID := uuid.New()
+	sub, err := service.Create(owner, subscription.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       400,
+		UserID:      userID,
+		StartDate:   "07-2023",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, tenant.FromContext(owner), sub.OrganizationID)
+	createdAt := time.Now()
+
+	// Подписка того же пользователя в другой организации не должна попадать в
+	// выборки владельца
+	_, err = service.Create(intruder, subscription.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       1000,
+		UserID:      userID,
+		StartDate:   "07-2023",
+	})
+	require.NoError(t, err)
+
+	t.Run("чтение", func(t *testing.T) {
+		_, err := subscriptions.Get(intruder, sub.ID)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+
+		_, err = subscriptions.GetAsOf(intruder, sub.ID, createdAt)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+
+		for _, asOf := range []*time.Time{nil, &createdAt} {
+			subs, err := subscriptions.List(intruder, subscription.ListFilter{UserID: &userID, IncludeDeleted: true, AsOf: asOf})
+			require.NoError(t, err)
+			for _, found := range subs {
+				assert.NotEqual(t, sub.ID, found.ID)
+			}
+
+			var streamed int
+			err = subscriptions.Stream(owner, subscription.ListFilter{UserID: &userID, AsOf: asOf}, func(found *subscription.Subscription) error {
+				assert.Equal(t, sub.ID, found.ID)
+				streamed++
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, 1, streamed)
+		}
+
+		total, err := subscriptions.CalculateTotalCost(owner, subscription.SubscriptionFilter{
+			UserID:      &userID,
+			StartPeriod: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndPeriod:   time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 400, total)
+	})
+
+	t.Run("изменение", func(t *testing.T) {
+		err := subscriptions.Update(intruder, &subscription.Subscription{ID: sub.ID, ServiceName: "Hijacked", Price: 1, StartDate: sub.StartDate})
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+		assert.ErrorIs(t, subscriptions.Delete(intruder, sub.ID), subscription.ErrSubscriptionNotFound)
+
+		require.NoError(t, subscriptions.Delete(owner, sub.ID))
+		assert.ErrorIs(t, subscriptions.Restore(intruder, sub.ID), subscription.ErrSubscriptionNotFound)
+		require.NoError(t, subscriptions.Restore(owner, sub.ID))
+
+		found, err := subscriptions.Get(owner, sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Netflix", found.ServiceName)
+		assert.Equal(t, 400, found.Price)
+	})
+
+	t.Run("журнал аудита", func(t *testing.T) {
+		entries, err := audits.List(intruder, audit.Filter{SubscriptionID: &sub.ID})
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+
+		entries, err = audits.List(owner, audit.Filter{SubscriptionID: &sub.ID})
+		require.NoError(t, err)
+		assert.NotEmpty(t, entries)
+	})
+
+	t.Run("журнал событий", func(t *testing.T) {
+		records, err := events.ListAfter(intruder, 0, event.LogFilter{}, 100)
+		require.NoError(t, err)
+		for _, record := range records {
+			assert.NotContains(t, string(record.Data), sub.ID.String())
+			assert.Equal(t, tenant.FromContext(intruder), record.OrganizationID)
+		}
+
+		records, err = events.ListAfter(owner, 0, event.LogFilter{}, 100)
+		require.NoError(t, err)
+		require.NotEmpty(t, records)
+		for _, record := range records {
+			assert.Equal(t, tenant.FromContext(owner), record.OrganizationID)
+		}
+	})
+
+	t.Run("webhooks", func(t *testing.T) {
+		endpoint := &webhook.Endpoint{URL: "https://owner.example.com/hook", Secret: "0123456789abcdef"}
+		require.NoError(t, webhooks.CreateEndpoint(owner, endpoint))
+		now := time.Now()
+		delivery := &webhook.Delivery{EndpointID: endpoint.ID, EventID: uuid.New(), EventType: event.SubscriptionCreated,
+			Payload: []byte(`{}`), Status: webhook.DeliveryPending, NextAttemptAt: &now}
+		require.NoError(t, webhooks.CreateDelivery(owner, delivery))
+
+		_, err := webhooks.GetEndpoint(intruder, endpoint.ID)
+		assert.ErrorIs(t, err, webhook.ErrEndpointNotFound)
+		_, err = webhooks.GetDelivery(intruder, delivery.ID)
+		assert.ErrorIs(t, err, webhook.ErrDeliveryNotFound)
+		assert.ErrorIs(t, webhooks.DeleteEndpoint(intruder, endpoint.ID), webhook.ErrEndpointNotFound)
+
+		for _, list := range []func(ctx context.Context) ([]*webhook.Endpoint, error){
+			webhooks.ListEndpoints,
+			func(ctx context.Context) ([]*webhook.Endpoint, error) {
+				return webhooks.ListEndpointsForEvent(ctx, event.SubscriptionCreated)
+			},
+		} {
+			endpoints, err := list(intruder)
+			require.NoError(t, err)
+			assert.Empty(t, endpoints)
+		}
+
+		deliveries, err := webhooks.ListDeliveries(intruder, endpoint.ID, webhook.DeliveryFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, deliveries)
+
+		// Обработчик очереди находит получателя по организации доставки
+		claimed, err := webhooks.ClaimDueDeliveries(context.Background(), now, time.Minute, 10)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		assert.Equal(t, tenant.FromContext(owner), claimed[0].OrganizationID)
+	})
+
+	t.Run("ключи API", func(t *testing.T) {
+		key := &apikey.Key{Name: "owner", Prefix: "0123456789ab", Hash: []byte("hash")}
+		require.NoError(t, apiKeys.Create(owner, key))
+
+		keys, err := apiKeys.List(intruder)
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+		assert.ErrorIs(t, apiKeys.Revoke(intruder, key.ID), apikey.ErrKeyNotFound)
+
+		// Аутентификация ищет ключ во всех организациях и узнает его организацию
+		found, err := apiKeys.GetByPrefix(context.Background(), key.Prefix)
+		require.NoError(t, err)
+		assert.Equal(t, tenant.FromContext(owner), found.OrganizationID)
+		assert.False(t, found.Revoked())
+	})
+}
+
+// TestRowLevelSecurity проверяет политики миграции 010: роль, не владеющая
+// таблицами, видит только строки организации из app.organization_id, а
+// владелец таблиц, под которым работает сервис, политиками не ограничивается
+func TestRowLevelSecurity(t *testing.T) {
+	db, cleanup := setupTestDatabase(t)
+	defer cleanup()
+
+	subscriptions := NewSubscriptionRepository(db)
+	owner := tenant.WithOrganization(context.Background(), uuid.New())
+	other := tenant.WithOrganization(context.Background(), uuid.New())
+	for _, ctx := range []context.Context{owner, other} {
+		require.NoError(t, subscriptions.Create(ctx, &subscription.Subscription{
+			ServiceName: "Netflix", Price: 400, UserID: uuid.New(), StartDate: time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+		}))
+	}
+
+	// Тестовый пользователь - суперпользователь, на которого политики не
+	// действуют никогда, поэтому проверки выполняются от обычных ролей:
+	// аналитики и владельца таблицы, как у сервиса
+	_, err := db.Exec(`CREATE ROLE analytics NOLOGIN;
+		GRANT SELECT ON ALL TABLES IN SCHEMA public TO analytics;
+		CREATE ROLE service_owner NOLOGIN;
+		ALTER TABLE subscriptions OWNER TO service_owner`)
+	require.NoError(t, err)
+
+	// visible возвращает организации строк subscriptions, видимых роли role в
+	// сеансе с app.organization_id = organizationID
+	visible := func(t *testing.T, role, organizationID string) []uuid.UUID {
+		t.Helper()
+		tx, err := db.Beginx()
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		_, err = tx.Exec(`SET LOCAL ROLE ` + role)
+		require.NoError(t, err)
+		_, err = tx.Exec(`SELECT set_config('app.organization_id', $1, true)`, organizationID)
+		require.NoError(t, err)
+
+		var organizations []uuid.UUID
+		require.NoError(t, tx.Select(&organizations, `SELECT organization_id FROM subscriptions`))
+		return organizations
+	}
+
+	t.Run("роль без организации в сеансе не видит строк", func(t *testing.T) {
+		assert.Empty(t, visible(t, "analytics", ""))
+	})
+
+	t.Run("роль видит только организацию сеанса", func(t *testing.T) {
+		assert.Equal(t, []uuid.UUID{tenant.FromContext(owner)}, visible(t, "analytics", tenant.FromContext(owner).String()))
+	})
+
+	t.Run("владелец таблиц политиками не ограничивается", func(t *testing.T) {
+		assert.Len(t, visible(t, "service_owner", ""), 2)
+	})
+}
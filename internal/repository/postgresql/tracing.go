@@ -0,0 +1,40 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName - имя инструментирующей библиотеки span SQL-запросов
+const tracerName = "github.com/subscription-service/internal/repository/postgresql"
+
+// startSpan начинает span SQL-запроса statement, выполняемого методом
+// репозитория name
+func startSpan(ctx context.Context, name, statement string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBQueryText(statement)),
+	)
+}
+
+// recordError отмечает ошибку запроса в span. Отсутствие строк - ожидаемый
+// результат запроса, а не ошибка
+func recordError(span trace.Span, err error) {
+	if err == nil || errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// endSpan отмечает ошибку запроса и завершает span
+func endSpan(span trace.Span, err error) {
+	recordError(span, err)
+	span.End()
+}
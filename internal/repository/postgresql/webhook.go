@@ -0,0 +1,323 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/webhook"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// deliveryColumns - столбцы журнала доставок в порядке полей webhook.Delivery
+const deliveryColumns = `id, endpoint_id, organization_id, event_id, event_type, redelivery_of, payload, status, attempts,
+			next_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at`
+
+// WebhookRepository реализует интерфейс webhook.Repository
+type WebhookRepository struct {
+	db    *sqlx.DB
+	timer queryTimer
+}
+
+// NewWebhookRepository создает новый экземпляр репозитория webhook-уведомлений
+func NewWebhookRepository(db *sqlx.DB, opts ...Option) *WebhookRepository {
+	return &WebhookRepository{db: db, timer: newQueryTimer("webhooks", opts)}
+}
+
+// endpointRow - строка таблицы webhook_endpoints
+type endpointRow struct {
+	ID             uuid.UUID      `db:"id"`
+	OrganizationID uuid.UUID      `db:"organization_id"`
+	URL            string         `db:"url"`
+	Secret         string         `db:"secret"`
+	Events         pq.StringArray `db:"events"`
+	CreatedAt      time.Time      `db:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at"`
+}
+
+// endpointColumns - столбцы таблицы webhook_endpoints в порядке полей endpointRow
+const endpointColumns = `id, organization_id, url, secret, events, created_at, updated_at`
+
+// toEndpoint преобразует строку таблицы в доменную модель
+func (row endpointRow) toEndpoint() *webhook.Endpoint {
+	events := make([]event.Type, 0, len(row.Events))
+	for _, name := range row.Events {
+		events = append(events, event.Type(name))
+	}
+	return &webhook.Endpoint{
+		ID:             row.ID,
+		OrganizationID: row.OrganizationID,
+		URL:            row.URL,
+		Secret:         row.Secret,
+		Events:         events,
+		CreatedAt:      row.CreatedAt,
+		UpdatedAt:      row.UpdatedAt,
+	}
+}
+
+// CreateEndpoint создает нового получателя в организации из контекста
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *webhook.Endpoint) error {
+	defer r.timer.observe("CreateEndpoint", time.Now())
+
+	query := `INSERT INTO webhook_endpoints (` + endpointColumns + `)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	endpoint.ID = uuid.New()
+	endpoint.OrganizationID = tenant.FromContext(ctx)
+	endpoint.CreatedAt = time.Now()
+	endpoint.UpdatedAt = endpoint.CreatedAt
+
+	events := make(pq.StringArray, 0, len(endpoint.Events))
+	for _, eventType := range endpoint.Events {
+		events = append(events, string(eventType))
+	}
+
+	_, err := executorFrom(ctx, r.db).ExecContext(ctx, query,
+		endpoint.ID,
+		endpoint.OrganizationID,
+		endpoint.URL,
+		endpoint.Secret,
+		events,
+		endpoint.CreatedAt,
+		endpoint.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+
+	return nil
+}
+
+// GetEndpoint возвращает получателя по ID
+func (r *WebhookRepository) GetEndpoint(ctx context.Context, id uuid.UUID) (*webhook.Endpoint, error) {
+	defer r.timer.observe("GetEndpoint", time.Now())
+
+	query := `SELECT ` + endpointColumns + ` FROM webhook_endpoints WHERE id = $1 AND organization_id = $2`
+
+	var row endpointRow
+	if err := executorFrom(ctx, r.db).GetContext(ctx, &row, query, id, tenant.FromContext(ctx)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, webhook.ErrEndpointNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	return row.toEndpoint(), nil
+}
+
+// ListEndpoints возвращает всех получателей организации
+func (r *WebhookRepository) ListEndpoints(ctx context.Context) ([]*webhook.Endpoint, error) {
+	defer r.timer.observe("ListEndpoints", time.Now())
+
+	query := `SELECT ` + endpointColumns + ` FROM webhook_endpoints
+			WHERE organization_id = $1
+			ORDER BY created_at, id`
+	return r.selectEndpoints(ctx, query, tenant.FromContext(ctx))
+}
+
+// ListEndpointsForEvent возвращает получателей, подписанных на событие данного типа.
+// Получатели с пустым списком событий подписаны на все события
+func (r *WebhookRepository) ListEndpointsForEvent(ctx context.Context, eventType event.Type) ([]*webhook.Endpoint, error) {
+	defer r.timer.observe("ListEndpointsForEvent", time.Now())
+
+	query := `SELECT ` + endpointColumns + ` FROM webhook_endpoints
+			WHERE organization_id = $1 AND (cardinality(events) = 0 OR $2 = ANY(events))
+			ORDER BY created_at, id`
+	return r.selectEndpoints(ctx, query, tenant.FromContext(ctx), string(eventType))
+}
+
+// selectEndpoints выполняет запрос выборки получателей
+func (r *WebhookRepository) selectEndpoints(ctx context.Context, query string, args ...interface{}) ([]*webhook.Endpoint, error) {
+	var rows []endpointRow
+	if err := executorFrom(ctx, r.db).SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+
+	endpoints := make([]*webhook.Endpoint, 0, len(rows))
+	for _, row := range rows {
+		endpoints = append(endpoints, row.toEndpoint())
+	}
+	return endpoints, nil
+}
+
+// DeleteEndpoint удаляет получателя; журнал его доставок удаляется каскадно
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	defer r.timer.observe("DeleteEndpoint", time.Now())
+
+	result, err := executorFrom(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM webhook_endpoints WHERE id = $1 AND organization_id = $2`, id, tenant.FromContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return webhook.ErrEndpointNotFound
+	}
+
+	return nil
+}
+
+// CreateDelivery добавляет доставку в журнал организации из контекста.
+// Повторная первая доставка события тому же получателю не добавляется:
+// ее отсекает уникальный индекс idx_webhook_deliveries_event
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *webhook.Delivery) error {
+	defer r.timer.observe("CreateDelivery", time.Now())
+
+	query := `INSERT INTO webhook_deliveries (` + deliveryColumns + `)
+			VALUES (:id, :endpoint_id, :organization_id, :event_id, :event_type, :redelivery_of, :payload, :status, :attempts,
+			:next_attempt_at, :last_status_code, :last_error, :delivered_at, :created_at, :updated_at)
+			ON CONFLICT DO NOTHING`
+
+	delivery.ID = uuid.New()
+	delivery.OrganizationID = tenant.FromContext(ctx)
+	delivery.CreatedAt = time.Now()
+	delivery.UpdatedAt = delivery.CreatedAt
+
+	if _, err := executorFrom(ctx, r.db).NamedExecContext(ctx, query, delivery); err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// GetDelivery возвращает доставку по ID
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id uuid.UUID) (*webhook.Delivery, error) {
+	defer r.timer.observe("GetDelivery", time.Now())
+
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = $1 AND organization_id = $2`
+
+	var delivery webhook.Delivery
+	if err := executorFrom(ctx, r.db).GetContext(ctx, &delivery, query, id, tenant.FromContext(ctx)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, webhook.ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	return &delivery, nil
+}
+
+// UpdateDelivery сохраняет результат попытки доставки. Результат выбранной
+// доставки сохраняется, только если время следующей попытки не сдвинул
+// другой обработчик, выбравший ее после окончания аренды
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *webhook.Delivery) error {
+	defer r.timer.observe("UpdateDelivery", time.Now())
+
+	query := `UPDATE webhook_deliveries SET
+			status = $1, attempts = $2, next_attempt_at = $3,
+			last_status_code = $4, last_error = $5,
+			delivered_at = $6, updated_at = $7
+			WHERE id = $8`
+
+	delivery.UpdatedAt = time.Now()
+
+	args := []interface{}{
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		delivery.DeliveredAt,
+		delivery.UpdatedAt,
+		delivery.ID,
+	}
+	if delivery.ClaimedUntil != nil {
+		query += " AND next_attempt_at = $9"
+		args = append(args, *delivery.ClaimedUntil)
+	}
+
+	result, err := executorFrom(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		if delivery.ClaimedUntil != nil {
+			return webhook.ErrLeaseLost
+		}
+		return webhook.ErrDeliveryNotFound
+	}
+
+	return nil
+}
+
+// ListDeliveries возвращает журнал доставок получателя, начиная с последних
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, endpointID uuid.UUID, filter webhook.DeliveryFilter) ([]*webhook.Delivery, error) {
+	defer r.timer.observe("ListDeliveries", time.Now())
+
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+			WHERE endpoint_id = :endpoint_id AND organization_id = :organization_id`
+	params := map[string]interface{}{"endpoint_id": endpointID, "organization_id": tenant.FromContext(ctx)}
+
+	if filter.Status != nil {
+		query += " AND status = :status"
+		params["status"] = *filter.Status
+	}
+
+	query += " ORDER BY created_at DESC, id"
+
+	if filter.Limit > 0 {
+		query += " LIMIT :limit OFFSET :offset"
+		params["limit"] = filter.Limit
+		params["offset"] = filter.Offset
+	}
+
+	nstmt, err := executorFrom(ctx, r.db).PrepareNamedContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare named statement: %w", err)
+	}
+	defer nstmt.Close()
+
+	deliveries := []*webhook.Delivery{}
+	if err := nstmt.SelectContext(ctx, &deliveries, params); err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// ClaimDueDeliveries выбирает доставки, время попытки которых наступило, и
+// сдвигает их следующую попытку на lease. SKIP LOCKED позволяет нескольким
+// экземплярам сервиса разбирать очередь, не мешая друг другу
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*webhook.Delivery, error) {
+	defer r.timer.observe("ClaimDueDeliveries", time.Now())
+
+	query := `UPDATE webhook_deliveries SET next_attempt_at = $1, updated_at = $2
+			WHERE id IN (
+				SELECT id FROM webhook_deliveries
+				WHERE status = $3 AND next_attempt_at <= $2
+				ORDER BY next_attempt_at
+				LIMIT $4
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING ` + deliveryColumns
+
+	deliveries := []*webhook.Delivery{}
+	if err := executorFrom(ctx, r.db).SelectContext(ctx, &deliveries, query, now.Add(lease), now, webhook.DeliveryPending, limit); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	// Время из базы уже округлено до точности столбца и совпадет при проверке аренды
+	for _, delivery := range deliveries {
+		claimedUntil := *delivery.NextAttemptAt
+		delivery.ClaimedUntil = &claimedUntil
+	}
+
+	return deliveries, nil
+}
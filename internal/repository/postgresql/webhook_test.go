@@ -0,0 +1,155 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/webhook"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	db, cleanup := setupTestDatabase(t)
+	defer cleanup()
+
+	repo := NewWebhookRepository(db)
+	ctx := context.Background()
+
+	allEvents := &webhook.Endpoint{URL: "https://example.com/all", Secret: "secret-all-events"}
+	deletedOnly := &webhook.Endpoint{
+		URL:    "https://example.com/deleted",
+		Secret: "secret-deleted-only",
+		Events: []event.Type{event.SubscriptionDeleted},
+	}
+
+	// Тест регистрации получателей
+	t.Run("CreateEndpoint", func(t *testing.T) {
+		require.NoError(t, repo.CreateEndpoint(ctx, allEvents))
+		require.NoError(t, repo.CreateEndpoint(ctx, deletedOnly))
+		assert.NotEqual(t, uuid.Nil, allEvents.ID)
+
+		fetched, err := repo.GetEndpoint(ctx, deletedOnly.ID)
+		require.NoError(t, err)
+		assert.Equal(t, deletedOnly.URL, fetched.URL)
+		assert.Equal(t, deletedOnly.Secret, fetched.Secret)
+		assert.Equal(t, []event.Type{event.SubscriptionDeleted}, fetched.Events)
+	})
+
+	// Тест выбора получателей по типу события
+	t.Run("ListEndpointsForEvent", func(t *testing.T) {
+		endpoints, err := repo.ListEndpointsForEvent(ctx, event.SubscriptionCreated)
+		require.NoError(t, err)
+		require.Len(t, endpoints, 1)
+		assert.Equal(t, allEvents.ID, endpoints[0].ID)
+
+		endpoints, err = repo.ListEndpointsForEvent(ctx, event.SubscriptionDeleted)
+		require.NoError(t, err)
+		assert.Len(t, endpoints, 2)
+	})
+
+	// Тест очереди доставок
+	t.Run("ClaimDueDeliveries", func(t *testing.T) {
+		now := time.Now()
+		delivery := &webhook.Delivery{
+			EndpointID:    allEvents.ID,
+			EventID:       uuid.New(),
+			EventType:     event.SubscriptionCreated,
+			Payload:       json.RawMessage(`{"id":"1"}`),
+			Status:        webhook.DeliveryPending,
+			NextAttemptAt: &now,
+		}
+		require.NoError(t, repo.CreateDelivery(ctx, delivery))
+
+		claimed, err := repo.ClaimDueDeliveries(ctx, now, time.Minute, 10)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		assert.Equal(t, delivery.ID, claimed[0].ID)
+		assert.JSONEq(t, `{"id":"1"}`, string(claimed[0].Payload))
+
+		// Выбранная доставка скрыта до окончания аренды
+		stale := claimed[0]
+		claimed, err = repo.ClaimDueDeliveries(ctx, now, time.Minute, 10)
+		require.NoError(t, err)
+		assert.Empty(t, claimed)
+
+		// После окончания аренды доставку выбирает другой обработчик, и
+		// результат прежнего не сохраняется
+		claimed, err = repo.ClaimDueDeliveries(ctx, now.Add(2*time.Minute), time.Minute, 10)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		stale.Attempts = 1
+		assert.ErrorIs(t, repo.UpdateDelivery(ctx, stale), webhook.ErrLeaseLost)
+
+		// Результат попытки сохраняется в журнал
+		status := 200
+		delivery.Status = webhook.DeliverySucceeded
+		delivery.Attempts = 1
+		delivery.LastStatusCode = &status
+		delivery.NextAttemptAt = nil
+		require.NoError(t, repo.UpdateDelivery(ctx, delivery))
+
+		failed := webhook.DeliveryFailed
+		deliveries, err := repo.ListDeliveries(ctx, allEvents.ID, webhook.DeliveryFilter{Status: &failed})
+		require.NoError(t, err)
+		assert.Empty(t, deliveries)
+
+		deliveries, err = repo.ListDeliveries(ctx, allEvents.ID, webhook.DeliveryFilter{})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, webhook.DeliverySucceeded, deliveries[0].Status)
+		assert.Equal(t, 200, *deliveries[0].LastStatusCode)
+	})
+
+	// Тест защиты от повторной первой доставки события
+	t.Run("CreateDelivery_Duplicate", func(t *testing.T) {
+		eventID := uuid.New()
+		newDelivery := func() *webhook.Delivery {
+			now := time.Now()
+			return &webhook.Delivery{
+				EndpointID:    deletedOnly.ID,
+				EventID:       eventID,
+				EventType:     event.SubscriptionDeleted,
+				Payload:       json.RawMessage(`{"id":"1"}`),
+				Status:        webhook.DeliveryPending,
+				NextAttemptAt: &now,
+			}
+		}
+		first := newDelivery()
+		require.NoError(t, repo.CreateDelivery(ctx, first))
+		require.NoError(t, repo.CreateDelivery(ctx, newDelivery()))
+
+		deliveries, err := repo.ListDeliveries(ctx, deletedOnly.ID, webhook.DeliveryFilter{})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, first.ID, deliveries[0].ID)
+		assert.Nil(t, deliveries[0].RedeliveryOf)
+
+		// Ручной повтор того же события добавляется
+		redelivery := newDelivery()
+		redelivery.RedeliveryOf = &first.ID
+		require.NoError(t, repo.CreateDelivery(ctx, redelivery))
+
+		fetched, err := repo.GetDelivery(ctx, redelivery.ID)
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, *fetched.RedeliveryOf)
+	})
+
+	// Тест удаления получателя вместе с журналом
+	t.Run("DeleteEndpoint", func(t *testing.T) {
+		require.NoError(t, repo.DeleteEndpoint(ctx, allEvents.ID))
+
+		_, err := repo.GetEndpoint(ctx, allEvents.ID)
+		assert.ErrorIs(t, err, webhook.ErrEndpointNotFound)
+
+		deliveries, err := repo.ListDeliveries(ctx, allEvents.ID, webhook.DeliveryFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, deliveries)
+
+		assert.ErrorIs(t, repo.DeleteEndpoint(ctx, allEvents.ID), webhook.ErrEndpointNotFound)
+	})
+}
@@ -0,0 +1,567 @@
+// Package repotest содержит общие тесты поведения репозиториев. Каждая
+// реализация репозитория запускает их у себя, чтобы реализации не расходились
+package repotest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// activeCounter - подсчет действующих подписок для метрик; необязателен
+type activeCounter interface {
+	CountActive(ctx context.Context, at time.Time) (int, error)
+}
+
+// RunSubscriptionRepository проверяет поведение репозитория подписок. open
+// вызывается в каждом подтесте и должна возвращать пустой репозиторий
+func RunSubscriptionRepository(t *testing.T, open func(t *testing.T) subscription.Repository) {
+	t.Run("создание и получение", func(t *testing.T) {
+		repo, ctx := open(t), newOrganization()
+
+		endDate := month(2024, 12)
+		sub := newSubscription("Netflix", 400, uuid.New(), month(2024, 1), &endDate)
+		sub.OrganizationID = uuid.New()
+		require.NoError(t, repo.Create(ctx, sub))
+		assert.NotEqual(t, uuid.Nil, sub.ID)
+		assert.Equal(t, tenant.FromContext(ctx), sub.OrganizationID)
+		assert.False(t, sub.CreatedAt.IsZero())
+
+		got, err := repo.Get(ctx, sub.ID)
+		require.NoError(t, err)
+		assertSubscription(t, sub, got)
+		assert.Nil(t, got.DeletedAt)
+
+		_, err = repo.Get(ctx, uuid.New())
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+	})
+
+	t.Run("обновление", func(t *testing.T) {
+		repo, ctx := open(t), newOrganization()
+
+		sub := newSubscription("Netflix", 400, uuid.New(), month(2024, 1), nil)
+		require.NoError(t, repo.Create(ctx, sub))
+		userID := sub.UserID
+		createdAt := sub.CreatedAt
+
+		endDate := month(2024, 6)
+		sub.ServiceName, sub.Price, sub.StartDate, sub.EndDate = "Netflix Premium", 600, month(2024, 2), &endDate
+		// Пользователя подписки изменить нельзя
+		sub.UserID = uuid.New()
+		require.NoError(t, repo.Update(ctx, sub))
+		assert.False(t, sub.UpdatedAt.Before(createdAt))
+
+		got, err := repo.Get(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Netflix Premium", got.ServiceName)
+		assert.Equal(t, 600, got.Price)
+		assert.Equal(t, userID, got.UserID)
+		assertDate(t, month(2024, 2), got.StartDate)
+		require.NotNil(t, got.EndDate)
+		assertDate(t, endDate, *got.EndDate)
+
+		// Снятие даты окончания
+		got.EndDate = nil
+		require.NoError(t, repo.Update(ctx, got))
+		got, err = repo.Get(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.EndDate)
+
+		missing := newSubscription("Netflix", 400, userID, month(2024, 1), nil)
+		missing.ID = uuid.New()
+		assert.ErrorIs(t, repo.Update(ctx, missing), subscription.ErrSubscriptionNotFound)
+
+		require.NoError(t, repo.Delete(ctx, sub.ID))
+		assert.ErrorIs(t, repo.Update(ctx, got), subscription.ErrSubscriptionNotFound)
+	})
+
+	t.Run("удаление и восстановление", func(t *testing.T) {
+		repo, ctx := open(t), newOrganization()
+
+		sub := newSubscription("Netflix", 400, uuid.New(), month(2024, 1), nil)
+		require.NoError(t, repo.Create(ctx, sub))
+
+		_, err := repo.GetDeleted(ctx, sub.ID)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotDeleted)
+
+		require.NoError(t, repo.Delete(ctx, sub.ID))
+
+		// Удаленная подписка читается только через GetDeleted
+		deleted, err := repo.GetDeleted(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, sub.UserID, deleted.UserID)
+		assert.NotNil(t, deleted.DeletedAt)
+		_, err = repo.GetDeleted(ctx, uuid.New())
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+
+		_, err = repo.Get(ctx, sub.ID)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+		assert.ErrorIs(t, repo.Delete(ctx, sub.ID), subscription.ErrSubscriptionNotFound)
+		assert.ErrorIs(t, repo.Delete(ctx, uuid.New()), subscription.ErrSubscriptionNotFound)
+
+		// Удаленная подписка видна только с IncludeDeleted
+		subs, err := repo.List(ctx, subscription.ListFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, subs)
+
+		subs, err = repo.List(ctx, subscription.ListFilter{IncludeDeleted: true})
+		require.NoError(t, err)
+		require.Len(t, subs, 1)
+		assert.NotNil(t, subs[0].DeletedAt)
+
+		require.NoError(t, repo.Restore(ctx, sub.ID))
+		got, err := repo.Get(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.DeletedAt)
+
+		assert.ErrorIs(t, repo.Restore(ctx, sub.ID), subscription.ErrSubscriptionNotDeleted)
+		assert.ErrorIs(t, repo.Restore(ctx, uuid.New()), subscription.ErrSubscriptionNotFound)
+	})
+
+	t.Run("очистка удаленных", func(t *testing.T) {
+		repo, ctx := open(t), newOrganization()
+		other := newOrganization()
+
+		kept := newSubscription("Netflix", 400, uuid.New(), month(2024, 1), nil)
+		require.NoError(t, repo.Create(ctx, kept))
+
+		var deleted []uuid.UUID
+		for _, c := range []context.Context{ctx, ctx, other} {
+			sub := newSubscription("Spotify", 200, uuid.New(), month(2024, 1), nil)
+			require.NoError(t, repo.Create(c, sub))
+			require.NoError(t, repo.Delete(c, sub.ID))
+			deleted = append(deleted, sub.ID)
+		}
+		deletedAt := time.Now()
+
+		// Подписки удалены позже границы и не очищаются
+		purged, err := repo.Purge(ctx, deletedAt.Add(-time.Hour), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 0, purged)
+
+		// Очистка выполняется во всех организациях пачками не больше limit
+		purged, err = repo.Purge(ctx, deletedAt.Add(time.Minute), 2)
+		require.NoError(t, err)
+		assert.Equal(t, 2, purged)
+		purged, err = repo.Purge(ctx, deletedAt.Add(time.Minute), 2)
+		require.NoError(t, err)
+		assert.Equal(t, 1, purged)
+
+		assert.ErrorIs(t, repo.Restore(ctx, deleted[0]), subscription.ErrSubscriptionNotFound)
+		assert.ErrorIs(t, repo.Restore(other, deleted[2]), subscription.ErrSubscriptionNotFound)
+
+		subs, err := repo.List(ctx, subscription.ListFilter{IncludeDeleted: true})
+		require.NoError(t, err)
+		require.Len(t, subs, 1)
+		assert.Equal(t, kept.ID, subs[0].ID)
+	})
+
+	t.Run("выборка: фильтры, порядок и страницы", func(t *testing.T) {
+		repo, ctx := open(t), newOrganization()
+
+		alice, bob := uuid.New(), uuid.New()
+		subs := []*subscription.Subscription{
+			newSubscription("Netflix", 400, alice, month(2024, 1), nil),
+			newSubscription("Spotify", 200, alice, month(2024, 2), nil),
+			newSubscription("Netflix", 500, bob, month(2024, 3), nil),
+		}
+		for _, sub := range subs {
+			require.NoError(t, repo.Create(ctx, sub))
+			// Разное время создания задает порядок выборки
+			time.Sleep(time.Millisecond)
+		}
+
+		all, err := repo.List(ctx, subscription.ListFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, ids(subs), ids(all))
+
+		found, err := repo.List(ctx, subscription.ListFilter{UserID: &alice})
+		require.NoError(t, err)
+		assert.Equal(t, ids(subs[:2]), ids(found))
+
+		netflix := "Netflix"
+		found, err = repo.List(ctx, subscription.ListFilter{ServiceName: &netflix})
+		require.NoError(t, err)
+		assert.Equal(t, ids([]*subscription.Subscription{subs[0], subs[2]}), ids(found))
+
+		found, err = repo.List(ctx, subscription.ListFilter{UserID: &alice, ServiceName: &netflix})
+		require.NoError(t, err)
+		assert.Equal(t, ids(subs[:1]), ids(found))
+
+		// Пустое название сервиса не ограничивает выборку
+		empty := ""
+		found, err = repo.List(ctx, subscription.ListFilter{ServiceName: &empty})
+		require.NoError(t, err)
+		assert.Len(t, found, 3)
+
+		found, err = repo.List(ctx, subscription.ListFilter{Limit: 2, Offset: 1})
+		require.NoError(t, err)
+		assert.Equal(t, ids(subs[1:]), ids(found))
+
+		found, err = repo.List(ctx, subscription.ListFilter{Limit: 2, Offset: 5})
+		require.NoError(t, err)
+		assert.Empty(t, found)
+
+		var streamed []*subscription.Subscription
+		err = repo.Stream(ctx, subscription.ListFilter{UserID: &alice}, func(sub *subscription.Subscription) error {
+			streamed = append(streamed, sub)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, ids(subs[:2]), ids(streamed))
+		assertSubscription(t, subs[0], streamed[0])
+
+		// Ошибка fn прерывает чтение и возвращается без изменений
+		stop := errors.New("stop")
+		calls := 0
+		err = repo.Stream(ctx, subscription.ListFilter{}, func(*subscription.Subscription) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("расчет стоимости", func(t *testing.T) {
+		repo, ctx := open(t), newOrganization()
+
+		alice, bob := uuid.New(), uuid.New()
+		january, march, december := month(2023, 1), month(2023, 3), month(2022, 12)
+		subs := []*subscription.Subscription{
+			newSubscription("Netflix", 100, alice, january, &march),
+			newSubscription("Spotify", 200, alice, month(2023, 6), nil),
+			newSubscription("Netflix", 300, bob, december, &december),
+		}
+		for _, sub := range subs {
+			require.NoError(t, repo.Create(ctx, sub))
+		}
+
+		cost := func(filter subscription.SubscriptionFilter) int {
+			t.Helper()
+			total, err := repo.CalculateTotalCost(ctx, filter)
+			require.NoError(t, err)
+			return total
+		}
+
+		// Подписка учитывается, если действует хотя бы в части периода;
+		// границы периода и подписки включаются
+		assert.Equal(t, 300, cost(subscription.SubscriptionFilter{StartPeriod: march, EndPeriod: month(2023, 6)}))
+		assert.Equal(t, 0, cost(subscription.SubscriptionFilter{StartPeriod: month(2023, 4), EndPeriod: month(2023, 5)}))
+		assert.Equal(t, 600, cost(subscription.SubscriptionFilter{StartPeriod: december, EndPeriod: month(2023, 12)}))
+		assert.Equal(t, 200, cost(subscription.SubscriptionFilter{StartPeriod: month(2030, 1), EndPeriod: month(2030, 12)}))
+
+		netflix := "Netflix"
+		assert.Equal(t, 100, cost(subscription.SubscriptionFilter{UserID: &alice, ServiceName: &netflix, StartPeriod: december, EndPeriod: month(2023, 12)}))
+		assert.Equal(t, 400, cost(subscription.SubscriptionFilter{ServiceName: &netflix, StartPeriod: december, EndPeriod: month(2023, 12)}))
+
+		// Удаленные подписки учитываются только с IncludeDeleted
+		require.NoError(t, repo.Delete(ctx, subs[2].ID))
+		assert.Equal(t, 300, cost(subscription.SubscriptionFilter{StartPeriod: december, EndPeriod: month(2023, 12)}))
+		assert.Equal(t, 600, cost(subscription.SubscriptionFilter{StartPeriod: december, EndPeriod: month(2023, 12), IncludeDeleted: true}))
+
+		// Пустой результат - 0, а не ошибка
+		assert.Equal(t, 0, cost(subscription.SubscriptionFilter{UserID: ptr(uuid.New()), StartPeriod: december, EndPeriod: month(2023, 12)}))
+	})
+
+	t.Run("сводка по сервисам", func(t *testing.T) {
+		repo, ctx := open(t), newOrganization()
+
+		alice, bob := uuid.New(), uuid.New()
+		january, march, december := month(2023, 1), month(2023, 3), month(2022, 12)
+		subs := []*subscription.Subscription{
+			newSubscription("Spotify", 200, alice, month(2023, 6), nil),
+			newSubscription("Netflix", 100, alice, january, &march),
+			newSubscription("Netflix", 300, bob, december, &december),
+			newSubscription("Netflix", 500, bob, january, nil),
+		}
+		for _, sub := range subs {
+			require.NoError(t, repo.Create(ctx, sub))
+		}
+		require.NoError(t, repo.Create(newOrganization(), newSubscription("Netflix", 1000, alice, january, nil)))
+
+		summarize := func(filter subscription.SummaryFilter) []subscription.ServiceSummary {
+			t.Helper()
+			summaries, err := repo.SummarizeByService(ctx, filter)
+			require.NoError(t, err)
+			result := make([]subscription.ServiceSummary, 0, len(summaries))
+			for _, summary := range summaries {
+				result = append(result, *summary)
+			}
+			return result
+		}
+
+		// Без периода учитываются все подписки организации, сервисы - по названию
+		assert.Equal(t, []subscription.ServiceSummary{
+			{ServiceName: "Netflix", SubscriptionCount: 3, UserCount: 2, MinPrice: 100, MaxPrice: 500, TotalCost: 900},
+			{ServiceName: "Spotify", SubscriptionCount: 1, UserCount: 1, MinPrice: 200, MaxPrice: 200, TotalCost: 200},
+		}, summarize(subscription.SummaryFilter{}))
+
+		// Период отбирает подписки так же, как расчет стоимости
+		assert.Equal(t, []subscription.ServiceSummary{
+			{ServiceName: "Netflix", SubscriptionCount: 2, UserCount: 2, MinPrice: 100, MaxPrice: 500, TotalCost: 600},
+		}, summarize(subscription.SummaryFilter{StartPeriod: march, EndPeriod: month(2023, 5)}))
+
+		assert.Equal(t, []subscription.ServiceSummary{
+			{ServiceName: "Netflix", SubscriptionCount: 1, UserCount: 1, MinPrice: 100, MaxPrice: 100, TotalCost: 100},
+			{ServiceName: "Spotify", SubscriptionCount: 1, UserCount: 1, MinPrice: 200, MaxPrice: 200, TotalCost: 200},
+		}, summarize(subscription.SummaryFilter{UserID: &alice}))
+
+		// Удаленные подписки в сводку не попадают
+		require.NoError(t, repo.Delete(ctx, subs[0].ID))
+		assert.Equal(t, []subscription.ServiceSummary{
+			{ServiceName: "Netflix", SubscriptionCount: 1, UserCount: 1, MinPrice: 100, MaxPrice: 100, TotalCost: 100},
+		}, summarize(subscription.SummaryFilter{UserID: &alice}))
+
+		assert.Empty(t, summarize(subscription.SummaryFilter{UserID: ptr(uuid.New())}))
+	})
+
+	t.Run("запросы на момент времени", func(t *testing.T) {
+		repo, ctx := open(t), newOrganization()
+
+		beforeCreate := tick()
+		userID := uuid.New()
+		sub := newSubscription("Netflix", 100, userID, month(2023, 7), nil)
+		require.NoError(t, repo.Create(ctx, sub))
+		created := tick()
+
+		sub.Price = 150
+		require.NoError(t, repo.Update(ctx, sub))
+		updated := tick()
+
+		require.NoError(t, repo.Delete(ctx, sub.ID))
+		deleted := tick()
+
+		_, err := repo.GetAsOf(ctx, sub.ID, beforeCreate)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+
+		original, err := repo.GetAsOf(ctx, sub.ID, created)
+		require.NoError(t, err)
+		assert.Equal(t, 100, original.Price)
+
+		current, err := repo.GetAsOf(ctx, sub.ID, updated)
+		require.NoError(t, err)
+		assert.Equal(t, 150, current.Price)
+
+		_, err = repo.GetAsOf(ctx, sub.ID, deleted)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+
+		subs, err := repo.List(ctx, subscription.ListFilter{UserID: &userID, AsOf: &created})
+		require.NoError(t, err)
+		require.Len(t, subs, 1)
+		assert.Equal(t, 100, subs[0].Price)
+
+		subs, err = repo.List(ctx, subscription.ListFilter{AsOf: &deleted})
+		require.NoError(t, err)
+		assert.Empty(t, subs)
+
+		subs, err = repo.List(ctx, subscription.ListFilter{AsOf: &deleted, IncludeDeleted: true})
+		require.NoError(t, err)
+		require.Len(t, subs, 1)
+		assert.NotNil(t, subs[0].DeletedAt)
+
+		subs, err = repo.List(ctx, subscription.ListFilter{AsOf: &beforeCreate})
+		require.NoError(t, err)
+		assert.Empty(t, subs)
+
+		period := subscription.SubscriptionFilter{StartPeriod: month(2023, 1), EndPeriod: month(2023, 12)}
+		for asOf, want := range map[*time.Time]int{&beforeCreate: 0, &created: 100, &updated: 150, &deleted: 0} {
+			filter := period
+			filter.AsOf = asOf
+			total, err := repo.CalculateTotalCost(ctx, filter)
+			require.NoError(t, err)
+			assert.Equal(t, want, total, asOf)
+		}
+
+		// История сохраняется после окончательной очистки
+		purged, err := repo.Purge(ctx, time.Now().Add(time.Minute), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, purged)
+		original, err = repo.GetAsOf(ctx, sub.ID, created)
+		require.NoError(t, err)
+		assert.Equal(t, 100, original.Price)
+	})
+
+	t.Run("организации изолированы", func(t *testing.T) {
+		repo, owner := open(t), newOrganization()
+		intruder := newOrganization()
+
+		userID := uuid.New()
+		sub := newSubscription("Netflix", 400, userID, month(2023, 7), nil)
+		require.NoError(t, repo.Create(owner, sub))
+		created := tick()
+		require.NoError(t, repo.Create(intruder, newSubscription("Netflix", 1000, userID, month(2023, 7), nil)))
+
+		_, err := repo.Get(intruder, sub.ID)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+		_, err = repo.GetAsOf(intruder, sub.ID, created)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+
+		changed := *sub
+		changed.Price = 1
+		assert.ErrorIs(t, repo.Update(intruder, &changed), subscription.ErrSubscriptionNotFound)
+		assert.ErrorIs(t, repo.Delete(intruder, sub.ID), subscription.ErrSubscriptionNotFound)
+		require.NoError(t, repo.Delete(owner, sub.ID))
+		_, err = repo.GetDeleted(intruder, sub.ID)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+		assert.ErrorIs(t, repo.Restore(intruder, sub.ID), subscription.ErrSubscriptionNotFound)
+		require.NoError(t, repo.Restore(owner, sub.ID))
+
+		for _, asOf := range []*time.Time{nil, &created} {
+			subs, err := repo.List(owner, subscription.ListFilter{UserID: &userID, IncludeDeleted: true, AsOf: asOf})
+			require.NoError(t, err)
+			assert.Equal(t, []uuid.UUID{sub.ID}, ids(subs))
+
+			total, err := repo.CalculateTotalCost(owner, subscription.SubscriptionFilter{
+				UserID: &userID, StartPeriod: month(2023, 1), EndPeriod: month(2023, 12), AsOf: asOf,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, 400, total)
+		}
+
+		got, err := repo.Get(owner, sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 400, got.Price)
+	})
+
+	t.Run("параллельные изменения", func(t *testing.T) {
+		repo, ctx := open(t), newOrganization()
+
+		const workers, perWorker = 8, 10
+		var wg sync.WaitGroup
+		errs := make(chan error, workers*perWorker)
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < perWorker; i++ {
+					sub := newSubscription("Netflix", 100, uuid.New(), month(2024, 1), nil)
+					if err := repo.Create(ctx, sub); err != nil {
+						errs <- err
+						return
+					}
+					sub.Price = 200
+					if err := repo.Update(ctx, sub); err != nil {
+						errs <- err
+						return
+					}
+					if _, err := repo.List(ctx, subscription.ListFilter{Limit: 5}); err != nil {
+						errs <- err
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+
+		total, err := repo.CalculateTotalCost(ctx, subscription.SubscriptionFilter{StartPeriod: month(2024, 1), EndPeriod: month(2024, 1)})
+		require.NoError(t, err)
+		assert.Equal(t, workers*perWorker*200, total)
+	})
+
+	t.Run("число действующих подписок", func(t *testing.T) {
+		repo := open(t)
+		counter, ok := repo.(activeCounter)
+		if !ok {
+			t.Skip("репозиторий не считает действующие подписки")
+		}
+
+		march := month(2023, 3)
+		subs := []struct {
+			ctx context.Context
+			sub *subscription.Subscription
+		}{
+			{newOrganization(), newSubscription("Netflix", 100, uuid.New(), month(2023, 1), &march)},
+			{newOrganization(), newSubscription("Spotify", 200, uuid.New(), month(2023, 2), nil)},
+			{newOrganization(), newSubscription("Yandex Plus", 300, uuid.New(), month(2023, 2), nil)},
+		}
+		for _, s := range subs {
+			require.NoError(t, repo.Create(s.ctx, s.sub))
+		}
+		require.NoError(t, repo.Delete(subs[2].ctx, subs[2].sub.ID))
+
+		// Подсчет выполняется во всех организациях без удаленных подписок
+		for at, want := range map[time.Time]int{month(2022, 12): 0, month(2023, 1): 1, march: 2, month(2023, 4): 1} {
+			count, err := counter.CountActive(context.Background(), at)
+			require.NoError(t, err)
+			assert.Equal(t, want, count, at)
+		}
+	})
+}
+
+// newOrganization возвращает контекст новой организации
+func newOrganization() context.Context {
+	return tenant.WithOrganization(context.Background(), uuid.New())
+}
+
+func newSubscription(serviceName string, price int, userID uuid.UUID, start time.Time, end *time.Time) *subscription.Subscription {
+	return &subscription.Subscription{
+		ServiceName: serviceName,
+		Price:       price,
+		UserID:      userID,
+		StartDate:   start,
+		EndDate:     end,
+	}
+}
+
+// month возвращает первое число месяца в UTC, как даты подписок
+func month(year int, m time.Month) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// tick возвращает текущее время, отделенное от соседних изменений, чтобы
+// запросы на момент времени не зависели от точности часов хранилища
+func tick() time.Time {
+	time.Sleep(10 * time.Millisecond)
+	now := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	return now
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// ids возвращает ID подписок в порядке выборки
+func ids(subs []*subscription.Subscription) []uuid.UUID {
+	result := make([]uuid.UUID, len(subs))
+	for i, sub := range subs {
+		result[i] = sub.ID
+	}
+	return result
+}
+
+// assertSubscription сравнивает сохраненные поля подписки. Даты сравниваются
+// как моменты времени: хранилища возвращают их в разных часовых поясах
+func assertSubscription(t *testing.T, want, got *subscription.Subscription) {
+	t.Helper()
+	assert.Equal(t, want.ID, got.ID)
+	assert.Equal(t, want.OrganizationID, got.OrganizationID)
+	assert.Equal(t, want.ServiceName, got.ServiceName)
+	assert.Equal(t, want.Price, got.Price)
+	assert.Equal(t, want.UserID, got.UserID)
+	assertDate(t, want.StartDate, got.StartDate)
+	if want.EndDate == nil {
+		assert.Nil(t, got.EndDate)
+	} else if assert.NotNil(t, got.EndDate) {
+		assertDate(t, *want.EndDate, *got.EndDate)
+	}
+	assert.WithinDuration(t, want.CreatedAt, got.CreatedAt, time.Millisecond)
+	assert.WithinDuration(t, want.UpdatedAt, got.UpdatedAt, time.Millisecond)
+}
+
+func assertDate(t *testing.T, want, got time.Time) {
+	t.Helper()
+	assert.True(t, want.Equal(got), "want %s, got %s", want, got)
+}
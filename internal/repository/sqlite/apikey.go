@@ -0,0 +1,173 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/domain/apikey"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// apiKeyColumns - столбцы таблицы api_keys в порядке полей apiKeyRow
+const apiKeyColumns = `id, organization_id, name, prefix, hash, scopes, created_at, last_used_at, revoked_at`
+
+// APIKeyRepository реализует интерфейс apikey.Repository поверх SQLite
+type APIKeyRepository struct {
+	db *sqlx.DB
+}
+
+var _ apikey.Repository = (*APIKeyRepository)(nil)
+
+// NewAPIKeyRepository создает репозиторий ключей API в базе SQLite
+func NewAPIKeyRepository(db *sqlx.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// apiKeyRow - строка ключа в формате хранения SQLite
+type apiKeyRow struct {
+	ID             uuid.UUID      `db:"id"`
+	OrganizationID uuid.UUID      `db:"organization_id"`
+	Name           string         `db:"name"`
+	Prefix         string         `db:"prefix"`
+	Hash           []byte         `db:"hash"`
+	Scopes         string         `db:"scopes"`
+	CreatedAt      string         `db:"created_at"`
+	LastUsedAt     sql.NullString `db:"last_used_at"`
+	RevokedAt      sql.NullString `db:"revoked_at"`
+}
+
+// key преобразует строку в ключ
+func (row *apiKeyRow) key() (*apikey.Key, error) {
+	key := &apikey.Key{
+		ID:             row.ID,
+		OrganizationID: row.OrganizationID,
+		Name:           row.Name,
+		Prefix:         row.Prefix,
+		Hash:           row.Hash,
+	}
+
+	var err error
+	if err = json.Unmarshal([]byte(row.Scopes), &key.Scopes); err != nil {
+		return nil, fmt.Errorf("invalid scopes of api key %s: %w", row.ID, err)
+	}
+	if key.CreatedAt, err = time.Parse(timestampFormat, row.CreatedAt); err != nil {
+		return nil, fmt.Errorf("invalid created_at of api key %s: %w", row.ID, err)
+	}
+	if key.LastUsedAt, err = parseNull(timestampFormat, row.LastUsedAt); err != nil {
+		return nil, fmt.Errorf("invalid last_used_at of api key %s: %w", row.ID, err)
+	}
+	if key.RevokedAt, err = parseNull(timestampFormat, row.RevokedAt); err != nil {
+		return nil, fmt.Errorf("invalid revoked_at of api key %s: %w", row.ID, err)
+	}
+	return key, nil
+}
+
+// Create сохраняет новый ключ в организации из контекста
+func (r *APIKeyRepository) Create(ctx context.Context, key *apikey.Key) error {
+	key.ID = uuid.New()
+	key.OrganizationID = tenant.FromContext(ctx)
+	key.CreatedAt = time.Now()
+
+	scopes := key.Scopes
+	if scopes == nil {
+		scopes = []auth.Scope{}
+	}
+	encoded, err := json.Marshal(scopes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal api key scopes: %w", err)
+	}
+
+	query := `INSERT INTO api_keys (id, organization_id, name, prefix, hash, scopes, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err = executorFrom(ctx, r.db).ExecContext(ctx, query,
+		key.ID,
+		key.OrganizationID,
+		key.Name,
+		key.Prefix,
+		key.Hash,
+		string(encoded),
+		formatTimestamp(key.CreatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return nil
+}
+
+// GetByPrefix возвращает ключ по префиксу токена
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*apikey.Key, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE prefix = ?`
+
+	var row apiKeyRow
+	if err := executorFrom(ctx, r.db).GetContext(ctx, &row, query, prefix); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apikey.ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return row.key()
+}
+
+// List возвращает ключи организации в порядке выпуска
+func (r *APIKeyRepository) List(ctx context.Context) ([]*apikey.Key, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE organization_id = ? ORDER BY created_at, id`
+
+	var rows []apiKeyRow
+	if err := executorFrom(ctx, r.db).SelectContext(ctx, &rows, query, tenant.FromContext(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	keys := make([]*apikey.Key, 0, len(rows))
+	for i := range rows {
+		key, err := rows[i].key()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list api keys: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Revoke отзывает ключ, сохраняя время первого отзыва
+func (r *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?)
+			WHERE id = ? AND organization_id = ?`
+
+	result, err := executorFrom(ctx, r.db).ExecContext(ctx, query, formatTimestamp(time.Now()), id, tenant.FromContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return apikey.ErrKeyNotFound
+	}
+
+	return nil
+}
+
+// TouchLastUsed обновляет время последнего использования ключа. Более
+// позднее время, записанное параллельным запросом, не перезаписывается
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `UPDATE api_keys SET last_used_at = ?1
+			WHERE id = ?2 AND (last_used_at IS NULL OR last_used_at < ?1)`
+
+	if _, err := executorFrom(ctx, r.db).ExecContext(ctx, query, formatTimestamp(at), id); err != nil {
+		return fmt.Errorf("failed to update api key last used time: %w", err)
+	}
+
+	return nil
+}
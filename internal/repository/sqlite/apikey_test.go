@@ -0,0 +1,67 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/domain/apikey"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	db := openTestDatabase(t)
+
+	repo := NewAPIKeyRepository(db)
+	ctx := context.Background()
+
+	key := &apikey.Key{
+		Name:   "reports",
+		Prefix: "0a1b2c3d4e5f",
+		Hash:   []byte{1, 2, 3},
+		Scopes: []auth.Scope{auth.ScopeSubscriptionsRead, auth.ScopeReportsRead},
+	}
+
+	t.Run("выпуск и поиск по префиксу", func(t *testing.T) {
+		require.NoError(t, repo.Create(ctx, key))
+		assert.NotEqual(t, uuid.Nil, key.ID)
+
+		fetched, err := repo.GetByPrefix(ctx, key.Prefix)
+		require.NoError(t, err)
+		assert.Equal(t, key.Hash, fetched.Hash)
+		assert.Equal(t, key.Scopes, fetched.Scopes)
+		assert.Nil(t, fetched.LastUsedAt)
+
+		_, err = repo.GetByPrefix(ctx, "unknown")
+		assert.ErrorIs(t, err, apikey.ErrKeyNotFound)
+	})
+
+	t.Run("время последнего использования", func(t *testing.T) {
+		later := time.Now().Truncate(time.Microsecond)
+		require.NoError(t, repo.TouchLastUsed(ctx, key.ID, later))
+		// Более раннее время не перезаписывает более позднее
+		require.NoError(t, repo.TouchLastUsed(ctx, key.ID, later.Add(-time.Hour)))
+
+		fetched, err := repo.GetByPrefix(ctx, key.Prefix)
+		require.NoError(t, err)
+		require.NotNil(t, fetched.LastUsedAt)
+		assert.True(t, later.Equal(*fetched.LastUsedAt))
+	})
+
+	t.Run("отзыв ключа", func(t *testing.T) {
+		require.NoError(t, repo.Revoke(ctx, key.ID))
+
+		fetched, err := repo.GetByPrefix(ctx, key.Prefix)
+		require.NoError(t, err)
+		assert.True(t, fetched.Revoked())
+
+		keys, err := repo.List(ctx)
+		require.NoError(t, err)
+		assert.Len(t, keys, 1)
+
+		assert.ErrorIs(t, repo.Revoke(ctx, uuid.New()), apikey.ErrKeyNotFound)
+	})
+}
@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/subscription-service/internal/domain/audit"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// AuditRepository реализует интерфейс audit.Repository поверх SQLite
+type AuditRepository struct {
+	db *sqlx.DB
+}
+
+var _ audit.Repository = (*AuditRepository)(nil)
+
+// NewAuditRepository создает репозиторий журнала аудита в базе SQLite
+func NewAuditRepository(db *sqlx.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// auditRow - строка журнала аудита в формате хранения SQLite
+type auditRow struct {
+	ID             uuid.UUID      `db:"id"`
+	SubscriptionID uuid.UUID      `db:"subscription_id"`
+	Operation      string         `db:"operation"`
+	Actor          string         `db:"actor"`
+	RequestID      string         `db:"request_id"`
+	Before         sql.NullString `db:"before"`
+	After          sql.NullString `db:"after"`
+	Changes        string         `db:"changes"`
+	CreatedAt      string         `db:"created_at"`
+}
+
+// entry преобразует строку в запись журнала
+func (row *auditRow) entry() (*audit.Entry, error) {
+	entry := &audit.Entry{
+		ID:             row.ID,
+		SubscriptionID: row.SubscriptionID,
+		Operation:      audit.Operation(row.Operation),
+		Actor:          row.Actor,
+		RequestID:      row.RequestID,
+	}
+	if row.Before.Valid {
+		entry.Before = json.RawMessage(row.Before.String)
+	}
+	if row.After.Valid {
+		entry.After = json.RawMessage(row.After.String)
+	}
+
+	var err error
+	if err = json.Unmarshal([]byte(row.Changes), &entry.Changes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal audit changes: %w", err)
+	}
+	if entry.CreatedAt, err = time.Parse(timestampFormat, row.CreatedAt); err != nil {
+		return nil, fmt.Errorf("invalid created_at of audit entry %s: %w", row.ID, err)
+	}
+	return entry, nil
+}
+
+// Add добавляет запись в журнал организации из контекста в транзакции из
+// контекста, если она есть
+func (r *AuditRepository) Add(ctx context.Context, entry *audit.Entry) error {
+	entry.ID = uuid.New()
+	entry.CreatedAt = time.Now()
+
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit changes: %w", err)
+	}
+
+	query := `INSERT INTO subscription_audit
+			(id, organization_id, subscription_id, operation, actor, request_id, before, after, changes, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = executorFrom(ctx, r.db).ExecContext(ctx, query,
+		entry.ID,
+		tenant.FromContext(ctx),
+		entry.SubscriptionID,
+		string(entry.Operation),
+		entry.Actor,
+		entry.RequestID,
+		nullableJSON(entry.Before),
+		nullableJSON(entry.After),
+		string(changes),
+		formatTimestamp(entry.CreatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// List возвращает записи журнала организации из контекста, удовлетворяющие
+// фильтру, начиная с последних
+func (r *AuditRepository) List(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	query := `SELECT id, subscription_id, operation, actor, request_id, before, after, changes, created_at
+			FROM subscription_audit WHERE organization_id = ?`
+	args := []interface{}{tenant.FromContext(ctx)}
+
+	if filter.SubscriptionID != nil {
+		query += " AND subscription_id = ?"
+		args = append(args, *filter.SubscriptionID)
+	}
+
+	if filter.Actor != "" {
+		query += " AND actor = ?"
+		args = append(args, filter.Actor)
+	}
+
+	if filter.Operation != nil {
+		query += " AND operation = ?"
+		args = append(args, string(*filter.Operation))
+	}
+
+	if filter.From != nil {
+		query += " AND created_at >= ?"
+		args = append(args, formatTimestamp(*filter.From))
+	}
+
+	if filter.To != nil {
+		query += " AND created_at < ?"
+		args = append(args, formatTimestamp(*filter.To))
+	}
+
+	query += " ORDER BY created_at DESC, id"
+
+	if filter.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	var rows []auditRow
+	if err := executorFrom(ctx, r.db).SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+
+	entries := make([]*audit.Entry, 0, len(rows))
+	for i := range rows {
+		entry, err := rows[i].entry()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// nullableJSON возвращает NULL для пустого значения JSON
+func nullableJSON(data json.RawMessage) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
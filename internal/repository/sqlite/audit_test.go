@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/actor"
+	"github.com/subscription-service/internal/domain/audit"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/usecase"
+)
+
+func TestAuditRepository(t *testing.T) {
+	db := openTestDatabase(t)
+
+	repo := NewAuditRepository(db)
+	subscriptions := NewSubscriptionRepository(db)
+	service := usecase.NewSubscriptionService(subscriptions,
+		usecase.WithTransactionManager(NewTxManager(db)),
+		usecase.WithAuditLog(repo))
+	ctx := actor.WithActor(context.Background(), "alice@example.com")
+
+	start := time.Now().Add(-time.Second)
+	sub, err := service.Create(ctx, subscription.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       400,
+		UserID:      uuid.New(),
+		StartDate:   "07-2023",
+	})
+	require.NoError(t, err)
+
+	price := 500
+	_, err = service.Update(actor.WithActor(context.Background(), "bob@example.com"), sub.ID, subscription.UpdateSubscriptionRequest{Price: &price})
+	require.NoError(t, err)
+	require.NoError(t, service.Delete(ctx, sub.ID))
+
+	// Записи журнала переживают удаление подписки
+	t.Run("история подписки", func(t *testing.T) {
+		entries, err := repo.List(ctx, audit.Filter{SubscriptionID: &sub.ID})
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+
+		assert.Equal(t, audit.OperationDelete, entries[0].Operation)
+		assert.Nil(t, entries[0].After)
+
+		update := entries[1]
+		assert.Equal(t, audit.OperationUpdate, update.Operation)
+		assert.Equal(t, "bob@example.com", update.Actor)
+		require.Contains(t, update.Changes, "price")
+		assert.JSONEq(t, "400", string(update.Changes["price"].Before))
+		assert.JSONEq(t, "500", string(update.Changes["price"].After))
+
+		assert.Equal(t, audit.OperationCreate, entries[2].Operation)
+		assert.Nil(t, entries[2].Before)
+	})
+
+	t.Run("фильтры журнала", func(t *testing.T) {
+		operation := audit.OperationUpdate
+		entries, err := repo.List(ctx, audit.Filter{Operation: &operation, From: &start})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		entries, err = repo.List(ctx, audit.Filter{Actor: "alice@example.com", Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, audit.OperationDelete, entries[0].Operation)
+
+		entries, err = repo.List(ctx, audit.Filter{To: &start})
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("журнал только пополняется", func(t *testing.T) {
+		_, err := db.Exec(`UPDATE subscription_audit SET actor = 'mallory@example.com'`)
+		assert.ErrorContains(t, err, "append-only")
+		_, err = db.Exec(`DELETE FROM subscription_audit`)
+		assert.ErrorContains(t, err, "append-only")
+	})
+}
@@ -0,0 +1,144 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/subscription-service/internal/domain/member"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// memberColumns - столбцы таблицы organization_members
+const memberColumns = `organization_id, user_id, role, created_at, updated_at`
+
+// MemberRepository реализует интерфейс member.Repository поверх SQLite
+type MemberRepository struct {
+	db *sqlx.DB
+}
+
+var _ member.Repository = (*MemberRepository)(nil)
+
+// NewMemberRepository создает репозиторий участников в базе SQLite
+func NewMemberRepository(db *sqlx.DB) *MemberRepository {
+	return &MemberRepository{db: db}
+}
+
+// memberRow - строка участника в формате хранения SQLite
+type memberRow struct {
+	OrganizationID uuid.UUID `db:"organization_id"`
+	UserID         uuid.UUID `db:"user_id"`
+	Role           string    `db:"role"`
+	CreatedAt      string    `db:"created_at"`
+	UpdatedAt      string    `db:"updated_at"`
+}
+
+// member преобразует строку в участника
+func (row *memberRow) member() (*member.Member, error) {
+	m := &member.Member{
+		OrganizationID: row.OrganizationID,
+		UserID:         row.UserID,
+		Role:           member.Role(row.Role),
+	}
+
+	var err error
+	if m.CreatedAt, err = time.Parse(timestampFormat, row.CreatedAt); err != nil {
+		return nil, fmt.Errorf("invalid created_at of member %s: %w", row.UserID, err)
+	}
+	if m.UpdatedAt, err = time.Parse(timestampFormat, row.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("invalid updated_at of member %s: %w", row.UserID, err)
+	}
+	return m, nil
+}
+
+// Get возвращает участника организации из контекста
+func (r *MemberRepository) Get(ctx context.Context, userID uuid.UUID) (*member.Member, error) {
+	query := `SELECT ` + memberColumns + ` FROM organization_members
+			WHERE organization_id = ? AND user_id = ?`
+
+	var row memberRow
+	if err := executorFrom(ctx, r.db).GetContext(ctx, &row, query, tenant.FromContext(ctx), userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, member.ErrMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+
+	return row.member()
+}
+
+// List возвращает участников организации в порядке добавления
+func (r *MemberRepository) List(ctx context.Context) ([]*member.Member, error) {
+	query := `SELECT ` + memberColumns + ` FROM organization_members
+			WHERE organization_id = ? ORDER BY created_at, user_id`
+
+	var rows []memberRow
+	if err := executorFrom(ctx, r.db).SelectContext(ctx, &rows, query, tenant.FromContext(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+
+	members := make([]*member.Member, 0, len(rows))
+	for i := range rows {
+		m, err := rows[i].member()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list members: %w", err)
+		}
+		members = append(members, m)
+	}
+
+	return members, nil
+}
+
+// Save добавляет участника в организацию из контекста или меняет его роль.
+// Время добавления существующего участника сохраняется
+func (r *MemberRepository) Save(ctx context.Context, m *member.Member) error {
+	query := `INSERT INTO organization_members (` + memberColumns + `)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (organization_id, user_id) DO UPDATE
+			SET role = excluded.role, updated_at = excluded.updated_at
+			RETURNING created_at, updated_at`
+
+	m.OrganizationID = tenant.FromContext(ctx)
+	now := formatTimestamp(time.Now())
+
+	var createdAt, updatedAt string
+	err := executorFrom(ctx, r.db).QueryRowxContext(ctx, query, m.OrganizationID, m.UserID, string(m.Role), now, now).
+		Scan(&createdAt, &updatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save member: %w", err)
+	}
+
+	if m.CreatedAt, err = time.Parse(timestampFormat, createdAt); err != nil {
+		return fmt.Errorf("invalid created_at of member %s: %w", m.UserID, err)
+	}
+	if m.UpdatedAt, err = time.Parse(timestampFormat, updatedAt); err != nil {
+		return fmt.Errorf("invalid updated_at of member %s: %w", m.UserID, err)
+	}
+
+	return nil
+}
+
+// Delete исключает участника из организации из контекста
+func (r *MemberRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	query := `DELETE FROM organization_members WHERE organization_id = ? AND user_id = ?`
+
+	result, err := executorFrom(ctx, r.db).ExecContext(ctx, query, tenant.FromContext(ctx), userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete member: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return member.ErrMemberNotFound
+	}
+
+	return nil
+}
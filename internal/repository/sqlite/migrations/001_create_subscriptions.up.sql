@@ -0,0 +1,21 @@
+-- SQLite не знает типов UUID, DATE и TIMESTAMPTZ, поэтому значения хранятся
+-- текстом: UUID - в каноническом виде в нижнем регистре, даты - как
+-- ГГГГ-ММ-ДД, моменты времени - в UTC с наносекундами фиксированной ширины.
+-- В таком виде сравнение и сортировка строк совпадают со сравнением значений
+CREATE TABLE subscriptions (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL,
+    service_name TEXT NOT NULL,
+    price INTEGER NOT NULL CHECK (price > 0),
+    user_id TEXT NOT NULL,
+    start_date TEXT NOT NULL,
+    end_date TEXT,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    deleted_at TEXT
+);
+
+CREATE INDEX idx_subscriptions_organization ON subscriptions(organization_id, user_id);
+CREATE INDEX idx_subscriptions_service_name ON subscriptions(service_name);
+CREATE INDEX idx_subscriptions_date_range ON subscriptions(start_date, end_date);
+CREATE INDEX idx_subscriptions_deleted_at ON subscriptions(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,22 @@
+-- Ревизии подписок для запросов на момент времени: каждая строка описывает
+-- состояние подписки в интервале [valid_from, valid_to). Ревизии записывает
+-- репозиторий в транзакции изменения; история очищенной подписки сохраняется
+CREATE TABLE subscription_history (
+    revision INTEGER PRIMARY KEY AUTOINCREMENT,
+    id TEXT NOT NULL,
+    organization_id TEXT NOT NULL,
+    service_name TEXT NOT NULL,
+    price INTEGER NOT NULL,
+    user_id TEXT NOT NULL,
+    start_date TEXT NOT NULL,
+    end_date TEXT,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    deleted_at TEXT,
+    valid_from TEXT NOT NULL,
+    valid_to TEXT
+);
+
+CREATE INDEX idx_subscription_history_id ON subscription_history(id, valid_from);
+CREATE INDEX idx_subscription_history_organization ON subscription_history(organization_id, valid_from, valid_to);
+CREATE UNIQUE INDEX idx_subscription_history_current ON subscription_history(id) WHERE valid_to IS NULL;
@@ -0,0 +1,19 @@
+-- Outbox событий, он же журнал событий для потоков SSE. Транзакции SQLite
+-- сразу захватывают блокировку записи и выполняются по одной, поэтому номера
+-- seq выдаются в порядке фиксации и читатель не пропустит событие
+CREATE TABLE outbox (
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    id TEXT NOT NULL UNIQUE,
+    event_type TEXT NOT NULL,
+    organization_id TEXT NOT NULL,
+    data TEXT NOT NULL,
+    occurred_at TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TEXT NOT NULL,
+    last_error TEXT,
+    published_at TEXT,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_outbox_pending ON outbox(next_attempt_at) WHERE published_at IS NULL;
+CREATE INDEX idx_outbox_organization ON outbox(organization_id, seq);
@@ -0,0 +1,28 @@
+-- Журнал аудита изменений подписок. Внешнего ключа на subscriptions нет:
+-- история удаленной подписки должна сохраняться
+CREATE TABLE subscription_audit (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL,
+    subscription_id TEXT NOT NULL,
+    operation TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    request_id TEXT NOT NULL DEFAULT '',
+    before TEXT,
+    after TEXT,
+    changes TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_subscription_audit_subscription ON subscription_audit(organization_id, subscription_id, created_at);
+CREATE INDEX idx_subscription_audit_created_at ON subscription_audit(organization_id, created_at);
+
+-- Журнал только пополняется: изменение и удаление записей запрещены
+CREATE TRIGGER subscription_audit_no_update BEFORE UPDATE ON subscription_audit
+BEGIN
+    SELECT RAISE(ABORT, 'subscription_audit is append-only');
+END;
+
+CREATE TRIGGER subscription_audit_no_delete BEFORE DELETE ON subscription_audit
+BEGIN
+    SELECT RAISE(ABORT, 'subscription_audit is append-only');
+END;
@@ -0,0 +1,15 @@
+-- Ключи API. Токен не хранится: по префиксу ключ находится, а SHA-256 токена
+-- подтверждает подлинность. Отозванные ключи остаются для истории
+CREATE TABLE api_keys (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL UNIQUE,
+    hash BLOB NOT NULL,
+    scopes TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    last_used_at TEXT,
+    revoked_at TEXT
+);
+
+CREATE INDEX idx_api_keys_organization ON api_keys(organization_id, created_at);
@@ -0,0 +1,10 @@
+-- Участники организаций и их роли. Пользователь без строки в таблице
+-- считается участником с ролью member
+CREATE TABLE organization_members (
+    organization_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'member', 'viewer')),
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    PRIMARY KEY (organization_id, user_id)
+);
@@ -0,0 +1,21 @@
+-- Ручной повтор доставки ссылается на доставку, которую повторяет. Первая
+-- доставка события получателю уникальна, как и в PostgreSQL
+ALTER TABLE webhook_deliveries ADD COLUMN redelivery_of TEXT;
+
+UPDATE webhook_deliveries SET redelivery_of = (
+    SELECT first.id FROM webhook_deliveries first
+    WHERE first.endpoint_id = webhook_deliveries.endpoint_id
+      AND first.event_id = webhook_deliveries.event_id
+    ORDER BY first.created_at, first.id
+    LIMIT 1
+)
+WHERE EXISTS (
+    SELECT 1 FROM webhook_deliveries earlier
+    WHERE earlier.endpoint_id = webhook_deliveries.endpoint_id
+      AND earlier.event_id = webhook_deliveries.event_id
+      AND (earlier.created_at < webhook_deliveries.created_at
+           OR (earlier.created_at = webhook_deliveries.created_at AND earlier.id < webhook_deliveries.id))
+);
+
+CREATE UNIQUE INDEX idx_webhook_deliveries_event ON webhook_deliveries(endpoint_id, event_id)
+    WHERE redelivery_of IS NULL;
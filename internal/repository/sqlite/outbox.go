@@ -0,0 +1,232 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/outbox"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// outboxColumns - столбцы outbox в порядке полей outboxRow
+const outboxColumns = `seq, id, event_type, organization_id, data, occurred_at, attempts, next_attempt_at,
+			last_error, published_at, created_at`
+
+// OutboxRepository реализует интерфейсы outbox.Repository и event.Log поверх SQLite
+type OutboxRepository struct {
+	db *sqlx.DB
+}
+
+var (
+	_ outbox.Repository = (*OutboxRepository)(nil)
+	_ event.Log         = (*OutboxRepository)(nil)
+)
+
+// NewOutboxRepository создает репозиторий outbox в базе SQLite
+func NewOutboxRepository(db *sqlx.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// outboxRow - строка outbox в формате хранения SQLite
+type outboxRow struct {
+	Sequence       int64          `db:"seq"`
+	ID             uuid.UUID      `db:"id"`
+	EventType      string         `db:"event_type"`
+	OrganizationID uuid.UUID      `db:"organization_id"`
+	Data           string         `db:"data"`
+	OccurredAt     string         `db:"occurred_at"`
+	Attempts       int            `db:"attempts"`
+	NextAttemptAt  string         `db:"next_attempt_at"`
+	LastError      sql.NullString `db:"last_error"`
+	PublishedAt    sql.NullString `db:"published_at"`
+	CreatedAt      string         `db:"created_at"`
+}
+
+// message преобразует строку в сообщение outbox
+func (row *outboxRow) message() (*outbox.Message, error) {
+	msg := &outbox.Message{
+		ID:             row.ID,
+		EventType:      event.Type(row.EventType),
+		OrganizationID: row.OrganizationID,
+		Data:           json.RawMessage(row.Data),
+		Attempts:       row.Attempts,
+	}
+	if row.LastError.Valid {
+		lastError := row.LastError.String
+		msg.LastError = &lastError
+	}
+
+	var err error
+	if msg.OccurredAt, err = time.Parse(timestampFormat, row.OccurredAt); err != nil {
+		return nil, fmt.Errorf("invalid occurred_at of outbox message %s: %w", row.ID, err)
+	}
+	if msg.NextAttemptAt, err = time.Parse(timestampFormat, row.NextAttemptAt); err != nil {
+		return nil, fmt.Errorf("invalid next_attempt_at of outbox message %s: %w", row.ID, err)
+	}
+	if msg.PublishedAt, err = parseNull(timestampFormat, row.PublishedAt); err != nil {
+		return nil, fmt.Errorf("invalid published_at of outbox message %s: %w", row.ID, err)
+	}
+	if msg.CreatedAt, err = time.Parse(timestampFormat, row.CreatedAt); err != nil {
+		return nil, fmt.Errorf("invalid created_at of outbox message %s: %w", row.ID, err)
+	}
+	return msg, nil
+}
+
+// Add записывает сообщение в outbox в транзакции из контекста, если она есть
+func (r *OutboxRepository) Add(ctx context.Context, msg *outbox.Message) error {
+	msg.CreatedAt = time.Now()
+
+	query := `INSERT INTO outbox (id, event_type, organization_id, data, occurred_at, attempts, next_attempt_at,
+			last_error, published_at, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := executorFrom(ctx, r.db).ExecContext(ctx, query,
+		msg.ID,
+		string(msg.EventType),
+		msg.OrganizationID,
+		string(msg.Data),
+		formatTimestamp(msg.OccurredAt),
+		msg.Attempts,
+		formatTimestamp(msg.NextAttemptAt),
+		msg.LastError,
+		nullTimestamp(msg.PublishedAt),
+		formatTimestamp(msg.CreatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimPending выбирает неопубликованные сообщения, время попытки которых
+// наступило, в порядке возникновения событий и сдвигает их следующую попытку на lease
+func (r *OutboxRepository) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*outbox.Message, error) {
+	query := `UPDATE outbox SET next_attempt_at = ?
+			WHERE id IN (
+				SELECT id FROM outbox
+				WHERE published_at IS NULL AND next_attempt_at <= ?
+				ORDER BY occurred_at, seq
+				LIMIT ?
+			)
+			RETURNING ` + outboxColumns
+
+	var rows []outboxRow
+	err := executorFrom(ctx, r.db).SelectContext(ctx, &rows, query, formatTimestamp(now.Add(lease)), formatTimestamp(now), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	messages := make([]*outbox.Message, 0, len(rows))
+	for i := range rows {
+		msg, err := rows[i].message()
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, nil
+}
+
+// MarkPublished отмечает сообщение опубликованным
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id uuid.UUID, publishedAt time.Time) error {
+	query := `UPDATE outbox SET published_at = ?, last_error = NULL WHERE id = ?`
+
+	if _, err := executorFrom(ctx, r.db).ExecContext(ctx, query, formatTimestamp(publishedAt), id); err != nil {
+		return fmt.Errorf("failed to mark outbox message published: %w", err)
+	}
+
+	return nil
+}
+
+// ScheduleRetry сохраняет результат неудачной попытки публикации
+func (r *OutboxRepository) ScheduleRetry(ctx context.Context, msg *outbox.Message) error {
+	query := `UPDATE outbox SET attempts = ?, next_attempt_at = ?, last_error = ? WHERE id = ?`
+
+	_, err := executorFrom(ctx, r.db).ExecContext(ctx, query,
+		msg.Attempts, formatTimestamp(msg.NextAttemptAt), msg.LastError, msg.ID)
+	if err != nil {
+		return fmt.Errorf("failed to schedule outbox retry: %w", err)
+	}
+
+	return nil
+}
+
+// Purge удаляет сообщения, опубликованные раньше publishedBefore, во всех
+// организациях: очистку выполняет фоновая задача, а не запрос клиента
+func (r *OutboxRepository) Purge(ctx context.Context, publishedBefore time.Time, limit int) (int, error) {
+	query := `DELETE FROM outbox WHERE seq IN (
+				SELECT seq FROM outbox
+				WHERE published_at < ?
+				ORDER BY seq
+				LIMIT ?
+			)`
+
+	result, err := executorFrom(ctx, r.db).ExecContext(ctx, query, formatTimestamp(publishedBefore), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge outbox messages: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// ListAfter возвращает события журнала организации из контекста с номером больше after
+func (r *OutboxRepository) ListAfter(ctx context.Context, after int64, filter event.LogFilter, limit int) ([]event.Record, error) {
+	query := `SELECT ` + outboxColumns + ` FROM outbox WHERE seq > ? AND organization_id = ?`
+	args := []interface{}{after, tenant.FromContext(ctx)}
+
+	if filter.UserID != nil {
+		query += " AND json_extract(data, '$.user_id') = ?"
+		args = append(args, filter.UserID.String())
+	}
+
+	query += " ORDER BY seq LIMIT ?"
+	args = append(args, limit)
+
+	var rows []outboxRow
+	if err := executorFrom(ctx, r.db).SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	records := make([]event.Record, 0, len(rows))
+	for i := range rows {
+		msg, err := rows[i].message()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list events: %w", err)
+		}
+		records = append(records, event.Record{
+			Sequence: rows[i].Sequence,
+			Event: event.Event{
+				ID:             msg.ID,
+				Type:           msg.EventType,
+				OrganizationID: msg.OrganizationID,
+				OccurredAt:     msg.OccurredAt,
+				Data:           msg.Data,
+			},
+		})
+	}
+
+	return records, nil
+}
+
+// LastSequence возвращает номер последнего события журнала. Номер общий для
+// всех организаций и служит только курсором, поэтому не ограничивается ими
+func (r *OutboxRepository) LastSequence(ctx context.Context) (int64, error) {
+	var seq int64
+	if err := executorFrom(ctx, r.db).GetContext(ctx, &seq, `SELECT COALESCE(MAX(seq), 0) FROM outbox`); err != nil {
+		return 0, fmt.Errorf("failed to get last event sequence: %w", err)
+	}
+
+	return seq, nil
+}
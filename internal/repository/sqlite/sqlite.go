@@ -0,0 +1,112 @@
+// Package sqlite реализует репозитории сервиса поверх SQLite для небольших
+// установок и демо на одной машине (database.driver: sqlite). Используется
+// драйвер modernc.org/sqlite на чистом Go, поэтому сборка не требует cgo
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	sqliteDriver "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// timestampFormat - формат моментов времени: UTC и наносекунды
+	// фиксированной ширины, чтобы строки сортировались как время
+	timestampFormat = "2006-01-02T15:04:05.000000000Z"
+	// dateFormat - формат дат подписок
+	dateFormat = "2006-01-02"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Open открывает базу в файле path, создавая его при необходимости.
+// Транзакции сразу захватывают блокировку записи, а занятая база ожидается
+// до busy_timeout, поэтому параллельные изменения не завершаются SQLITE_BUSY
+func Open(ctx context.Context, path string) (*sqlx.DB, error) {
+	query := url.Values{
+		"_pragma": {"foreign_keys(1)", "busy_timeout(5000)", "journal_mode(WAL)"},
+		"_txlock": {"immediate"},
+	}
+	db, err := sqlx.Open("sqlite", "file:"+path+"?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Migrate применяет к базе встроенные миграции SQLite. Версия схемы
+// хранится в таблице table golang-migrate
+func Migrate(db *sqlx.DB, table string) error {
+	source, err := iofs.New(migrations, "migrations")
+	if err != nil {
+		return fmt.Errorf("failed to read migrations: %w", err)
+	}
+	// Закрытие migrate.Migrate закрыло бы и базу, поэтому закрывается только источник
+	defer source.Close()
+
+	driver, err := sqliteDriver.WithInstance(db.DB, &sqliteDriver.Config{MigrationsTable: table})
+	if err != nil {
+		return fmt.Errorf("failed to create migration driver: %w", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", source, "sqlite", driver)
+	if err != nil {
+		return fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	return nil
+}
+
+// formatTimestamp возвращает момент времени в формате хранения
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(timestampFormat)
+}
+
+// formatDate возвращает дату в формате хранения
+func formatDate(t time.Time) string {
+	return t.Format(dateFormat)
+}
+
+// nullTimestamp возвращает момент времени в формате хранения или NULL
+func nullTimestamp(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTimestamp(*t), Valid: true}
+}
+
+// nullDate возвращает дату в формате хранения или NULL
+func nullDate(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatDate(*t), Valid: true}
+}
+
+// parseNull разбирает необязательное значение в формате layout
+func parseNull(layout string, s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := time.Parse(layout, s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
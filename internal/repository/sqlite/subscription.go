@@ -0,0 +1,504 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// subscriptionColumns - столбцы таблицы subscriptions, общие с subscription_history
+const subscriptionColumns = `id, organization_id, service_name, price, user_id, start_date, end_date, created_at, updated_at, deleted_at`
+
+// subscriptionRow - строка подписки в формате хранения SQLite
+type subscriptionRow struct {
+	ID             uuid.UUID      `db:"id"`
+	OrganizationID uuid.UUID      `db:"organization_id"`
+	ServiceName    string         `db:"service_name"`
+	Price          int            `db:"price"`
+	UserID         uuid.UUID      `db:"user_id"`
+	StartDate      string         `db:"start_date"`
+	EndDate        sql.NullString `db:"end_date"`
+	CreatedAt      string         `db:"created_at"`
+	UpdatedAt      string         `db:"updated_at"`
+	DeletedAt      sql.NullString `db:"deleted_at"`
+}
+
+// subscription преобразует строку в подписку
+func (row *subscriptionRow) subscription() (*subscription.Subscription, error) {
+	sub := &subscription.Subscription{
+		ID:             row.ID,
+		OrganizationID: row.OrganizationID,
+		ServiceName:    row.ServiceName,
+		Price:          row.Price,
+		UserID:         row.UserID,
+	}
+
+	var err error
+	if sub.StartDate, err = time.Parse(dateFormat, row.StartDate); err != nil {
+		return nil, fmt.Errorf("invalid start_date of subscription %s: %w", row.ID, err)
+	}
+	if sub.EndDate, err = parseNull(dateFormat, row.EndDate); err != nil {
+		return nil, fmt.Errorf("invalid end_date of subscription %s: %w", row.ID, err)
+	}
+	if sub.CreatedAt, err = time.Parse(timestampFormat, row.CreatedAt); err != nil {
+		return nil, fmt.Errorf("invalid created_at of subscription %s: %w", row.ID, err)
+	}
+	if sub.UpdatedAt, err = time.Parse(timestampFormat, row.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("invalid updated_at of subscription %s: %w", row.ID, err)
+	}
+	if sub.DeletedAt, err = parseNull(timestampFormat, row.DeletedAt); err != nil {
+		return nil, fmt.Errorf("invalid deleted_at of subscription %s: %w", row.ID, err)
+	}
+	return sub, nil
+}
+
+// SubscriptionRepository реализует интерфейс subscription.Repository поверх SQLite
+type SubscriptionRepository struct {
+	db *sqlx.DB
+	tx *TxManager
+}
+
+var _ subscription.Repository = (*SubscriptionRepository)(nil)
+
+// NewSubscriptionRepository создает репозиторий подписок в базе SQLite
+func NewSubscriptionRepository(db *sqlx.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{db: db, tx: NewTxManager(db)}
+}
+
+// Create создает новую запись о подписке в организации из контекста
+func (r *SubscriptionRepository) Create(ctx context.Context, sub *subscription.Subscription) error {
+	sub.ID = uuid.New()
+	sub.OrganizationID = tenant.FromContext(ctx)
+	sub.CreatedAt = time.Now()
+	sub.UpdatedAt = sub.CreatedAt
+	sub.DeletedAt = nil
+
+	err := r.withinTransaction(ctx, func(tx executor) error {
+		query := `INSERT INTO subscriptions
+				(id, organization_id, service_name, price, user_id, start_date, end_date, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		_, err := tx.ExecContext(ctx, query,
+			sub.ID,
+			sub.OrganizationID,
+			sub.ServiceName,
+			sub.Price,
+			sub.UserID,
+			formatDate(sub.StartDate),
+			nullDate(sub.EndDate),
+			formatTimestamp(sub.CreatedAt),
+			formatTimestamp(sub.UpdatedAt),
+		)
+		if err != nil {
+			return err
+		}
+		return trackHistory(ctx, tx, sub.ID, sub.CreatedAt)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create subscription: %w", err)
+	}
+
+	return nil
+}
+
+// Get возвращает подписку по ID
+func (r *SubscriptionRepository) Get(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + `
+			FROM subscriptions WHERE id = ? AND organization_id = ? AND deleted_at IS NULL`
+
+	sub, err := r.get(ctx, query, id, tenant.FromContext(ctx))
+	if err != nil {
+		return nil, wrapError(err, "failed to get subscription")
+	}
+	return sub, nil
+}
+
+// GetDeleted возвращает удаленную подписку, еще не очищенную по сроку хранения
+func (r *SubscriptionRepository) GetDeleted(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + `
+			FROM subscriptions WHERE id = ? AND organization_id = ?`
+
+	sub, err := r.get(ctx, query, id, tenant.FromContext(ctx))
+	if err != nil {
+		return nil, wrapError(err, "failed to get deleted subscription")
+	}
+	if sub.DeletedAt == nil {
+		return nil, subscription.ErrSubscriptionNotDeleted
+	}
+	return sub, nil
+}
+
+// GetAsOf возвращает ревизию подписки, действовавшую в момент asOf
+func (r *SubscriptionRepository) GetAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (*subscription.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + `
+			FROM subscription_history
+			WHERE id = ? AND organization_id = ?
+			AND valid_from <= ? AND (valid_to IS NULL OR valid_to > ?) AND deleted_at IS NULL`
+
+	at := formatTimestamp(asOf)
+	sub, err := r.get(ctx, query, id, tenant.FromContext(ctx), at, at)
+	if err != nil {
+		return nil, wrapError(err, "failed to get subscription revision")
+	}
+	return sub, nil
+}
+
+// get выбирает одну подписку; ErrSubscriptionNotFound, если строки нет
+func (r *SubscriptionRepository) get(ctx context.Context, query string, args ...interface{}) (*subscription.Subscription, error) {
+	var row subscriptionRow
+	if err := executorFrom(ctx, r.db).GetContext(ctx, &row, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, subscription.ErrSubscriptionNotFound
+		}
+		return nil, err
+	}
+	return row.subscription()
+}
+
+// Update обновляет существующую подписку
+func (r *SubscriptionRepository) Update(ctx context.Context, sub *subscription.Subscription) error {
+	sub.UpdatedAt = time.Now()
+
+	err := r.withinTransaction(ctx, func(tx executor) error {
+		query := `UPDATE subscriptions SET
+				service_name = ?, price = ?, start_date = ?, end_date = ?, updated_at = ?
+				WHERE id = ? AND organization_id = ? AND deleted_at IS NULL`
+		result, err := tx.ExecContext(ctx, query,
+			sub.ServiceName,
+			sub.Price,
+			formatDate(sub.StartDate),
+			nullDate(sub.EndDate),
+			formatTimestamp(sub.UpdatedAt),
+			sub.ID,
+			tenant.FromContext(ctx),
+		)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+		return trackHistory(ctx, tx, sub.ID, sub.UpdatedAt)
+	})
+	if err != nil {
+		return wrapError(err, "failed to update subscription")
+	}
+
+	return nil
+}
+
+// Delete помечает подписку удаленной; строка остается в таблице до очистки
+func (r *SubscriptionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+
+	err := r.withinTransaction(ctx, func(tx executor) error {
+		query := `UPDATE subscriptions SET deleted_at = ?
+				WHERE id = ? AND organization_id = ? AND deleted_at IS NULL`
+		result, err := tx.ExecContext(ctx, query, formatTimestamp(now), id, tenant.FromContext(ctx))
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+		return trackHistory(ctx, tx, id, now)
+	})
+	if err != nil {
+		return wrapError(err, "failed to delete subscription")
+	}
+
+	return nil
+}
+
+// Restore снимает пометку об удалении с подписки
+func (r *SubscriptionRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+
+	// Транзакция сразу захватывает блокировку записи, поэтому проверка и
+	// снятие пометки не расходятся с параллельным удалением или очисткой
+	err := r.withinTransaction(ctx, func(tx executor) error {
+		query := `SELECT deleted_at FROM subscriptions WHERE id = ? AND organization_id = ?`
+		var deletedAt sql.NullString
+		if err := tx.GetContext(ctx, &deletedAt, query, id, tenant.FromContext(ctx)); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return subscription.ErrSubscriptionNotFound
+			}
+			return err
+		}
+		if !deletedAt.Valid {
+			return subscription.ErrSubscriptionNotDeleted
+		}
+
+		query = `UPDATE subscriptions SET deleted_at = NULL, updated_at = ? WHERE id = ?`
+		if _, err := tx.ExecContext(ctx, query, formatTimestamp(now), id); err != nil {
+			return err
+		}
+		return trackHistory(ctx, tx, id, now)
+	})
+	if err != nil {
+		return wrapError(err, "failed to restore subscription")
+	}
+
+	return nil
+}
+
+// Purge окончательно удаляет подписки, удаленные раньше deletedBefore, во
+// всех организациях: очистку выполняет фоновая задача, а не запрос клиента.
+// История подписок сохраняется, их текущие ревизии закрываются
+func (r *SubscriptionRepository) Purge(ctx context.Context, deletedBefore time.Time, limit int) (int, error) {
+	now := formatTimestamp(time.Now())
+
+	var purged int
+	err := r.withinTransaction(ctx, func(tx executor) error {
+		var ids []uuid.UUID
+		query := `SELECT id FROM subscriptions WHERE deleted_at < ? LIMIT ?`
+		if err := tx.SelectContext(ctx, &ids, query, formatTimestamp(deletedBefore), limit); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		query, args, err := sqlx.In(`UPDATE subscription_history SET valid_to = ? WHERE valid_to IS NULL AND id IN (?)`, now, ids)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+
+		query, args, err = sqlx.In(`DELETE FROM subscriptions WHERE id IN (?)`, ids)
+		if err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		purged = int(rowsAffected)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge subscriptions: %w", err)
+	}
+
+	return purged, nil
+}
+
+// CountActive возвращает число неудаленных подписок, действующих в момент at,
+// во всех организациях. Используется для метрик сервиса
+func (r *SubscriptionRepository) CountActive(ctx context.Context, at time.Time) (int, error) {
+	// Как в PostgreSQL, дата окончания сравнивается с моментом at как полночь
+	// этой даты: подписка, заканчивающаяся в день at, действует только в полночь
+	endCondition := "end_date >= ?"
+	if at.Hour() != 0 || at.Minute() != 0 || at.Second() != 0 || at.Nanosecond() != 0 {
+		endCondition = "end_date > ?"
+	}
+	query := `SELECT COUNT(*) FROM subscriptions
+			WHERE deleted_at IS NULL AND start_date <= ? AND (end_date IS NULL OR ` + endCondition + `)`
+
+	var count int
+	if err := executorFrom(ctx, r.db).GetContext(ctx, &count, query, formatDate(at), formatDate(at)); err != nil {
+		return 0, fmt.Errorf("failed to count active subscriptions: %w", err)
+	}
+
+	return count, nil
+}
+
+// List возвращает список подписок, удовлетворяющих фильтру
+func (r *SubscriptionRepository) List(ctx context.Context, filter subscription.ListFilter) ([]*subscription.Subscription, error) {
+	query, args := buildListQuery(ctx, filter)
+
+	var rows []subscriptionRow
+	if err := executorFrom(ctx, r.db).SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	subs := make([]*subscription.Subscription, 0, len(rows))
+	for i := range rows {
+		sub, err := rows[i].subscription()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, nil
+}
+
+// Stream построчно читает подписки из курсора и передает каждую в fn,
+// не загружая всю выборку в память
+func (r *SubscriptionRepository) Stream(ctx context.Context, filter subscription.ListFilter, fn func(*subscription.Subscription) error) error {
+	query, args := buildListQuery(ctx, filter)
+
+	rows, err := executorFrom(ctx, r.db).QueryxContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row subscriptionRow
+		if err := rows.StructScan(&row); err != nil {
+			return fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		sub, err := row.subscription()
+		if err != nil {
+			return fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		if err := fn(sub); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate subscriptions: %w", err)
+	}
+
+	return nil
+}
+
+// CalculateTotalCost рассчитывает общую стоимость подписок по фильтру
+func (r *SubscriptionRepository) CalculateTotalCost(ctx context.Context, filter subscription.SubscriptionFilter) (int, error) {
+	source, args := subscriptionSource(ctx, filter.AsOf)
+	query := `SELECT COALESCE(SUM(price), 0) FROM ` + source
+	query, args = appendFilters(query, args, filter.IncludeDeleted, filter.UserID, filter.ServiceName)
+
+	// Подписка должна действовать хотя бы в части периода
+	query += " AND start_date <= ? AND (end_date IS NULL OR end_date >= ?)"
+	args = append(args, formatDate(filter.EndPeriod), formatDate(filter.StartPeriod))
+
+	var totalCost int
+	if err := executorFrom(ctx, r.db).GetContext(ctx, &totalCost, query, args...); err != nil {
+		return 0, fmt.Errorf("failed to calculate total cost: %w", err)
+	}
+
+	return totalCost, nil
+}
+
+// SummarizeByService возвращает сводку неудаленных подписок по каждому
+// сервису в порядке названий
+func (r *SubscriptionRepository) SummarizeByService(ctx context.Context, filter subscription.SummaryFilter) ([]*subscription.ServiceSummary, error) {
+	source, args := subscriptionSource(ctx, nil)
+	query := `SELECT service_name, COUNT(*) AS subscription_count, COUNT(DISTINCT user_id) AS user_count,
+			MIN(price) AS min_price, MAX(price) AS max_price, SUM(price) AS total_cost
+			FROM ` + source
+	query, args = appendFilters(query, args, false, filter.UserID, nil)
+
+	if !filter.StartPeriod.IsZero() {
+		query += " AND start_date <= ? AND (end_date IS NULL OR end_date >= ?)"
+		args = append(args, formatDate(filter.EndPeriod), formatDate(filter.StartPeriod))
+	}
+	query += " GROUP BY service_name ORDER BY service_name"
+
+	summaries := []*subscription.ServiceSummary{}
+	if err := executorFrom(ctx, r.db).SelectContext(ctx, &summaries, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to summarize subscriptions: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// subscriptionSource возвращает источник строк подписок: саму таблицу или,
+// для запроса на момент времени, ревизии из истории, действовавшие в asOf.
+// Источник ограничен организацией из контекста
+func subscriptionSource(ctx context.Context, asOf *time.Time) (string, []interface{}) {
+	args := []interface{}{tenant.FromContext(ctx)}
+	if asOf == nil {
+		return `subscriptions WHERE organization_id = ?`, args
+	}
+	at := formatTimestamp(*asOf)
+	return `subscription_history WHERE organization_id = ?
+			AND valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)`, append(args, at, at)
+}
+
+// appendFilters добавляет к запросу общие условия выборки и расчета стоимости
+func appendFilters(query string, args []interface{}, includeDeleted bool, userID *uuid.UUID, serviceName *string) (string, []interface{}) {
+	if !includeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+	if userID != nil {
+		query += " AND user_id = ?"
+		args = append(args, *userID)
+	}
+	if serviceName != nil && *serviceName != "" {
+		query += " AND service_name = ?"
+		args = append(args, *serviceName)
+	}
+	return query, args
+}
+
+// buildListQuery строит запрос выборки подписок по фильтру
+func buildListQuery(ctx context.Context, filter subscription.ListFilter) (string, []interface{}) {
+	source, args := subscriptionSource(ctx, filter.AsOf)
+	query := `SELECT ` + subscriptionColumns + ` FROM ` + source
+	query, args = appendFilters(query, args, filter.IncludeDeleted, filter.UserID, filter.ServiceName)
+
+	// Стабильный порядок делает выгрузку воспроизводимой; текстовые UUID
+	// сортируются так же, как UUID в PostgreSQL
+	query += " ORDER BY created_at, id"
+
+	if filter.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	return query, args
+}
+
+// withinTransaction выполняет fn в транзакции из контекста, а если ее нет -
+// в собственной транзакции, которую фиксирует при успехе
+func (r *SubscriptionRepository) withinTransaction(ctx context.Context, fn func(tx executor) error) error {
+	return r.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		return fn(executorFrom(ctx, r.db))
+	})
+}
+
+// trackHistory закрывает текущую ревизию подписки и записывает новую,
+// действующую с момента at, как триггер истории в PostgreSQL
+func trackHistory(ctx context.Context, tx executor, id uuid.UUID, at time.Time) error {
+	validFrom := formatTimestamp(at)
+
+	query := `UPDATE subscription_history SET valid_to = ? WHERE id = ? AND valid_to IS NULL`
+	if _, err := tx.ExecContext(ctx, query, validFrom, id); err != nil {
+		return fmt.Errorf("failed to close subscription revision: %w", err)
+	}
+
+	query = `INSERT INTO subscription_history (` + subscriptionColumns + `, valid_from)
+			SELECT ` + subscriptionColumns + `, ? FROM subscriptions WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, query, validFrom, id); err != nil {
+		return fmt.Errorf("failed to record subscription revision: %w", err)
+	}
+	return nil
+}
+
+// wrapError добавляет к ошибке описание операции. Ошибки предметной области
+// возвращаются без изменений, как в репозитории PostgreSQL
+func wrapError(err error, message string) error {
+	if errors.Is(err, subscription.ErrSubscriptionNotFound) || errors.Is(err, subscription.ErrSubscriptionNotDeleted) {
+		return err
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// requireAffected возвращает ErrSubscriptionNotFound, если запрос не изменил ни одной строки
+func requireAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return subscription.ErrSubscriptionNotFound
+	}
+	return nil
+}
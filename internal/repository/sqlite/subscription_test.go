@@ -0,0 +1,30 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/repository/repotest"
+)
+
+func TestSubscriptionRepository(t *testing.T) {
+	repotest.RunSubscriptionRepository(t, func(t *testing.T) subscription.Repository {
+		return NewSubscriptionRepository(openTestDatabase(t))
+	})
+}
+
+// openTestDatabase открывает базу во временном каталоге теста и применяет миграции
+func openTestDatabase(t *testing.T) *sqlx.DB {
+	db, err := Open(context.Background(), filepath.Join(t.TempDir(), "subscriptions.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, Migrate(db, "schema_migrations"))
+	// Повторный запуск не меняет примененную схему
+	require.NoError(t, Migrate(db, "schema_migrations"))
+	return db
+}
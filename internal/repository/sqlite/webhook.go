@@ -0,0 +1,400 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/webhook"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// endpointColumns - столбцы таблицы webhook_endpoints в порядке полей endpointRow
+const endpointColumns = `id, organization_id, url, secret, events, created_at, updated_at`
+
+// deliveryColumns - столбцы журнала доставок в порядке полей deliveryRow
+const deliveryColumns = `id, endpoint_id, organization_id, event_id, event_type, redelivery_of, payload, status, attempts,
+			next_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at`
+
+// WebhookRepository реализует интерфейс webhook.Repository поверх SQLite
+type WebhookRepository struct {
+	db *sqlx.DB
+}
+
+var _ webhook.Repository = (*WebhookRepository)(nil)
+
+// NewWebhookRepository создает репозиторий webhook-уведомлений в базе SQLite
+func NewWebhookRepository(db *sqlx.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// endpointRow - строка получателя в формате хранения SQLite
+type endpointRow struct {
+	ID             uuid.UUID `db:"id"`
+	OrganizationID uuid.UUID `db:"organization_id"`
+	URL            string    `db:"url"`
+	Secret         string    `db:"secret"`
+	Events         string    `db:"events"`
+	CreatedAt      string    `db:"created_at"`
+	UpdatedAt      string    `db:"updated_at"`
+}
+
+// endpoint преобразует строку в получателя
+func (row *endpointRow) endpoint() (*webhook.Endpoint, error) {
+	endpoint := &webhook.Endpoint{
+		ID:             row.ID,
+		OrganizationID: row.OrganizationID,
+		URL:            row.URL,
+		Secret:         row.Secret,
+		Events:         []event.Type{},
+	}
+
+	var err error
+	if err = json.Unmarshal([]byte(row.Events), &endpoint.Events); err != nil {
+		return nil, fmt.Errorf("invalid events of webhook endpoint %s: %w", row.ID, err)
+	}
+	if endpoint.CreatedAt, err = time.Parse(timestampFormat, row.CreatedAt); err != nil {
+		return nil, fmt.Errorf("invalid created_at of webhook endpoint %s: %w", row.ID, err)
+	}
+	if endpoint.UpdatedAt, err = time.Parse(timestampFormat, row.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("invalid updated_at of webhook endpoint %s: %w", row.ID, err)
+	}
+	return endpoint, nil
+}
+
+// deliveryRow - строка журнала доставок в формате хранения SQLite
+type deliveryRow struct {
+	ID             uuid.UUID      `db:"id"`
+	EndpointID     uuid.UUID      `db:"endpoint_id"`
+	OrganizationID uuid.UUID      `db:"organization_id"`
+	EventID        uuid.UUID      `db:"event_id"`
+	EventType      string         `db:"event_type"`
+	RedeliveryOf   uuid.NullUUID  `db:"redelivery_of"`
+	Payload        string         `db:"payload"`
+	Status         string         `db:"status"`
+	Attempts       int            `db:"attempts"`
+	NextAttemptAt  sql.NullString `db:"next_attempt_at"`
+	LastStatusCode sql.NullInt64  `db:"last_status_code"`
+	LastError      sql.NullString `db:"last_error"`
+	DeliveredAt    sql.NullString `db:"delivered_at"`
+	CreatedAt      string         `db:"created_at"`
+	UpdatedAt      string         `db:"updated_at"`
+}
+
+// delivery преобразует строку в запись журнала доставок
+func (row *deliveryRow) delivery() (*webhook.Delivery, error) {
+	delivery := &webhook.Delivery{
+		ID:             row.ID,
+		EndpointID:     row.EndpointID,
+		OrganizationID: row.OrganizationID,
+		EventID:        row.EventID,
+		EventType:      event.Type(row.EventType),
+		Payload:        json.RawMessage(row.Payload),
+		Status:         webhook.DeliveryStatus(row.Status),
+		Attempts:       row.Attempts,
+	}
+	if row.RedeliveryOf.Valid {
+		delivery.RedeliveryOf = &row.RedeliveryOf.UUID
+	}
+	if row.LastStatusCode.Valid {
+		code := int(row.LastStatusCode.Int64)
+		delivery.LastStatusCode = &code
+	}
+	if row.LastError.Valid {
+		lastError := row.LastError.String
+		delivery.LastError = &lastError
+	}
+
+	var err error
+	if delivery.NextAttemptAt, err = parseNull(timestampFormat, row.NextAttemptAt); err != nil {
+		return nil, fmt.Errorf("invalid next_attempt_at of webhook delivery %s: %w", row.ID, err)
+	}
+	if delivery.DeliveredAt, err = parseNull(timestampFormat, row.DeliveredAt); err != nil {
+		return nil, fmt.Errorf("invalid delivered_at of webhook delivery %s: %w", row.ID, err)
+	}
+	if delivery.CreatedAt, err = time.Parse(timestampFormat, row.CreatedAt); err != nil {
+		return nil, fmt.Errorf("invalid created_at of webhook delivery %s: %w", row.ID, err)
+	}
+	if delivery.UpdatedAt, err = time.Parse(timestampFormat, row.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("invalid updated_at of webhook delivery %s: %w", row.ID, err)
+	}
+	return delivery, nil
+}
+
+// CreateEndpoint создает нового получателя в организации из контекста
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *webhook.Endpoint) error {
+	endpoint.ID = uuid.New()
+	endpoint.OrganizationID = tenant.FromContext(ctx)
+	endpoint.CreatedAt = time.Now()
+	endpoint.UpdatedAt = endpoint.CreatedAt
+
+	events := endpoint.Events
+	if events == nil {
+		events = []event.Type{}
+	}
+	encoded, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook events: %w", err)
+	}
+
+	query := `INSERT INTO webhook_endpoints (` + endpointColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err = executorFrom(ctx, r.db).ExecContext(ctx, query,
+		endpoint.ID,
+		endpoint.OrganizationID,
+		endpoint.URL,
+		endpoint.Secret,
+		string(encoded),
+		formatTimestamp(endpoint.CreatedAt),
+		formatTimestamp(endpoint.UpdatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+
+	return nil
+}
+
+// GetEndpoint возвращает получателя по ID
+func (r *WebhookRepository) GetEndpoint(ctx context.Context, id uuid.UUID) (*webhook.Endpoint, error) {
+	query := `SELECT ` + endpointColumns + ` FROM webhook_endpoints WHERE id = ? AND organization_id = ?`
+
+	var row endpointRow
+	if err := executorFrom(ctx, r.db).GetContext(ctx, &row, query, id, tenant.FromContext(ctx)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, webhook.ErrEndpointNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	return row.endpoint()
+}
+
+// ListEndpoints возвращает всех получателей организации
+func (r *WebhookRepository) ListEndpoints(ctx context.Context) ([]*webhook.Endpoint, error) {
+	query := `SELECT ` + endpointColumns + ` FROM webhook_endpoints
+			WHERE organization_id = ?
+			ORDER BY created_at, id`
+	return r.selectEndpoints(ctx, query, tenant.FromContext(ctx))
+}
+
+// ListEndpointsForEvent возвращает получателей, подписанных на событие данного типа.
+// Получатели с пустым списком событий подписаны на все события
+func (r *WebhookRepository) ListEndpointsForEvent(ctx context.Context, eventType event.Type) ([]*webhook.Endpoint, error) {
+	query := `SELECT ` + endpointColumns + ` FROM webhook_endpoints
+			WHERE organization_id = ?
+			AND (json_array_length(events) = 0 OR EXISTS (SELECT 1 FROM json_each(events) WHERE value = ?))
+			ORDER BY created_at, id`
+	return r.selectEndpoints(ctx, query, tenant.FromContext(ctx), string(eventType))
+}
+
+// selectEndpoints выполняет запрос выборки получателей
+func (r *WebhookRepository) selectEndpoints(ctx context.Context, query string, args ...interface{}) ([]*webhook.Endpoint, error) {
+	var rows []endpointRow
+	if err := executorFrom(ctx, r.db).SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+
+	endpoints := make([]*webhook.Endpoint, 0, len(rows))
+	for i := range rows {
+		endpoint, err := rows[i].endpoint()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, nil
+}
+
+// DeleteEndpoint удаляет получателя; журнал его доставок удаляется каскадно
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	result, err := executorFrom(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM webhook_endpoints WHERE id = ? AND organization_id = ?`, id, tenant.FromContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return webhook.ErrEndpointNotFound
+	}
+
+	return nil
+}
+
+// CreateDelivery добавляет доставку в журнал организации из контекста.
+// Повторная первая доставка события тому же получателю не добавляется:
+// ее отсекает уникальный индекс idx_webhook_deliveries_event
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *webhook.Delivery) error {
+	delivery.ID = uuid.New()
+	delivery.OrganizationID = tenant.FromContext(ctx)
+	delivery.CreatedAt = time.Now()
+	delivery.UpdatedAt = delivery.CreatedAt
+
+	query := `INSERT INTO webhook_deliveries (` + deliveryColumns + `)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT DO NOTHING`
+	_, err := executorFrom(ctx, r.db).ExecContext(ctx, query,
+		delivery.ID,
+		delivery.EndpointID,
+		delivery.OrganizationID,
+		delivery.EventID,
+		string(delivery.EventType),
+		delivery.RedeliveryOf,
+		string(delivery.Payload),
+		string(delivery.Status),
+		delivery.Attempts,
+		nullTimestamp(delivery.NextAttemptAt),
+		delivery.LastStatusCode,
+		delivery.LastError,
+		nullTimestamp(delivery.DeliveredAt),
+		formatTimestamp(delivery.CreatedAt),
+		formatTimestamp(delivery.UpdatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// GetDelivery возвращает доставку по ID
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id uuid.UUID) (*webhook.Delivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = ? AND organization_id = ?`
+
+	var row deliveryRow
+	if err := executorFrom(ctx, r.db).GetContext(ctx, &row, query, id, tenant.FromContext(ctx)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, webhook.ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	return row.delivery()
+}
+
+// UpdateDelivery сохраняет результат попытки доставки. Результат выбранной
+// доставки сохраняется, только если время следующей попытки не сдвинул
+// другой обработчик, выбравший ее после окончания аренды
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *webhook.Delivery) error {
+	delivery.UpdatedAt = time.Now()
+
+	query := `UPDATE webhook_deliveries SET
+			status = ?, attempts = ?, next_attempt_at = ?,
+			last_status_code = ?, last_error = ?,
+			delivered_at = ?, updated_at = ?
+			WHERE id = ?`
+	args := []interface{}{
+		string(delivery.Status),
+		delivery.Attempts,
+		nullTimestamp(delivery.NextAttemptAt),
+		delivery.LastStatusCode,
+		delivery.LastError,
+		nullTimestamp(delivery.DeliveredAt),
+		formatTimestamp(delivery.UpdatedAt),
+		delivery.ID,
+	}
+	if delivery.ClaimedUntil != nil {
+		query += " AND next_attempt_at = ?"
+		args = append(args, formatTimestamp(*delivery.ClaimedUntil))
+	}
+
+	result, err := executorFrom(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		if delivery.ClaimedUntil != nil {
+			return webhook.ErrLeaseLost
+		}
+		return webhook.ErrDeliveryNotFound
+	}
+
+	return nil
+}
+
+// ListDeliveries возвращает журнал доставок получателя, начиная с последних
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, endpointID uuid.UUID, filter webhook.DeliveryFilter) ([]*webhook.Delivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+			WHERE endpoint_id = ? AND organization_id = ?`
+	args := []interface{}{endpointID, tenant.FromContext(ctx)}
+
+	if filter.Status != nil {
+		query += " AND status = ?"
+		args = append(args, string(*filter.Status))
+	}
+
+	query += " ORDER BY created_at DESC, id"
+
+	if filter.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	return r.selectDeliveries(ctx, query, args...)
+}
+
+// ClaimDueDeliveries выбирает доставки, время попытки которых наступило, и
+// сдвигает их следующую попытку на lease. Запись в SQLite выполняется по
+// одной транзакции, поэтому две выборки не получат одну доставку
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*webhook.Delivery, error) {
+	query := `UPDATE webhook_deliveries SET next_attempt_at = ?, updated_at = ?
+			WHERE id IN (
+				SELECT id FROM webhook_deliveries
+				WHERE status = ? AND next_attempt_at <= ?
+				ORDER BY next_attempt_at
+				LIMIT ?
+			)
+			RETURNING ` + deliveryColumns
+
+	deliveries, err := r.selectDeliveries(ctx, query,
+		formatTimestamp(now.Add(lease)),
+		formatTimestamp(now),
+		string(webhook.DeliveryPending),
+		formatTimestamp(now),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, delivery := range deliveries {
+		claimedUntil := *delivery.NextAttemptAt
+		delivery.ClaimedUntil = &claimedUntil
+	}
+
+	return deliveries, nil
+}
+
+// selectDeliveries выполняет запрос выборки доставок
+func (r *WebhookRepository) selectDeliveries(ctx context.Context, query string, args ...interface{}) ([]*webhook.Delivery, error) {
+	var rows []deliveryRow
+	if err := executorFrom(ctx, r.db).SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]*webhook.Delivery, 0, len(rows))
+	for i := range rows {
+		delivery, err := rows[i].delivery()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,31 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Header - имя заголовка HTTP (и ключа метаданных gRPC) с ID запроса
+const Header = "X-Request-ID"
+
+// requestIDKey - ключ контекста для request ID
+type requestIDKey struct{}
+
+// New генерирует новый ID запроса
+func New() string {
+	return uuid.New().String()
+}
+
+// WithRequestID сохраняет ID запроса в контексте
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// FromContext возвращает ID запроса из контекста или пустую строку
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/internal/domain/outbox"
+	"github.com/subscription-service/internal/domain/subscription"
+)
+
+// Config хранит настройки очистки
+type Config struct {
+	// Interval - период запуска очистки
+	Interval time.Duration
+	// Retention - срок хранения записей: удаленную подписку можно восстановить,
+	// а опубликованное событие - прочитать из журнала событий
+	Retention time.Duration
+	// BatchSize - число записей, удаляемых одним запросом
+	BatchSize int
+}
+
+// purgeFunc удаляет до limit записей старше before и возвращает их число
+type purgeFunc func(ctx context.Context, before time.Time, limit int) (int, error)
+
+// Purger окончательно удаляет записи, срок хранения которых истек
+type Purger struct {
+	purge purgeFunc
+	// target - название очищаемых записей для журнала
+	target string
+	config Config
+	now    func() time.Time
+}
+
+// NewPurger создает обработчик очистки удаленных подписок
+func NewPurger(repo subscription.Repository, config Config) *Purger {
+	return &Purger{purge: repo.Purge, target: "deleted subscriptions", config: config, now: time.Now}
+}
+
+// NewOutboxPurger создает обработчик очистки опубликованных событий outbox.
+// Потоки событий возобновляются по Last-Event-ID только в пределах Retention:
+// более ранние события из журнала уже удалены
+func NewOutboxPurger(repo outbox.Repository, config Config) *Purger {
+	return &Purger{purge: repo.Purge, target: "published outbox messages", config: config, now: time.Now}
+}
+
+// Run запускает очистку с периодом Interval до отмены контекста
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := p.PurgeExpired(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to purge " + p.target)
+		}
+		if purged > 0 {
+			log.Info().Int("purged", purged).Msg("Purged " + p.target)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpired удаляет пачками все записи старше Retention и возвращает их
+// число. Короткие запросы не держат блокировки долго
+func (p *Purger) PurgeExpired(ctx context.Context) (int, error) {
+	before := p.now().Add(-p.config.Retention)
+
+	total := 0
+	for {
+		purged, err := p.purge(ctx, before, p.config.BatchSize)
+		total += purged
+		if err != nil {
+			return total, err
+		}
+		if purged < p.config.BatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/outbox"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/repository/memory"
+)
+
+// purgeRepository хранит время удаления подписок и удаляет их пачками
+type purgeRepository struct {
+	subscription.Repository
+	deletedAt []time.Time
+	calls     int
+	err       error
+}
+
+func (r *purgeRepository) Purge(_ context.Context, deletedBefore time.Time, limit int) (int, error) {
+	r.calls++
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	var kept []time.Time
+	purged := 0
+	for _, at := range r.deletedAt {
+		if purged < limit && at.Before(deletedBefore) {
+			purged++
+			continue
+		}
+		kept = append(kept, at)
+	}
+	r.deletedAt = kept
+	return purged, nil
+}
+
+func TestPurger_PurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	config := Config{Retention: 30 * 24 * time.Hour, BatchSize: 2}
+
+	t.Run("удаляются пачками только подписки старше срока хранения", func(t *testing.T) {
+		repo := &purgeRepository{deletedAt: []time.Time{
+			now.AddDate(0, -3, 0),
+			now.AddDate(0, -2, 0),
+			now.AddDate(0, 0, -31),
+			now.AddDate(0, 0, -29),
+			now.Add(-time.Hour),
+		}}
+		purger := NewPurger(repo, config)
+		purger.now = func() time.Time { return now }
+
+		purged, err := purger.PurgeExpired(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, purged)
+		assert.Len(t, repo.deletedAt, 2)
+		// Вторая пачка неполная, поэтому третьего запроса нет
+		assert.Equal(t, 2, repo.calls)
+	})
+
+	t.Run("ошибка хранилища прерывает очистку", func(t *testing.T) {
+		repo := &purgeRepository{err: errors.New("database error")}
+		purger := NewPurger(repo, config)
+
+		_, err := purger.PurgeExpired(ctx)
+		assert.EqualError(t, err, "database error")
+		assert.Equal(t, 1, repo.calls)
+	})
+}
+
+func TestOutboxPurger_PurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repo := memory.NewOutboxRepository()
+
+	var messages []*outbox.Message
+	for i := 0; i < 4; i++ {
+		evt, err := event.New(event.SubscriptionCreated, map[string]string{})
+		require.NoError(t, err)
+		msg := outbox.NewMessage(evt)
+		require.NoError(t, repo.Add(ctx, msg))
+		messages = append(messages, msg)
+	}
+	// Два события опубликованы раньше срока хранения, одно - недавно, а
+	// последнее еще не опубликовано
+	require.NoError(t, repo.MarkPublished(ctx, messages[0].ID, now.AddDate(0, 0, -10)))
+	require.NoError(t, repo.MarkPublished(ctx, messages[1].ID, now.AddDate(0, 0, -8)))
+	require.NoError(t, repo.MarkPublished(ctx, messages[2].ID, now.Add(-time.Hour)))
+
+	purger := NewOutboxPurger(repo, Config{Retention: 7 * 24 * time.Hour, BatchSize: 1})
+	purger.now = func() time.Time { return now }
+
+	purged, err := purger.PurgeExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+
+	records, err := repo.ListAfter(ctx, 0, event.LogFilter{}, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, messages[2].ID, records[0].ID)
+	assert.Equal(t, messages[3].ID, records[1].ID)
+}
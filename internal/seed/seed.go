@@ -0,0 +1,288 @@
+// Package seed генерирует воспроизводимые наборы подписок для демо-стендов и
+// нагрузочных тестов и сохраняет их через репозиторий подписок
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/domain/subscription"
+)
+
+// TrialPrice - цена пробного месяца: сервисы предлагают первый месяц за 1 рубль
+const TrialPrice = 1
+
+// trialConversion - доля пробных подписок, после которых пользователь
+// продолжает пользоваться сервисом за полную цену
+const trialConversion = 0.6
+
+// Service - сервис каталога с ценами тарифов
+type Service struct {
+	Name string
+	// Plans - цены тарифов по возрастанию; дешевые тарифы выбирают чаще
+	Plans []int
+	// Weight - относительная популярность сервиса
+	Weight int
+}
+
+// Catalog - сервисы, из которых составляются подписки
+var Catalog = []Service{
+	{Name: "Yandex Plus", Plans: []int{299, 399}, Weight: 20},
+	{Name: "Kinopoisk HD", Plans: []int{299, 599}, Weight: 12},
+	{Name: "Netflix", Plans: []int{599, 999, 1599}, Weight: 10},
+	{Name: "Spotify Premium", Plans: []int{199, 299, 499}, Weight: 10},
+	{Name: "YouTube Premium", Plans: []int{299, 549, 799}, Weight: 9},
+	{Name: "VK Combo", Plans: []int{199, 299}, Weight: 9},
+	{Name: "SberPrime", Plans: []int{199, 399}, Weight: 8},
+	{Name: "Telegram Premium", Plans: []int{299}, Weight: 8},
+	{Name: "Apple Music", Plans: []int{169, 299}, Weight: 6},
+	{Name: "iCloud+", Plans: []int{149, 299, 999}, Weight: 6},
+	{Name: "Google One", Plans: []int{139, 349, 999}, Weight: 5},
+	{Name: "Tinkoff Pro", Plans: []int{199}, Weight: 5},
+	{Name: "Microsoft 365", Plans: []int{499, 999}, Weight: 4},
+	{Name: "PlayStation Plus", Plans: []int{599, 899, 1299}, Weight: 4},
+	{Name: "Xbox Game Pass", Plans: []int{699, 1499}, Weight: 3},
+	{Name: "Disney+", Plans: []int{399, 799}, Weight: 3},
+	{Name: "Amazon Prime", Plans: []int{399, 899}, Weight: 2},
+	{Name: "HBO Max", Plans: []int{599, 999}, Weight: 2},
+	{Name: "Notion Premium", Plans: []int{499, 999}, Weight: 2},
+	{Name: "Adobe Creative Cloud", Plans: []int{1999, 4999}, Weight: 1},
+}
+
+// Config хранит параметры генерируемого набора. Одинаковые параметры дают
+// одинаковый набор: пользователей, сервисы, цены и даты
+type Config struct {
+	// Seed - начальное значение генератора случайных чисел
+	Seed int64
+	// Users - число пользователей
+	Users int
+	// Subscriptions - число подписок в наборе
+	Subscriptions int
+	// From и To - первый и последний месяц, в которые начинаются подписки.
+	// Подписки, которые не отменены к концу To, остаются бессрочными
+	From time.Time
+	To   time.Time
+	// TrialRate - доля подписок, начинающихся с пробного месяца за TrialPrice
+	TrialRate float64
+	// ChurnRate - вероятность отмены подписки в каждый месяц
+	ChurnRate float64
+	// PriceChangeRate - вероятность повышения цены при каждом годовом продлении
+	PriceChangeRate float64
+}
+
+// DefaultConfig возвращает параметры набора за два года, заканчивающиеся месяцем now
+func DefaultConfig(now time.Time) Config {
+	to := month(now)
+	return Config{
+		Seed:            1,
+		Users:           50,
+		Subscriptions:   500,
+		From:            to.AddDate(0, -23, 0),
+		To:              to,
+		TrialRate:       0.2,
+		ChurnRate:       0.05,
+		PriceChangeRate: 0.3,
+	}
+}
+
+// Validate проверяет параметры набора
+func (c Config) Validate() error {
+	var errs []error
+	if c.Users < 1 {
+		errs = append(errs, errors.New("users must be at least 1"))
+	}
+	if c.Subscriptions < 0 {
+		errs = append(errs, errors.New("subscriptions must not be negative"))
+	}
+	if c.From.IsZero() || c.To.IsZero() {
+		errs = append(errs, errors.New("from and to are required"))
+	} else if month(c.To).Before(month(c.From)) {
+		errs = append(errs, errors.New("to must not be before from"))
+	}
+	if c.TrialRate < 0 || c.TrialRate > 1 {
+		errs = append(errs, errors.New("trial rate must be between 0 and 1"))
+	}
+	if c.PriceChangeRate < 0 || c.PriceChangeRate > 1 {
+		errs = append(errs, errors.New("price change rate must be between 0 and 1"))
+	}
+	// При нулевом оттоке подписки длятся бесконечно, при единичном - один месяц
+	if c.ChurnRate <= 0 || c.ChurnRate > 1 {
+		errs = append(errs, errors.New("churn rate must be greater than 0 and at most 1"))
+	}
+	return errors.Join(errs...)
+}
+
+// Generate составляет набор подписок. Подписки одного пользователя на
+// сервис идут подряд: пробный месяц, затем платная подписка, которую при
+// повышении цены сменяет подписка с новой ценой со следующего месяца
+func Generate(config Config) ([]*subscription.Subscription, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		config: config,
+		rand:   rand.New(rand.NewSource(config.Seed)),
+		from:   month(config.From),
+		to:     month(config.To),
+	}
+	users, err := g.users()
+	if err != nil {
+		return nil, err
+	}
+
+	// Распределение Ципфа: у немногих пользователей много подписок, у
+	// большинства - одна-две
+	zipf := rand.NewZipf(g.rand, 1.3, 4, uint64(len(users)-1))
+
+	subs := make([]*subscription.Subscription, 0, config.Subscriptions)
+	for len(subs) < config.Subscriptions {
+		userID := users[zipf.Uint64()]
+		for _, sub := range g.lifecycle(userID) {
+			if len(subs) == config.Subscriptions {
+				break
+			}
+			subs = append(subs, sub)
+		}
+	}
+	return subs, nil
+}
+
+// Load сохраняет подписки через репозиторий в организацию из контекста и
+// возвращает число сохраненных подписок. Репозиторий назначает подпискам ID
+func Load(ctx context.Context, repo subscription.Repository, subs []*subscription.Subscription) (int, error) {
+	for i, sub := range subs {
+		if err := repo.Create(ctx, sub); err != nil {
+			return i, err
+		}
+	}
+	return len(subs), nil
+}
+
+// generator хранит состояние генерации одного набора
+type generator struct {
+	config   Config
+	rand     *rand.Rand
+	from, to time.Time
+}
+
+// users создает ID пользователей из генератора набора, чтобы они
+// повторялись при том же Seed
+func (g *generator) users() ([]uuid.UUID, error) {
+	users := make([]uuid.UUID, g.config.Users)
+	for i := range users {
+		id, err := uuid.NewRandomFromReader(g.rand)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate user id: %w", err)
+		}
+		users[i] = id
+	}
+	return users, nil
+}
+
+// lifecycle составляет историю подписки пользователя на один сервис
+func (g *generator) lifecycle(userID uuid.UUID) []*subscription.Subscription {
+	service := g.service()
+	start := g.from.AddDate(0, g.rand.Intn(monthsBetween(g.from, g.to)+1), 0)
+
+	var subs []*subscription.Subscription
+	if g.rand.Float64() < g.config.TrialRate {
+		trialEnd := start
+		subs = append(subs, newSubscription(service.Name, TrialPrice, userID, start, &trialEnd))
+		start = start.AddDate(0, 1, 0)
+		if start.After(g.to) || g.rand.Float64() >= trialConversion {
+			return subs
+		}
+	}
+
+	// Последний оплаченный месяц; после To подписка считается действующей
+	end := start.AddDate(0, g.lifetime()-1, 0)
+	price := g.plan(service)
+	for {
+		// Повышение цены возможно при каждом годовом продлении
+		renewal := start.AddDate(0, 12, 0)
+		for !renewal.After(end) && !renewal.After(g.to) && g.rand.Float64() >= g.config.PriceChangeRate {
+			renewal = renewal.AddDate(0, 12, 0)
+		}
+		if renewal.After(end) || renewal.After(g.to) {
+			break
+		}
+
+		last := renewal.AddDate(0, -1, 0)
+		subs = append(subs, newSubscription(service.Name, price, userID, start, &last))
+		start, price = renewal, raise(price, g.rand)
+	}
+
+	var endDate *time.Time
+	if !end.After(g.to) {
+		endDate = &end
+	}
+	return append(subs, newSubscription(service.Name, price, userID, start, endDate))
+}
+
+// service выбирает сервис каталога с учетом популярности
+func (g *generator) service() Service {
+	total := 0
+	for _, s := range Catalog {
+		total += s.Weight
+	}
+	n := g.rand.Intn(total)
+	for _, s := range Catalog {
+		if n < s.Weight {
+			return s
+		}
+		n -= s.Weight
+	}
+	return Catalog[len(Catalog)-1]
+}
+
+// plan выбирает тариф: каждый следующий тариф выбирают вдвое реже предыдущего
+func (g *generator) plan(service Service) int {
+	for _, price := range service.Plans[:len(service.Plans)-1] {
+		if g.rand.Intn(2) == 0 {
+			return price
+		}
+	}
+	return service.Plans[len(service.Plans)-1]
+}
+
+// lifetime возвращает число оплаченных месяцев до отмены: геометрическое
+// распределение с вероятностью отмены ChurnRate в каждый месяц
+func (g *generator) lifetime() int {
+	p := g.config.ChurnRate
+	if p >= 1 {
+		return 1
+	}
+	return 1 + int(math.Log(1-g.rand.Float64())/math.Log(1-p))
+}
+
+// raise повышает цену на 10-30% с округлением до цены вида ...9 рублей
+func raise(price int, r *rand.Rand) int {
+	raised := price * (110 + r.Intn(21)) / 100
+	return (raised/10+1)*10 - 1
+}
+
+func newSubscription(serviceName string, price int, userID uuid.UUID, start time.Time, end *time.Time) *subscription.Subscription {
+	return &subscription.Subscription{
+		ServiceName: serviceName,
+		Price:       price,
+		UserID:      userID,
+		StartDate:   start,
+		EndDate:     end,
+	}
+}
+
+// month возвращает первое число месяца t в UTC
+func month(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// monthsBetween возвращает число месяцев от from до to
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+}
@@ -0,0 +1,150 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/domain/subscription"
+)
+
+// createRepository запоминает созданные подписки и возвращает err после limit подписок
+type createRepository struct {
+	subscription.Repository
+	created []*subscription.Subscription
+	limit   int
+	err     error
+}
+
+func (r *createRepository) Create(_ context.Context, sub *subscription.Subscription) error {
+	if r.err != nil && len(r.created) == r.limit {
+		return r.err
+	}
+	sub.ID = uuid.New()
+	r.created = append(r.created, sub)
+	return nil
+}
+
+func testConfig() Config {
+	config := DefaultConfig(time.Date(2024, 12, 15, 0, 0, 0, 0, time.UTC))
+	config.Seed = 42
+	return config
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("одинаковый seed дает одинаковый набор", func(t *testing.T) {
+		first, err := Generate(testConfig())
+		require.NoError(t, err)
+		second, err := Generate(testConfig())
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+
+		config := testConfig()
+		config.Seed = 43
+		other, err := Generate(config)
+		require.NoError(t, err)
+		assert.NotEqual(t, first, other)
+	})
+
+	t.Run("число подписок, пользователей и диапазон дат", func(t *testing.T) {
+		config := testConfig()
+		config.Users = 10
+		subs, err := Generate(config)
+		require.NoError(t, err)
+		require.Len(t, subs, config.Subscriptions)
+
+		users := map[uuid.UUID]bool{}
+		for _, sub := range subs {
+			users[sub.UserID] = true
+			assert.GreaterOrEqual(t, sub.Price, 1)
+			assert.False(t, sub.StartDate.Before(config.From), sub.StartDate)
+			if sub.EndDate != nil {
+				assert.False(t, sub.EndDate.Before(sub.StartDate))
+				assert.False(t, sub.EndDate.After(config.To))
+			}
+		}
+		assert.LessOrEqual(t, len(users), config.Users)
+	})
+
+	t.Run("пробные периоды, отмены и повышения цен", func(t *testing.T) {
+		subs, err := Generate(testConfig())
+		require.NoError(t, err)
+
+		trials, cancelled, active, raised := 0, 0, 0, 0
+		for i, sub := range subs {
+			switch {
+			case sub.Price == TrialPrice:
+				trials++
+				require.NotNil(t, sub.EndDate)
+				assert.Equal(t, sub.StartDate, *sub.EndDate)
+			case sub.EndDate == nil:
+				active++
+			default:
+				cancelled++
+			}
+			// Подписку с новой ценой на тот же сервис начинают со следующего месяца
+			if i > 0 {
+				prev := subs[i-1]
+				if prev.UserID == sub.UserID && prev.ServiceName == sub.ServiceName &&
+					prev.Price != TrialPrice && prev.EndDate != nil && sub.StartDate.Equal(prev.EndDate.AddDate(0, 1, 0)) {
+					assert.Greater(t, sub.Price, prev.Price)
+					raised++
+				}
+			}
+		}
+		assert.Positive(t, trials)
+		assert.Positive(t, cancelled)
+		assert.Positive(t, active)
+		assert.Positive(t, raised)
+	})
+
+	t.Run("один месяц и один пользователь", func(t *testing.T) {
+		config := testConfig()
+		config.Users = 1
+		config.From = config.To
+		subs, err := Generate(config)
+		require.NoError(t, err)
+		require.Len(t, subs, config.Subscriptions)
+		for _, sub := range subs {
+			assert.Equal(t, config.To, sub.StartDate)
+			assert.Equal(t, subs[0].UserID, sub.UserID)
+		}
+	})
+
+	t.Run("некорректные параметры", func(t *testing.T) {
+		config := testConfig()
+		config.Users = 0
+		config.From, config.To = config.To, config.From
+		config.ChurnRate = 0
+		_, err := Generate(config)
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "users")
+		assert.ErrorContains(t, err, "to must not be before from")
+		assert.ErrorContains(t, err, "churn rate")
+	})
+}
+
+func TestLoad(t *testing.T) {
+	subs, err := Generate(testConfig())
+	require.NoError(t, err)
+
+	t.Run("подписки сохраняются через репозиторий", func(t *testing.T) {
+		repo := &createRepository{}
+		n, err := Load(context.Background(), repo, subs)
+		require.NoError(t, err)
+		assert.Equal(t, len(subs), n)
+		assert.Len(t, repo.created, len(subs))
+	})
+
+	t.Run("ошибка репозитория прерывает загрузку", func(t *testing.T) {
+		failure := errors.New("insert failed")
+		repo := &createRepository{limit: 3, err: failure}
+		n, err := Load(context.Background(), repo, subs)
+		assert.ErrorIs(t, err, failure)
+		assert.Equal(t, 3, n)
+	})
+}
@@ -0,0 +1,298 @@
+package subctl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/actor"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/delivery/http/middleware"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// subscriptionsPath - путь ресурса подписок HTTP API
+const subscriptionsPath = "/api/v1/subscriptions"
+
+// APIError - ответ HTTP API об ошибке в формате RFC 7807
+type APIError struct {
+	Details problem.Details
+}
+
+// Error возвращает описание ошибки вместе с ошибками полей
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s", e.Details.Status, e.Details.Code)
+	if e.Details.Detail != "" {
+		fmt.Fprintf(&b, ": %s", e.Details.Detail)
+	}
+	for _, field := range e.Details.Errors {
+		fmt.Fprintf(&b, "; %s: %s", field.Field, field.Message)
+	}
+	return b.String()
+}
+
+// Unwrap сопоставляет код ошибки API с ошибкой доменного слоя, чтобы клиент
+// можно было использовать вместо сервиса: errors.Is(err, subscription.ErrSubscriptionNotFound)
+func (e *APIError) Unwrap() error {
+	switch e.Details.Code {
+	case problem.CodeNotFound:
+		return subscription.ErrSubscriptionNotFound
+	case problem.CodeConflict:
+		return subscription.ErrSubscriptionNotDeleted
+	case problem.CodeUnauthorized:
+		return auth.ErrUnauthenticated
+	case problem.CodeForbidden:
+		return auth.ErrForbidden
+	case problem.CodeValidationFailed, problem.CodeInvalidInput, problem.CodeInvalidQuery, problem.CodeInvalidID, problem.CodeInvalidPayload:
+		return subscription.ErrInvalidInput
+	default:
+		return nil
+	}
+}
+
+// ClientConfig хранит настройки клиента HTTP API
+type ClientConfig struct {
+	// BaseURL - адрес сервиса, например http://localhost:8080
+	BaseURL string
+	// APIKey передается в заголовке X-API-Key
+	APIKey string
+	// Organization - организация, с данными которой работает клиент; пустое
+	// значение - организация ключа
+	Organization string
+	// Actor записывается в журнал аудита изменений
+	Actor string
+	// Timeout ограничивает каждый запрос, кроме выгрузки; 0 - без ограничения
+	Timeout time.Duration
+}
+
+// Client реализует subscription.Service поверх HTTP API сервиса
+type Client struct {
+	config ClientConfig
+	http   *http.Client
+}
+
+var _ subscription.Service = (*Client)(nil)
+
+// NewClient создает клиент HTTP API
+func NewClient(config ClientConfig) *Client {
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	return &Client{config: config, http: &http.Client{}}
+}
+
+// Create создает подписку
+func (c *Client) Create(ctx context.Context, req subscription.CreateSubscriptionRequest) (*subscription.Subscription, error) {
+	var sub subscription.Subscription
+	if err := c.do(ctx, http.MethodPost, subscriptionsPath, nil, req, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Get возвращает подписку по ID
+func (c *Client) Get(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	var sub subscription.Subscription
+	if err := c.do(ctx, http.MethodGet, subscriptionsPath+"/"+id.String(), nil, nil, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// GetAsOf возвращает подписку в том виде, в котором она существовала в момент asOf
+func (c *Client) GetAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (*subscription.Subscription, error) {
+	query := url.Values{"as_of": {asOf.Format(time.RFC3339)}}
+	var sub subscription.Subscription
+	if err := c.do(ctx, http.MethodGet, subscriptionsPath+"/"+id.String(), query, nil, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Update изменяет подписку
+func (c *Client) Update(ctx context.Context, id uuid.UUID, req subscription.UpdateSubscriptionRequest) (*subscription.Subscription, error) {
+	var sub subscription.Subscription
+	if err := c.do(ctx, http.MethodPut, subscriptionsPath+"/"+id.String(), nil, req, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Delete удаляет подписку
+func (c *Client) Delete(ctx context.Context, id uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, subscriptionsPath+"/"+id.String(), nil, nil, nil)
+}
+
+// Restore восстанавливает удаленную подписку
+func (c *Client) Restore(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	var sub subscription.Subscription
+	if err := c.do(ctx, http.MethodPost, subscriptionsPath+"/"+id.String()+"/restore", nil, nil, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// List возвращает подписки по фильтру. HTTP API не поддерживает постраничную
+// выборку подписок, поэтому Limit и Offset применяются к полученному списку
+func (c *Client) List(ctx context.Context, filter subscription.ListFilter) ([]*subscription.Subscription, error) {
+	var subs []*subscription.Subscription
+	if err := c.do(ctx, http.MethodGet, subscriptionsPath, listQuery(filter), nil, &subs); err != nil {
+		return nil, err
+	}
+
+	if filter.Offset >= len(subs) {
+		return []*subscription.Subscription{}, nil
+	}
+	subs = subs[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(subs) {
+		subs = subs[:filter.Limit]
+	}
+	return subs, nil
+}
+
+// Export передает в fn подписки по фильтру, читая выгрузку NDJSON потоком
+func (c *Client) Export(ctx context.Context, filter subscription.ListFilter, fn func(*subscription.Subscription) error) error {
+	query := listQuery(filter)
+	query.Set("format", "ndjson")
+
+	resp, err := c.send(ctx, http.MethodGet, subscriptionsPath+"/export", query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var sub subscription.Subscription
+		if err := json.Unmarshal(scanner.Bytes(), &sub); err != nil {
+			return fmt.Errorf("failed to decode exported subscription: %w", err)
+		}
+		if err := fn(&sub); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read export: %w", err)
+	}
+	return nil
+}
+
+// CalculateTotalCost рассчитывает стоимость подписок за период
+func (c *Client) CalculateTotalCost(ctx context.Context, filter subscription.SubscriptionFilter) (*subscription.TotalCostResponse, error) {
+	query := listQuery(subscription.ListFilter{
+		UserID:         filter.UserID,
+		ServiceName:    filter.ServiceName,
+		IncludeDeleted: filter.IncludeDeleted,
+		AsOf:           filter.AsOf,
+	})
+	query.Set("start_period", subscription.FormatMonthYear(filter.StartPeriod))
+	query.Set("end_period", subscription.FormatMonthYear(filter.EndPeriod))
+
+	var cost subscription.TotalCostResponse
+	if err := c.do(ctx, http.MethodGet, subscriptionsPath+"/calculate-cost", query, nil, &cost); err != nil {
+		return nil, err
+	}
+	return &cost, nil
+}
+
+// listQuery переводит фильтр списка в параметры query-строки
+func listQuery(filter subscription.ListFilter) url.Values {
+	query := url.Values{}
+	if filter.UserID != nil {
+		query.Set("user_id", filter.UserID.String())
+	}
+	if filter.ServiceName != nil {
+		query.Set("service_name", *filter.ServiceName)
+	}
+	if filter.IncludeDeleted {
+		query.Set("include_deleted", strconv.FormatBool(true))
+	}
+	if filter.AsOf != nil {
+		query.Set("as_of", filter.AsOf.Format(time.RFC3339))
+	}
+	return query
+}
+
+// do выполняет запрос с телом body в JSON и декодирует ответ в out, если он задан
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	if c.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+		defer cancel()
+	}
+
+	resp, err := c.send(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// send отправляет запрос и возвращает успешный ответ; ответ об ошибке
+// преобразуется в *APIError
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	target := c.config.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.config.APIKey != "" {
+		req.Header.Set(middleware.APIKeyHeader, c.config.APIKey)
+	}
+	if c.config.Organization != "" {
+		req.Header.Set(tenant.Header, c.config.Organization)
+	}
+	if c.config.Actor != "" {
+		req.Header.Set(actor.Header, c.config.Actor)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", c.config.BaseURL, err)
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	apiErr := &APIError{}
+	if err := json.NewDecoder(resp.Body).Decode(&apiErr.Details); err != nil || apiErr.Details.Code == "" {
+		return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	apiErr.Details.Status = resp.StatusCode
+	return nil, apiErr
+}
@@ -0,0 +1,242 @@
+package subctl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/actor"
+	"github.com/subscription-service/internal/delivery/http/handler"
+	"github.com/subscription-service/internal/delivery/http/middleware"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// MockSubscriptionService мок для сервиса подписок
+type MockSubscriptionService struct {
+	mock.Mock
+}
+
+func (m *MockSubscriptionService) Create(ctx context.Context, req subscription.CreateSubscriptionRequest) (*subscription.Subscription, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Get(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) GetAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (*subscription.Subscription, error) {
+	args := m.Called(ctx, id, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Update(ctx context.Context, id uuid.UUID, req subscription.UpdateSubscriptionRequest) (*subscription.Subscription, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionService) Restore(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) List(ctx context.Context, filter subscription.ListFilter) ([]*subscription.Subscription, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Export(ctx context.Context, filter subscription.ListFilter, fn func(*subscription.Subscription) error) error {
+	args := m.Called(ctx, filter, fn)
+	if subs, ok := args.Get(1).([]*subscription.Subscription); ok {
+		for _, sub := range subs {
+			if err := fn(sub); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionService) CalculateTotalCost(ctx context.Context, filter subscription.SubscriptionFilter) (*subscription.TotalCostResponse, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.TotalCostResponse), args.Error(1)
+}
+
+// testSubscription возвращает подписку с заполненными полями
+func testSubscription() *subscription.Subscription {
+	endDate := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	return &subscription.Subscription{
+		ID:          uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		ServiceName: "Yandex Plus",
+		Price:       400,
+		UserID:      uuid.MustParse("22222222-2222-2222-2222-222222222222"),
+		StartDate:   time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:     &endDate,
+		CreatedAt:   time.Date(2024, 7, 2, 10, 0, 0, 0, time.UTC),
+		UpdatedAt:   time.Date(2024, 7, 2, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+// newTestServer запускает маршруты подписок HTTP API над моком сервиса и
+// сохраняет заголовки последнего запроса в headers
+func newTestServer(t *testing.T, service subscription.Service, headers *http.Header) *httptest.Server {
+	t.Helper()
+	h := handler.NewSubscriptionHandler(service)
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if headers != nil {
+				*headers = r.Header.Clone()
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Route(subscriptionsPath, func(r chi.Router) {
+		r.Post("/", h.Create)
+		r.Get("/", h.List)
+		r.Get("/export", h.Export)
+		r.Get("/calculate-cost", h.CalculateTotalCost)
+		r.Get("/{id}", h.Get)
+		r.Put("/{id}", h.Update)
+		r.Delete("/{id}", h.Delete)
+		r.Post("/{id}/restore", h.Restore)
+	})
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient(t *testing.T) {
+	t.Run("создание передает ключ, организацию и исполнителя", func(t *testing.T) {
+		service := new(MockSubscriptionService)
+		var headers http.Header
+		server := newTestServer(t, service, &headers)
+		client := NewClient(ClientConfig{BaseURL: server.URL + "/", APIKey: "key", Organization: "org", Actor: "support"})
+
+		sub := testSubscription()
+		req := subscription.CreateSubscriptionRequest{ServiceName: sub.ServiceName, Price: sub.Price, UserID: sub.UserID, StartDate: "07-2024"}
+		service.On("Create", mock.Anything, req).Return(sub, nil)
+
+		created, err := client.Create(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, sub.ID, created.ID)
+		assert.True(t, sub.StartDate.Equal(created.StartDate))
+		assert.Equal(t, "key", headers.Get(middleware.APIKeyHeader))
+		assert.Equal(t, "org", headers.Get(tenant.Header))
+		assert.Equal(t, "support", headers.Get(actor.Header))
+		service.AssertExpectations(t)
+	})
+
+	t.Run("ошибки API сопоставляются с ошибками домена", func(t *testing.T) {
+		service := new(MockSubscriptionService)
+		server := newTestServer(t, service, nil)
+		client := NewClient(ClientConfig{BaseURL: server.URL})
+
+		id := uuid.New()
+		service.On("Get", mock.Anything, id).Return(nil, subscription.ErrSubscriptionNotFound)
+		_, err := client.Get(context.Background(), id)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+
+		service.On("Restore", mock.Anything, id).Return(nil, subscription.ErrSubscriptionNotDeleted)
+		_, err = client.Restore(context.Background(), id)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotDeleted)
+
+		// Запрос, не прошедший валидацию API, содержит ошибки полей
+		_, err = client.Create(context.Background(), subscription.CreateSubscriptionRequest{ServiceName: "Netflix"})
+		var apiErr *APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, problem.CodeValidationFailed, apiErr.Details.Code)
+		assert.Equal(t, http.StatusBadRequest, apiErr.Details.Status)
+		assert.NotEmpty(t, apiErr.Details.Errors)
+		assert.ErrorIs(t, err, subscription.ErrInvalidInput)
+		assert.Contains(t, err.Error(), "price")
+	})
+
+	t.Run("список с фильтром и постраничной выборкой", func(t *testing.T) {
+		service := new(MockSubscriptionService)
+		server := newTestServer(t, service, nil)
+		client := NewClient(ClientConfig{BaseURL: server.URL})
+
+		userID := uuid.New()
+		name := "Netflix"
+		subs := []*subscription.Subscription{testSubscription(), testSubscription(), testSubscription()}
+		service.On("List", mock.Anything, subscription.ListFilter{UserID: &userID, ServiceName: &name, IncludeDeleted: true}).Return(subs, nil)
+
+		got, err := client.List(context.Background(), subscription.ListFilter{
+			UserID: &userID, ServiceName: &name, IncludeDeleted: true, Limit: 1, Offset: 1,
+		})
+		require.NoError(t, err)
+		assert.Len(t, got, 1)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("выгрузка читается из NDJSON", func(t *testing.T) {
+		service := new(MockSubscriptionService)
+		server := newTestServer(t, service, nil)
+		client := NewClient(ClientConfig{BaseURL: server.URL})
+
+		subs := []*subscription.Subscription{testSubscription(), testSubscription()}
+		service.On("Export", mock.Anything, subscription.ListFilter{}, mock.Anything).Return(nil, subs)
+
+		var got []*subscription.Subscription
+		err := client.Export(context.Background(), subscription.ListFilter{}, func(sub *subscription.Subscription) error {
+			got = append(got, sub)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, subs[0].ID, got[0].ID)
+	})
+
+	t.Run("расчет стоимости передает период", func(t *testing.T) {
+		service := new(MockSubscriptionService)
+		server := newTestServer(t, service, nil)
+		client := NewClient(ClientConfig{BaseURL: server.URL})
+
+		filter := subscription.SubscriptionFilter{
+			StartPeriod: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndPeriod:   time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+		}
+		service.On("CalculateTotalCost", mock.Anything, filter).Return(&subscription.TotalCostResponse{TotalCost: 4800}, nil)
+
+		cost, err := client.CalculateTotalCost(context.Background(), filter)
+		require.NoError(t, err)
+		assert.Equal(t, 4800, cost.TotalCost)
+	})
+}
@@ -0,0 +1,438 @@
+package subctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/subscription-service/internal/delivery/http/problem"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/export"
+	"github.com/subscription-service/internal/i18n"
+	"github.com/subscription-service/internal/validation"
+)
+
+// withService открывает сервис подписок и вызывает fn, закрывая сервис после нее
+func (a *app) withService(cmd *cobra.Command, fn func(ctx context.Context, service subscription.Service, output Output) error) error {
+	output, err := a.output()
+	if err != nil {
+		return err
+	}
+
+	ctx, service, closeFn, err := a.service(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	return fn(ctx, service, output)
+}
+
+// filterFlags - флаги фильтрации подписок, общие для list, export и cost
+type filterFlags struct {
+	userID         string
+	serviceName    string
+	includeDeleted bool
+	asOf           string
+}
+
+func (f *filterFlags) register(flags *pflag.FlagSet) {
+	flags.StringVar(&f.userID, "user-id", "", "only subscriptions of this user")
+	flags.StringVar(&f.serviceName, "service-name", "", "only subscriptions to this service")
+	flags.BoolVar(&f.includeDeleted, "include-deleted", false, "include deleted subscriptions")
+	flags.StringVar(&f.asOf, "as-of", "", "state at this moment (RFC 3339)")
+}
+
+// listFilter возвращает фильтр списка по флагам
+func (f *filterFlags) listFilter() (subscription.ListFilter, error) {
+	filter := subscription.ListFilter{IncludeDeleted: f.includeDeleted}
+
+	var err error
+	if filter.UserID, err = parseOptionalUUID("user_id", f.userID); err != nil {
+		return filter, err
+	}
+	if f.serviceName != "" {
+		filter.ServiceName = &f.serviceName
+	}
+	if filter.AsOf, err = parseAsOf(f.asOf); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+func (a *app) listCommand() *cobra.Command {
+	var (
+		filters       filterFlags
+		limit, offset int
+	)
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List subscriptions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			filter, err := filters.listFilter()
+			if err != nil {
+				return err
+			}
+			if limit < 0 || offset < 0 {
+				return fmt.Errorf("--limit and --offset must not be negative")
+			}
+			filter.Limit, filter.Offset = limit, offset
+
+			return a.withService(cmd, func(ctx context.Context, service subscription.Service, output Output) error {
+				subs, err := service.List(ctx, filter)
+				if err != nil {
+					return err
+				}
+				return printSubscriptions(cmd.OutOrStdout(), output, subs)
+			})
+		},
+	}
+	filters.register(cmd.Flags())
+	cmd.Flags().IntVar(&limit, "limit", 0, "maximum number of subscriptions (0 - all)")
+	cmd.Flags().IntVar(&offset, "offset", 0, "number of subscriptions to skip")
+	return cmd
+}
+
+func (a *app) getCommand() *cobra.Command {
+	var asOf string
+	cmd := &cobra.Command{
+		Use:   "get ID",
+		Short: "Show a subscription",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			at, err := parseAsOf(asOf)
+			if err != nil {
+				return err
+			}
+
+			return a.withService(cmd, func(ctx context.Context, service subscription.Service, output Output) error {
+				var sub *subscription.Subscription
+				if at != nil {
+					sub, err = service.GetAsOf(ctx, id, *at)
+				} else {
+					sub, err = service.Get(ctx, id)
+				}
+				if err != nil {
+					return err
+				}
+				return printSubscription(cmd.OutOrStdout(), output, sub)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&asOf, "as-of", "", "show the subscription as it was at this moment (RFC 3339)")
+	return cmd
+}
+
+func (a *app) createCommand() *cobra.Command {
+	var (
+		req     subscription.CreateSubscriptionRequest
+		userID  string
+		endDate string
+	)
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a subscription",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if userID != "" {
+				id, err := uuid.Parse(userID)
+				if err != nil {
+					return invalidField("user_id", "uuid", "must be a valid UUID")
+				}
+				req.UserID = id
+			}
+			if cmd.Flags().Changed("end-date") {
+				req.EndDate = &endDate
+			}
+
+			return a.withService(cmd, func(ctx context.Context, service subscription.Service, output Output) error {
+				if err := validate(ctx, req); err != nil {
+					return err
+				}
+				sub, err := service.Create(ctx, req)
+				if err != nil {
+					return err
+				}
+				return printSubscription(cmd.OutOrStdout(), output, sub)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&req.ServiceName, "service-name", "", "service name")
+	cmd.Flags().IntVar(&req.Price, "price", 0, "monthly price in rubles")
+	cmd.Flags().StringVar(&userID, "user-id", "", "subscriber user ID")
+	cmd.Flags().StringVar(&req.StartDate, "start-date", "", "start month (MM-YYYY)")
+	cmd.Flags().StringVar(&endDate, "end-date", "", "end month (MM-YYYY); open-ended if omitted")
+	return cmd
+}
+
+func (a *app) updateCommand() *cobra.Command {
+	var (
+		req     subscription.UpdateSubscriptionRequest
+		price   int
+		endDate string
+	)
+	cmd := &cobra.Command{
+		Use:   "update ID",
+		Short: "Change fields of a subscription",
+		Long:  "Change fields of a subscription. Only the flags that are given are changed; --end-date \"\" makes the subscription open-ended.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("price") {
+				req.Price = &price
+			}
+			if cmd.Flags().Changed("end-date") {
+				req.EndDate = &endDate
+			}
+
+			return a.withService(cmd, func(ctx context.Context, service subscription.Service, output Output) error {
+				if err := validate(ctx, req); err != nil {
+					return err
+				}
+				sub, err := service.Update(ctx, id, req)
+				if err != nil {
+					return err
+				}
+				return printSubscription(cmd.OutOrStdout(), output, sub)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&req.ServiceName, "service-name", "", "new service name")
+	cmd.Flags().IntVar(&price, "price", 0, "new monthly price in rubles")
+	cmd.Flags().StringVar(&req.StartDate, "start-date", "", "new start month (MM-YYYY)")
+	cmd.Flags().StringVar(&endDate, "end-date", "", "new end month (MM-YYYY)")
+	return cmd
+}
+
+func (a *app) deleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete ID",
+		Short: "Delete a subscription; it can be restored until it is purged",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+
+			return a.withService(cmd, func(ctx context.Context, service subscription.Service, output Output) error {
+				if err := service.Delete(ctx, id); err != nil {
+					return err
+				}
+				if output == OutputTable {
+					fmt.Fprintf(cmd.OutOrStdout(), "Subscription %s deleted\n", id)
+				}
+				return nil
+			})
+		},
+	}
+}
+
+func (a *app) restoreCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore ID",
+		Short: "Restore a deleted subscription",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+
+			return a.withService(cmd, func(ctx context.Context, service subscription.Service, output Output) error {
+				sub, err := service.Restore(ctx, id)
+				if err != nil {
+					return err
+				}
+				return printSubscription(cmd.OutOrStdout(), output, sub)
+			})
+		},
+	}
+}
+
+func (a *app) costCommand() *cobra.Command {
+	var (
+		filters                filterFlags
+		startPeriod, endPeriod string
+	)
+	cmd := &cobra.Command{
+		Use:   "cost",
+		Short: "Calculate the total cost of subscriptions for a period",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			list, err := filters.listFilter()
+			if err != nil {
+				return err
+			}
+			filter := subscription.SubscriptionFilter{
+				UserID:         list.UserID,
+				ServiceName:    list.ServiceName,
+				IncludeDeleted: list.IncludeDeleted,
+				AsOf:           list.AsOf,
+			}
+
+			if filter.StartPeriod, err = parseMonthYear("start_period", startPeriod); err != nil {
+				return err
+			}
+			if filter.EndPeriod, err = parseMonthYear("end_period", endPeriod); err != nil {
+				return err
+			}
+			if filter.EndPeriod.Before(filter.StartPeriod) {
+				return invalidField("end_period", subscription.CodeEndBeforeStart, "cannot be before start_period")
+			}
+
+			return a.withService(cmd, func(ctx context.Context, service subscription.Service, output Output) error {
+				cost, err := service.CalculateTotalCost(ctx, filter)
+				if err != nil {
+					return err
+				}
+				return printCost(cmd.OutOrStdout(), output, cost)
+			})
+		},
+	}
+	filters.register(cmd.Flags())
+	cmd.Flags().StringVar(&startPeriod, "start-period", "", "first month of the period (MM-YYYY)")
+	cmd.Flags().StringVar(&endPeriod, "end-period", "", "last month of the period (MM-YYYY)")
+	return cmd
+}
+
+func (a *app) exportCommand() *cobra.Command {
+	var (
+		filters filterFlags
+		format  string
+		file    string
+	)
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export subscriptions to CSV, NDJSON or XLSX",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			filter, err := filters.listFilter()
+			if err != nil {
+				return err
+			}
+			f, err := export.ParseFormat(format)
+			if err != nil {
+				return err
+			}
+
+			return a.withService(cmd, func(ctx context.Context, service subscription.Service, _ Output) error {
+				out, closeOut, err := createOutput(cmd.OutOrStdout(), file)
+				if err != nil {
+					return err
+				}
+
+				ew, err := export.NewWriter(f, out)
+				if err == nil {
+					err = service.Export(ctx, filter, ew.Write)
+				}
+				if err == nil {
+					err = ew.Close()
+				}
+				if closeErr := closeOut(); err == nil {
+					err = closeErr
+				}
+				return err
+			})
+		},
+	}
+	filters.register(cmd.Flags())
+	cmd.Flags().StringVar(&format, "format", string(export.FormatCSV), "file format: csv, ndjson or xlsx")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "write to this file instead of standard output")
+	return cmd
+}
+
+// createOutput возвращает файл path или stdout, если путь не задан
+func createOutput(stdout io.Writer, path string) (io.Writer, func() error, error) {
+	if path == "" || path == "-" {
+		return stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return f, f.Close, nil
+}
+
+// validate проверяет запрос теми же правилами, что и HTTP API
+func validate(ctx context.Context, req any) error {
+	err := validation.Validator().Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	fields := problem.FromValidationErrors(err, i18n.Translator(ctx))
+	if len(fields) == 0 {
+		return fmt.Errorf("%w: %v", subscription.ErrInvalidInput, err)
+	}
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	return fmt.Errorf("%w: %s", subscription.ErrInvalidInput, strings.Join(messages, "; "))
+}
+
+// invalidField возвращает ошибку значения флага или поля запроса с теми же
+// кодами, что и ошибки параметров HTTP API
+func invalidField(field, code, message string) error {
+	return subscription.NewValidationError(field, code, message)
+}
+
+// parseID разбирает ID подписки из аргумента команды
+func parseID(s string) (uuid.UUID, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil, invalidField("id", "uuid", "must be a valid UUID")
+	}
+	return id, nil
+}
+
+// parseOptionalUUID разбирает необязательный UUID; пустая строка - nil
+func parseOptionalUUID(field, s string) (*uuid.UUID, error) {
+	if s == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return nil, invalidField(field, "uuid", "must be a valid UUID")
+	}
+	return &id, nil
+}
+
+// parseAsOf разбирает необязательную метку времени RFC 3339
+func parseAsOf(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	at, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, invalidField("as_of", "datetime", "must be an RFC 3339 timestamp")
+	}
+	return &at, nil
+}
+
+// parseMonthYear разбирает обязательный месяц в формате MM-YYYY
+func parseMonthYear(field, s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, invalidField(field, "required", "is required")
+	}
+	t, err := subscription.ParseMonthYear(s)
+	if err != nil {
+		return time.Time{}, invalidField(field, subscription.CodeMonthYear, "must be in MM-YYYY format")
+	}
+	return t, nil
+}
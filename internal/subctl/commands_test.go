@@ -0,0 +1,200 @@
+package subctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/actor"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// runCommand выполняет subctl --direct с аргументами args над сервисом service
+// и возвращает стандартный вывод и вывод ошибок
+func runCommand(t *testing.T, service subscription.Service, args ...string) (string, string, error) {
+	t.Helper()
+	root := NewRootCommand(func(context.Context, string) (subscription.Service, func() error, error) {
+		return service, func() error { return nil }, nil
+	})
+
+	var stdout, stderr bytes.Buffer
+	root.SetOut(&stdout)
+	root.SetErr(&stderr)
+	root.SetArgs(append([]string{"--direct", "--actor", "support"}, args...))
+	err := root.ExecuteContext(context.Background())
+	return stdout.String(), stderr.String(), err
+}
+
+func TestCommands(t *testing.T) {
+	t.Run("список выводится таблицей", func(t *testing.T) {
+		service := new(MockSubscriptionService)
+		sub := testSubscription()
+		service.On("List", mock.Anything, subscription.ListFilter{Limit: 10}).Return([]*subscription.Subscription{sub}, nil)
+
+		out, _, err := runCommand(t, service, "list", "--limit", "10")
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		require.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[0], "ID "))
+		assert.Contains(t, lines[1], "Yandex Plus")
+		assert.Contains(t, lines[1], "07-2024")
+	})
+
+	t.Run("подписка выводится в JSON", func(t *testing.T) {
+		service := new(MockSubscriptionService)
+		sub := testSubscription()
+		service.On("Get", mock.Anything, sub.ID).Return(sub, nil)
+
+		out, _, err := runCommand(t, service, "get", sub.ID.String(), "-o", "json")
+		require.NoError(t, err)
+
+		var got subscription.Subscription
+		require.NoError(t, json.Unmarshal([]byte(out), &got))
+		assert.Equal(t, sub.ID, got.ID)
+	})
+
+	t.Run("организация и исполнитель передаются в контексте", func(t *testing.T) {
+		service := new(MockSubscriptionService)
+		org := uuid.New()
+		sub := testSubscription()
+		service.On("Delete", mock.MatchedBy(func(ctx context.Context) bool {
+			return tenant.FromContext(ctx) == org && actor.FromContext(ctx) == "support"
+		}), sub.ID).Return(nil)
+
+		out, _, err := runCommand(t, service, "--org", org.String(), "delete", sub.ID.String())
+		require.NoError(t, err)
+		assert.Contains(t, out, "deleted")
+		service.AssertExpectations(t)
+	})
+
+	t.Run("запрос проверяется до вызова сервиса", func(t *testing.T) {
+		service := new(MockSubscriptionService)
+
+		_, _, err := runCommand(t, service, "create", "--service-name", "Netflix", "--price", "0",
+			"--user-id", uuid.NewString(), "--start-date", "07-2024")
+		assert.ErrorIs(t, err, subscription.ErrInvalidInput)
+		assert.Contains(t, err.Error(), "price")
+
+		_, _, err = runCommand(t, service, "get", "not-a-uuid")
+		assert.ErrorIs(t, err, subscription.ErrInvalidInput)
+
+		_, _, err = runCommand(t, service, "cost", "--start-period", "12-2024", "--end-period", "01-2024")
+		assert.ErrorIs(t, err, subscription.ErrInvalidInput)
+
+		service.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("обновление меняет только указанные поля", func(t *testing.T) {
+		service := new(MockSubscriptionService)
+		sub := testSubscription()
+		price, endDate := 500, ""
+		service.On("Update", mock.Anything, sub.ID, subscription.UpdateSubscriptionRequest{Price: &price, EndDate: &endDate}).Return(sub, nil)
+
+		_, _, err := runCommand(t, service, "update", sub.ID.String(), "--price", "500", "--end-date", "")
+		require.NoError(t, err)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("стоимость выводится в CSV", func(t *testing.T) {
+		service := new(MockSubscriptionService)
+		service.On("CalculateTotalCost", mock.Anything, mock.Anything).Return(&subscription.TotalCostResponse{TotalCost: 2400}, nil)
+
+		out, _, err := runCommand(t, service, "cost", "--start-period", "01-2024", "--end-period", "06-2024", "-o", "csv")
+		require.NoError(t, err)
+		assert.Equal(t, "total_cost\n2400\n", out)
+	})
+
+	t.Run("выгрузка в файл", func(t *testing.T) {
+		service := new(MockSubscriptionService)
+		service.On("Export", mock.Anything, subscription.ListFilter{}, mock.Anything).Return(nil, []*subscription.Subscription{testSubscription()})
+
+		file := filepath.Join(t.TempDir(), "subs.ndjson")
+		_, _, err := runCommand(t, service, "export", "--format", "ndjson", "--file", file)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"service_name":"Yandex Plus"`)
+	})
+
+	t.Run("неизвестный формат вывода", func(t *testing.T) {
+		_, _, err := runCommand(t, new(MockSubscriptionService), "list", "-o", "yaml")
+		assert.Error(t, err)
+	})
+}
+
+func TestImport(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("CSV: некорректные строки пропускаются", func(t *testing.T) {
+		service := new(MockSubscriptionService)
+		endDate := "12-2024"
+		service.On("Create", mock.Anything, subscription.CreateSubscriptionRequest{
+			ServiceName: "Netflix", Price: 600, UserID: userID, StartDate: "01-2024", EndDate: &endDate,
+		}).Return(testSubscription(), nil).Once()
+
+		file := filepath.Join(t.TempDir(), "subs.csv")
+		content := "id,service_name,price,user_id,start_date,end_date\n" +
+			"x,Netflix,600," + userID.String() + ",01-2024,12-2024\n" +
+			"x,Netflix,abc," + userID.String() + ",01-2024,\n" +
+			"x,,600," + userID.String() + ",01-2024,\n"
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o644))
+
+		out, errOut, err := runCommand(t, service, "import", file)
+		assert.Error(t, err)
+		assert.Equal(t, "1 imported, 2 failed\n", out)
+		assert.Contains(t, errOut, "line 3: price")
+		assert.Contains(t, errOut, "line 4: ")
+		service.AssertExpectations(t)
+	})
+
+	t.Run("CSV: экранированные формулы из выгрузки", func(t *testing.T) {
+		service := new(MockSubscriptionService)
+		service.On("Create", mock.Anything, subscription.CreateSubscriptionRequest{
+			ServiceName: "=Netflix", Price: 600, UserID: userID, StartDate: "01-2024",
+		}).Return(testSubscription(), nil).Once()
+
+		file := filepath.Join(t.TempDir(), "subs.csv")
+		content := "service_name,price,user_id,start_date\n" +
+			"'=Netflix,600," + userID.String() + ",01-2024\n"
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o644))
+
+		out, _, err := runCommand(t, service, "import", file)
+		require.NoError(t, err)
+		assert.Equal(t, "1 imported, 0 failed\n", out)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("NDJSON из выгрузки без изменений", func(t *testing.T) {
+		service := new(MockSubscriptionService)
+
+		sub := testSubscription()
+		line, err := json.Marshal(sub)
+		require.NoError(t, err)
+		file := filepath.Join(t.TempDir(), "subs.ndjson")
+		require.NoError(t, os.WriteFile(file, append(line, '\n'), 0o644))
+
+		out, _, err := runCommand(t, service, "import", file, "--dry-run")
+		require.NoError(t, err)
+		assert.Equal(t, "1 valid, 0 failed\n", out)
+		service.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CSV без обязательной колонки", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "subs.csv")
+		require.NoError(t, os.WriteFile(file, []byte("service_name,price\nNetflix,600\n"), 0o644))
+
+		_, _, err := runCommand(t, new(MockSubscriptionService), "import", file)
+		assert.ErrorContains(t, err, "user_id")
+	})
+}
@@ -0,0 +1,247 @@
+package subctl
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/export"
+)
+
+// importRow - подписка из файла импорта с номером строки для сообщений об ошибках
+type importRow struct {
+	line int
+	req  subscription.CreateSubscriptionRequest
+	err  error
+}
+
+func (a *app) importCommand() *cobra.Command {
+	var (
+		format string
+		dryRun bool
+	)
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Create subscriptions from a CSV or NDJSON file",
+		Long: `Create subscriptions from a CSV or NDJSON file in the export layout, so a
+file produced by "subctl export" can be imported into another organization.
+
+CSV needs a header with service_name, price, user_id and start_date columns;
+end_date is optional and other columns, such as id, are ignored. Every row is
+validated and created separately: invalid rows are reported and skipped, and
+the command fails if any row was not imported. Use "-" to read standard input.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := importFormat(format, args[0])
+			if err != nil {
+				return err
+			}
+
+			in, closeIn, err := openInput(cmd.InOrStdin(), args[0])
+			if err != nil {
+				return err
+			}
+			defer closeIn()
+
+			rows, err := readImport(in, f)
+			if err != nil {
+				return err
+			}
+
+			return a.withService(cmd, func(ctx context.Context, service subscription.Service, _ Output) error {
+				return importRows(ctx, service, rows, dryRun, cmd.OutOrStdout(), cmd.ErrOrStderr())
+			})
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "", "file format: csv or ndjson (default: by file extension, csv for standard input)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only validate the file, create nothing")
+	return cmd
+}
+
+// importFormat определяет формат файла импорта по флагу или расширению
+func importFormat(format, path string) (export.Format, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	f, err := export.ParseFormat(format)
+	if err != nil {
+		return "", err
+	}
+	if f == export.FormatXLSX {
+		return "", fmt.Errorf("%w: import supports csv and ndjson", export.ErrUnsupportedFormat)
+	}
+	return f, nil
+}
+
+// openInput открывает файл path или stdin для "-"
+func openInput(stdin io.Reader, path string) (io.Reader, func() error, error) {
+	if path == "-" {
+		return stdin, func() error { return nil }, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open import file: %w", err)
+	}
+	return f, f.Close, nil
+}
+
+// importRows проверяет и создает подписки по одной. Ошибки строк выводятся
+// в errOut; итоговая ошибка возвращается, если хотя бы одна строка не импортирована
+func importRows(ctx context.Context, service subscription.Service, rows []importRow, dryRun bool, out, errOut io.Writer) error {
+	imported, failed := 0, 0
+	for _, row := range rows {
+		err := row.err
+		if err == nil {
+			err = validate(ctx, row.req)
+		}
+		if err == nil && !dryRun {
+			_, err = service.Create(ctx, row.req)
+		}
+		if err != nil {
+			fmt.Fprintf(errOut, "line %d: %v\n", row.line, err)
+			failed++
+			// Отмена или недоступность сервиса не относятся к строке: остальные
+			// строки завершатся той же ошибкой
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		imported++
+	}
+
+	verb := "imported"
+	if dryRun {
+		verb = "valid"
+	}
+	fmt.Fprintf(out, "%d %s, %d failed\n", imported, verb, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rows were not imported", failed, len(rows))
+	}
+	return nil
+}
+
+// readImport читает все строки файла импорта. Ошибки отдельных строк
+// сохраняются в строках, ошибка чтения файла целиком возвращается сразу
+func readImport(r io.Reader, format export.Format) ([]importRow, error) {
+	if format == export.FormatNDJSON {
+		return readNDJSON(r)
+	}
+	return readCSV(r)
+}
+
+// readCSV читает CSV с заголовком в формате выгрузки, включая экранирование
+// формул
+func readCSV(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, required := range []string{"service_name", "price", "user_id", "start_date"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header has no %s column", required)
+		}
+	}
+
+	var rows []importRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(export.UnescapeFormula(record[i]))
+			}
+			return ""
+		}
+		row := importRow{line: line}
+		row.req, row.err = createRequest(value("service_name"), value("price"), value("user_id"), value("start_date"), value("end_date"))
+		rows = append(rows, row)
+	}
+}
+
+// createRequest собирает запрос на создание из строковых значений колонок
+func createRequest(serviceName, price, userID, startDate, endDate string) (subscription.CreateSubscriptionRequest, error) {
+	req := subscription.CreateSubscriptionRequest{ServiceName: serviceName, StartDate: startDate}
+	if price != "" {
+		p, err := strconv.Atoi(price)
+		if err != nil {
+			return req, invalidField("price", "number", "must be a number")
+		}
+		req.Price = p
+	}
+	if userID != "" {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return req, invalidField("user_id", "uuid", "must be a valid UUID")
+		}
+		req.UserID = id
+	}
+	if endDate != "" {
+		req.EndDate = &endDate
+	}
+	return req, nil
+}
+
+// readNDJSON читает подписки в представлении JSON API, по одной на строке
+func readNDJSON(r io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []importRow
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		row := importRow{line: line}
+		var sub subscription.Subscription
+		if err := json.Unmarshal(scanner.Bytes(), &sub); err != nil {
+			row.err = fmt.Errorf("%w: %v", subscription.ErrInvalidInput, err)
+		} else {
+			row.req = subscription.CreateSubscriptionRequest{
+				ServiceName: sub.ServiceName,
+				Price:       sub.Price,
+				UserID:      sub.UserID,
+			}
+			if !sub.StartDate.IsZero() {
+				row.req.StartDate = subscription.FormatMonthYear(sub.StartDate)
+			}
+			if sub.EndDate != nil {
+				endDate := subscription.FormatMonthYear(*sub.EndDate)
+				row.req.EndDate = &endDate
+			}
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read NDJSON: %w", err)
+	}
+	return rows, nil
+}
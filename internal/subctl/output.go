@@ -0,0 +1,113 @@
+package subctl
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/export"
+)
+
+// Output - формат вывода результатов команд
+type Output string
+
+// Поддерживаемые форматы вывода
+const (
+	OutputTable Output = "table"
+	OutputJSON  Output = "json"
+	OutputCSV   Output = "csv"
+)
+
+// ParseOutput разбирает название формата вывода
+func ParseOutput(s string) (Output, error) {
+	switch Output(strings.ToLower(s)) {
+	case OutputTable:
+		return OutputTable, nil
+	case OutputJSON:
+		return OutputJSON, nil
+	case OutputCSV:
+		return OutputCSV, nil
+	default:
+		return "", fmt.Errorf("unsupported output format %q: use table, json or csv", s)
+	}
+}
+
+// printSubscriptions выводит подписки в формате output. Таблица и CSV
+// содержат те же колонки, что и выгрузка подписок
+func printSubscriptions(w io.Writer, output Output, subs []*subscription.Subscription) error {
+	switch output {
+	case OutputJSON:
+		return printJSON(w, subs)
+	case OutputCSV:
+		ew, err := export.NewWriter(export.FormatCSV, w)
+		if err != nil {
+			return err
+		}
+		for _, sub := range subs {
+			if err := ew.Write(sub); err != nil {
+				return err
+			}
+		}
+		return ew.Close()
+	default:
+		rows := make([][]string, len(subs))
+		for i, sub := range subs {
+			rows[i] = export.Record(sub)
+		}
+		return printTable(w, export.Columns, rows)
+	}
+}
+
+// printSubscription выводит одну подписку; JSON - объектом, а не списком
+func printSubscription(w io.Writer, output Output, sub *subscription.Subscription) error {
+	if output == OutputJSON {
+		return printJSON(w, sub)
+	}
+	return printSubscriptions(w, output, []*subscription.Subscription{sub})
+}
+
+// printCost выводит результат расчета стоимости
+func printCost(w io.Writer, output Output, cost *subscription.TotalCostResponse) error {
+	header := []string{"total_cost"}
+	row := []string{strconv.Itoa(cost.TotalCost)}
+
+	switch output {
+	case OutputJSON:
+		return printJSON(w, cost)
+	case OutputCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.WriteAll([][]string{header, row}); err != nil {
+			return err
+		}
+		return cw.Error()
+	default:
+		return printTable(w, header, [][]string{row})
+	}
+}
+
+// printJSON выводит значение в JSON с отступами
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printTable выводит строки выровненными колонками с заголовком в верхнем регистре
+func printTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	upper := make([]string, len(header))
+	for i, column := range header {
+		upper[i] = strings.ToUpper(column)
+	}
+	fmt.Fprintln(tw, strings.Join(upper, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
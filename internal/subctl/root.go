@@ -0,0 +1,151 @@
+package subctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/subscription-service/internal/actor"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// Переменные окружения, задающие глобальные флаги, если они не указаны
+const (
+	EnvServer       = "SUBCTL_SERVER"
+	EnvAPIKey       = "SUBCTL_API_KEY"
+	EnvOrganization = "SUBCTL_ORGANIZATION"
+)
+
+// defaultServer - адрес HTTP API по умолчанию
+const defaultServer = "http://localhost:8080"
+
+// DirectOpener подключается к базе по конфигурации сервиса из каталога
+// configPath и возвращает сервис подписок, работающий без HTTP API, и
+// функцию освобождения ресурсов
+type DirectOpener func(ctx context.Context, configPath string) (subscription.Service, func() error, error)
+
+// options - глобальные флаги subctl
+type options struct {
+	server       string
+	apiKey       string
+	organization string
+	actor        string
+	direct       bool
+	configPath   string
+	output       string
+	timeout      time.Duration
+}
+
+// app хранит глобальные флаги и открытый сервис для команд
+type app struct {
+	opts       options
+	openDirect DirectOpener
+}
+
+// NewRootCommand создает корневую команду subctl. openDirect используется с
+// флагом --direct; nil отключает работу без HTTP API
+func NewRootCommand(openDirect DirectOpener) *cobra.Command {
+	a := &app{openDirect: openDirect}
+
+	root := &cobra.Command{
+		Use:   "subctl",
+		Short: "Manage subscriptions through the HTTP API or directly in the database",
+		Long: `subctl manages subscriptions of the subscription service.
+
+By default it talks to the HTTP API (--server, --api-key). With --direct it
+uses the service configuration to work with the database directly, bypassing
+authentication; use it only where the service itself runs.
+
+Requests are validated with the same rules as the API before they are sent.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&a.opts.server, "server", "", "service URL (env "+EnvServer+", default "+defaultServer+")")
+	flags.StringVar(&a.opts.apiKey, "api-key", "", "API key (env "+EnvAPIKey+")")
+	flags.StringVar(&a.opts.organization, "org", "", "organization ID (env "+EnvOrganization+")")
+	flags.StringVar(&a.opts.actor, "actor", defaultActor(), "actor recorded in the audit log")
+	flags.BoolVar(&a.opts.direct, "direct", false, "use the database directly instead of the HTTP API")
+	flags.StringVar(&a.opts.configPath, "config", "", "directory with config.yaml for --direct")
+	flags.StringVarP(&a.opts.output, "output", "o", string(OutputTable), "output format: table, json or csv")
+	flags.DurationVar(&a.opts.timeout, "timeout", 30*time.Second, "timeout of a single API request")
+
+	root.AddCommand(
+		a.listCommand(),
+		a.getCommand(),
+		a.createCommand(),
+		a.updateCommand(),
+		a.deleteCommand(),
+		a.restoreCommand(),
+		a.costCommand(),
+		a.exportCommand(),
+		a.importCommand(),
+	)
+	return root
+}
+
+// defaultActor возвращает исполнителя по умолчанию: subctl и имя пользователя ОС
+func defaultActor() string {
+	if user := os.Getenv("USER"); user != "" {
+		return "subctl:" + user
+	}
+	return "subctl"
+}
+
+// output возвращает формат вывода из флага --output
+func (a *app) output() (Output, error) {
+	return ParseOutput(a.opts.output)
+}
+
+// service открывает сервис подписок согласно глобальным флагам и возвращает
+// контекст команды, в котором для --direct заданы организация и исполнитель
+func (a *app) service(cmd *cobra.Command) (context.Context, subscription.Service, func() error, error) {
+	ctx := cmd.Context()
+	organization := envDefault(a.opts.organization, EnvOrganization)
+
+	if !a.opts.direct {
+		server := envDefault(a.opts.server, EnvServer)
+		if server == "" {
+			server = defaultServer
+		}
+		client := NewClient(ClientConfig{
+			BaseURL:      server,
+			APIKey:       envDefault(a.opts.apiKey, EnvAPIKey),
+			Organization: organization,
+			Actor:        a.opts.actor,
+			Timeout:      a.opts.timeout,
+		})
+		return ctx, client, func() error { return nil }, nil
+	}
+
+	if a.openDirect == nil {
+		return nil, nil, nil, fmt.Errorf("--direct is not supported by this build")
+	}
+	if organization != "" {
+		id, err := uuid.Parse(organization)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: %q", tenant.ErrInvalidOrganization, organization)
+		}
+		ctx = tenant.WithOrganization(ctx, id)
+	}
+	ctx = actor.WithActor(ctx, a.opts.actor)
+
+	service, closeFn, err := a.openDirect(ctx, a.opts.configPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return ctx, service, closeFn, nil
+}
+
+// envDefault возвращает value, если оно задано, иначе значение переменной окружения env
+func envDefault(value, env string) string {
+	if value != "" {
+		return value
+	}
+	return os.Getenv(env)
+}
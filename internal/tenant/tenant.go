@@ -0,0 +1,65 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/auth"
+)
+
+// Header - имя заголовка HTTP (и ключа метаданных gRPC) с организацией,
+// данными которой оперирует запрос
+const Header = "X-Organization-ID"
+
+// Default - организация данных, созданных до разделения на организации, и
+// клиентов, не привязанных к организации
+var Default = uuid.Nil
+
+// ErrInvalidOrganization возвращается, если ID организации не является UUID
+var ErrInvalidOrganization = errors.New("invalid organization id")
+
+// organizationKey - ключ контекста для организации запроса
+type organizationKey struct{}
+
+// WithOrganization сохраняет организацию запроса в контексте
+func WithOrganization(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, organizationKey{}, id)
+}
+
+// FromContext возвращает организацию запроса из контекста или Default
+func FromContext(ctx context.Context) uuid.UUID {
+	if id, ok := ctx.Value(organizationKey{}).(uuid.UUID); ok {
+		return id
+	}
+	return Default
+}
+
+// Resolve определяет организацию запроса клиента principal по значению
+// заголовка requested. Клиент, привязанный к организации, работает только с
+// ней. Клиент без организации работает с Default, а другую организацию может
+// выбрать заголовком, только если у него есть право admin
+func Resolve(principal *auth.Principal, requested string) (uuid.UUID, error) {
+	var (
+		id  = Default
+		err error
+	)
+	if requested != "" {
+		if id, err = uuid.Parse(requested); err != nil {
+			return uuid.Nil, fmt.Errorf("%w: %q", ErrInvalidOrganization, requested)
+		}
+	}
+
+	if principal.OrganizationID != nil {
+		if requested != "" && id != *principal.OrganizationID {
+			return uuid.Nil, fmt.Errorf("%w: organization %s", auth.ErrForbidden, id)
+		}
+		return *principal.OrganizationID, nil
+	}
+
+	if id != Default && !principal.Allows(auth.ScopeAdmin) {
+		return uuid.Nil, fmt.Errorf("%w: organization %s", auth.ErrForbidden, id)
+	}
+	return id, nil
+}
@@ -0,0 +1,92 @@
+// Package tracing настраивает трассировку OpenTelemetry: экспорт span и
+// распространение контекста трассировки по W3C Trace Context
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Экспортеры span
+const (
+	// ExporterNone отключает экспорт; контекст трассировки из заголовков
+	// все равно передается дальше и попадает в логи
+	ExporterNone = "none"
+	// ExporterStdout пишет span в стандартный вывод в JSON
+	ExporterStdout = "stdout"
+	// ExporterOTLP отправляет span коллектору по OTLP/gRPC
+	ExporterOTLP = "otlp"
+)
+
+// Config хранит настройки трассировки
+type Config struct {
+	ServiceName string
+	Exporter    string
+	// Endpoint - адрес коллектора OTLP (host:port)
+	Endpoint string
+	// Insecure отключает TLS при подключении к коллектору
+	Insecure bool
+	// SampleRatio - доля трассировок, начатых сервисом, которые сохраняются;
+	// решение вызывающего из traceparent соблюдается
+	SampleRatio float64
+}
+
+// Setup настраивает глобальные TracerProvider и распространение контекста
+// трассировки и возвращает функцию, которая отправляет накопленные span и
+// останавливает экспорт
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporter, err := newExporter(ctx, config, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	provider := NewProvider(exporter, config)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewProvider создает TracerProvider, пакетами отправляющий span в exporter
+func NewProvider(exporter sdktrace.SpanExporter, config Config) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(config.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+}
+
+// newExporter создает экспортер span по имени из конфигурации; nil, если
+// экспорт отключен. Экспортер stdout пишет в w
+func newExporter(ctx context.Context, config Config, w io.Writer) (sdktrace.SpanExporter, error) {
+	switch config.Exporter {
+	case ExporterNone, "":
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(w))
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(config.Endpoint)}
+		if config.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", config.Exporter)
+	}
+}
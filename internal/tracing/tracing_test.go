@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewExporter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stdout пишет span в JSON", func(t *testing.T) {
+		var out bytes.Buffer
+		exporter, err := newExporter(ctx, Config{Exporter: ExporterStdout}, &out)
+		require.NoError(t, err)
+
+		provider := NewProvider(exporter, Config{ServiceName: "subscription-service", SampleRatio: 1})
+		_, span := provider.Tracer("test").Start(ctx, "SubscriptionService.Get")
+		span.End()
+		require.NoError(t, provider.Shutdown(ctx))
+
+		assert.Contains(t, out.String(), `"Name":"SubscriptionService.Get"`)
+		assert.Contains(t, out.String(), "subscription-service")
+	})
+
+	t.Run("none отключает экспорт", func(t *testing.T) {
+		exporter, err := newExporter(ctx, Config{Exporter: ExporterNone}, nil)
+		assert.NoError(t, err)
+		assert.Nil(t, exporter)
+	})
+
+	t.Run("неизвестный экспортер", func(t *testing.T) {
+		_, err := newExporter(ctx, Config{Exporter: "jaeger"}, nil)
+		assert.Error(t, err)
+	})
+}
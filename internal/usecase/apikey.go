@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/domain/apikey"
+)
+
+// Формат токена ключа API: sk_<префикс>_<секрет>. Префикс не секретен и
+// служит для поиска ключа, секрет проверяется по хешу всего токена
+const (
+	apiKeyTokenPrefix = "sk_"
+	apiKeyPrefixSize  = 6
+	apiKeySecretSize  = 32
+)
+
+// apiKeyLastUsedResolution - точность времени последнего использования ключа.
+// Время обновляется не чаще этого интервала, чтобы не писать в базу на каждый запрос
+const apiKeyLastUsedResolution = time.Minute
+
+// bootstrapSubject - клиент, предъявивший начальный ключ из конфигурации
+const bootstrapSubject = "bootstrap"
+
+// APIKeyService реализует управление ключами API и аутентификацию по ним
+type APIKeyService struct {
+	repo          apikey.Repository
+	bootstrapHash []byte
+	now           func() time.Time
+}
+
+// APIKeyOption настраивает APIKeyService
+type APIKeyOption func(*APIKeyService)
+
+// WithBootstrapKey принимает заданный в конфигурации токен как ключ с правом
+// admin. Он нужен, чтобы выпустить первые ключи, и не хранится в базе
+func WithBootstrapKey(token string) APIKeyOption {
+	return func(s *APIKeyService) {
+		if token != "" {
+			s.bootstrapHash = hashAPIKey(token)
+		}
+	}
+}
+
+// NewAPIKeyService создает новый экземпляр сервиса ключей API
+func NewAPIKeyService(repo apikey.Repository, opts ...APIKeyOption) *APIKeyService {
+	s := &APIKeyService{repo: repo, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Issue выпускает ключ с указанными правами доступа в организации запроса
+func (s *APIKeyService) Issue(ctx context.Context, req apikey.IssueKeyRequest) (*apikey.IssuedKey, error) {
+	scopes := make([]auth.Scope, 0, len(req.Scopes))
+	for _, name := range req.Scopes {
+		scope := auth.Scope(name)
+		if !scope.Valid() {
+			return nil, fmt.Errorf("%w: %q", apikey.ErrUnknownScope, name)
+		}
+		scopes = append(scopes, scope)
+	}
+
+	prefix, err := randomHex(apiKeyPrefixSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	secret, err := randomHex(apiKeySecretSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	token := apiKeyTokenPrefix + prefix + "_" + secret
+
+	key := &apikey.Key{
+		Name:   req.Name,
+		Prefix: prefix,
+		Hash:   hashAPIKey(token),
+		Scopes: scopes,
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return &apikey.IssuedKey{Key: *key, Token: token}, nil
+}
+
+// List возвращает ключи организации запроса, включая отозванные
+func (s *APIKeyService) List(ctx context.Context) ([]*apikey.Key, error) {
+	keys, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Revoke отзывает ключ. Отозванный ключ остается в списке
+func (s *APIKeyService) Revoke(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.Revoke(ctx, id); err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	return nil
+}
+
+// Authenticate проверяет токен ключа API и возвращает клиента с правами ключа
+func (s *APIKeyService) Authenticate(ctx context.Context, token string) (*auth.Principal, error) {
+	hash := hashAPIKey(token)
+	if s.bootstrapHash != nil && subtle.ConstantTimeCompare(hash, s.bootstrapHash) == 1 {
+		return &auth.Principal{Subject: bootstrapSubject, Scopes: []auth.Scope{auth.ScopeAdmin}}, nil
+	}
+
+	prefix, ok := parseAPIKeyPrefix(token)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	key, err := s.repo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, apikey.ErrKeyNotFound) {
+			return nil, auth.ErrUnauthenticated
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	if subtle.ConstantTimeCompare(hash, key.Hash) != 1 || key.Revoked() {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	now := s.now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyLastUsedResolution {
+		// Время использования носит справочный характер: ошибка записи не
+		// должна отклонять запрос
+		if err := s.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			log.Warn().Err(err).Str("api_key", key.Prefix).Msg("Failed to update api key last used time")
+		}
+	}
+
+	// Названия ключей не уникальны, поэтому клиент определяется по ID ключа:
+	// от субъекта зависят квоты частоты запросов и исполнитель в журнале аудита
+	return &auth.Principal{
+		Subject:        "api-key:" + key.ID.String(),
+		Scopes:         key.Scopes,
+		OrganizationID: &key.OrganizationID,
+	}, nil
+}
+
+// parseAPIKeyPrefix извлекает префикс из токена формата sk_<префикс>_<секрет>
+func parseAPIKeyPrefix(token string) (string, bool) {
+	rest, ok := strings.CutPrefix(token, apiKeyTokenPrefix)
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != 2*apiKeyPrefixSize || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
+
+// hashAPIKey возвращает SHA-256 токена. Токен содержит 256 бит случайных
+// данных, поэтому медленная функция хеширования не требуется
+func hashAPIKey(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// randomHex возвращает size случайных байт в шестнадцатеричной записи
+func randomHex(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/domain/apikey"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// memoryAPIKeyRepository - хранилище ключей API в памяти
+type memoryAPIKeyRepository struct {
+	keys    []*apikey.Key
+	touches int
+}
+
+func (r *memoryAPIKeyRepository) Create(ctx context.Context, key *apikey.Key) error {
+	key.ID = uuid.New()
+	key.OrganizationID = tenant.FromContext(ctx)
+	key.CreatedAt = time.Now()
+	stored := *key
+	r.keys = append(r.keys, &stored)
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) GetByPrefix(_ context.Context, prefix string) (*apikey.Key, error) {
+	for _, key := range r.keys {
+		if key.Prefix == prefix {
+			found := *key
+			return &found, nil
+		}
+	}
+	return nil, apikey.ErrKeyNotFound
+}
+
+func (r *memoryAPIKeyRepository) List(_ context.Context) ([]*apikey.Key, error) {
+	return r.keys, nil
+}
+
+func (r *memoryAPIKeyRepository) Revoke(_ context.Context, id uuid.UUID) error {
+	for _, key := range r.keys {
+		if key.ID == id {
+			now := time.Now()
+			key.RevokedAt = &now
+			return nil
+		}
+	}
+	return apikey.ErrKeyNotFound
+}
+
+func (r *memoryAPIKeyRepository) TouchLastUsed(_ context.Context, id uuid.UUID, at time.Time) error {
+	for _, key := range r.keys {
+		if key.ID == id {
+			key.LastUsedAt = &at
+			r.touches++
+		}
+	}
+	return nil
+}
+
+func TestAPIKeyService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("выпущенный ключ аутентифицирует клиента с его правами", func(t *testing.T) {
+		repo := &memoryAPIKeyRepository{}
+		service := NewAPIKeyService(repo)
+
+		issued, err := service.Issue(ctx, apikey.IssueKeyRequest{Name: "reports", Scopes: []string{"reports:read"}})
+		require.NoError(t, err)
+		assert.Regexp(t, `^sk_[0-9a-f]{12}_[0-9a-f]{64}$`, issued.Token)
+
+		// Хранится только хеш токена
+		require.Len(t, repo.keys, 1)
+		assert.Equal(t, issued.Prefix, repo.keys[0].Prefix)
+		assert.NotContains(t, string(repo.keys[0].Hash), issued.Token)
+
+		principal, err := service.Authenticate(ctx, issued.Token)
+		require.NoError(t, err)
+		assert.Equal(t, "api-key:"+issued.ID.String(), principal.Subject)
+		assert.Equal(t, []auth.Scope{auth.ScopeReportsRead}, principal.Scopes)
+	})
+
+	t.Run("ключи с одинаковым названием - разные клиенты", func(t *testing.T) {
+		service := NewAPIKeyService(&memoryAPIKeyRepository{})
+
+		subjects := map[string]struct{}{}
+		for i := 0; i < 2; i++ {
+			issued, err := service.Issue(ctx, apikey.IssueKeyRequest{Name: "reports", Scopes: []string{"reports:read"}})
+			require.NoError(t, err)
+			principal, err := service.Authenticate(ctx, issued.Token)
+			require.NoError(t, err)
+			subjects[principal.Subject] = struct{}{}
+		}
+		assert.Len(t, subjects, 2)
+	})
+
+	t.Run("ключ привязан к организации, в которой выпущен", func(t *testing.T) {
+		service := NewAPIKeyService(&memoryAPIKeyRepository{})
+		organizationID := uuid.New()
+
+		issued, err := service.Issue(tenant.WithOrganization(ctx, organizationID), apikey.IssueKeyRequest{Name: "team", Scopes: []string{"admin"}})
+		require.NoError(t, err)
+		assert.Equal(t, organizationID, issued.OrganizationID)
+
+		principal, err := service.Authenticate(ctx, issued.Token)
+		require.NoError(t, err)
+		require.NotNil(t, principal.OrganizationID)
+		assert.Equal(t, organizationID, *principal.OrganizationID)
+	})
+
+	t.Run("неизвестное право доступа", func(t *testing.T) {
+		service := NewAPIKeyService(&memoryAPIKeyRepository{})
+
+		_, err := service.Issue(ctx, apikey.IssueKeyRequest{Name: "bad", Scopes: []string{"subscriptions:delete"}})
+		assert.ErrorIs(t, err, apikey.ErrUnknownScope)
+	})
+
+	t.Run("отклоняются чужой секрет, отозванный ключ и мусор", func(t *testing.T) {
+		repo := &memoryAPIKeyRepository{}
+		service := NewAPIKeyService(repo)
+
+		issued, err := service.Issue(ctx, apikey.IssueKeyRequest{Name: "ci", Scopes: []string{"admin"}})
+		require.NoError(t, err)
+
+		forged := issued.Token[:len(issued.Token)-1] + "0"
+		if forged == issued.Token {
+			forged = issued.Token[:len(issued.Token)-1] + "1"
+		}
+		for _, token := range []string{forged, "sk_unknown_secret", "not-a-key", ""} {
+			_, err := service.Authenticate(ctx, token)
+			assert.ErrorIs(t, err, auth.ErrUnauthenticated, token)
+		}
+
+		require.NoError(t, service.Revoke(ctx, issued.ID))
+		_, err = service.Authenticate(ctx, issued.Token)
+		assert.ErrorIs(t, err, auth.ErrUnauthenticated)
+	})
+
+	t.Run("время использования обновляется не чаще раза в минуту", func(t *testing.T) {
+		repo := &memoryAPIKeyRepository{}
+		service := NewAPIKeyService(repo)
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		service.now = func() time.Time { return now }
+
+		issued, err := service.Issue(ctx, apikey.IssueKeyRequest{Name: "ci", Scopes: []string{"subscriptions:read"}})
+		require.NoError(t, err)
+
+		for _, step := range []time.Duration{0, 10 * time.Second, 50 * time.Second} {
+			now = now.Add(step)
+			_, err := service.Authenticate(ctx, issued.Token)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, repo.touches)
+		assert.Equal(t, now, *repo.keys[0].LastUsedAt)
+	})
+
+	t.Run("начальный ключ из конфигурации дает право admin", func(t *testing.T) {
+		service := NewAPIKeyService(&memoryAPIKeyRepository{}, WithBootstrapKey("bootstrap-secret"))
+
+		principal, err := service.Authenticate(ctx, "bootstrap-secret")
+		require.NoError(t, err)
+		assert.True(t, principal.Allows(auth.ScopeAdmin))
+		assert.Nil(t, principal.OrganizationID, "начальный ключ не привязан к организации")
+
+		_, err = service.Authenticate(ctx, "bootstrap-secret2")
+		assert.ErrorIs(t, err, auth.ErrUnauthenticated)
+	})
+}
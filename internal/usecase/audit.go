@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/domain/audit"
+	"github.com/subscription-service/internal/domain/subscription"
+)
+
+// AuditService реализует чтение журнала аудита изменений подписок
+type AuditService struct {
+	repo          audit.Repository
+	subscriptions subscription.Repository
+	policy        *Policy
+}
+
+// NewAuditService создает новый экземпляр сервиса журнала аудита. История
+// подписки доступна тем же клиентам, что и сама подписка; без политики все
+// пользователи видят историю только своих подписок
+func NewAuditService(repo audit.Repository, subscriptions subscription.Repository, policy *Policy) *AuditService {
+	if policy == nil {
+		policy = NewPolicy(nil)
+	}
+	return &AuditService{repo: repo, subscriptions: subscriptions, policy: policy}
+}
+
+// History возвращает историю изменений подписки. История удаленной подписки
+// остается доступной; подписка, о которой нет ни записей, ни строки в
+// хранилище, считается не найденной
+func (s *AuditService) History(ctx context.Context, subscriptionID uuid.UUID, filter audit.Filter) ([]*audit.Entry, error) {
+	if err := s.checkOwner(ctx, subscriptionID); err != nil {
+		return nil, fmt.Errorf("failed to get subscription history: %w", err)
+	}
+
+	filter.SubscriptionID = &subscriptionID
+
+	entries, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription history: %w", err)
+	}
+	if len(entries) > 0 {
+		return entries, nil
+	}
+
+	// Пустая выборка может означать, что фильтр ничего не нашел, или что
+	// подписки не существует
+	exists, err := s.hasEntries(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if _, err := s.subscriptions.Get(ctx, subscriptionID); err != nil {
+			return nil, fmt.Errorf("failed to get subscription history: %w", err)
+		}
+	}
+
+	return entries, nil
+}
+
+// checkOwner скрывает историю подписки другого пользователя от участника,
+// которому видны только свои подписки. Владелец определяется по журналу,
+// поэтому проверка работает и для очищенных подписок
+func (s *AuditService) checkOwner(ctx context.Context, subscriptionID uuid.UUID) error {
+	subject, err := s.policy.Subject(ctx)
+	if err != nil {
+		return err
+	}
+	if _, restricted := subject.RestrictedTo(); !restricted {
+		return nil
+	}
+
+	entries, err := s.repo.List(ctx, audit.Filter{SubscriptionID: &subscriptionID, Limit: 1})
+	if err != nil {
+		return err
+	}
+
+	var owner uuid.UUID
+	if len(entries) > 0 {
+		if owner, err = entryOwner(entries[0]); err != nil {
+			return err
+		}
+	} else {
+		sub, err := s.subscriptions.Get(ctx, subscriptionID)
+		if err != nil {
+			return err
+		}
+		owner = sub.UserID
+	}
+
+	return Authorize(subject, ActionView, owner)
+}
+
+// entryOwner возвращает пользователя подписки по снимку из записи журнала.
+// Пользователь подписки не меняется, поэтому подходит любая запись
+func entryOwner(entry *audit.Entry) (uuid.UUID, error) {
+	snapshot := entry.After
+	if len(snapshot) == 0 {
+		snapshot = entry.Before
+	}
+
+	var sub struct {
+		UserID uuid.UUID `json:"user_id"`
+	}
+	if err := json.Unmarshal(snapshot, &sub); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to decode audit snapshot: %w", err)
+	}
+	return sub.UserID, nil
+}
+
+// hasEntries проверяет, есть ли в журнале хотя бы одна запись о подписке
+func (s *AuditService) hasEntries(ctx context.Context, subscriptionID uuid.UUID) (bool, error) {
+	entries, err := s.repo.List(ctx, audit.Filter{SubscriptionID: &subscriptionID, Limit: 1})
+	if err != nil {
+		return false, fmt.Errorf("failed to get subscription history: %w", err)
+	}
+	return len(entries) > 0, nil
+}
+
+// List возвращает записи журнала по всем подпискам
+func (s *AuditService) List(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	entries, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/actor"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/domain/audit"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/requestid"
+)
+
+// recordingAuditLog запоминает записи журнала аудита и отдает их в обратном порядке
+type recordingAuditLog struct {
+	entries []*audit.Entry
+	err     error
+}
+
+func (l *recordingAuditLog) Add(_ context.Context, entry *audit.Entry) error {
+	if l.err != nil {
+		return l.err
+	}
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func (l *recordingAuditLog) List(_ context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	var entries []*audit.Entry
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		entry := l.entries[i]
+		if filter.SubscriptionID != nil && entry.SubscriptionID != *filter.SubscriptionID {
+			continue
+		}
+		if filter.Operation != nil && entry.Operation != *filter.Operation {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func TestSubscriptionService_Audit(t *testing.T) {
+	ctx := requestid.WithRequestID(actor.WithActor(context.Background(), "alice@example.com"), "req-1")
+	id := uuid.New()
+	current := func() *subscription.Subscription {
+		return &subscription.Subscription{
+			ID:          id,
+			ServiceName: "Netflix",
+			Price:       400,
+			UserID:      uuid.New(),
+			StartDate:   time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+
+	t.Run("изменение записывается с исполнителем и списком измененных полей", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		auditLog := &recordingAuditLog{}
+		service := NewSubscriptionService(mockRepo, WithAuditLog(auditLog))
+
+		mockRepo.On("Get", ctx, id).Return(current(), nil).Once()
+		mockRepo.On("Update", ctx, mock.AnythingOfType("*subscription.Subscription")).Return(nil).Once()
+
+		price := 500
+		endDate := "12-2023"
+		_, err := service.Update(ctx, id, subscription.UpdateSubscriptionRequest{Price: &price, EndDate: &endDate})
+		require.NoError(t, err)
+
+		require.Len(t, auditLog.entries, 1)
+		entry := auditLog.entries[0]
+		assert.Equal(t, audit.OperationUpdate, entry.Operation)
+		assert.Equal(t, id, entry.SubscriptionID)
+		assert.Equal(t, "alice@example.com", entry.Actor)
+		assert.Equal(t, "req-1", entry.RequestID)
+
+		assert.Len(t, entry.Changes, 2)
+		assert.JSONEq(t, "400", string(entry.Changes["price"].Before))
+		assert.JSONEq(t, "500", string(entry.Changes["price"].After))
+		assert.Nil(t, entry.Changes["end_date"].Before)
+		assert.JSONEq(t, `"2023-12-01T00:00:00Z"`, string(entry.Changes["end_date"].After))
+	})
+
+	t.Run("создание и удаление записываются без исполнителя как anonymous", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		auditLog := &recordingAuditLog{}
+		service := NewSubscriptionService(mockRepo, WithAuditLog(auditLog))
+		ctx := context.Background()
+
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*subscription.Subscription")).Run(func(args mock.Arguments) {
+			args.Get(1).(*subscription.Subscription).ID = id
+		}).Return(nil).Once()
+		mockRepo.On("Get", ctx, id).Return(current(), nil).Once()
+		mockRepo.On("Delete", ctx, id).Return(nil).Once()
+
+		_, err := service.Create(ctx, subscription.CreateSubscriptionRequest{
+			ServiceName: "Netflix",
+			Price:       400,
+			UserID:      uuid.New(),
+			StartDate:   "07-2023",
+		})
+		require.NoError(t, err)
+		require.NoError(t, service.Delete(ctx, id))
+
+		require.Len(t, auditLog.entries, 2)
+		created, deleted := auditLog.entries[0], auditLog.entries[1]
+		assert.Equal(t, audit.OperationCreate, created.Operation)
+		assert.Equal(t, actor.Anonymous, created.Actor)
+		assert.Nil(t, created.Before)
+		assert.Contains(t, created.Changes, "service_name")
+
+		assert.Equal(t, audit.OperationDelete, deleted.Operation)
+		assert.Nil(t, deleted.After)
+		assert.JSONEq(t, "400", string(deleted.Changes["price"].Before))
+	})
+
+	t.Run("ошибка журнала откатывает изменение", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		tx := &recordingTxManager{}
+		service := NewSubscriptionService(mockRepo,
+			WithAuditLog(&recordingAuditLog{err: errors.New("audit unavailable")}),
+			WithTransactionManager(tx))
+
+		mockRepo.On("Get", mock.Anything, id).Return(current(), nil).Once()
+		mockRepo.On("Delete", mock.Anything, id).Return(nil).Once()
+
+		err := service.Delete(ctx, id)
+		assert.ErrorContains(t, err, "audit unavailable")
+		assert.Equal(t, 1, tx.rolledBack)
+	})
+}
+
+func TestAuditService_History(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	t.Run("история удаленной подписки доступна", func(t *testing.T) {
+		auditLog := &recordingAuditLog{entries: []*audit.Entry{
+			{SubscriptionID: id, Operation: audit.OperationCreate},
+			{SubscriptionID: id, Operation: audit.OperationDelete},
+			{SubscriptionID: uuid.New(), Operation: audit.OperationCreate},
+		}}
+		service := NewAuditService(auditLog, new(MockRepository), nil)
+
+		entries, err := service.History(ctx, id, audit.Filter{})
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, audit.OperationDelete, entries[0].Operation)
+	})
+
+	t.Run("пустая выборка по существующей подписке", func(t *testing.T) {
+		auditLog := &recordingAuditLog{entries: []*audit.Entry{{SubscriptionID: id, Operation: audit.OperationCreate}}}
+		service := NewAuditService(auditLog, new(MockRepository), nil)
+
+		operation := audit.OperationDelete
+		entries, err := service.History(ctx, id, audit.Filter{Operation: &operation})
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("неизвестная подписка", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("Get", ctx, id).Return(nil, subscription.ErrSubscriptionNotFound).Once()
+		service := NewAuditService(&recordingAuditLog{}, mockRepo, nil)
+
+		_, err := service.History(ctx, id, audit.Filter{})
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+	})
+	t.Run("история подписки другого пользователя скрыта", func(t *testing.T) {
+		owner := uuid.New()
+		auditLog := &recordingAuditLog{}
+		entry, err := audit.NewEntry(audit.OperationCreate, nil, &subscription.Subscription{ID: id, UserID: owner})
+		require.NoError(t, err)
+		require.NoError(t, auditLog.Add(ctx, entry))
+		service := NewAuditService(auditLog, new(MockRepository), nil)
+
+		entries, err := service.History(auth.WithPrincipal(ctx, &auth.Principal{UserID: owner}), id, audit.Filter{})
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+
+		_, err = service.History(auth.WithPrincipal(ctx, &auth.Principal{UserID: uuid.New()}), id, audit.Filter{})
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+	})
+}
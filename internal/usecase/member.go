@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/domain/member"
+)
+
+// MemberService реализует управление участниками организации. Участниками
+// управляют владельцы и администраторы, а владельцев назначают и снимают
+// только владельцы
+type MemberService struct {
+	repo   member.Repository
+	policy *Policy
+}
+
+// NewMemberService создает новый экземпляр сервиса участников
+func NewMemberService(repo member.Repository, policy *Policy) *MemberService {
+	return &MemberService{repo: repo, policy: policy}
+}
+
+// List возвращает участников организации запроса
+func (s *MemberService) List(ctx context.Context) ([]*member.Member, error) {
+	if err := s.policy.Authorize(ctx, ActionManageMembers, uuid.Nil); err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+
+	members, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	return members, nil
+}
+
+// SetRole добавляет пользователя в организацию запроса или меняет его роль
+func (s *MemberService) SetRole(ctx context.Context, userID uuid.UUID, req member.SetRoleRequest) (*member.Member, error) {
+	role := member.Role(req.Role)
+	if !role.Valid() {
+		return nil, fmt.Errorf("%w: %q", member.ErrUnknownRole, req.Role)
+	}
+
+	if err := s.authorizeChange(ctx, userID, role); err != nil {
+		return nil, fmt.Errorf("failed to set member role: %w", err)
+	}
+
+	m := &member.Member{UserID: userID, Role: role}
+	if err := s.repo.Save(ctx, m); err != nil {
+		return nil, fmt.Errorf("failed to set member role: %w", err)
+	}
+	return m, nil
+}
+
+// Remove исключает пользователя из организации запроса. После этого он
+// снова получает роль по умолчанию
+func (s *MemberService) Remove(ctx context.Context, userID uuid.UUID) error {
+	if err := s.authorizeChange(ctx, userID, member.DefaultRole); err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+
+	if err := s.repo.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	return nil
+}
+
+// authorizeChange проверяет, может ли клиент назначить пользователю userID
+// роль role. Назначение и снятие владельца требуют роли владельца
+func (s *MemberService) authorizeChange(ctx context.Context, userID uuid.UUID, role member.Role) error {
+	subject, err := s.policy.Subject(ctx)
+	if err != nil {
+		return err
+	}
+	if err := Authorize(subject, ActionManageMembers, uuid.Nil); err != nil {
+		return err
+	}
+
+	current, err := s.repo.Get(ctx, userID)
+	if err != nil && !errors.Is(err, member.ErrMemberNotFound) {
+		return err
+	}
+	if role == member.RoleOwner || (current != nil && current.Role == member.RoleOwner) {
+		return Authorize(subject, ActionManageOwners, uuid.Nil)
+	}
+	return nil
+}
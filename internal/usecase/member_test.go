@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/domain/member"
+)
+
+func TestMemberService(t *testing.T) {
+	ownerID := uuid.New()
+	adminID := uuid.New()
+	memberID := uuid.New()
+	repo := newMemoryMemberRepository(
+		member.Member{UserID: ownerID, Role: member.RoleOwner},
+		member.Member{UserID: adminID, Role: member.RoleAdmin},
+	)
+	service := NewMemberService(repo, NewPolicy(repo))
+	as := func(userID uuid.UUID) context.Context {
+		return auth.WithPrincipal(context.Background(), &auth.Principal{UserID: userID, Scopes: auth.UserScopes})
+	}
+
+	t.Run("администратор назначает роли участникам", func(t *testing.T) {
+		m, err := service.SetRole(as(adminID), memberID, member.SetRoleRequest{Role: "viewer"})
+		require.NoError(t, err)
+		assert.Equal(t, member.RoleViewer, m.Role)
+
+		members, err := service.List(as(adminID))
+		require.NoError(t, err)
+		assert.Len(t, members, 3)
+	})
+
+	t.Run("неизвестная роль", func(t *testing.T) {
+		_, err := service.SetRole(as(ownerID), memberID, member.SetRoleRequest{Role: "superuser"})
+		assert.ErrorIs(t, err, member.ErrUnknownRole)
+	})
+
+	t.Run("владельцев назначает и снимает только владелец", func(t *testing.T) {
+		_, err := service.SetRole(as(adminID), memberID, member.SetRoleRequest{Role: "owner"})
+		assert.ErrorIs(t, err, member.ErrInsufficientRole)
+		assert.ErrorIs(t, service.Remove(as(adminID), ownerID), member.ErrInsufficientRole)
+
+		_, err = service.SetRole(as(ownerID), memberID, member.SetRoleRequest{Role: "owner"})
+		require.NoError(t, err)
+		require.NoError(t, service.Remove(as(ownerID), memberID))
+	})
+
+	t.Run("участник и наблюдатель не управляют участниками", func(t *testing.T) {
+		viewerID := uuid.New()
+		require.NoError(t, repo.Save(context.Background(), &member.Member{UserID: viewerID, Role: member.RoleViewer}))
+
+		for _, userID := range []uuid.UUID{uuid.New(), viewerID} {
+			_, err := service.List(as(userID))
+			assert.ErrorIs(t, err, member.ErrInsufficientRole)
+			_, err = service.SetRole(as(userID), userID, member.SetRoleRequest{Role: "admin"})
+			assert.ErrorIs(t, err, member.ErrInsufficientRole)
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/domain/member"
+	"github.com/subscription-service/internal/domain/subscription"
+)
+
+// Action - операция, разрешение на которую проверяет политика доступа
+type Action string
+
+// Операции политики доступа
+const (
+	// ActionView - чтение подписок, их истории, событий и стоимости
+	ActionView Action = "view"
+	// ActionViewDeleted - выборки и расчеты с удаленными подписками (include_deleted)
+	ActionViewDeleted Action = "view_deleted"
+	// ActionCreate - создание подписки
+	ActionCreate Action = "create"
+	// ActionModify - изменение, удаление и восстановление подписки
+	ActionModify Action = "modify"
+	// ActionManageMembers - просмотр участников и назначение ролей, кроме владельца
+	ActionManageMembers Action = "manage_members"
+	// ActionManageOwners - назначение и снятие владельцев организации
+	ActionManageOwners Action = "manage_owners"
+)
+
+// Subject - клиент, для которого политика принимает решение
+type Subject struct {
+	// UserID - пользователь, от имени которого действует клиент
+	UserID uuid.UUID
+	// Role - роль пользователя в организации запроса
+	Role member.Role
+	// Unrestricted отмечает клиентов, к которым роли не применяются: с правом
+	// admin и ключи API, выпущенные для сервисов
+	Unrestricted bool
+	// Admin отмечает клиентов с правом admin и фоновые задачи. Из клиентов
+	// без ограничений только они управляют участниками организации: иначе
+	// ключ с правом subscriptions:write мог бы назначить владельца
+	Admin bool
+}
+
+// RestrictedTo возвращает пользователя, подписками которого ограничен субъект.
+// Ограничены только участники с ролью member
+func (s Subject) RestrictedTo() (uuid.UUID, bool) {
+	if s.Unrestricted || s.Role != member.RoleMember {
+		return uuid.Nil, false
+	}
+	return s.UserID, true
+}
+
+// Authorize решает, может ли субъект выполнить операцию над подписками
+// пользователя owner. Подписки, которые субъект не видит, для него не
+// существуют: на них возвращается subscription.ErrSubscriptionNotFound. Если
+// подписки видны, но роль не позволяет операцию, возвращается
+// member.ErrInsufficientRole
+func Authorize(subject Subject, action Action, owner uuid.UUID) error {
+	if subject.Unrestricted {
+		if (action == ActionManageMembers || action == ActionManageOwners) && !subject.Admin {
+			return fmt.Errorf("%w: managing members requires the admin scope", auth.ErrForbidden)
+		}
+		return nil
+	}
+
+	restrictedTo, restricted := subject.RestrictedTo()
+	visible := !restricted || owner == restrictedTo
+
+	switch action {
+	case ActionView:
+		if !visible {
+			return subscription.ErrSubscriptionNotFound
+		}
+	case ActionCreate:
+		if subject.Role == member.RoleViewer {
+			return member.ErrInsufficientRole
+		}
+		if !visible {
+			return errAnotherUser()
+		}
+	case ActionModify:
+		if !visible {
+			return subscription.ErrSubscriptionNotFound
+		}
+		if subject.Role == member.RoleViewer {
+			return member.ErrInsufficientRole
+		}
+	case ActionViewDeleted, ActionManageMembers:
+		if subject.Role != member.RoleOwner && subject.Role != member.RoleAdmin {
+			return member.ErrInsufficientRole
+		}
+	case ActionManageOwners:
+		if subject.Role != member.RoleOwner {
+			return member.ErrInsufficientRole
+		}
+	default:
+		return fmt.Errorf("%w: unknown action %q", auth.ErrForbidden, action)
+	}
+	return nil
+}
+
+// Policy определяет субъект запроса по клиенту из контекста и его роли в
+// организации и проверяет операции сервисов
+type Policy struct {
+	members member.Repository
+}
+
+// NewPolicy создает политику доступа. Без хранилища участников все
+// пользователи получают роль по умолчанию
+func NewPolicy(members member.Repository) *Policy {
+	return &Policy{members: members}
+}
+
+// Subject возвращает субъект запроса. Запросы без клиента, например из
+// фоновых задач, не ограничиваются
+func (p *Policy) Subject(ctx context.Context) (Subject, error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return Subject{Unrestricted: true, Admin: true}, nil
+	}
+	userID, restricted := principal.RestrictedTo()
+	if !restricted {
+		return Subject{UserID: principal.UserID, Unrestricted: true, Admin: principal.Allows(auth.ScopeAdmin)}, nil
+	}
+
+	subject := Subject{UserID: userID, Role: member.DefaultRole}
+	if p.members == nil {
+		return subject, nil
+	}
+
+	m, err := p.members.Get(ctx, userID)
+	switch {
+	case err == nil:
+		subject.Role = m.Role
+	case !errors.Is(err, member.ErrMemberNotFound):
+		return Subject{}, fmt.Errorf("failed to get member role: %w", err)
+	}
+	return subject, nil
+}
+
+// Authorize проверяет, может ли клиент из контекста выполнить операцию над
+// подписками пользователя owner
+func (p *Policy) Authorize(ctx context.Context, action Action, owner uuid.UUID) error {
+	subject, err := p.Subject(ctx)
+	if err != nil {
+		return err
+	}
+	return Authorize(subject, action, owner)
+}
+
+// ScopeUser ограничивает фильтр по пользователю подписками, которые видит
+// клиент из контекста. Запрос подписок другого пользователя участником
+// отклоняется
+func (p *Policy) ScopeUser(ctx context.Context, userID *uuid.UUID) (*uuid.UUID, error) {
+	subject, err := p.Subject(ctx)
+	if err != nil {
+		return nil, err
+	}
+	restrictedTo, ok := subject.RestrictedTo()
+	if !ok {
+		return userID, nil
+	}
+	if userID != nil && *userID != restrictedTo {
+		return nil, errAnotherUser()
+	}
+	return &restrictedTo, nil
+}
+
+// ScopeDeleted проверяет, может ли клиент из контекста учитывать удаленные
+// подписки. Удаленные подписки видят только владельцы и администраторы
+// организации и клиенты без ограничений
+func (p *Policy) ScopeDeleted(ctx context.Context, includeDeleted bool) error {
+	if !includeDeleted {
+		return nil
+	}
+	return p.Authorize(ctx, ActionViewDeleted, uuid.Nil)
+}
+
+// errAnotherUser возвращает ошибку обращения к подпискам другого пользователя
+func errAnotherUser() error {
+	return fmt.Errorf("%w: subscriptions of another user", auth.ErrForbidden)
+}
@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/domain/member"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/repository/memory"
+)
+
+// memoryMemberRepository - хранилище участников одной организации в памяти
+type memoryMemberRepository struct {
+	mu      sync.Mutex
+	members map[uuid.UUID]member.Member
+}
+
+func newMemoryMemberRepository(members ...member.Member) *memoryMemberRepository {
+	repo := &memoryMemberRepository{members: make(map[uuid.UUID]member.Member)}
+	for _, m := range members {
+		repo.members[m.UserID] = m
+	}
+	return repo
+}
+
+func (r *memoryMemberRepository) Get(_ context.Context, userID uuid.UUID) (*member.Member, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.members[userID]
+	if !ok {
+		return nil, member.ErrMemberNotFound
+	}
+	return &m, nil
+}
+
+func (r *memoryMemberRepository) List(_ context.Context) ([]*member.Member, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	members := make([]*member.Member, 0, len(r.members))
+	for _, m := range r.members {
+		m := m
+		members = append(members, &m)
+	}
+	return members, nil
+}
+
+func (r *memoryMemberRepository) Save(_ context.Context, m *member.Member) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m.CreatedAt, m.UpdatedAt = time.Now(), time.Now()
+	r.members[m.UserID] = *m
+	return nil
+}
+
+func (r *memoryMemberRepository) Delete(_ context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.members[userID]; !ok {
+		return member.ErrMemberNotFound
+	}
+	delete(r.members, userID)
+	return nil
+}
+
+func TestAuthorize(t *testing.T) {
+	self := uuid.New()
+	other := uuid.New()
+	subject := func(role member.Role) Subject {
+		return Subject{UserID: self, Role: role}
+	}
+
+	tests := []struct {
+		name    string
+		subject Subject
+		action  Action
+		owner   uuid.UUID
+		wantErr error
+	}{
+		{"участник видит свои подписки", subject(member.RoleMember), ActionView, self, nil},
+		{"участник не видит чужие подписки", subject(member.RoleMember), ActionView, other, subscription.ErrSubscriptionNotFound},
+		{"участник создает свои подписки", subject(member.RoleMember), ActionCreate, self, nil},
+		{"участник не создает чужие подписки", subject(member.RoleMember), ActionCreate, other, auth.ErrForbidden},
+		{"участник изменяет свои подписки", subject(member.RoleMember), ActionModify, self, nil},
+		{"участник не изменяет чужие подписки", subject(member.RoleMember), ActionModify, other, subscription.ErrSubscriptionNotFound},
+		{"участник не управляет участниками", subject(member.RoleMember), ActionManageMembers, uuid.Nil, member.ErrInsufficientRole},
+		{"участник не видит удаленные подписки", subject(member.RoleMember), ActionViewDeleted, uuid.Nil, member.ErrInsufficientRole},
+
+		{"наблюдатель видит чужие подписки", subject(member.RoleViewer), ActionView, other, nil},
+		{"наблюдатель не создает подписки", subject(member.RoleViewer), ActionCreate, self, member.ErrInsufficientRole},
+		{"наблюдатель не изменяет подписки", subject(member.RoleViewer), ActionModify, other, member.ErrInsufficientRole},
+		{"наблюдатель не управляет участниками", subject(member.RoleViewer), ActionManageMembers, uuid.Nil, member.ErrInsufficientRole},
+		{"наблюдатель не видит удаленные подписки", subject(member.RoleViewer), ActionViewDeleted, uuid.Nil, member.ErrInsufficientRole},
+
+		{"администратор изменяет чужие подписки", subject(member.RoleAdmin), ActionModify, other, nil},
+		{"администратор создает чужие подписки", subject(member.RoleAdmin), ActionCreate, other, nil},
+		{"администратор управляет участниками", subject(member.RoleAdmin), ActionManageMembers, uuid.Nil, nil},
+		{"администратор видит удаленные подписки", subject(member.RoleAdmin), ActionViewDeleted, uuid.Nil, nil},
+		{"администратор не назначает владельцев", subject(member.RoleAdmin), ActionManageOwners, uuid.Nil, member.ErrInsufficientRole},
+
+		{"владелец изменяет чужие подписки", subject(member.RoleOwner), ActionModify, other, nil},
+		{"владелец назначает владельцев", subject(member.RoleOwner), ActionManageOwners, uuid.Nil, nil},
+		{"владелец видит удаленные подписки", subject(member.RoleOwner), ActionViewDeleted, uuid.Nil, nil},
+
+		{"клиент без ограничений", Subject{Unrestricted: true}, ActionModify, other, nil},
+		{"администратор сервиса назначает владельцев", Subject{Unrestricted: true, Admin: true}, ActionManageOwners, uuid.Nil, nil},
+		{"ключ API без права admin не управляет участниками", Subject{Unrestricted: true}, ActionManageMembers, uuid.Nil, auth.ErrForbidden},
+		{"ключ API без права admin не назначает владельцев", Subject{Unrestricted: true}, ActionManageOwners, uuid.Nil, auth.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Authorize(tt.subject, tt.action, tt.owner)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestPolicy_Subject(t *testing.T) {
+	userID := uuid.New()
+	user := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: userID, Scopes: auth.UserScopes})
+
+	t.Run("роль из хранилища участников", func(t *testing.T) {
+		policy := NewPolicy(newMemoryMemberRepository(member.Member{UserID: userID, Role: member.RoleViewer}))
+
+		subject, err := policy.Subject(user)
+		require.NoError(t, err)
+		assert.Equal(t, Subject{UserID: userID, Role: member.RoleViewer}, subject)
+	})
+
+	t.Run("пользователь без роли - участник", func(t *testing.T) {
+		for _, policy := range []*Policy{NewPolicy(nil), NewPolicy(newMemoryMemberRepository())} {
+			subject, err := policy.Subject(user)
+			require.NoError(t, err)
+			assert.Equal(t, member.DefaultRole, subject.Role)
+		}
+	})
+
+	t.Run("роли не применяются к администраторам и сервисам", func(t *testing.T) {
+		policy := NewPolicy(newMemoryMemberRepository(member.Member{UserID: userID, Role: member.RoleViewer}))
+
+		for _, tt := range []struct {
+			ctx   context.Context
+			admin bool
+		}{
+			{context.Background(), true},
+			{auth.WithPrincipal(context.Background(), &auth.Principal{UserID: userID, Scopes: []auth.Scope{auth.ScopeAdmin}}), true},
+			{auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "api-key:reports", Scopes: auth.UserScopes}), false},
+		} {
+			subject, err := policy.Subject(tt.ctx)
+			require.NoError(t, err)
+			assert.True(t, subject.Unrestricted)
+			assert.Equal(t, tt.admin, subject.Admin)
+		}
+	})
+}
+
+func TestSubscriptionService_Roles(t *testing.T) {
+	memberID := uuid.New()
+	viewerID := uuid.New()
+	adminID := uuid.New()
+	policy := NewPolicy(newMemoryMemberRepository(
+		member.Member{UserID: viewerID, Role: member.RoleViewer},
+		member.Member{UserID: adminID, Role: member.RoleAdmin},
+	))
+	as := func(userID uuid.UUID) context.Context {
+		return auth.WithPrincipal(context.Background(), &auth.Principal{UserID: userID, Scopes: auth.UserScopes})
+	}
+	sub := &subscription.Subscription{ID: uuid.New(), UserID: memberID, ServiceName: "Netflix", Price: 400, StartDate: time.Now()}
+
+	t.Run("наблюдатель видит подписки участников, но не изменяет их", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("Get", mock.Anything, sub.ID).Return(sub, nil)
+		mockRepo.On("List", mock.Anything, subscription.ListFilter{}).Return([]*subscription.Subscription{sub}, nil)
+		service := NewSubscriptionService(mockRepo, WithPolicy(policy))
+
+		got, err := service.Get(as(viewerID), sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, sub, got)
+
+		subs, err := service.List(as(viewerID), subscription.ListFilter{})
+		require.NoError(t, err)
+		assert.Len(t, subs, 1)
+
+		price := 500
+		_, err = service.Update(as(viewerID), sub.ID, subscription.UpdateSubscriptionRequest{Price: &price})
+		assert.ErrorIs(t, err, member.ErrInsufficientRole)
+		assert.ErrorIs(t, service.Delete(as(viewerID), sub.ID), member.ErrInsufficientRole)
+
+		_, err = service.Create(as(viewerID), subscription.CreateSubscriptionRequest{
+			ServiceName: "Spotify",
+			Price:       200,
+			UserID:      viewerID,
+			StartDate:   "07-2023",
+		})
+		assert.ErrorIs(t, err, member.ErrInsufficientRole)
+
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("администратор управляет подписками участников", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("Get", mock.Anything, sub.ID).Return(sub, nil)
+		mockRepo.On("Delete", mock.Anything, sub.ID).Return(nil).Once()
+		service := NewSubscriptionService(mockRepo, WithPolicy(policy))
+
+		assert.NoError(t, service.Delete(as(adminID), sub.ID))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("участник по-прежнему ограничен своими подписками", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("Get", mock.Anything, sub.ID).Return(sub, nil)
+		service := NewSubscriptionService(mockRepo, WithPolicy(policy))
+
+		_, err := service.Get(as(uuid.New()), sub.ID)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+
+		_, err = service.List(as(uuid.New()), subscription.ListFilter{UserID: &memberID})
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+	})
+
+	t.Run("запрещенное восстановление не снимает пометку об удалении", func(t *testing.T) {
+		// Хранилище в памяти не откатывает изменения, поэтому проверка
+		// доступа должна выполняться до записи
+		repo := memory.NewSubscriptionRepository()
+		auditLog := &recordingAuditLog{}
+		service := NewSubscriptionService(repo,
+			WithPolicy(policy), WithAuditLog(auditLog), WithTransactionManager(memory.NewTxManager()))
+
+		deleted := &subscription.Subscription{UserID: memberID, ServiceName: "Netflix", Price: 400, StartDate: time.Now()}
+		require.NoError(t, repo.Create(context.Background(), deleted))
+		require.NoError(t, repo.Delete(context.Background(), deleted.ID))
+
+		_, err := service.Restore(as(uuid.New()), deleted.ID)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+		_, err = service.Restore(as(viewerID), deleted.ID)
+		assert.ErrorIs(t, err, member.ErrInsufficientRole)
+
+		_, err = repo.Get(context.Background(), deleted.ID)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+		_, err = repo.GetDeleted(context.Background(), deleted.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, auditLog.entries)
+
+		restored, err := service.Restore(as(memberID), deleted.ID)
+		require.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+	})
+}
@@ -6,25 +6,82 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/subscription-service/internal/actor"
+	"github.com/subscription-service/internal/domain/audit"
+	"github.com/subscription-service/internal/domain/event"
 	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/domain/transaction"
+	"github.com/subscription-service/internal/requestid"
+	"github.com/subscription-service/internal/tenant"
 )
 
-// SubscriptionService реализует сервис для работы с подписками
+// SubscriptionService реализует сервис для работы с подписками. Каждая
+// операция проверяется политикой доступа по роли клиента в организации
 type SubscriptionService struct {
-	repo subscription.Repository
+	repo      subscription.Repository
+	publisher event.Publisher
+	tx        transaction.Manager
+	audit     audit.Repository
+	policy    *Policy
+}
+
+// Option настраивает SubscriptionService
+type Option func(*SubscriptionService)
+
+// WithEventPublisher подключает публикацию событий жизненного цикла подписок
+func WithEventPublisher(publisher event.Publisher) Option {
+	return func(s *SubscriptionService) {
+		s.publisher = publisher
+	}
+}
+
+// WithTransactionManager выполняет изменение подписки и публикацию события в
+// одной транзакции. Вместе с издателем, пишущим в outbox, это гарантирует, что
+// событие не потеряется и не появится без изменения
+func WithTransactionManager(tx transaction.Manager) Option {
+	return func(s *SubscriptionService) {
+		s.tx = tx
+	}
+}
+
+// WithAuditLog записывает каждое изменение подписки в журнал аудита. С
+// менеджером транзакций запись журнала фиксируется вместе с изменением
+func WithAuditLog(repo audit.Repository) Option {
+	return func(s *SubscriptionService) {
+		s.audit = repo
+	}
+}
+
+// WithPolicy проверяет операции политикой с ролями участников организации.
+// По умолчанию все пользователи работают только со своими подписками
+func WithPolicy(policy *Policy) Option {
+	return func(s *SubscriptionService) {
+		s.policy = policy
+	}
 }
 
 // NewSubscriptionService создает новый экземпляр сервиса подписок
-func NewSubscriptionService(repo subscription.Repository) *SubscriptionService {
-	return &SubscriptionService{repo: repo}
+func NewSubscriptionService(repo subscription.Repository, opts ...Option) *SubscriptionService {
+	s := &SubscriptionService{repo: repo, policy: NewPolicy(nil)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Create создает новую подписку
 func (s *SubscriptionService) Create(ctx context.Context, req subscription.CreateSubscriptionRequest) (*subscription.Subscription, error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.Create")
+	defer span.End()
+
+	if err := s.policy.Authorize(ctx, ActionCreate, req.UserID); err != nil {
+		return nil, err
+	}
+
 	// Преобразуем строку с датой начала в time.Time
 	startDate, err := subscription.ParseMonthYear(req.StartDate)
 	if err != nil {
-		return nil, fmt.Errorf("invalid start date: %w", err)
+		return nil, fmt.Errorf("invalid start date: %w", invalidMonthYear("start_date"))
 	}
 
 	// Если указана дата окончания, преобразуем её
@@ -32,12 +89,12 @@ func (s *SubscriptionService) Create(ctx context.Context, req subscription.Creat
 	if req.EndDate != nil && *req.EndDate != "" {
 		parsedEndDate, err := subscription.ParseMonthYear(*req.EndDate)
 		if err != nil {
-			return nil, fmt.Errorf("invalid end date: %w", err)
+			return nil, fmt.Errorf("invalid end date: %w", invalidMonthYear("end_date"))
 		}
 
 		// Проверка, что дата окончания не раньше даты начала
 		if parsedEndDate.Before(startDate) {
-			return nil, fmt.Errorf("%w: end date cannot be before start date", subscription.ErrInvalidInput)
+			return nil, errEndBeforeStart()
 		}
 
 		endDate = &parsedEndDate
@@ -52,9 +109,18 @@ func (s *SubscriptionService) Create(ctx context.Context, req subscription.Creat
 		EndDate:     endDate,
 	}
 
-	// Сохраняем в репозиторий
-	if err := s.repo.Create(ctx, sub); err != nil {
-		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	// Сохраняем в репозиторий вместе с событием
+	err = s.withinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, sub); err != nil {
+			return fmt.Errorf("failed to create subscription: %w", err)
+		}
+		if err := s.record(ctx, audit.OperationCreate, nil, sub); err != nil {
+			return err
+		}
+		return s.publish(ctx, event.SubscriptionCreated, sub)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return sub, nil
@@ -62,20 +128,68 @@ func (s *SubscriptionService) Create(ctx context.Context, req subscription.Creat
 
 // Get возвращает подписку по ID
 func (s *SubscriptionService) Get(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.Get")
+	defer span.End()
+
 	sub, err := s.repo.Get(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get subscription: %w", err)
 	}
+	if err := s.policy.Authorize(ctx, ActionView, sub.UserID); err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// GetAsOf возвращает подписку по состоянию на момент asOf
+func (s *SubscriptionService) GetAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (*subscription.Subscription, error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.GetAsOf")
+	defer span.End()
+
+	sub, err := s.repo.GetAsOf(ctx, id, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	if err := s.policy.Authorize(ctx, ActionView, sub.UserID); err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
 	return sub, nil
 }
 
 // Update обновляет существующую подписку
 func (s *SubscriptionService) Update(ctx context.Context, id uuid.UUID, req subscription.UpdateSubscriptionRequest) (*subscription.Subscription, error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.Update")
+	defer span.End()
+
+	var sub *subscription.Subscription
+	err := s.withinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		sub, err = s.update(ctx, id, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// update читает подписку, применяет изменения из запроса, сохраняет ее и публикует события
+func (s *SubscriptionService) update(ctx context.Context, id uuid.UUID, req subscription.UpdateSubscriptionRequest) (*subscription.Subscription, error) {
 	// Получаем текущую подписку
 	sub, err := s.repo.Get(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get subscription for update: %w", err)
 	}
+	if err := s.policy.Authorize(ctx, ActionModify, sub.UserID); err != nil {
+		return nil, fmt.Errorf("failed to get subscription for update: %w", err)
+	}
+
+	// Копия текущего состояния для журнала аудита; поля подписки ниже
+	// заменяются, а не изменяются по указателю
+	before := *sub
+
+	// Подписка считается отмененной, когда у бессрочной подписки появляется дата окончания
+	wasOpenEnded := sub.EndDate == nil
 
 	// Обновляем поля, если они указаны в запросе
 	if req.ServiceName != "" {
@@ -89,7 +203,7 @@ func (s *SubscriptionService) Update(ctx context.Context, id uuid.UUID, req subs
 	if req.StartDate != "" {
 		startDate, err := subscription.ParseMonthYear(req.StartDate)
 		if err != nil {
-			return nil, fmt.Errorf("invalid start date: %w", err)
+			return nil, fmt.Errorf("invalid start date: %w", invalidMonthYear("start_date"))
 		}
 		sub.StartDate = startDate
 	}
@@ -102,12 +216,12 @@ func (s *SubscriptionService) Update(ctx context.Context, id uuid.UUID, req subs
 			// Иначе парсим новую дату окончания
 			endDate, err := subscription.ParseMonthYear(*req.EndDate)
 			if err != nil {
-				return nil, fmt.Errorf("invalid end date: %w", err)
+				return nil, fmt.Errorf("invalid end date: %w", invalidMonthYear("end_date"))
 			}
 
 			// Проверяем, что дата окончания не раньше даты начала
 			if endDate.Before(sub.StartDate) {
-				return nil, fmt.Errorf("%w: end date cannot be before start date", subscription.ErrInvalidInput)
+				return nil, errEndBeforeStart()
 			}
 
 			sub.EndDate = &endDate
@@ -119,28 +233,136 @@ func (s *SubscriptionService) Update(ctx context.Context, id uuid.UUID, req subs
 		return nil, fmt.Errorf("failed to update subscription: %w", err)
 	}
 
+	if err := s.record(ctx, audit.OperationUpdate, &before, sub); err != nil {
+		return nil, err
+	}
+
+	if err := s.publish(ctx, event.SubscriptionUpdated, sub); err != nil {
+		return nil, err
+	}
+	if wasOpenEnded && sub.EndDate != nil {
+		if err := s.publish(ctx, event.SubscriptionCancelled, sub); err != nil {
+			return nil, err
+		}
+	}
+
 	return sub, nil
 }
 
-// Delete удаляет подписку по ID
+// Delete удаляет подписку по ID. Подписку можно восстановить, пока она не
+// очищена по истечении срока хранения
 func (s *SubscriptionService) Delete(ctx context.Context, id uuid.UUID) error {
-	if err := s.repo.Delete(ctx, id); err != nil {
-		return fmt.Errorf("failed to delete subscription: %w", err)
+	ctx, span := startSpan(ctx, "SubscriptionService.Delete")
+	defer span.End()
+
+	return s.withinTransaction(ctx, func(ctx context.Context) error {
+		// Событие содержит удаленную подписку, чтобы его можно было отнести к пользователю
+		sub, err := s.repo.Get(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete subscription: %w", err)
+		}
+		if err := s.policy.Authorize(ctx, ActionModify, sub.UserID); err != nil {
+			return fmt.Errorf("failed to delete subscription: %w", err)
+		}
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete subscription: %w", err)
+		}
+		if err := s.record(ctx, audit.OperationDelete, sub, nil); err != nil {
+			return err
+		}
+		return s.publish(ctx, event.SubscriptionDeleted, sub)
+	})
+}
+
+// Restore восстанавливает удаленную подписку, еще не очищенную по сроку хранения.
+// Доступ проверяется по владельцу удаленной подписки до изменения, поэтому
+// запрещенное восстановление ничего не меняет в любом хранилище
+func (s *SubscriptionService) Restore(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.Restore")
+	defer span.End()
+
+	var sub *subscription.Subscription
+	err := s.withinTransaction(ctx, func(ctx context.Context) error {
+		deleted, err := s.repo.GetDeleted(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to restore subscription: %w", err)
+		}
+		if err := s.policy.Authorize(ctx, ActionModify, deleted.UserID); err != nil {
+			return fmt.Errorf("failed to restore subscription: %w", err)
+		}
+		if err := s.repo.Restore(ctx, id); err != nil {
+			return fmt.Errorf("failed to restore subscription: %w", err)
+		}
+
+		if sub, err = s.repo.Get(ctx, id); err != nil {
+			return fmt.Errorf("failed to get restored subscription: %w", err)
+		}
+		if err := s.record(ctx, audit.OperationRestore, nil, sub); err != nil {
+			return err
+		}
+		return s.publish(ctx, event.SubscriptionRestored, sub)
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return sub, nil
 }
 
-// List возвращает список всех подписок
-func (s *SubscriptionService) List(ctx context.Context) ([]*subscription.Subscription, error) {
-	subs, err := s.repo.List(ctx)
+// List возвращает список подписок, удовлетворяющих фильтру
+func (s *SubscriptionService) List(ctx context.Context, filter subscription.ListFilter) ([]*subscription.Subscription, error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.List")
+	defer span.End()
+
+	userID, err := s.policy.ScopeUser(ctx, filter.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.ScopeDeleted(ctx, filter.IncludeDeleted); err != nil {
+		return nil, err
+	}
+	filter.UserID = userID
+
+	subs, err := s.repo.List(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
 	}
 	return subs, nil
 }
 
+// Export построчно передает подписки, удовлетворяющие фильтру, в fn
+func (s *SubscriptionService) Export(ctx context.Context, filter subscription.ListFilter, fn func(*subscription.Subscription) error) error {
+	ctx, span := startSpan(ctx, "SubscriptionService.Export")
+	defer span.End()
+
+	userID, err := s.policy.ScopeUser(ctx, filter.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.policy.ScopeDeleted(ctx, filter.IncludeDeleted); err != nil {
+		return err
+	}
+	filter.UserID = userID
+
+	if err := s.repo.Stream(ctx, filter, fn); err != nil {
+		return fmt.Errorf("failed to export subscriptions: %w", err)
+	}
+	return nil
+}
+
 // CalculateTotalCost рассчитывает общую стоимость подписок за период
 func (s *SubscriptionService) CalculateTotalCost(ctx context.Context, filter subscription.SubscriptionFilter) (*subscription.TotalCostResponse, error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.CalculateTotalCost")
+	defer span.End()
+
+	userID, err := s.policy.ScopeUser(ctx, filter.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.ScopeDeleted(ctx, filter.IncludeDeleted); err != nil {
+		return nil, err
+	}
+	filter.UserID = userID
+
 	totalCost, err := s.repo.CalculateTotalCost(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate total cost: %w", err)
@@ -150,3 +372,77 @@ func (s *SubscriptionService) CalculateTotalCost(ctx context.Context, filter sub
 		TotalCost: totalCost,
 	}, nil
 }
+
+// SummarizeByService возвращает сводку подписок по каждому сервису
+func (s *SubscriptionService) SummarizeByService(ctx context.Context, filter subscription.SummaryFilter) ([]*subscription.ServiceSummary, error) {
+	ctx, span := startSpan(ctx, "SubscriptionService.SummarizeByService")
+	defer span.End()
+
+	userID, err := s.policy.ScopeUser(ctx, filter.UserID)
+	if err != nil {
+		return nil, err
+	}
+	filter.UserID = userID
+
+	summaries, err := s.repo.SummarizeByService(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize subscriptions: %w", err)
+	}
+	return summaries, nil
+}
+
+// withinTransaction выполняет fn в транзакции, если подключен менеджер транзакций
+func (s *SubscriptionService) withinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.tx == nil {
+		return fn(ctx)
+	}
+	return s.tx.WithinTransaction(ctx, fn)
+}
+
+// publish публикует событие, если подключен издатель. Ошибка публикации
+// возвращается, чтобы откатить транзакцию вместе с изменением
+func (s *SubscriptionService) publish(ctx context.Context, eventType event.Type, data interface{}) error {
+	if s.publisher == nil {
+		return nil
+	}
+
+	evt, err := event.New(eventType, data)
+	if err != nil {
+		return err
+	}
+	evt.OrganizationID = tenant.FromContext(ctx)
+	if err := s.publisher.Publish(ctx, evt); err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// record добавляет изменение подписки в журнал аудита, если он подключен.
+// Ошибка записи возвращается, чтобы изменение не осталось без следа
+func (s *SubscriptionService) record(ctx context.Context, operation audit.Operation, before, after *subscription.Subscription) error {
+	if s.audit == nil {
+		return nil
+	}
+
+	entry, err := audit.NewEntry(operation, before, after)
+	if err != nil {
+		return err
+	}
+	entry.Actor = actor.FromContext(ctx)
+	entry.RequestID = requestid.FromContext(ctx)
+
+	if err := s.audit.Add(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record %s audit entry: %w", operation, err)
+	}
+	return nil
+}
+
+// invalidMonthYear возвращает ошибку валидации поля с датой в формате MM-YYYY
+func invalidMonthYear(field string) error {
+	return subscription.NewValidationError(field, subscription.CodeMonthYear, "must be in MM-YYYY format")
+}
+
+// errEndBeforeStart возвращает ошибку валидации для даты окончания раньше даты начала
+func errEndBeforeStart() error {
+	return subscription.NewValidationError("end_date", subscription.CodeEndBeforeStart, "end date cannot be before start date")
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
 	"time"
@@ -9,6 +10,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/subscription-service/internal/auth"
+	"github.com/subscription-service/internal/domain/audit"
+	"github.com/subscription-service/internal/domain/event"
 	"github.com/subscription-service/internal/domain/subscription"
 )
 
@@ -30,6 +34,22 @@ func (m *MockRepository) Get(ctx context.Context, id uuid.UUID) (*subscription.S
 	return args.Get(0).(*subscription.Subscription), args.Error(1)
 }
 
+func (m *MockRepository) GetAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (*subscription.Subscription, error) {
+	args := m.Called(ctx, id, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
+func (m *MockRepository) GetDeleted(ctx context.Context, id uuid.UUID) (*subscription.Subscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*subscription.Subscription), args.Error(1)
+}
+
 func (m *MockRepository) Update(ctx context.Context, sub *subscription.Subscription) error {
 	args := m.Called(ctx, sub)
 	return args.Error(0)
@@ -40,16 +60,39 @@ func (m *MockRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
-func (m *MockRepository) List(ctx context.Context) ([]*subscription.Subscription, error) {
-	args := m.Called(ctx)
+func (m *MockRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Purge(ctx context.Context, deletedBefore time.Time, limit int) (int, error) {
+	args := m.Called(ctx, deletedBefore, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) List(ctx context.Context, filter subscription.ListFilter) ([]*subscription.Subscription, error) {
+	args := m.Called(ctx, filter)
 	return args.Get(0).([]*subscription.Subscription), args.Error(1)
 }
 
+func (m *MockRepository) Stream(ctx context.Context, filter subscription.ListFilter, fn func(*subscription.Subscription) error) error {
+	args := m.Called(ctx, filter, fn)
+	return args.Error(0)
+}
+
 func (m *MockRepository) CalculateTotalCost(ctx context.Context, filter subscription.SubscriptionFilter) (int, error) {
 	args := m.Called(ctx, filter)
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockRepository) SummarizeByService(ctx context.Context, filter subscription.SummaryFilter) ([]*subscription.ServiceSummary, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*subscription.ServiceSummary), args.Error(1)
+}
+
 func TestSubscriptionService_Create(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewSubscriptionService(mockRepo)
@@ -107,6 +150,21 @@ func TestSubscriptionService_Create(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		assert.Contains(t, err.Error(), "invalid start date")
+		assert.ErrorIs(t, err, subscription.ErrInvalidInput)
+	})
+
+	t.Run("дата окончания раньше даты начала", func(t *testing.T) {
+		endDate := "06-2023"
+		invalidReq := createReq
+		invalidReq.EndDate = &endDate
+
+		result, err := service.Create(ctx, invalidReq)
+
+		assert.Nil(t, result)
+		var validationErr *subscription.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "end_date", validationErr.Field)
+		assert.Equal(t, subscription.CodeEndBeforeStart, validationErr.Code)
 	})
 }
 
@@ -157,3 +215,320 @@ func TestSubscriptionService_CalculateTotalCost(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestSubscriptionService_Export(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewSubscriptionService(mockRepo)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	filter := subscription.ListFilter{UserID: &userID}
+
+	t.Run("успешная выгрузка", func(t *testing.T) {
+		// Репозиторий передает в колбэк две подписки
+		mockRepo.On("Stream", ctx, filter, mock.Anything).Run(func(args mock.Arguments) {
+			fn := args.Get(2).(func(*subscription.Subscription) error)
+			_ = fn(&subscription.Subscription{ID: uuid.New(), UserID: userID})
+			_ = fn(&subscription.Subscription{ID: uuid.New(), UserID: userID})
+		}).Return(nil).Once()
+
+		var exported int
+		err := service.Export(ctx, filter, func(*subscription.Subscription) error {
+			exported++
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, exported)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ошибка репозитория", func(t *testing.T) {
+		mockRepo.On("Stream", ctx, filter, mock.Anything).Return(errors.New("database error")).Once()
+
+		err := service.Export(ctx, filter, func(*subscription.Subscription) error { return nil })
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to export subscriptions")
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// recordingPublisher запоминает опубликованные события
+type recordingPublisher struct {
+	events []event.Event
+	err    error
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, evt event.Event) error {
+	p.events = append(p.events, evt)
+	return p.err
+}
+
+// types возвращает типы опубликованных событий по порядку
+func (p *recordingPublisher) types() []event.Type {
+	types := make([]event.Type, 0, len(p.events))
+	for _, evt := range p.events {
+		types = append(types, evt.Type)
+	}
+	return types
+}
+
+// recordingTxManager считает зафиксированные и откаченные транзакции
+type recordingTxManager struct {
+	committed  int
+	rolledBack int
+}
+
+func (m *recordingTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		m.rolledBack++
+		return err
+	}
+	m.committed++
+	return nil
+}
+
+func TestSubscriptionService_Events(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("создание публикует subscription.created", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		publisher := &recordingPublisher{}
+		service := NewSubscriptionService(mockRepo, WithEventPublisher(publisher))
+
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*subscription.Subscription")).Run(func(args mock.Arguments) {
+			args.Get(1).(*subscription.Subscription).ID = uuid.New()
+		}).Return(nil).Once()
+
+		sub, err := service.Create(ctx, subscription.CreateSubscriptionRequest{
+			ServiceName: "Netflix",
+			Price:       400,
+			UserID:      userID,
+			StartDate:   "07-2023",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []event.Type{event.SubscriptionCreated}, publisher.types())
+
+		var data subscription.Subscription
+		assert.NoError(t, json.Unmarshal(publisher.events[0].Data, &data))
+		assert.Equal(t, sub.ID, data.ID)
+		assert.NotEqual(t, uuid.Nil, publisher.events[0].ID)
+	})
+
+	t.Run("установка даты окончания публикует subscription.cancelled", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		publisher := &recordingPublisher{}
+		service := NewSubscriptionService(mockRepo, WithEventPublisher(publisher))
+
+		id := uuid.New()
+		mockRepo.On("Get", ctx, id).Return(&subscription.Subscription{
+			ID:          id,
+			ServiceName: "Netflix",
+			Price:       400,
+			UserID:      userID,
+			StartDate:   time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+		}, nil).Once()
+		mockRepo.On("Update", ctx, mock.AnythingOfType("*subscription.Subscription")).Return(nil).Once()
+
+		endDate := "12-2023"
+		_, err := service.Update(ctx, id, subscription.UpdateSubscriptionRequest{EndDate: &endDate})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []event.Type{event.SubscriptionUpdated, event.SubscriptionCancelled}, publisher.types())
+	})
+
+	t.Run("удаление публикует subscription.deleted", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		publisher := &recordingPublisher{}
+		service := NewSubscriptionService(mockRepo, WithEventPublisher(publisher))
+
+		id := uuid.New()
+		mockRepo.On("Get", mock.Anything, id).Return(&subscription.Subscription{ID: id, UserID: userID}, nil).Once()
+		mockRepo.On("Delete", ctx, id).Return(nil).Once()
+
+		assert.NoError(t, service.Delete(ctx, id))
+		assert.Equal(t, []event.Type{event.SubscriptionDeleted}, publisher.types())
+		var data subscription.Subscription
+		assert.NoError(t, json.Unmarshal(publisher.events[0].Data, &data))
+		assert.Equal(t, id, data.ID)
+		assert.Equal(t, userID, data.UserID)
+	})
+
+	t.Run("восстановление публикует subscription.restored и пишется в журнал", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		publisher := &recordingPublisher{}
+		auditLog := &recordingAuditLog{}
+		service := NewSubscriptionService(mockRepo, WithEventPublisher(publisher), WithAuditLog(auditLog))
+
+		id := uuid.New()
+		deletedAt := time.Now()
+		mockRepo.On("GetDeleted", ctx, id).Return(&subscription.Subscription{ID: id, UserID: userID, DeletedAt: &deletedAt}, nil).Once()
+		mockRepo.On("Restore", ctx, id).Return(nil).Once()
+		mockRepo.On("Get", ctx, id).Return(&subscription.Subscription{ID: id, UserID: userID, Price: 400}, nil).Once()
+
+		sub, err := service.Restore(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, id, sub.ID)
+		assert.Equal(t, []event.Type{event.SubscriptionRestored}, publisher.types())
+
+		assert.Len(t, auditLog.entries, 1)
+		assert.Equal(t, audit.OperationRestore, auditLog.entries[0].Operation)
+		assert.Nil(t, auditLog.entries[0].Before)
+	})
+
+	t.Run("восстановление неудаленной подписки не публикует событие", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		publisher := &recordingPublisher{}
+		service := NewSubscriptionService(mockRepo, WithEventPublisher(publisher))
+
+		id := uuid.New()
+		mockRepo.On("GetDeleted", ctx, id).Return(nil, subscription.ErrSubscriptionNotDeleted).Once()
+
+		_, err := service.Restore(ctx, id)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotDeleted)
+		assert.Empty(t, publisher.events)
+		mockRepo.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything)
+	})
+
+	t.Run("изменение и событие выполняются в одной транзакции", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		publisher := &recordingPublisher{}
+		tx := &recordingTxManager{}
+		service := NewSubscriptionService(mockRepo, WithEventPublisher(publisher), WithTransactionManager(tx))
+
+		id := uuid.New()
+		mockRepo.On("Get", mock.Anything, id).Return(&subscription.Subscription{ID: id, UserID: userID}, nil).Once()
+		mockRepo.On("Delete", mock.Anything, id).Return(nil).Once()
+
+		assert.NoError(t, service.Delete(ctx, id))
+		assert.Equal(t, 1, tx.committed)
+		assert.Equal(t, 0, tx.rolledBack)
+	})
+
+	t.Run("ошибка публикации откатывает изменение", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		publisher := &recordingPublisher{err: errors.New("outbox unavailable")}
+		tx := &recordingTxManager{}
+		service := NewSubscriptionService(mockRepo, WithEventPublisher(publisher), WithTransactionManager(tx))
+
+		id := uuid.New()
+		mockRepo.On("Get", mock.Anything, id).Return(&subscription.Subscription{ID: id, UserID: userID}, nil).Once()
+		mockRepo.On("Delete", mock.Anything, id).Return(nil).Once()
+
+		err := service.Delete(ctx, id)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to publish subscription.deleted event")
+		assert.Equal(t, 0, tx.committed)
+		assert.Equal(t, 1, tx.rolledBack)
+	})
+
+	t.Run("ошибка репозитория не публикует событие", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		publisher := &recordingPublisher{}
+		service := NewSubscriptionService(mockRepo, WithEventPublisher(publisher))
+
+		id := uuid.New()
+		mockRepo.On("Get", ctx, id).Return(nil, subscription.ErrSubscriptionNotFound).Once()
+
+		assert.Error(t, service.Delete(ctx, id))
+		assert.Empty(t, publisher.events)
+	})
+}
+
+func TestSubscriptionService_UserScope(t *testing.T) {
+	userID := uuid.New()
+	otherID := uuid.New()
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: userID, Scopes: auth.UserScopes})
+	own := &subscription.Subscription{ID: uuid.New(), UserID: userID, StartDate: time.Now()}
+	foreign := &subscription.Subscription{ID: uuid.New(), UserID: otherID, StartDate: time.Now()}
+
+	t.Run("чужая подписка не найдена", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("Get", ctx, own.ID).Return(own, nil)
+		mockRepo.On("Get", mock.Anything, foreign.ID).Return(foreign, nil)
+		service := NewSubscriptionService(mockRepo)
+
+		got, err := service.Get(ctx, own.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, own, got)
+
+		_, err = service.Get(ctx, foreign.ID)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+
+		price := 500
+		_, err = service.Update(ctx, foreign.ID, subscription.UpdateSubscriptionRequest{Price: &price})
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+
+		err = service.Delete(ctx, foreign.ID)
+		assert.ErrorIs(t, err, subscription.ErrSubscriptionNotFound)
+
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("выборки ограничены своими подписками", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("List", ctx, subscription.ListFilter{UserID: &userID}).Return([]*subscription.Subscription{own}, nil).Once()
+		mockRepo.On("CalculateTotalCost", ctx, mock.MatchedBy(func(filter subscription.SubscriptionFilter) bool {
+			return filter.UserID != nil && *filter.UserID == userID
+		})).Return(400, nil).Once()
+		mockRepo.On("SummarizeByService", ctx, subscription.SummaryFilter{UserID: &userID}).
+			Return([]*subscription.ServiceSummary{}, nil).Once()
+		service := NewSubscriptionService(mockRepo)
+
+		subs, err := service.List(ctx, subscription.ListFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, subs, 1)
+
+		_, err = service.CalculateTotalCost(ctx, subscription.SubscriptionFilter{})
+		assert.NoError(t, err)
+
+		_, err = service.SummarizeByService(ctx, subscription.SummaryFilter{})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("запрос подписок другого пользователя запрещен", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewSubscriptionService(mockRepo)
+
+		_, err := service.List(ctx, subscription.ListFilter{UserID: &otherID})
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+
+		_, err = service.CalculateTotalCost(ctx, subscription.SubscriptionFilter{UserID: &otherID})
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+
+		_, err = service.SummarizeByService(ctx, subscription.SummaryFilter{UserID: &otherID})
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+
+		_, err = service.Create(ctx, subscription.CreateSubscriptionRequest{
+			ServiceName: "Netflix",
+			Price:       400,
+			UserID:      otherID,
+			StartDate:   "07-2023",
+		})
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("администратор и ключи сервисов не ограничены", func(t *testing.T) {
+		for _, principal := range []*auth.Principal{
+			{UserID: userID, Scopes: []auth.Scope{auth.ScopeAdmin}},
+			{Subject: "api-key:reports", Scopes: []auth.Scope{auth.ScopeReportsRead}},
+		} {
+			ctx := auth.WithPrincipal(context.Background(), principal)
+			mockRepo := new(MockRepository)
+			mockRepo.On("Get", ctx, foreign.ID).Return(foreign, nil).Once()
+			mockRepo.On("List", ctx, subscription.ListFilter{UserID: &otherID}).Return([]*subscription.Subscription{foreign}, nil).Once()
+			service := NewSubscriptionService(mockRepo)
+
+			_, err := service.Get(ctx, foreign.ID)
+			assert.NoError(t, err)
+			_, err = service.List(ctx, subscription.ListFilter{UserID: &otherID})
+			assert.NoError(t, err)
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName - имя инструментирующей библиотеки span сервисов
+const tracerName = "github.com/subscription-service/internal/usecase"
+
+// startSpan начинает span операции сервиса; вызывающий завершает его через
+// defer span.End(). Несохраняемый span (трассировка выключена или не попала
+// в выборку) не добавляется в контекст: контекст вызывающего уже несет ту же
+// трассировку, и репозитории получают его без изменений
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, name)
+	if !span.IsRecording() {
+		return ctx, span
+	}
+	return spanCtx, span
+}
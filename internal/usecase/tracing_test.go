@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/domain/subscription"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSubscriptionService_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "GET /api/v1/subscriptions/calculate-cost")
+	filter := subscription.SubscriptionFilter{
+		StartPeriod: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndPeriod:   time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Репозиторий получает контекст со span сервиса
+	var repoSpan trace.SpanContext
+	mockRepo := new(MockRepository)
+	mockRepo.On("CalculateTotalCost", mock.MatchedBy(func(ctx context.Context) bool {
+		repoSpan = trace.SpanContextFromContext(ctx)
+		return true
+	}), filter).Return(100, nil).Once()
+
+	_, err := NewSubscriptionService(mockRepo).CalculateTotalCost(ctx, filter)
+	require.NoError(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "SubscriptionService.CalculateTotalCost", span.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.Equal(t, span.SpanContext().SpanID(), repoSpan.SpanID())
+}
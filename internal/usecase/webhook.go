@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/webhook"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// webhookSecretSize - размер генерируемого секрета подписи в байтах
+const webhookSecretSize = 32
+
+// WebhookService реализует управление webhook-получателями и публикует
+// события, ставя их доставки в очередь
+type WebhookService struct {
+	repo webhook.Repository
+}
+
+// NewWebhookService создает новый экземпляр сервиса webhook-уведомлений
+func NewWebhookService(repo webhook.Repository) *WebhookService {
+	return &WebhookService{repo: repo}
+}
+
+// CreateEndpoint регистрирует нового получателя
+func (s *WebhookService) CreateEndpoint(ctx context.Context, req webhook.CreateEndpointRequest) (*webhook.Endpoint, error) {
+	events := make([]event.Type, 0, len(req.Events))
+	for _, name := range req.Events {
+		eventType := event.Type(name)
+		if !eventType.Valid() {
+			return nil, fmt.Errorf("%w: %q", webhook.ErrUnknownEventType, name)
+		}
+		events = append(events, eventType)
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := generateSecret()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = generated
+	}
+
+	endpoint := &webhook.Endpoint{
+		URL:    req.URL,
+		Secret: secret,
+		Events: events,
+	}
+
+	if err := s.repo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+// GetEndpoint возвращает получателя по ID без секрета
+func (s *WebhookService) GetEndpoint(ctx context.Context, id uuid.UUID) (*webhook.Endpoint, error) {
+	endpoint, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	endpoint.Secret = ""
+	return endpoint, nil
+}
+
+// ListEndpoints возвращает всех получателей без секретов
+func (s *WebhookService) ListEndpoints(ctx context.Context) ([]*webhook.Endpoint, error) {
+	endpoints, err := s.repo.ListEndpoints(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	for _, endpoint := range endpoints {
+		endpoint.Secret = ""
+	}
+	return endpoints, nil
+}
+
+// DeleteEndpoint удаляет получателя вместе с журналом его доставок
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.DeleteEndpoint(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries возвращает журнал доставок получателя
+func (s *WebhookService) ListDeliveries(ctx context.Context, endpointID uuid.UUID, filter webhook.DeliveryFilter) ([]*webhook.Delivery, error) {
+	// Проверяем существование получателя, чтобы отличить пустой журнал от неизвестного ID
+	if _, err := s.repo.GetEndpoint(ctx, endpointID); err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, endpointID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Redeliver создает новую доставку того же события. ID события сохраняется,
+// поэтому получатель может распознать повтор
+func (s *WebhookService) Redeliver(ctx context.Context, deliveryID uuid.UUID) (*webhook.Delivery, error) {
+	original, err := s.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	delivery := newDelivery(original.EndpointID, original.EventID, original.EventType, original.Payload)
+	delivery.RedeliveryOf = &original.ID
+	if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// Publish ставит событие в очередь доставки всем подписанным на него
+// получателям организации, в которой произошло событие. Повторная публикация
+// того же события, например после сбоя, не создает получателям, которым
+// событие уже поставлено в очередь, вторую доставку
+func (s *WebhookService) Publish(ctx context.Context, evt event.Event) error {
+	ctx = tenant.WithOrganization(ctx, evt.OrganizationID)
+	endpoints, err := s.repo.ListEndpointsForEvent(ctx, evt.Type)
+	if err != nil {
+		return fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	for _, endpoint := range endpoints {
+		delivery := newDelivery(endpoint.ID, evt.ID, evt.Type, payload)
+		if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to create webhook delivery: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// newDelivery создает доставку, готовую к немедленной отправке
+func newDelivery(endpointID, eventID uuid.UUID, eventType event.Type, payload json.RawMessage) *webhook.Delivery {
+	now := time.Now()
+	return &webhook.Delivery{
+		EndpointID:    endpointID,
+		EventID:       eventID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        webhook.DeliveryPending,
+		NextAttemptAt: &now,
+	}
+}
+
+// generateSecret генерирует случайный секрет подписи
+func generateSecret() (string, error) {
+	return randomHex(webhookSecretSize)
+}
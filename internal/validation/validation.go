@@ -0,0 +1,42 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/internal/i18n"
+)
+
+var (
+	once     sync.Once
+	validate *validator.Validate
+)
+
+// Validator возвращает общий для всех транспортов валидатор запросов. Он
+// называет поля так же, как они называются в JSON, чтобы клиент мог
+// сопоставить ошибку с полем формы, и переводит сообщения на поддерживаемые языки
+func Validator() *validator.Validate {
+	once.Do(func() {
+		v := validator.New()
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+
+		if err := i18n.RegisterValidationTranslations(v); err != nil {
+			log.Error().Err(err).Msg("Failed to register validation translations")
+		}
+
+		validate = v
+	})
+	return validate
+}
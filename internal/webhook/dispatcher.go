@@ -0,0 +1,239 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/subscription-service/internal/backoff"
+	"github.com/subscription-service/internal/domain/webhook"
+	"github.com/subscription-service/internal/tenant"
+)
+
+// Заголовки запроса доставки
+const (
+	// HeaderID содержит ID события; одинаков у всех доставок и повторов события
+	HeaderID = "X-Webhook-ID"
+	// HeaderDelivery содержит ID доставки
+	HeaderDelivery = "X-Webhook-Delivery"
+	// HeaderEvent содержит тип события
+	HeaderEvent = "X-Webhook-Event"
+	// HeaderTimestamp содержит время отправки в секундах Unix
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature содержит подпись вида sha256=<hex>
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// maxErrorBodySize ограничивает фрагмент ответа получателя, сохраняемый в журнал
+const maxErrorBodySize = 512
+
+// Config хранит настройки отправки webhook-уведомлений
+type Config struct {
+	// PollInterval - период опроса очереди доставок
+	PollInterval time.Duration
+	// BatchSize - максимальное число доставок, обрабатываемых за один опрос
+	BatchSize int
+	// Timeout ограничивает время одного запроса к получателю
+	Timeout time.Duration
+	// MaxAttempts - число попыток, после которого доставка считается неудачной
+	MaxAttempts int
+	// BackoffBase - задержка перед второй попыткой; каждая следующая удваивается
+	BackoffBase time.Duration
+	// BackoffMax ограничивает задержку между попытками
+	BackoffMax time.Duration
+	// Lease - время, на которое выбранная доставка скрывается от других
+	// обработчиков. Должно превышать Timeout: доставки выбираются по одной,
+	// и аренда покрывает один запрос к получателю
+	Lease time.Duration
+}
+
+// Dispatcher отправляет доставки из очереди получателям
+type Dispatcher struct {
+	repo   webhook.Repository
+	client *http.Client
+	config Config
+	now    func() time.Time
+}
+
+// NewDispatcher создает новый экземпляр обработчика очереди доставок
+func NewDispatcher(repo webhook.Repository, config Config) *Dispatcher {
+	// Аренда короче запроса отдала бы доставку другому экземпляру во время
+	// отправки, и получатель получил бы ее дважды
+	if config.Lease <= config.Timeout {
+		lease := 2 * config.Timeout
+		log.Warn().Dur("lease", config.Lease).Dur("timeout", config.Timeout).Dur("using", lease).
+			Msg("Webhook lease does not cover the request timeout, extending it")
+		config.Lease = lease
+	}
+
+	return &Dispatcher{
+		repo:   repo,
+		client: &http.Client{Timeout: config.Timeout},
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Run опрашивает очередь доставок до отмены контекста
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DispatchDue(ctx); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to dispatch webhook deliveries")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue отправляет до BatchSize доставок, время попытки которых
+// наступило. Доставки выбираются по одной непосредственно перед отправкой:
+// аренда, взятая сразу на всю пачку, истекла бы у последних доставок раньше,
+// чем до них дойдет очередь
+func (d *Dispatcher) DispatchDue(ctx context.Context) error {
+	for i := 0; i < d.config.BatchSize; i++ {
+		deliveries, err := d.repo.ClaimDueDeliveries(ctx, d.now(), d.config.Lease, 1)
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		if err := d.dispatch(ctx, deliveries[0]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dispatch выполняет одну попытку доставки и сохраняет ее результат
+func (d *Dispatcher) dispatch(ctx context.Context, delivery *webhook.Delivery) error {
+	logger := log.With().
+		Str("delivery_id", delivery.ID.String()).
+		Str("endpoint_id", delivery.EndpointID.String()).
+		Str("event_type", string(delivery.EventType)).
+		Logger()
+
+	// Очередь общая для всех организаций: получатель ищется в организации доставки
+	endpoint, err := d.repo.GetEndpoint(tenant.WithOrganization(ctx, delivery.OrganizationID), delivery.EndpointID)
+	if err != nil {
+		if !errors.Is(err, webhook.ErrEndpointNotFound) {
+			return err
+		}
+		// Получатель удален, пока доставка ждала в очереди
+		d.fail(delivery, nil, "webhook endpoint not found")
+		return d.save(ctx, delivery)
+	}
+
+	delivery.Attempts++
+	statusCode, sendErr := d.send(ctx, endpoint, delivery)
+	switch {
+	case sendErr == nil:
+		now := d.now()
+		delivery.Status = webhook.DeliverySucceeded
+		delivery.LastStatusCode = &statusCode
+		delivery.LastError = nil
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+		logger.Info().Int("attempt", delivery.Attempts).Msg("Webhook delivered")
+	case delivery.Attempts >= d.config.MaxAttempts:
+		d.fail(delivery, statusCodePtr(statusCode), sendErr.Error())
+		logger.Warn().Err(sendErr).Int("attempt", delivery.Attempts).Msg("Webhook delivery failed, giving up")
+	default:
+		message := sendErr.Error()
+		next := d.now().Add(backoff.Exponential(d.config.BackoffBase, d.config.BackoffMax, delivery.Attempts))
+		delivery.LastStatusCode = statusCodePtr(statusCode)
+		delivery.LastError = &message
+		delivery.NextAttemptAt = &next
+		logger.Warn().Err(sendErr).Int("attempt", delivery.Attempts).Time("next_attempt_at", next).Msg("Webhook delivery failed, will retry")
+	}
+
+	return d.save(ctx, delivery)
+}
+
+// save сохраняет результат попытки. Если аренда истекла и доставку уже взял
+// другой обработчик, результат этой попытки отбрасывается: журнал ведет
+// обработчик, владеющий арендой
+func (d *Dispatcher) save(ctx context.Context, delivery *webhook.Delivery) error {
+	err := d.repo.UpdateDelivery(ctx, delivery)
+	if errors.Is(err, webhook.ErrLeaseLost) {
+		log.Warn().Str("delivery_id", delivery.ID.String()).Msg("Webhook delivery lease expired before the attempt was recorded")
+		return nil
+	}
+	return err
+}
+
+// fail помечает доставку как окончательно неудачную
+func (d *Dispatcher) fail(delivery *webhook.Delivery, statusCode *int, message string) {
+	delivery.Status = webhook.DeliveryFailed
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = &message
+	delivery.NextAttemptAt = nil
+}
+
+// send отправляет событие получателю. Успехом считается любой ответ 2xx
+func (d *Dispatcher) send(ctx context.Context, endpoint *webhook.Endpoint, delivery *webhook.Delivery) (int, error) {
+	timestamp := d.now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, delivery.EventID.String())
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	// Дочитываем тело, чтобы соединение можно было переиспользовать
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
+
+// Sign вычисляет подпись доставки: HMAC-SHA256 от строки "<timestamp>.<body>".
+// Получатель проверяет подпись, повторив вычисление со своим секретом
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// statusCodePtr возвращает указатель на код ответа или nil, если ответа не было
+func statusCodePtr(code int) *int {
+	if code == 0 {
+		return nil
+	}
+	return &code
+}
@@ -0,0 +1,431 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subscription-service/internal/domain/event"
+	"github.com/subscription-service/internal/domain/subscription"
+	"github.com/subscription-service/internal/domain/webhook"
+	"github.com/subscription-service/internal/repository/memory"
+	"github.com/subscription-service/internal/usecase"
+)
+
+// memoryRepository - хранилище webhook-получателей и доставок в памяти
+type memoryRepository struct {
+	mu         sync.Mutex
+	endpoints  map[uuid.UUID]*webhook.Endpoint
+	deliveries map[uuid.UUID]*webhook.Delivery
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{
+		endpoints:  map[uuid.UUID]*webhook.Endpoint{},
+		deliveries: map[uuid.UUID]*webhook.Delivery{},
+	}
+}
+
+func (r *memoryRepository) CreateEndpoint(_ context.Context, endpoint *webhook.Endpoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	endpoint.ID = uuid.New()
+	copied := *endpoint
+	r.endpoints[endpoint.ID] = &copied
+	return nil
+}
+
+func (r *memoryRepository) GetEndpoint(_ context.Context, id uuid.UUID) (*webhook.Endpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	endpoint, ok := r.endpoints[id]
+	if !ok {
+		return nil, webhook.ErrEndpointNotFound
+	}
+	copied := *endpoint
+	return &copied, nil
+}
+
+func (r *memoryRepository) ListEndpoints(ctx context.Context) ([]*webhook.Endpoint, error) {
+	return r.ListEndpointsForEvent(ctx, "")
+}
+
+func (r *memoryRepository) ListEndpointsForEvent(_ context.Context, eventType event.Type) ([]*webhook.Endpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var endpoints []*webhook.Endpoint
+	for _, endpoint := range r.endpoints {
+		if eventType == "" || endpoint.Accepts(eventType) {
+			copied := *endpoint
+			endpoints = append(endpoints, &copied)
+		}
+	}
+	return endpoints, nil
+}
+
+func (r *memoryRepository) DeleteEndpoint(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.endpoints, id)
+	return nil
+}
+
+func (r *memoryRepository) CreateDelivery(_ context.Context, delivery *webhook.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery.ID = uuid.New()
+	copied := *delivery
+	r.deliveries[delivery.ID] = &copied
+	return nil
+}
+
+func (r *memoryRepository) GetDelivery(_ context.Context, id uuid.UUID) (*webhook.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return nil, webhook.ErrDeliveryNotFound
+	}
+	copied := *delivery
+	return &copied, nil
+}
+
+func (r *memoryRepository) UpdateDelivery(_ context.Context, delivery *webhook.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *delivery
+	r.deliveries[delivery.ID] = &copied
+	return nil
+}
+
+func (r *memoryRepository) ListDeliveries(_ context.Context, endpointID uuid.UUID, _ webhook.DeliveryFilter) ([]*webhook.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deliveries []*webhook.Delivery
+	for _, delivery := range r.deliveries {
+		if delivery.EndpointID == endpointID {
+			copied := *delivery
+			deliveries = append(deliveries, &copied)
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *memoryRepository) ClaimDueDeliveries(_ context.Context, now time.Time, lease time.Duration, limit int) ([]*webhook.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []*webhook.Delivery
+	for _, delivery := range r.deliveries {
+		if len(claimed) == limit {
+			break
+		}
+		if delivery.Status != webhook.DeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		leased := now.Add(lease)
+		delivery.NextAttemptAt = &leased
+		copied := *delivery
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+// subscriptionRepository - минимальное хранилище подписок для сквозного теста
+type subscriptionRepository struct {
+	subscription.Repository
+}
+
+func (subscriptionRepository) Create(_ context.Context, sub *subscription.Subscription) error {
+	sub.ID = uuid.New()
+	return nil
+}
+
+// receivedRequest - запрос, принятый тестовым получателем
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver запускает получателя, который отвечает статусами из statuses по порядку,
+// а после их исчерпания - 200
+func newReceiver(t *testing.T, statuses ...int) (*httptest.Server, func() []receivedRequest) {
+	var (
+		mu       sync.Mutex
+		received []receivedRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, receivedRequest{header: r.Header.Clone(), body: body})
+		status := http.StatusOK
+		if len(received) <= len(statuses) {
+			status = statuses[len(received)-1]
+		}
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []receivedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]receivedRequest(nil), received...)
+	}
+}
+
+// testConfig - настройки с короткими задержками
+var testConfig = Config{
+	BatchSize:   10,
+	Timeout:     time.Second,
+	MaxAttempts: 3,
+	BackoffBase: time.Minute,
+	BackoffMax:  time.Hour,
+	Lease:       time.Minute,
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+	const secret = "0123456789abcdef0123456789abcdef"
+
+	t.Run("событие подписки доставляется с подписью", func(t *testing.T) {
+		server, received := newReceiver(t)
+		repo := newMemoryRepository()
+		webhooks := usecase.NewWebhookService(repo)
+		subscriptions := usecase.NewSubscriptionService(subscriptionRepository{}, usecase.WithEventPublisher(webhooks))
+
+		_, err := webhooks.CreateEndpoint(ctx, webhook.CreateEndpointRequest{
+			URL:    server.URL,
+			Secret: secret,
+			Events: []string{string(event.SubscriptionCreated)},
+		})
+		require.NoError(t, err)
+
+		sub, err := subscriptions.Create(ctx, subscription.CreateSubscriptionRequest{
+			ServiceName: "Netflix",
+			Price:       400,
+			UserID:      uuid.New(),
+			StartDate:   "07-2023",
+		})
+		require.NoError(t, err)
+
+		dispatcher := NewDispatcher(repo, testConfig)
+		require.NoError(t, dispatcher.DispatchDue(ctx))
+
+		requests := received()
+		require.Len(t, requests, 1)
+		header := requests[0].header
+		assert.Equal(t, "application/json", header.Get("Content-Type"))
+		assert.Equal(t, string(event.SubscriptionCreated), header.Get(HeaderEvent))
+
+		// Получатель проверяет подпись своим секретом
+		timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, Sign(secret, timestamp, requests[0].body), header.Get(HeaderSignature))
+
+		var evt event.Event
+		require.NoError(t, json.Unmarshal(requests[0].body, &evt))
+		assert.Equal(t, evt.ID.String(), header.Get(HeaderID))
+		var data subscription.Subscription
+		require.NoError(t, json.Unmarshal(evt.Data, &data))
+		assert.Equal(t, sub.ID, data.ID)
+
+		delivery, err := repo.GetDelivery(ctx, uuid.MustParse(header.Get(HeaderDelivery)))
+		require.NoError(t, err)
+		assert.Equal(t, webhook.DeliverySucceeded, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.NotNil(t, delivery.DeliveredAt)
+	})
+
+	t.Run("неподписанный получатель не получает событие", func(t *testing.T) {
+		server, received := newReceiver(t)
+		repo := newMemoryRepository()
+		webhooks := usecase.NewWebhookService(repo)
+
+		_, err := webhooks.CreateEndpoint(ctx, webhook.CreateEndpointRequest{
+			URL:    server.URL,
+			Events: []string{string(event.SubscriptionDeleted)},
+		})
+		require.NoError(t, err)
+
+		evt, err := event.New(event.SubscriptionCreated, map[string]string{})
+		require.NoError(t, err)
+		require.NoError(t, webhooks.Publish(ctx, evt))
+
+		require.NoError(t, NewDispatcher(repo, testConfig).DispatchDue(ctx))
+		assert.Empty(t, received())
+	})
+
+	t.Run("повторная публикация события не дублирует доставки", func(t *testing.T) {
+		// Ретранслятор outbox публикует событие повторно, если сбой произошел
+		// после того, как часть доставок уже создана
+		server, received := newReceiver(t)
+		repo := memory.NewWebhookRepository()
+		webhooks := usecase.NewWebhookService(repo)
+
+		var endpoints []uuid.UUID
+		for i := 0; i < 2; i++ {
+			endpoint, err := webhooks.CreateEndpoint(ctx, webhook.CreateEndpointRequest{URL: server.URL})
+			require.NoError(t, err)
+			endpoints = append(endpoints, endpoint.ID)
+		}
+
+		evt, err := event.New(event.SubscriptionCreated, map[string]string{"id": uuid.New().String()})
+		require.NoError(t, err)
+		require.NoError(t, webhooks.Publish(ctx, evt))
+		require.NoError(t, webhooks.Publish(ctx, evt))
+
+		for _, id := range endpoints {
+			deliveries, err := webhooks.ListDeliveries(ctx, id, webhook.DeliveryFilter{})
+			require.NoError(t, err)
+			assert.Len(t, deliveries, 1)
+		}
+
+		require.NoError(t, NewDispatcher(repo, testConfig).DispatchDue(ctx))
+		assert.Len(t, received(), 2)
+	})
+
+	t.Run("повтор с экспоненциальной задержкой и отказ после лимита попыток", func(t *testing.T) {
+		server, received := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable)
+		repo := newMemoryRepository()
+		webhooks := usecase.NewWebhookService(repo)
+
+		_, err := webhooks.CreateEndpoint(ctx, webhook.CreateEndpointRequest{URL: server.URL})
+		require.NoError(t, err)
+		evt, err := event.New(event.SubscriptionDeleted, map[string]string{"id": uuid.New().String()})
+		require.NoError(t, err)
+		require.NoError(t, webhooks.Publish(ctx, evt))
+
+		now := time.Now()
+		dispatcher := NewDispatcher(repo, testConfig)
+		dispatcher.now = func() time.Time { return now }
+
+		deliveryOf := func() *webhook.Delivery {
+			for _, delivery := range repo.deliveries {
+				copied := *delivery
+				return &copied
+			}
+			return nil
+		}
+
+		// Первая попытка: следующая через BackoffBase
+		require.NoError(t, dispatcher.DispatchDue(ctx))
+		delivery := deliveryOf()
+		assert.Equal(t, webhook.DeliveryPending, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, http.StatusInternalServerError, *delivery.LastStatusCode)
+		assert.Equal(t, now.Add(time.Minute), *delivery.NextAttemptAt)
+
+		// До наступления времени попытки доставка не отправляется
+		require.NoError(t, dispatcher.DispatchDue(ctx))
+		assert.Len(t, received(), 1)
+
+		// Вторая попытка: задержка удваивается
+		now = now.Add(time.Minute)
+		require.NoError(t, dispatcher.DispatchDue(ctx))
+		delivery = deliveryOf()
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.Equal(t, now.Add(2*time.Minute), *delivery.NextAttemptAt)
+
+		// Третья попытка исчерпывает лимит
+		now = now.Add(2 * time.Minute)
+		require.NoError(t, dispatcher.DispatchDue(ctx))
+		delivery = deliveryOf()
+		assert.Equal(t, webhook.DeliveryFailed, delivery.Status)
+		assert.Equal(t, 3, delivery.Attempts)
+		assert.Nil(t, delivery.NextAttemptAt)
+		assert.Contains(t, *delivery.LastError, "unexpected status 503")
+
+		requests := received()
+		require.Len(t, requests, 3)
+		// Все попытки несут один и тот же ID события
+		for _, req := range requests {
+			assert.Equal(t, evt.ID.String(), req.header.Get(HeaderID))
+		}
+
+		// Ручной повтор создает новую доставку того же события
+		redelivered, err := webhooks.Redeliver(ctx, delivery.ID)
+		require.NoError(t, err)
+		require.NoError(t, dispatcher.DispatchDue(ctx))
+
+		requests = received()
+		require.Len(t, requests, 4)
+		assert.Equal(t, evt.ID.String(), requests[3].header.Get(HeaderID))
+		assert.Equal(t, redelivered.ID.String(), requests[3].header.Get(HeaderDelivery))
+	})
+
+	t.Run("доставки выбираются по одной перед отправкой", func(t *testing.T) {
+		server, received := newReceiver(t)
+		repo := &claimRecorder{Repository: memory.NewWebhookRepository()}
+		webhooks := usecase.NewWebhookService(repo)
+
+		_, err := webhooks.CreateEndpoint(ctx, webhook.CreateEndpointRequest{URL: server.URL})
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			evt, err := event.New(event.SubscriptionCreated, map[string]string{"id": uuid.New().String()})
+			require.NoError(t, err)
+			require.NoError(t, webhooks.Publish(ctx, evt))
+		}
+
+		require.NoError(t, NewDispatcher(repo, testConfig).DispatchDue(ctx))
+
+		assert.Len(t, received(), 3)
+		// Три выборки с доставкой и последняя пустая
+		assert.Equal(t, []int{1, 1, 1, 1}, repo.limits)
+	})
+
+	t.Run("результат попытки с перехваченной арендой не сохраняется", func(t *testing.T) {
+		repo := memory.NewWebhookRepository()
+		webhooks := usecase.NewWebhookService(repo)
+		var now time.Time
+
+		// Пока получатель отвечает, аренда истекает и доставку выбирает другой экземпляр
+		var reclaimed []*webhook.Delivery
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var err error
+			reclaimed, err = repo.ClaimDueDeliveries(r.Context(), now.Add(2*testConfig.Lease), testConfig.Lease, 10)
+			assert.NoError(t, err)
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(server.Close)
+
+		endpoint, err := webhooks.CreateEndpoint(ctx, webhook.CreateEndpointRequest{URL: server.URL})
+		require.NoError(t, err)
+		evt, err := event.New(event.SubscriptionCreated, map[string]string{"id": uuid.New().String()})
+		require.NoError(t, err)
+		require.NoError(t, webhooks.Publish(ctx, evt))
+
+		now = time.Now()
+		dispatcher := NewDispatcher(repo, testConfig)
+		dispatcher.now = func() time.Time { return now }
+		require.NoError(t, dispatcher.DispatchDue(ctx))
+		require.Len(t, reclaimed, 1)
+
+		// Журнал ведет новый владелец аренды: попытка старого не записана
+		deliveries, err := repo.ListDeliveries(ctx, endpoint.ID, webhook.DeliveryFilter{})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, webhook.DeliveryPending, deliveries[0].Status)
+		assert.Zero(t, deliveries[0].Attempts)
+		assert.Equal(t, *reclaimed[0].ClaimedUntil, *deliveries[0].NextAttemptAt)
+	})
+}
+
+// claimRecorder запоминает размер каждой выборки очереди доставок
+type claimRecorder struct {
+	webhook.Repository
+	limits []int
+}
+
+func (r *claimRecorder) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*webhook.Delivery, error) {
+	r.limits = append(r.limits, limit)
+	return r.Repository.ClaimDueDeliveries(ctx, now, lease, limit)
+}
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_outbox_user_id;
+DROP INDEX IF EXISTS idx_outbox_seq;
+ALTER TABLE outbox DROP COLUMN IF EXISTS tx_id;
+ALTER TABLE outbox DROP COLUMN IF EXISTS seq;
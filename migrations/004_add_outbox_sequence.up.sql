@@ -0,0 +1,10 @@
+-- Порядковый номер события служит курсором журнала событий (Last-Event-ID)
+ALTER TABLE outbox ADD COLUMN seq BIGSERIAL;
+
+-- ID транзакции, записавшей событие. Журнал читает только события транзакций,
+-- завершенных до начала всех еще активных, поэтому событие с меньшим номером,
+-- зафиксированное позже, не будет пропущено
+ALTER TABLE outbox ADD COLUMN tx_id xid8 NOT NULL DEFAULT pg_current_xact_id();
+
+CREATE UNIQUE INDEX idx_outbox_seq ON outbox(seq);
+CREATE INDEX idx_outbox_user_id ON outbox((data->>'user_id'), seq);
@@ -0,0 +1,28 @@
+-- Журнал аудита изменений подписок. Внешнего ключа на subscriptions нет:
+-- история удаленной подписки должна сохраняться
+CREATE TABLE IF NOT EXISTS subscription_audit (
+    id UUID PRIMARY KEY,
+    subscription_id UUID NOT NULL,
+    operation VARCHAR(16) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+    before JSONB,
+    after JSONB,
+    changes JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_subscription_audit_subscription ON subscription_audit(subscription_id, created_at);
+CREATE INDEX idx_subscription_audit_created_at ON subscription_audit(created_at);
+CREATE INDEX idx_subscription_audit_actor ON subscription_audit(actor, created_at);
+
+-- Журнал только пополняется: изменение и удаление записей запрещены
+CREATE OR REPLACE FUNCTION subscription_audit_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'subscription_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER subscription_audit_append_only
+    BEFORE UPDATE OR DELETE ON subscription_audit
+    FOR EACH ROW EXECUTE FUNCTION subscription_audit_append_only();
@@ -0,0 +1,5 @@
+-- Мягкое удаление: удаленная подписка остается в таблице до истечения срока хранения
+ALTER TABLE subscriptions ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- Очистка выбирает удаленные подписки по времени удаления
+CREATE INDEX idx_subscriptions_deleted_at ON subscriptions(deleted_at) WHERE deleted_at IS NOT NULL;
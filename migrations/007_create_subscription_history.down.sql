@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS subscriptions_track_history ON subscriptions;
+DROP FUNCTION IF EXISTS subscriptions_track_history();
+DROP TABLE IF EXISTS subscription_history;
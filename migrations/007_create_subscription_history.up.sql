@@ -0,0 +1,61 @@
+-- Ревизии подписок для запросов на момент времени: каждая строка описывает
+-- состояние подписки в интервале [valid_from, valid_to). Текущей ревизии
+-- соответствует valid_to IS NULL. Внешнего ключа на subscriptions нет:
+-- история очищенной подписки должна сохраняться
+CREATE TABLE IF NOT EXISTS subscription_history (
+    revision BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL,
+    service_name VARCHAR(255) NOT NULL,
+    price INT NOT NULL,
+    user_id UUID NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_to TIMESTAMPTZ
+);
+
+CREATE INDEX idx_subscription_history_id ON subscription_history(id, valid_from);
+CREATE INDEX idx_subscription_history_validity ON subscription_history(valid_from, valid_to);
+CREATE UNIQUE INDEX idx_subscription_history_current ON subscription_history(id) WHERE valid_to IS NULL;
+
+-- Ревизии ведет триггер, поэтому история не зависит от того, какой код
+-- изменил таблицу. Время ревизии - начало транзакции, как у now()
+CREATE OR REPLACE FUNCTION subscriptions_track_history() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE subscription_history SET valid_to = now()
+        WHERE id = OLD.id AND valid_to IS NULL;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO subscription_history
+            (id, service_name, price, user_id, start_date, end_date, created_at, updated_at, deleted_at, valid_from)
+        VALUES
+            (NEW.id, NEW.service_name, NEW.price, NEW.user_id, NEW.start_date, NEW.end_date,
+             NEW.created_at, NEW.updated_at, NEW.deleted_at, now());
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER subscriptions_track_history
+    AFTER INSERT OR UPDATE OR DELETE ON subscriptions
+    FOR EACH ROW EXECUTE FUNCTION subscriptions_track_history();
+
+-- Изменения до появления истории неизвестны: текущее состояние считается
+-- действовавшим с момента создания, а удаленные подписки получают ревизию
+-- до удаления и ревизию с пометкой об удалении
+INSERT INTO subscription_history
+    (id, service_name, price, user_id, start_date, end_date, created_at, updated_at, deleted_at, valid_from, valid_to)
+SELECT id, service_name, price, user_id, start_date, end_date, created_at, updated_at, NULL, created_at, deleted_at
+FROM subscriptions;
+
+INSERT INTO subscription_history
+    (id, service_name, price, user_id, start_date, end_date, created_at, updated_at, deleted_at, valid_from)
+SELECT id, service_name, price, user_id, start_date, end_date, created_at, updated_at, deleted_at, deleted_at
+FROM subscriptions
+WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,26 @@
+CREATE OR REPLACE FUNCTION subscriptions_track_history() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE subscription_history SET valid_to = now()
+        WHERE id = OLD.id AND valid_to IS NULL;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO subscription_history
+            (id, service_name, price, user_id, start_date, end_date, created_at, updated_at, deleted_at, valid_from)
+        VALUES
+            (NEW.id, NEW.service_name, NEW.price, NEW.user_id, NEW.start_date, NEW.end_date,
+             NEW.created_at, NEW.updated_at, NEW.deleted_at, now());
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE webhook_endpoints DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE outbox DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE subscription_audit DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE subscription_history DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS organization_id;
@@ -0,0 +1,47 @@
+-- Организация, которой принадлежат данные. Данные, созданные до разделения на
+-- организации, относятся к организации по умолчанию (нулевой UUID)
+ALTER TABLE subscriptions ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE subscription_history ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE subscription_audit ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE outbox ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE webhook_endpoints ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE webhook_deliveries ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE api_keys ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+
+-- Все запросы клиентов ограничены организацией
+CREATE INDEX idx_subscriptions_organization ON subscriptions(organization_id, user_id);
+CREATE INDEX idx_subscription_history_organization ON subscription_history(organization_id, id);
+CREATE INDEX idx_subscription_audit_organization ON subscription_audit(organization_id, created_at);
+CREATE INDEX idx_outbox_organization ON outbox(organization_id, seq);
+CREATE INDEX idx_webhook_endpoints_organization ON webhook_endpoints(organization_id);
+CREATE INDEX idx_api_keys_organization ON api_keys(organization_id);
+
+-- Ревизия наследует организацию подписки
+CREATE OR REPLACE FUNCTION subscriptions_track_history() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE subscription_history SET valid_to = now()
+        WHERE id = OLD.id AND valid_to IS NULL;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO subscription_history
+            (id, organization_id, service_name, price, user_id, start_date, end_date,
+             created_at, updated_at, deleted_at, valid_from)
+        VALUES
+            (NEW.id, NEW.organization_id, NEW.service_name, NEW.price, NEW.user_id, NEW.start_date, NEW.end_date,
+             NEW.created_at, NEW.updated_at, NEW.deleted_at, now());
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Организация не задается по умолчанию: сервис всегда указывает ее явно
+ALTER TABLE subscriptions ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE subscription_history ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE subscription_audit ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE outbox ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE webhook_endpoints ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE webhook_deliveries ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE api_keys ALTER COLUMN organization_id DROP DEFAULT;
@@ -0,0 +1,15 @@
+DROP POLICY IF EXISTS organization_isolation ON api_keys;
+DROP POLICY IF EXISTS organization_isolation ON webhook_deliveries;
+DROP POLICY IF EXISTS organization_isolation ON webhook_endpoints;
+DROP POLICY IF EXISTS organization_isolation ON outbox;
+DROP POLICY IF EXISTS organization_isolation ON subscription_audit;
+DROP POLICY IF EXISTS organization_isolation ON subscription_history;
+DROP POLICY IF EXISTS organization_isolation ON subscriptions;
+
+ALTER TABLE api_keys DISABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_deliveries DISABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_endpoints DISABLE ROW LEVEL SECURITY;
+ALTER TABLE outbox DISABLE ROW LEVEL SECURITY;
+ALTER TABLE subscription_audit DISABLE ROW LEVEL SECURITY;
+ALTER TABLE subscription_history DISABLE ROW LEVEL SECURITY;
+ALTER TABLE subscriptions DISABLE ROW LEVEL SECURITY;
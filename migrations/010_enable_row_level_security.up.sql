@@ -0,0 +1,33 @@
+-- Политики разграничения строк по организации. Сервис ограничивает каждый
+-- запрос организацией сам, а политики защищают данные от других ролей базы,
+-- например ролей отчетов и аналитики: такая роль видит только строки
+-- организации, заданной в сеансе командой SET app.organization_id = '<uuid>',
+-- а без нее не видит ничего.
+--
+-- Политики защищают только от других ролей базы. FORCE ROW LEVEL SECURITY
+-- намеренно не включается: сервис подключается владельцем таблиц и не задает
+-- app.organization_id, а фоновые задачи работают со всеми организациями.
+-- Запросы самого сервиса разграничиваются только условиями organization_id,
+-- которые репозитории добавляют в каждый запрос
+ALTER TABLE subscriptions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE subscription_history ENABLE ROW LEVEL SECURITY;
+ALTER TABLE subscription_audit ENABLE ROW LEVEL SECURITY;
+ALTER TABLE outbox ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_endpoints ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_deliveries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE api_keys ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY organization_isolation ON subscriptions
+    USING (organization_id = NULLIF(current_setting('app.organization_id', true), '')::uuid);
+CREATE POLICY organization_isolation ON subscription_history
+    USING (organization_id = NULLIF(current_setting('app.organization_id', true), '')::uuid);
+CREATE POLICY organization_isolation ON subscription_audit
+    USING (organization_id = NULLIF(current_setting('app.organization_id', true), '')::uuid);
+CREATE POLICY organization_isolation ON outbox
+    USING (organization_id = NULLIF(current_setting('app.organization_id', true), '')::uuid);
+CREATE POLICY organization_isolation ON webhook_endpoints
+    USING (organization_id = NULLIF(current_setting('app.organization_id', true), '')::uuid);
+CREATE POLICY organization_isolation ON webhook_deliveries
+    USING (organization_id = NULLIF(current_setting('app.organization_id', true), '')::uuid);
+CREATE POLICY organization_isolation ON api_keys
+    USING (organization_id = NULLIF(current_setting('app.organization_id', true), '')::uuid);
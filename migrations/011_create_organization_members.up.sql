@@ -0,0 +1,15 @@
+-- Участники организаций и их роли. Пользователь без строки в таблице
+-- считается участником с ролью member
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'admin', 'member', 'viewer')),
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+ALTER TABLE organization_members ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY organization_isolation ON organization_members
+    USING (organization_id = NULLIF(current_setting('app.organization_id', true), '')::uuid);
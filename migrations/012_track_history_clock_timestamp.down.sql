@@ -0,0 +1,19 @@
+CREATE OR REPLACE FUNCTION subscriptions_track_history() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE subscription_history SET valid_to = now()
+        WHERE id = OLD.id AND valid_to IS NULL;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO subscription_history
+            (id, organization_id, service_name, price, user_id, start_date, end_date,
+             created_at, updated_at, deleted_at, valid_from)
+        VALUES
+            (NEW.id, NEW.organization_id, NEW.service_name, NEW.price, NEW.user_id, NEW.start_date, NEW.end_date,
+             NEW.created_at, NEW.updated_at, NEW.deleted_at, now());
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,28 @@
+-- Время ревизии берется из clock_timestamp(), а не из now(): now() возвращает
+-- начало транзакции, и при параллельных изменениях ревизии одной подписки
+-- могли записываться не по порядку, а ревизия - заканчиваться раньше, чем
+-- началась. Изменения одной подписки упорядочены блокировкой строки, поэтому
+-- время, взятое в триггере, растет вместе с ревизиями. Закрытие текущей
+-- ревизии и начало новой используют один момент, чтобы между ними не было
+-- промежутка
+CREATE OR REPLACE FUNCTION subscriptions_track_history() RETURNS trigger AS $$
+DECLARE
+    changed_at TIMESTAMPTZ := clock_timestamp();
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE subscription_history SET valid_to = changed_at
+        WHERE id = OLD.id AND valid_to IS NULL;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO subscription_history
+            (id, organization_id, service_name, price, user_id, start_date, end_date,
+             created_at, updated_at, deleted_at, valid_from)
+        VALUES
+            (NEW.id, NEW.organization_id, NEW.service_name, NEW.price, NEW.user_id, NEW.start_date, NEW.end_date,
+             NEW.created_at, NEW.updated_at, NEW.deleted_at, changed_at);
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,17 @@
+-- Ручной повтор доставки ссылается на доставку, которую повторяет. Первая
+-- доставка события получателю уникальна: повторная публикация события после
+-- сбоя ретранслятора outbox не создает получателю вторую доставку
+ALTER TABLE webhook_deliveries ADD COLUMN redelivery_of UUID;
+
+-- Доставки одного события, созданные до появления индекса, считаются
+-- повторами самой ранней из них
+UPDATE webhook_deliveries d SET redelivery_of = first.id
+FROM (
+    SELECT DISTINCT ON (endpoint_id, event_id) id, endpoint_id, event_id
+    FROM webhook_deliveries
+    ORDER BY endpoint_id, event_id, created_at, id
+) first
+WHERE d.endpoint_id = first.endpoint_id AND d.event_id = first.event_id AND d.id <> first.id;
+
+CREATE UNIQUE INDEX idx_webhook_deliveries_event ON webhook_deliveries(endpoint_id, event_id)
+    WHERE redelivery_of IS NULL;